@@ -0,0 +1,291 @@
+package goconcurrentqueue
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// AdaptiveLIFOMode defines what an AdaptiveLIFO does while it is overloaded
+type AdaptiveLIFOMode int
+
+const (
+	// AdaptiveLIFOServeNewest serves the newest element first (LIFO) while overloaded (default). The stale elements are
+	// served last, so the overload lasts until the queue gets drained.
+	AdaptiveLIFOServeNewest AdaptiveLIFOMode = iota
+	// AdaptiveLIFOShedOldest drops the elements waiting longer than the threshold (see SetShedHandler) and keeps
+	// serving the rest FIFO
+	AdaptiveLIFOShedOldest
+)
+
+// adaptiveLIFOElement is an element enqueued into an AdaptiveLIFO
+type adaptiveLIFOElement struct {
+	value      interface{}
+	enqueuedAt time.Time
+}
+
+// AdaptiveLIFO concurrent queue following the "adaptive LIFO" pattern for request-serving workloads: it is a FIFO
+// queue while it keeps up, but once the oldest element has been waiting longer than the threshold (overload) the
+// newest elements are served first, so the requests still within their deadline get answered (p99 latency) instead of
+// the ones whose callers probably gave up. Optionally the stale elements get dropped instead (AdaptiveLIFOShedOldest).
+// It goes back to FIFO as soon as the oldest element's wait is under the threshold: with AdaptiveLIFOShedOldest that
+// happens right after the stale elements get dropped, but with AdaptiveLIFOServeNewest the oldest element is served
+// last, so the queue stays overloaded until it gets drained (every stale element served).
+type AdaptiveLIFO struct {
+	// adaptiveLIFOElement, oldest first
+	ring        ringBuffer
+	threshold   time.Duration
+	mode        AdaptiveLIFOMode
+	shedHandler func(value interface{})
+	// elements dropped while overloaded (AdaptiveLIFOShedOldest)
+	shed        uint64
+	rwmutex     sync.RWMutex
+	lockRWmutex sync.RWMutex
+	isLocked    bool
+	// queue for watchers that will wait for next elements (if queue is empty at DequeueOrWaitForNextElement execution )
+	waitForNextElementChan chan chan interface{}
+}
+
+// NewAdaptiveLIFO returns a new AdaptiveLIFO concurrent queue, overloaded once its oldest element waits longer than
+// threshold
+func NewAdaptiveLIFO(threshold time.Duration) *AdaptiveLIFO {
+	ret := &AdaptiveLIFO{}
+	ret.initialize(threshold)
+
+	return ret
+}
+
+func (st *AdaptiveLIFO) initialize(threshold time.Duration) {
+	st.threshold = threshold
+	st.waitForNextElementChan = make(chan chan interface{}, WaitForNextElementChanCapacity)
+}
+
+// SetMode sets what to do while overloaded
+func (st *AdaptiveLIFO) SetMode(mode AdaptiveLIFOMode) {
+	st.rwmutex.Lock()
+	defer st.rwmutex.Unlock()
+
+	st.mode = mode
+}
+
+// SetShedHandler sets the function invoked with every element dropped while overloaded (AdaptiveLIFOShedOldest mode),
+// i.e. to answer the requests with an error. The handler runs at the goroutine whose operation dropped the elements,
+// out of the queue's lock. nil removes the handler.
+func (st *AdaptiveLIFO) SetShedHandler(handler func(value interface{})) {
+	st.rwmutex.Lock()
+	defer st.rwmutex.Unlock()
+
+	st.shedHandler = handler
+}
+
+// GetShed returns the number of elements dropped while overloaded (AdaptiveLIFOShedOldest mode)
+func (st *AdaptiveLIFO) GetShed() uint64 {
+	st.rwmutex.RLock()
+	defer st.rwmutex.RUnlock()
+
+	return st.shed
+}
+
+// IsOverloaded returns true whether the oldest element has been waiting longer than the threshold
+func (st *AdaptiveLIFO) IsOverloaded() bool {
+	st.rwmutex.RLock()
+	defer st.rwmutex.RUnlock()
+
+	return st.isOverloaded(time.Now())
+}
+
+// isOverloaded returns true whether the oldest element has been waiting longer than the threshold at the given moment.
+// st.rwmutex must be locked by the caller.
+func (st *AdaptiveLIFO) isOverloaded(now time.Time) bool {
+	if st.ring.length() == 0 {
+		return false
+	}
+
+	return now.Sub(st.ring.get(0).(adaptiveLIFOElement).enqueuedAt) > st.threshold
+}
+
+// Enqueue enqueues an element. Returns error if queue is locked.
+func (st *AdaptiveLIFO) Enqueue(value interface{}) error {
+	if st.IsLocked() {
+		return ErrLockedQueue
+	}
+
+	st.rwmutex.Lock()
+	defer st.rwmutex.Unlock()
+
+	// check if there is a listener waiting for the next element (this element)
+	select {
+	case listener := <-st.waitForNextElementChan:
+		select {
+		case listener <- value:
+			return nil
+		default:
+		}
+	default:
+	}
+
+	st.ring.pushBack(adaptiveLIFOElement{value: value, enqueuedAt: time.Now()})
+
+	return nil
+}
+
+// Dequeue dequeues an element: the oldest one, or the newest one while overloaded (see AdaptiveLIFOMode). Returns
+// error if queue is locked or empty.
+func (st *AdaptiveLIFO) Dequeue() (interface{}, error) {
+	if st.IsLocked() {
+		return nil, ErrLockedQueue
+	}
+
+	st.rwmutex.Lock()
+	value, ok, shed := st.dequeue(time.Now())
+	st.rwmutex.Unlock()
+
+	st.notifyShed(shed)
+
+	if !ok {
+		return nil, ErrEmptyQueue
+	}
+
+	return value, nil
+}
+
+// dequeue removes and returns the next element to serve at the given moment, along with the dropped elements (if any).
+// st.rwmutex must be locked by the caller.
+func (st *AdaptiveLIFO) dequeue(now time.Time) (interface{}, bool, []interface{}) {
+	var shed []interface{}
+
+	if st.isOverloaded(now) {
+		if st.mode == AdaptiveLIFOServeNewest {
+			return st.ring.removeAt(st.ring.length() - 1).(adaptiveLIFOElement).value, true, nil
+		}
+
+		for st.isOverloaded(now) {
+			shed = append(shed, st.ring.popFront().(adaptiveLIFOElement).value)
+		}
+		st.shed += uint64(len(shed))
+	}
+
+	if st.ring.length() == 0 {
+		return nil, false, shed
+	}
+
+	return st.ring.popFront().(adaptiveLIFOElement).value, true, shed
+}
+
+// notifyShed sends the dropped elements to the shed handler (if any)
+func (st *AdaptiveLIFO) notifyShed(shed []interface{}) {
+	if len(shed) == 0 {
+		return
+	}
+
+	st.rwmutex.RLock()
+	handler := st.shedHandler
+	st.rwmutex.RUnlock()
+
+	if handler == nil {
+		return
+	}
+
+	for _, value := range shed {
+		handler(value)
+	}
+}
+
+// DequeueOrWaitForNextElement dequeues an element (if exist) or waits until the next element gets enqueued and returns
+// it. Multiple calls to DequeueOrWaitForNextElement() would enqueue multiple "listeners" for future enqueued elements.
+func (st *AdaptiveLIFO) DequeueOrWaitForNextElement() (interface{}, error) {
+	return st.DequeueOrWaitForNextElementWithContext(context.Background())
+}
+
+// DequeueOrWaitForNextElementWithContext works as DequeueOrWaitForNextElement, but it stops waiting once the context
+// is done, returning ctx.Err().
+func (st *AdaptiveLIFO) DequeueOrWaitForNextElementWithContext(ctx context.Context) (interface{}, error) {
+	if st.IsLocked() {
+		return nil, ErrLockedQueue
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	st.rwmutex.Lock()
+	value, ok, shed := st.dequeue(time.Now())
+	if ok {
+		st.rwmutex.Unlock()
+		st.notifyShed(shed)
+
+		return value, nil
+	}
+
+	// channel to wait for next enqueued element (buffered, so Enqueue never blocks handing it over)
+	waitChan := make(chan interface{}, 1)
+
+	select {
+	case st.waitForNextElementChan <- waitChan:
+		st.rwmutex.Unlock()
+		st.notifyShed(shed)
+
+		select {
+		case value := <-waitChan:
+			return value, nil
+		case <-ctx.Done():
+			st.rwmutex.Lock()
+			defer st.rwmutex.Unlock()
+
+			removeListener(st.waitForNextElementChan, waitChan)
+			// the element could have been handed over right before the listener's removal
+			select {
+			case value := <-waitChan:
+				return value, nil
+			default:
+				return nil, ctx.Err()
+			}
+		}
+	default:
+		st.rwmutex.Unlock()
+		st.notifyShed(shed)
+
+		// too many watchers (waitForNextElementChanCapacity) enqueued waiting for next elements
+		return nil, NewQueueError(QueueErrorCodeEmptyQueue, "empty queue and can't wait for next element because there are too many DequeueOrWaitForNextElement() waiting")
+	}
+}
+
+// GetLen returns the number of enqueued elements
+func (st *AdaptiveLIFO) GetLen() int {
+	st.rwmutex.RLock()
+	defer st.rwmutex.RUnlock()
+
+	return st.ring.length()
+}
+
+// GetCap returns the queue's capacity
+func (st *AdaptiveLIFO) GetCap() int {
+	st.rwmutex.RLock()
+	defer st.rwmutex.RUnlock()
+
+	return st.ring.capacity()
+}
+
+// Lock // Locks the queue. No enqueue/dequeue operations will be allowed after this point.
+func (st *AdaptiveLIFO) Lock() {
+	st.lockRWmutex.Lock()
+	defer st.lockRWmutex.Unlock()
+
+	st.isLocked = true
+}
+
+// Unlock unlocks the queue
+func (st *AdaptiveLIFO) Unlock() {
+	st.lockRWmutex.Lock()
+	defer st.lockRWmutex.Unlock()
+
+	st.isLocked = false
+}
+
+// IsLocked returns true whether the queue is locked
+func (st *AdaptiveLIFO) IsLocked() bool {
+	st.lockRWmutex.RLock()
+	defer st.lockRWmutex.RUnlock()
+
+	return st.isLocked
+}
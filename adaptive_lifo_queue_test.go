@@ -0,0 +1,187 @@
+package goconcurrentqueue
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+const (
+	adaptiveLIFOTestThreshold = 20 * time.Millisecond
+)
+
+type AdaptiveLIFOTestSuite struct {
+	suite.Suite
+	queue *AdaptiveLIFO
+}
+
+func (suite *AdaptiveLIFOTestSuite) SetupTest() {
+	suite.queue = NewAdaptiveLIFO(adaptiveLIFOTestThreshold)
+}
+
+// dequeueAll dequeues every element
+func (suite *AdaptiveLIFOTestSuite) dequeueAll() []interface{} {
+	elements := make([]interface{}, 0)
+	for {
+		value, err := suite.queue.Dequeue()
+		if err != nil {
+			suite.Equal(ErrEmptyQueue, err)
+			return elements
+		}
+		elements = append(elements, value)
+	}
+}
+
+// ***************************************************************************************
+// ** Initialization
+// ***************************************************************************************
+
+// no elements at initialization
+func (suite *AdaptiveLIFOTestSuite) TestNoElementsAtInitialization() {
+	suite.Equal(0, suite.queue.GetLen())
+	suite.False(suite.queue.IsLocked())
+	suite.False(suite.queue.IsOverloaded())
+
+	var queue Queue = suite.queue
+	suite.NotNil(queue)
+}
+
+// ***************************************************************************************
+// ** Dequeue
+// ***************************************************************************************
+
+// FIFO while it keeps up
+func (suite *AdaptiveLIFOTestSuite) TestDequeueFIFO() {
+	for i := 0; i < 5; i++ {
+		suite.NoError(suite.queue.Enqueue(i))
+	}
+
+	suite.Equal([]interface{}{0, 1, 2, 3, 4}, suite.dequeueAll())
+}
+
+// newest first while overloaded
+func (suite *AdaptiveLIFOTestSuite) TestDequeueOverloadedServeNewest() {
+	suite.queue.Enqueue("stale 1")
+	suite.queue.Enqueue("stale 2")
+	time.Sleep(2 * adaptiveLIFOTestThreshold)
+	suite.True(suite.queue.IsOverloaded())
+
+	suite.queue.Enqueue("fresh 1")
+	suite.queue.Enqueue("fresh 2")
+
+	value, err := suite.queue.Dequeue()
+	suite.NoError(err)
+	suite.Equal("fresh 2", value)
+
+	suite.Equal([]interface{}{"fresh 1", "stale 2", "stale 1"}, suite.dequeueAll())
+	suite.Equal(uint64(0), suite.queue.GetShed())
+}
+
+// AdaptiveLIFOServeNewest: overloaded until the stale elements are served, FIFO again once drained
+func (suite *AdaptiveLIFOTestSuite) TestDequeueOverloadedUntilDrained() {
+	suite.queue.Enqueue("stale")
+	time.Sleep(2 * adaptiveLIFOTestThreshold)
+	suite.queue.Enqueue("fresh 1")
+	suite.queue.Enqueue("fresh 2")
+
+	value, _ := suite.queue.Dequeue()
+	suite.Equal("fresh 2", value)
+	value, _ = suite.queue.Dequeue()
+	suite.Equal("fresh 1", value)
+	suite.True(suite.queue.IsOverloaded(), "the stale element is still waiting")
+
+	value, _ = suite.queue.Dequeue()
+	suite.Equal("stale", value)
+	suite.False(suite.queue.IsOverloaded())
+
+	suite.queue.Enqueue(1)
+	suite.queue.Enqueue(2)
+	suite.Equal([]interface{}{1, 2}, suite.dequeueAll())
+}
+
+// stale elements get dropped while overloaded (AdaptiveLIFOShedOldest)
+func (suite *AdaptiveLIFOTestSuite) TestDequeueOverloadedShedOldest() {
+	shed := make([]interface{}, 0)
+	suite.queue.SetMode(AdaptiveLIFOShedOldest)
+	suite.queue.SetShedHandler(func(value interface{}) {
+		shed = append(shed, value)
+		// the handler runs out of the lock
+		suite.queue.GetLen()
+	})
+
+	suite.queue.Enqueue("stale 1")
+	suite.queue.Enqueue("stale 2")
+	time.Sleep(2 * adaptiveLIFOTestThreshold)
+	suite.queue.Enqueue("fresh 1")
+	suite.queue.Enqueue("fresh 2")
+
+	suite.Equal([]interface{}{"fresh 1", "fresh 2"}, suite.dequeueAll())
+	suite.Equal([]interface{}{"stale 1", "stale 2"}, shed)
+	suite.Equal(uint64(2), suite.queue.GetShed())
+	suite.False(suite.queue.IsOverloaded())
+}
+
+// every element could get dropped
+func (suite *AdaptiveLIFOTestSuite) TestDequeueShedEverything() {
+	suite.queue.SetMode(AdaptiveLIFOShedOldest)
+	suite.queue.Enqueue(1)
+	time.Sleep(2 * adaptiveLIFOTestThreshold)
+
+	_, err := suite.queue.Dequeue()
+	suite.Equal(ErrEmptyQueue, err)
+	suite.Equal(0, suite.queue.GetLen())
+}
+
+// locked queue
+func (suite *AdaptiveLIFOTestSuite) TestLock() {
+	suite.queue.Lock()
+
+	suite.Equal(ErrLockedQueue, suite.queue.Enqueue(1))
+	_, err := suite.queue.Dequeue()
+	suite.Equal(ErrLockedQueue, err)
+	_, err = suite.queue.DequeueOrWaitForNextElement()
+	suite.Equal(ErrLockedQueue, err)
+
+	suite.queue.Unlock()
+	suite.NoError(suite.queue.Enqueue(1))
+}
+
+// ***************************************************************************************
+// ** DequeueOrWaitForNextElement
+// ***************************************************************************************
+
+// waits until the next element gets enqueued
+func (suite *AdaptiveLIFOTestSuite) TestDequeueOrWaitForNextElement() {
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		suite.queue.Enqueue(testValue)
+	}()
+
+	value, err := suite.queue.DequeueOrWaitForNextElement()
+	suite.NoError(err)
+	suite.Equal(testValue, value)
+	suite.Equal(0, suite.queue.GetLen())
+}
+
+// context done while waiting
+func (suite *AdaptiveLIFOTestSuite) TestDequeueOrWaitForNextElementWithContext() {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err := suite.queue.DequeueOrWaitForNextElementWithContext(ctx)
+	suite.Equal(context.DeadlineExceeded, err)
+
+	// the cancelled listener doesn't take the next element
+	suite.queue.Enqueue(1)
+	suite.Equal(1, suite.queue.GetLen())
+}
+
+// ***************************************************************************************
+// ** Run suite
+// ***************************************************************************************
+
+func TestAdaptiveLIFOTestSuite(t *testing.T) {
+	suite.Run(t, new(AdaptiveLIFOTestSuite))
+}
@@ -0,0 +1,43 @@
+//go:build 386 || arm || mips || mipsle
+// +build 386 arm mips mipsle
+
+package goconcurrentqueue
+
+import (
+	"testing"
+	"unsafe"
+
+	"github.com/stretchr/testify/suite"
+)
+
+// 32-bit platforms only guarantee 64-bit alignment for the first word of an allocated struct: every 64-bit field
+// accessed atomically must sit at a 64-bit aligned offset.
+type AtomicAlignTestSuite struct {
+	suite.Suite
+}
+
+// SPSCQueue counters
+func (suite *AtomicAlignTestSuite) TestSPSCQueueOffsets() {
+	var queue SPSCQueue
+
+	suite.Equal(uintptr(0), unsafe.Offsetof(queue.head)%8, "head must be 64-bit aligned")
+	suite.Equal(uintptr(0), unsafe.Offsetof(queue.tail)%8, "tail must be 64-bit aligned")
+}
+
+// the atomic operations don't panic on an allocated queue
+func (suite *AtomicAlignTestSuite) TestSPSCQueueAllocated() {
+	queue := NewSPSCQueue(2)
+
+	suite.Equal(uintptr(0), uintptr(unsafe.Pointer(&queue.head))%8)
+	suite.Equal(uintptr(0), uintptr(unsafe.Pointer(&queue.tail))%8)
+
+	suite.NoError(queue.Enqueue(1))
+	suite.Equal(1, queue.GetLen())
+	value, err := queue.Dequeue()
+	suite.NoError(err)
+	suite.Equal(1, value)
+}
+
+func TestAtomicAlignTestSuite(t *testing.T) {
+	suite.Run(t, new(AtomicAlignTestSuite))
+}
@@ -0,0 +1,158 @@
+package goconcurrentqueue
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	// default interval between the autoscaler's backlog checks
+	autoscalerDefaultInterval = 100 * time.Millisecond
+)
+
+// ScaleReason is why an autoscaler added or removed a worker
+type ScaleReason string
+
+const (
+	// ScaleReasonBacklog means the queue held more elements than AutoscalerConfig.TargetBacklog
+	ScaleReasonBacklog ScaleReason = "backlog"
+	// ScaleReasonAge means the last dequeued element waited longer than AutoscalerConfig.TargetAge
+	ScaleReasonAge ScaleReason = "age"
+	// ScaleReasonIdle means a worker waited AutoscalerConfig.IdleTimeout for an element
+	ScaleReasonIdle ScaleReason = "idle"
+)
+
+// ScaleEvent is an autoscaler's decision, see AutoscalerConfig.OnScale
+type ScaleEvent struct {
+	Reason ScaleReason
+	// workers before and after the decision
+	From int
+	To   int
+	// queue's length and last dequeued element's wait (0 if unknown) when a worker got added
+	Backlog int
+	Age     time.Duration
+}
+
+// AutoscalerConfig makes a Consume call's workers elastic, see ConsumeAutoscaler
+type AutoscalerConfig struct {
+	// bounds of the number of workers (MinWorkers at least 1)
+	MinWorkers int
+	MaxWorkers int
+	// a worker gets added (every Interval) while the queue holds more than TargetBacklog elements, 0 disables it
+	TargetBacklog int
+	// a worker gets added (every Interval) while there are elements waiting and the last dequeued one waited longer than
+	// TargetAge, 0 disables it. The enqueue times are recorded while Consume runs, the elements enqueued before don't
+	// count.
+	TargetAge time.Duration
+	// a worker exits once it waited IdleTimeout for an element (unless there are MinWorkers), 0 means the workers never
+	// get removed
+	IdleTimeout time.Duration
+	// time between the backlog / age checks, 0 means 100ms
+	Interval time.Duration
+	// invoked with every decision (i.e. to log them), one at a time: it must not block
+	OnScale func(event ScaleEvent)
+}
+
+// ConsumeAutoscaler makes the number of workers elastic: Consume starts with its workers argument (clamped within
+// [MinWorkers, MaxWorkers]), adds workers while the backlog / age exceed their targets and removes the idle ones. It
+// can't be combined with ConsumeWorkers. Returns error (at Consume) if the bounds are invalid, there is no target or
+// any duration is negative.
+func ConsumeAutoscaler(config AutoscalerConfig) ConsumeOption {
+	return func(options *consumeOptions) error {
+		if config.MinWorkers < 1 {
+			return NewQueueError(QueueErrorCodeInvalidConfig, fmt.Sprintf("invalid min workers: %v", config.MinWorkers))
+		}
+		if config.MaxWorkers < config.MinWorkers {
+			return NewQueueError(QueueErrorCodeInvalidConfig, fmt.Sprintf("invalid max workers: %v", config.MaxWorkers))
+		}
+		if config.TargetBacklog < 0 {
+			return NewQueueError(QueueErrorCodeInvalidConfig, fmt.Sprintf("invalid target backlog: %v", config.TargetBacklog))
+		}
+		if config.TargetBacklog == 0 && config.TargetAge == 0 {
+			return NewQueueError(QueueErrorCodeInvalidConfig, "no backlog / age target")
+		}
+		for _, d := range []time.Duration{config.TargetAge, config.IdleTimeout, config.Interval} {
+			if d < 0 {
+				return NewQueueError(QueueErrorCodeInvalidConfig, fmt.Sprintf("invalid duration: %v", d))
+			}
+		}
+		if config.Interval == 0 {
+			config.Interval = autoscalerDefaultInterval
+		}
+		options.autoscaler = &autoscaler{config: config}
+
+		return nil
+	}
+}
+
+// autoscaler counts a Consume call's workers and takes the scaling decisions
+type autoscaler struct {
+	config  AutoscalerConfig
+	mutex   sync.Mutex
+	workers int
+}
+
+// start sets the initial number of workers (clamped within the bounds) and returns it
+func (st *autoscaler) start(workers int) int {
+	st.mutex.Lock()
+	defer st.mutex.Unlock()
+
+	if workers < st.config.MinWorkers {
+		workers = st.config.MinWorkers
+	}
+	if workers > st.config.MaxWorkers {
+		workers = st.config.MaxWorkers
+	}
+	st.workers = workers
+
+	return workers
+}
+
+// grow counts a new worker if the backlog or the age exceed their targets (and there are less than MaxWorkers).
+// Returns true if a worker has to be added.
+func (st *autoscaler) grow(backlog int, age time.Duration) bool {
+	st.mutex.Lock()
+	defer st.mutex.Unlock()
+
+	if st.workers >= st.config.MaxWorkers {
+		return false
+	}
+
+	var reason ScaleReason
+	switch {
+	case st.config.TargetBacklog > 0 && backlog > st.config.TargetBacklog:
+		reason = ScaleReasonBacklog
+	case st.config.TargetAge > 0 && backlog > 0 && age > st.config.TargetAge:
+		reason = ScaleReasonAge
+	default:
+		return false
+	}
+
+	st.workers++
+	st.notify(ScaleEvent{Reason: reason, From: st.workers - 1, To: st.workers, Backlog: backlog, Age: age})
+
+	return true
+}
+
+// shrink discounts an idle worker (unless there are MinWorkers). Returns true if the worker has to exit.
+func (st *autoscaler) shrink() bool {
+	st.mutex.Lock()
+	defer st.mutex.Unlock()
+
+	if st.workers <= st.config.MinWorkers {
+		return false
+	}
+
+	st.workers--
+	st.notify(ScaleEvent{Reason: ScaleReasonIdle, From: st.workers + 1, To: st.workers})
+
+	return true
+}
+
+// notify invokes OnScale (if any). st.mutex must be locked by the caller.
+func (st *autoscaler) notify(event ScaleEvent) {
+	if st.config.OnScale != nil {
+		st.config.OnScale(event)
+	}
+}
@@ -0,0 +1,175 @@
+package goconcurrentqueue
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type AutoscalerTestSuite struct {
+	suite.Suite
+	// OnScale events
+	mutex  sync.Mutex
+	events []ScaleEvent
+}
+
+func (suite *AutoscalerTestSuite) SetupTest() {
+	suite.events = nil
+}
+
+func (suite *AutoscalerTestSuite) onScale(event ScaleEvent) {
+	suite.mutex.Lock()
+	defer suite.mutex.Unlock()
+
+	suite.events = append(suite.events, event)
+}
+
+// scaleEvents returns a copy of the events so far
+func (suite *AutoscalerTestSuite) scaleEvents() []ScaleEvent {
+	suite.mutex.Lock()
+	defer suite.mutex.Unlock()
+
+	return append([]ScaleEvent(nil), suite.events...)
+}
+
+// waitForEvent waits (up to 2s) until an event with the given reason and resulting workers got notified
+func (suite *AutoscalerTestSuite) waitForEvent(reason ScaleReason, to int) bool {
+	for i := 0; i < 2000; i++ {
+		for _, event := range suite.scaleEvents() {
+			if event.Reason == reason && event.To == to {
+				return true
+			}
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	return false
+}
+
+// decisions within the bounds: backlog first, age only while elements wait
+func (suite *AutoscalerTestSuite) TestGrowShrink() {
+	scaler := &autoscaler{config: AutoscalerConfig{MinWorkers: 1, MaxWorkers: 3, TargetBacklog: 5, TargetAge: time.Second, OnScale: suite.onScale}}
+	suite.Equal(3, scaler.start(10))
+	suite.Equal(1, scaler.start(0))
+
+	suite.False(scaler.grow(5, 0))
+	suite.True(scaler.grow(6, 2*time.Second))
+	suite.False(scaler.grow(0, 2*time.Second), "stale age, nothing waits")
+	suite.True(scaler.grow(1, 2*time.Second))
+	suite.False(scaler.grow(100, 0), "max workers")
+
+	suite.True(scaler.shrink())
+	suite.True(scaler.shrink())
+	suite.False(scaler.shrink(), "min workers")
+
+	suite.Equal([]ScaleEvent{
+		{Reason: ScaleReasonBacklog, From: 1, To: 2, Backlog: 6, Age: 2 * time.Second},
+		{Reason: ScaleReasonAge, From: 2, To: 3, Backlog: 1, Age: 2 * time.Second},
+		{Reason: ScaleReasonIdle, From: 3, To: 2},
+		{Reason: ScaleReasonIdle, From: 2, To: 1},
+	}, suite.scaleEvents())
+}
+
+// invalid configs: nothing gets consumed
+func (suite *AutoscalerTestSuite) TestInvalid() {
+	fifo := NewFIFO()
+	fifo.Enqueue(1)
+
+	for _, config := range []AutoscalerConfig{
+		{MinWorkers: 0, MaxWorkers: 1, TargetBacklog: 1},
+		{MinWorkers: 2, MaxWorkers: 1, TargetBacklog: 1},
+		{MinWorkers: 1, MaxWorkers: 1, TargetBacklog: -1},
+		{MinWorkers: 1, MaxWorkers: 1},
+		{MinWorkers: 1, MaxWorkers: 1, TargetBacklog: 1, IdleTimeout: -time.Second},
+	} {
+		err := fifo.Consume(context.Background(), 1, func(value interface{}) error {
+			return nil
+		}, ConsumeAutoscaler(config))
+		suite.Equal(QueueErrorCodeInvalidConfig, errorCode(err), config)
+	}
+
+	err := fifo.Consume(context.Background(), 1, func(value interface{}) error {
+		return nil
+	}, ConsumeAutoscaler(AutoscalerConfig{MinWorkers: 1, MaxWorkers: 1, TargetBacklog: 1}), ConsumeWorkers("gpu", 1))
+	suite.Equal(QueueErrorCodeInvalidConfig, errorCode(err))
+	suite.Equal(1, fifo.GetLen())
+}
+
+// FIFO: workers get added while the backlog exceeds the target and removed once idle
+func (suite *AutoscalerTestSuite) TestConsumeBacklog() {
+	var (
+		fifo        = NewFIFO()
+		ctx, cancel = context.WithTimeout(context.Background(), 5*time.Second)
+		done        = make(chan error, 1)
+	)
+	defer cancel()
+	for i := 0; i < 40; i++ {
+		fifo.Enqueue(i)
+	}
+
+	go func() {
+		done <- fifo.Consume(ctx, 1, func(value interface{}) error {
+			time.Sleep(5 * time.Millisecond)
+			return nil
+		}, ConsumeAutoscaler(AutoscalerConfig{
+			MinWorkers:    1,
+			MaxWorkers:    3,
+			TargetBacklog: 2,
+			IdleTimeout:   20 * time.Millisecond,
+			Interval:      time.Millisecond,
+			OnScale:       suite.onScale,
+		}))
+	}()
+
+	suite.True(suite.waitForEvent(ScaleReasonBacklog, 3))
+	suite.True(suite.waitForEvent(ScaleReasonIdle, 1))
+	cancel()
+	suite.NoError(<-done)
+
+	suite.Equal(0, fifo.GetLen())
+	for _, event := range suite.scaleEvents() {
+		suite.True(event.To >= 1 && event.To <= 3, event)
+	}
+}
+
+// FixedFIFO: workers get added while the elements wait longer than the target
+func (suite *AutoscalerTestSuite) TestConsumeAge() {
+	var (
+		fifo        = NewFixedFIFO(100)
+		ctx, cancel = context.WithTimeout(context.Background(), 5*time.Second)
+		done        = make(chan error, 1)
+	)
+	defer cancel()
+
+	go func() {
+		done <- fifo.Consume(ctx, 1, func(value interface{}) error {
+			time.Sleep(5 * time.Millisecond)
+			return nil
+		}, ConsumeAutoscaler(AutoscalerConfig{
+			MinWorkers: 1,
+			MaxWorkers: 2,
+			TargetAge:  time.Millisecond,
+			Interval:   time.Millisecond,
+			OnScale:    suite.onScale,
+		}))
+	}()
+	// enqueued once Consume records the enqueue times
+	for i := 0; i < 1000 && !fifo.waitSLO.enabled(); i++ {
+		time.Sleep(time.Millisecond)
+	}
+	for i := 0; i < 40; i++ {
+		fifo.Enqueue(i)
+	}
+
+	suite.True(suite.waitForEvent(ScaleReasonAge, 2))
+	cancel()
+	suite.NoError(<-done)
+	suite.False(fifo.waitSLO.enabled(), "no more enqueue times once Consume is done")
+}
+
+func TestAutoscalerTestSuite(t *testing.T) {
+	suite.Run(t, new(AutoscalerTestSuite))
+}
@@ -0,0 +1,133 @@
+package goconcurrentqueue
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// BroadcastQueue delivers every enqueued element to every subscriber (publish / subscribe), unlike the rest of the
+// queues whose consumers compete for the elements. Each subscriber consumes from its own queue, so its backpressure
+// policy is the queue's one: a FIFO never rejects, a FixedFIFO rejects / drops / blocks following its overflow policy
+// (NewBoundedFIFO).
+// Enqueues are serialized, so every subscriber gets the elements in the same order. A subscriber's queue blocking
+// (OverflowPolicyBlock) blocks the publishers.
+type BroadcastQueue struct {
+	// elements rejected by the subscribers' queues (i.e. full or locked). First field: 64-bit aligned on 32-bit
+	// platforms.
+	rejected uint64
+	// copy-on-write: replaced on every Subscribe / Unsubscribe, so enqueues don't hold the lock while delivering
+	subscribers      []Queue
+	rejectionHandler func(subscriber Queue, value interface{}, err error)
+	rwmutex          sync.RWMutex
+	// serializes the enqueues
+	enqueueMutex sync.Mutex
+	lockRWmutex  sync.RWMutex
+	isLocked     bool
+}
+
+// NewBroadcastQueue returns a new BroadcastQueue with no subscribers
+func NewBroadcastQueue() *BroadcastQueue {
+	return &BroadcastQueue{}
+}
+
+// Subscribe registers a subscriber's queue: it gets every element enqueued from now on
+func (st *BroadcastQueue) Subscribe(subscriber Queue) {
+	st.rwmutex.Lock()
+	defer st.rwmutex.Unlock()
+
+	subscribers := make([]Queue, len(st.subscribers), len(st.subscribers)+1)
+	copy(subscribers, st.subscribers)
+	st.subscribers = append(subscribers, subscriber)
+}
+
+// Unsubscribe removes a subscriber's queue, it doesn't get any further element (the ones already delivered stay at
+// its queue). Returns false if the queue wasn't subscribed.
+func (st *BroadcastQueue) Unsubscribe(subscriber Queue) bool {
+	st.rwmutex.Lock()
+	defer st.rwmutex.Unlock()
+
+	for i, current := range st.subscribers {
+		if current == subscriber {
+			subscribers := make([]Queue, 0, len(st.subscribers)-1)
+			subscribers = append(subscribers, st.subscribers[:i]...)
+			st.subscribers = append(subscribers, st.subscribers[i+1:]...)
+			return true
+		}
+	}
+
+	return false
+}
+
+// GetSubscribers returns the number of subscribers
+func (st *BroadcastQueue) GetSubscribers() int {
+	st.rwmutex.RLock()
+	defer st.rwmutex.RUnlock()
+
+	return len(st.subscribers)
+}
+
+// SetRejectionHandler sets the function invoked every time a subscriber's queue rejects an element (i.e. full
+// capacity), along with the queue's error. The handler runs at the Enqueue caller's goroutine. nil removes the
+// handler.
+func (st *BroadcastQueue) SetRejectionHandler(handler func(subscriber Queue, value interface{}, err error)) {
+	st.rwmutex.Lock()
+	defer st.rwmutex.Unlock()
+
+	st.rejectionHandler = handler
+}
+
+// GetRejected returns the number of elements rejected by the subscribers' queues
+func (st *BroadcastQueue) GetRejected() uint64 {
+	return atomic.LoadUint64(&st.rejected)
+}
+
+// Enqueue delivers the element to every subscriber. A subscriber's queue rejecting it doesn't affect the rest (see
+// SetRejectionHandler). Returns error if queue is locked.
+func (st *BroadcastQueue) Enqueue(value interface{}) error {
+	if st.IsLocked() {
+		return ErrLockedQueue
+	}
+
+	st.enqueueMutex.Lock()
+	defer st.enqueueMutex.Unlock()
+
+	st.rwmutex.RLock()
+	subscribers := st.subscribers
+	handler := st.rejectionHandler
+	st.rwmutex.RUnlock()
+
+	for _, subscriber := range subscribers {
+		if err := subscriber.Enqueue(value); err != nil {
+			atomic.AddUint64(&st.rejected, 1)
+			if handler != nil {
+				handler(subscriber, value, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Lock // Locks the queue. No enqueue operations will be allowed after this point.
+func (st *BroadcastQueue) Lock() {
+	st.lockRWmutex.Lock()
+	defer st.lockRWmutex.Unlock()
+
+	st.isLocked = true
+}
+
+// Unlock unlocks the queue
+func (st *BroadcastQueue) Unlock() {
+	st.lockRWmutex.Lock()
+	defer st.lockRWmutex.Unlock()
+
+	st.isLocked = false
+}
+
+// IsLocked returns true whether the queue is locked
+func (st *BroadcastQueue) IsLocked() bool {
+	st.lockRWmutex.RLock()
+	defer st.lockRWmutex.RUnlock()
+
+	return st.isLocked
+}
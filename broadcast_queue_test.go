@@ -0,0 +1,151 @@
+package goconcurrentqueue
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type BroadcastQueueTestSuite struct {
+	suite.Suite
+	broadcast *BroadcastQueue
+}
+
+func (suite *BroadcastQueueTestSuite) SetupTest() {
+	suite.broadcast = NewBroadcastQueue()
+}
+
+// ***************************************************************************************
+// ** Subscribe / Unsubscribe
+// ***************************************************************************************
+
+// no subscribers: the elements go nowhere
+func (suite *BroadcastQueueTestSuite) TestNoSubscribers() {
+	suite.Equal(0, suite.broadcast.GetSubscribers())
+	suite.NoError(suite.broadcast.Enqueue(1))
+}
+
+// every subscriber gets every element
+func (suite *BroadcastQueueTestSuite) TestSubscribe() {
+	var (
+		first  = NewFIFO()
+		second = NewFIFO()
+	)
+	suite.broadcast.Subscribe(first)
+	suite.NoError(suite.broadcast.Enqueue(1))
+	suite.broadcast.Subscribe(second)
+	suite.NoError(suite.broadcast.Enqueue(2))
+
+	suite.Equal(2, suite.broadcast.GetSubscribers())
+	suite.Equal([]interface{}{1, 2}, first.Drain())
+	suite.Equal([]interface{}{2}, second.Drain(), "elements enqueued before subscribing aren't delivered")
+}
+
+// unsubscribed queues keep the delivered elements but get no more
+func (suite *BroadcastQueueTestSuite) TestUnsubscribe() {
+	var (
+		first  = NewFIFO()
+		second = NewFIFO()
+	)
+	suite.broadcast.Subscribe(first)
+	suite.broadcast.Subscribe(second)
+	suite.broadcast.Enqueue(1)
+
+	suite.True(suite.broadcast.Unsubscribe(first))
+	suite.False(suite.broadcast.Unsubscribe(first))
+	suite.broadcast.Enqueue(2)
+
+	suite.Equal(1, suite.broadcast.GetSubscribers())
+	suite.Equal([]interface{}{1}, first.Drain())
+	suite.Equal([]interface{}{1, 2}, second.Drain())
+}
+
+// ***************************************************************************************
+// ** Backpressure
+// ***************************************************************************************
+
+// a full subscriber doesn't affect the rest, its rejections are reported
+func (suite *BroadcastQueueTestSuite) TestRejections() {
+	var (
+		slow     = NewFixedFIFO(1)
+		latest   = NewKeepLatestFixedFIFO(1)
+		fast     = NewFIFO()
+		rejected = make([]interface{}, 0)
+	)
+	suite.broadcast.Subscribe(slow)
+	suite.broadcast.Subscribe(latest)
+	suite.broadcast.Subscribe(fast)
+	suite.broadcast.SetRejectionHandler(func(subscriber Queue, value interface{}, err error) {
+		suite.Equal(slow, subscriber)
+		suite.Equal(ErrFullCapacity, err)
+		rejected = append(rejected, value)
+	})
+
+	for i := 0; i < 3; i++ {
+		suite.NoError(suite.broadcast.Enqueue(i))
+	}
+
+	suite.Equal([]interface{}{0}, slow.Drain())
+	suite.Equal([]interface{}{2}, latest.Drain())
+	suite.Equal([]interface{}{0, 1, 2}, fast.Drain())
+	suite.Equal([]interface{}{1, 2}, rejected)
+	suite.Equal(uint64(2), suite.broadcast.GetRejected())
+}
+
+// concurrent publishers: every subscriber gets the elements in the same order
+func (suite *BroadcastQueueTestSuite) TestSameOrder() {
+	var (
+		subscribers = []*FIFO{NewFIFO(), NewFIFO(), NewFIFO()}
+		wg          sync.WaitGroup
+		totalGRs    = 4
+		perGR       = 100
+	)
+	for _, subscriber := range subscribers {
+		suite.broadcast.Subscribe(subscriber)
+	}
+
+	for gr := 0; gr < totalGRs; gr++ {
+		wg.Add(1)
+		go func(gr int) {
+			defer wg.Done()
+			for i := 0; i < perGR; i++ {
+				suite.broadcast.Enqueue(gr*perGR + i)
+			}
+		}(gr)
+	}
+	wg.Wait()
+
+	expected := subscribers[0].Drain()
+	suite.Equal(totalGRs*perGR, len(expected))
+	for _, subscriber := range subscribers[1:] {
+		suite.Equal(expected, subscriber.Drain())
+	}
+}
+
+// ***************************************************************************************
+// ** Lock / Unlock / IsLocked
+// ***************************************************************************************
+
+// locked queue: nothing gets delivered
+func (suite *BroadcastQueueTestSuite) TestLock() {
+	subscriber := NewFIFO()
+	suite.broadcast.Subscribe(subscriber)
+
+	suite.broadcast.Lock()
+	suite.True(suite.broadcast.IsLocked())
+	suite.Equal(ErrLockedQueue, suite.broadcast.Enqueue(1))
+	suite.Equal(0, subscriber.GetLen())
+
+	suite.broadcast.Unlock()
+	suite.NoError(suite.broadcast.Enqueue(1))
+	suite.Equal(1, subscriber.GetLen())
+}
+
+// ***************************************************************************************
+// ** Run suite
+// ***************************************************************************************
+
+func TestBroadcastQueueTestSuite(t *testing.T) {
+	suite.Run(t, new(BroadcastQueueTestSuite))
+}
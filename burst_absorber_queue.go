@@ -0,0 +1,232 @@
+package goconcurrentqueue
+
+import (
+	"fmt"
+	"sync"
+)
+
+// BurstAbsorber concurrent queue: a small fixed capacity front (FixedFIFO) backed by an elastic overflow (FIFO).
+// Elements are enqueued into the front while it has room, bursts spill over into the overflow and get promoted to the
+// front as it drains. The overall order is FIFO.
+// The overflow can be limited (overflowCapacity > 0), enqueueing over the limit follows the overflow policy: a full
+// capacity error by default, see NewBurstAbsorberWithPolicy.
+type BurstAbsorber struct {
+	front    *FixedFIFO
+	overflow *FIFO
+	// 0 == no limit, protected by mutex
+	overflowCapacity int
+	// what to do at the overflow limit and the elements dropped so far, protected by mutex
+	overflowPolicy OverflowPolicy
+	evictions      uint64
+	// serializes the decisions about where enqueue and the promotions from the overflow to the front
+	mutex       sync.Mutex
+	lockRWmutex sync.RWMutex
+	isLocked    bool
+}
+
+// NewBurstAbsorber returns a new BurstAbsorber queue with the given front capacity and overflow limit (0 means no limit)
+func NewBurstAbsorber(frontCapacity int, overflowCapacity int) *BurstAbsorber {
+	ret := &BurstAbsorber{}
+	ret.initialize(frontCapacity, overflowCapacity)
+
+	return ret
+}
+
+// NewBurstAbsorberWithPolicy returns a new BurstAbsorber queue following the given policy at the overflow limit:
+// OverflowPolicyReject, OverflowPolicyDropOldest (the overflow's oldest element, the front's ones are about to be
+// consumed) or OverflowPolicyDropNewest. Returns error if the policy is OverflowPolicyBlock or unknown.
+func NewBurstAbsorberWithPolicy(frontCapacity int, overflowCapacity int, policy OverflowPolicy) (*BurstAbsorber, error) {
+	if err := validateBurstAbsorberPolicy(policy); err != nil {
+		return nil, err
+	}
+
+	ret := NewBurstAbsorber(frontCapacity, overflowCapacity)
+	ret.overflowPolicy = policy
+
+	return ret, nil
+}
+
+// validateBurstAbsorberPolicy returns error if the BurstAbsorber doesn't support the overflow policy
+func validateBurstAbsorberPolicy(policy OverflowPolicy) error {
+	switch policy {
+	case OverflowPolicyReject, OverflowPolicyDropOldest, OverflowPolicyDropNewest:
+		return nil
+	}
+
+	return NewQueueError(QueueErrorCodeInvalidConfig, fmt.Sprintf("unsupported overflow policy: %v", policy))
+}
+
+func (st *BurstAbsorber) initialize(frontCapacity int, overflowCapacity int) {
+	st.front = NewFixedFIFO(frontCapacity)
+	st.overflow = NewFIFO()
+	st.overflowCapacity = overflowCapacity
+}
+
+// Enqueue enqueues an element. Returns error if queue is locked or both the front and the overflow are full (unless
+// the overflow policy drops an element instead).
+func (st *BurstAbsorber) Enqueue(value interface{}) error {
+	if st.IsLocked() {
+		return ErrLockedQueue
+	}
+
+	st.mutex.Lock()
+	defer st.mutex.Unlock()
+
+	// keep the order: the front only takes new elements if nothing is waiting at the overflow
+	if st.overflow.GetLen() == 0 {
+		if err := st.front.Enqueue(value); err != ErrFullCapacity {
+			return err
+		}
+	}
+
+	if st.overflowCapacity > 0 && st.overflow.GetLen() >= st.overflowCapacity {
+		switch st.overflowPolicy {
+		case OverflowPolicyDropNewest:
+			st.evict(value)
+			return nil
+		case OverflowPolicyDropOldest:
+			// the overflow was just found non empty, nobody else dequeues from it (st.mutex)
+			oldest, _ := st.overflow.Dequeue()
+			st.evict(oldest)
+		default:
+			return NewQueueError(QueueErrorCodeFullCapacity, "BurstAbsorber queue is at full capacity")
+		}
+	}
+
+	return st.overflow.Enqueue(value)
+}
+
+// evict counts the dropped element. st.mutex must be locked by the caller.
+func (st *BurstAbsorber) evict(value interface{}) {
+	st.evictions++
+	failDroppedFuture(value)
+}
+
+// GetOverflowPolicy returns what the queue does at the overflow limit
+func (st *BurstAbsorber) GetOverflowPolicy() OverflowPolicy {
+	st.mutex.Lock()
+	defer st.mutex.Unlock()
+
+	return st.overflowPolicy
+}
+
+// GetEvictions returns the number of elements dropped at the overflow limit (drop-oldest / drop-newest policies)
+func (st *BurstAbsorber) GetEvictions() uint64 {
+	st.mutex.Lock()
+	defer st.mutex.Unlock()
+
+	return st.evictions
+}
+
+// Dequeue dequeues an element. Returns error if queue is locked or empty.
+func (st *BurstAbsorber) Dequeue() (interface{}, error) {
+	if st.IsLocked() {
+		return nil, ErrLockedQueue
+	}
+
+	st.mutex.Lock()
+	defer st.mutex.Unlock()
+
+	value, err := st.front.Dequeue()
+	if err != nil {
+		return nil, err
+	}
+	st.promote()
+
+	return value, nil
+}
+
+// DequeueOrWaitForNextElement dequeues an element (if exist) or waits until the next element gets enqueued and returns it.
+// Multiple calls to DequeueOrWaitForNextElement() would enqueue multiple "listeners" for future enqueued elements.
+func (st *BurstAbsorber) DequeueOrWaitForNextElement() (interface{}, error) {
+	if st.IsLocked() {
+		return nil, ErrLockedQueue
+	}
+
+	st.mutex.Lock()
+	if value, err := st.front.Dequeue(); err == nil {
+		st.promote()
+		st.mutex.Unlock()
+		return value, nil
+	}
+	st.mutex.Unlock()
+
+	// the front is empty, so is the overflow: the next enqueued element goes to the front (and to this listener)
+	value, err := st.front.DequeueOrWaitForNextElement()
+	if err != nil {
+		return nil, err
+	}
+
+	st.mutex.Lock()
+	st.promote()
+	st.mutex.Unlock()
+
+	return value, nil
+}
+
+// promote moves elements from the overflow to the front while it has room. st.mutex must be locked by the caller.
+func (st *BurstAbsorber) promote() {
+	for st.overflow.GetLen() > 0 && st.front.GetLen() < st.front.GetCap() {
+		value, err := st.overflow.Dequeue()
+		if err != nil {
+			return
+		}
+		st.front.Enqueue(value)
+	}
+}
+
+// GetLen returns the number of enqueued elements (front + overflow)
+func (st *BurstAbsorber) GetLen() int {
+	st.mutex.Lock()
+	defer st.mutex.Unlock()
+
+	return st.front.GetLen() + st.overflow.GetLen()
+}
+
+// GetFrontLen returns the number of elements at the front
+func (st *BurstAbsorber) GetFrontLen() int {
+	return st.front.GetLen()
+}
+
+// GetOverflowLen returns the number of elements at the overflow
+func (st *BurstAbsorber) GetOverflowLen() int {
+	return st.overflow.GetLen()
+}
+
+// GetCap returns the queue's capacity (front + overflow)
+func (st *BurstAbsorber) GetCap() int {
+	st.mutex.Lock()
+	defer st.mutex.Unlock()
+
+	if st.overflowCapacity > 0 {
+		return st.front.GetCap() + st.overflowCapacity
+	}
+
+	return st.front.GetCap() + st.overflow.GetCap()
+}
+
+// Lock // Locks the queue. No enqueue/dequeue operations will be allowed after this point.
+// The DequeueOrWaitForNextElement callers already waiting keep waiting: no element gets enqueued while the queue is
+// locked, they get the first ones enqueued once it is unlocked.
+func (st *BurstAbsorber) Lock() {
+	st.lockRWmutex.Lock()
+	defer st.lockRWmutex.Unlock()
+
+	st.isLocked = true
+}
+
+// Unlock unlocks the queue
+func (st *BurstAbsorber) Unlock() {
+	st.lockRWmutex.Lock()
+	defer st.lockRWmutex.Unlock()
+
+	st.isLocked = false
+}
+
+// IsLocked returns true whether the queue is locked
+func (st *BurstAbsorber) IsLocked() bool {
+	st.lockRWmutex.RLock()
+	defer st.lockRWmutex.RUnlock()
+
+	return st.isLocked
+}
@@ -0,0 +1,240 @@
+package goconcurrentqueue
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+const (
+	burstAbsorberFrontCapacity    = 5
+	burstAbsorberOverflowCapacity = 10
+)
+
+type BurstAbsorberTestSuite struct {
+	suite.Suite
+	queue *BurstAbsorber
+}
+
+func (suite *BurstAbsorberTestSuite) SetupTest() {
+	suite.queue = NewBurstAbsorber(burstAbsorberFrontCapacity, burstAbsorberOverflowCapacity)
+}
+
+// ***************************************************************************************
+// ** Queue initialization
+// ***************************************************************************************
+
+// no elements at initialization
+func (suite *BurstAbsorberTestSuite) TestNoElementsAtInitialization() {
+	suite.Equal(0, suite.queue.GetLen())
+	suite.Equal(burstAbsorberFrontCapacity+burstAbsorberOverflowCapacity, suite.queue.GetCap())
+	suite.False(suite.queue.IsLocked())
+}
+
+// ***************************************************************************************
+// ** Enqueue && Dequeue
+// ***************************************************************************************
+
+// locked queue
+func (suite *BurstAbsorberTestSuite) TestLockSingleGR() {
+	suite.queue.Lock()
+	suite.Equal(ErrLockedQueue, suite.queue.Enqueue(1))
+
+	_, err := suite.queue.Dequeue()
+	suite.Equal(ErrLockedQueue, err)
+	_, err = suite.queue.DequeueOrWaitForNextElement()
+	suite.Equal(ErrLockedQueue, err)
+
+	suite.queue.Unlock()
+	suite.NoError(suite.queue.Enqueue(1))
+}
+
+// bursts spill over into the overflow, which is promoted as the front drains, keeping the FIFO order
+func (suite *BurstAbsorberTestSuite) TestOverflowPromotionSingleGR() {
+	total := burstAbsorberFrontCapacity + burstAbsorberOverflowCapacity
+	for i := 0; i < total; i++ {
+		suite.NoError(suite.queue.Enqueue(i))
+	}
+	suite.Equal(burstAbsorberFrontCapacity, suite.queue.GetFrontLen())
+	suite.Equal(burstAbsorberOverflowCapacity, suite.queue.GetOverflowLen())
+
+	for i := 0; i < total; i++ {
+		value, err := suite.queue.Dequeue()
+		suite.NoError(err)
+		suite.Equal(i, value)
+
+		// the front is refilled while the overflow has elements
+		if suite.queue.GetOverflowLen() > 0 {
+			suite.Equal(burstAbsorberFrontCapacity, suite.queue.GetFrontLen())
+		}
+	}
+
+	_, err := suite.queue.Dequeue()
+	suite.Equal(ErrEmptyQueue, err)
+}
+
+// enqueue over the overflow limit
+func (suite *BurstAbsorberTestSuite) TestFullCapacitySingleGR() {
+	for i := 0; i < burstAbsorberFrontCapacity+burstAbsorberOverflowCapacity; i++ {
+		suite.NoError(suite.queue.Enqueue(i))
+	}
+
+	err := suite.queue.Enqueue(testValue)
+	suite.Error(err)
+	customError, ok := err.(*QueueError)
+	suite.True(ok, "Expected error type: QueueError")
+	suite.Equal(QueueErrorCodeFullCapacity, customError.Code())
+}
+
+// unlimited overflow
+func (suite *BurstAbsorberTestSuite) TestUnlimitedOverflowSingleGR() {
+	queue := NewBurstAbsorber(2, 0)
+	for i := 0; i < 1000; i++ {
+		suite.NoError(queue.Enqueue(i))
+	}
+	suite.Equal(1000, queue.GetLen())
+}
+
+// overflow policies at the overflow limit: the front is never touched
+func (suite *BurstAbsorberTestSuite) TestOverflowPolicies() {
+	for policy, expected := range map[OverflowPolicy][]interface{}{
+		OverflowPolicyReject:     {0, 1, 2},
+		OverflowPolicyDropOldest: {0, 2, 3},
+		OverflowPolicyDropNewest: {0, 1, 2},
+	} {
+		queue, err := NewBurstAbsorberWithPolicy(1, 2, policy)
+		suite.Require().NoError(err)
+		for i := 0; i < 3; i++ {
+			suite.NoError(queue.Enqueue(i))
+		}
+
+		err = queue.Enqueue(3)
+		if policy == OverflowPolicyReject {
+			suite.Equal(QueueErrorCodeFullCapacity, errorCode(err))
+			suite.Equal(uint64(0), queue.GetEvictions())
+		} else {
+			suite.NoError(err, policy.String())
+			suite.Equal(uint64(1), queue.GetEvictions(), policy.String())
+		}
+		suite.Equal(policy, queue.GetOverflowPolicy())
+
+		dequeued := make([]interface{}, 0)
+		for queue.GetLen() > 0 {
+			value, err := queue.Dequeue()
+			suite.Require().NoError(err)
+			dequeued = append(dequeued, value)
+		}
+		suite.Equal(expected, dequeued, policy.String())
+	}
+}
+
+// unsupported overflow policies
+func (suite *BurstAbsorberTestSuite) TestOverflowPolicyInvalid() {
+	for _, policy := range []OverflowPolicy{OverflowPolicyBlock, OverflowPolicy(100)} {
+		queue, err := NewBurstAbsorberWithPolicy(1, 1, policy)
+		suite.Nil(queue)
+		suite.Equal(QueueErrorCodeInvalidConfig, errorCode(err))
+
+		err = suite.queue.Reconfigure(WithOverflowPolicy(policy))
+		suite.Equal(QueueErrorCodeInvalidConfig, errorCode(err))
+	}
+
+	suite.NoError(suite.queue.Reconfigure(WithOverflowPolicy(OverflowPolicyDropNewest)))
+	suite.Equal(OverflowPolicyDropNewest, suite.queue.GetOverflowPolicy())
+}
+
+// ***************************************************************************************
+// ** DequeueOrWaitForNextElement
+// ***************************************************************************************
+
+// waiting for the next enqueued element
+func (suite *BurstAbsorberTestSuite) TestDequeueOrWaitForNextElementSingleGR() {
+	result := make(chan interface{}, 1)
+	go func() {
+		value, _ := suite.queue.DequeueOrWaitForNextElement()
+		result <- value
+	}()
+
+	time.Sleep(10 * time.Millisecond)
+	suite.NoError(suite.queue.Enqueue(testValue))
+
+	select {
+	case value := <-result:
+		suite.Equal(testValue, value)
+	case <-time.After(2 * time.Second):
+		suite.FailNow("too much time waiting for the enqueued element")
+	}
+	suite.Equal(0, suite.queue.GetLen())
+}
+
+// consumers waiting while the queue gets locked get the first element enqueued once it is unlocked
+func (suite *BurstAbsorberTestSuite) TestDequeueOrWaitForNextElementLocked() {
+	result := make(chan interface{}, 1)
+	go func() {
+		value, _ := suite.queue.DequeueOrWaitForNextElement()
+		result <- value
+	}()
+	time.Sleep(10 * time.Millisecond)
+
+	suite.queue.Lock()
+	suite.Equal(ErrLockedQueue, suite.queue.Enqueue(1))
+	suite.queue.Unlock()
+	suite.NoError(suite.queue.Enqueue(testValue))
+
+	select {
+	case value := <-result:
+		suite.Equal(testValue, value)
+	case <-time.After(2 * time.Second):
+		suite.FailNow("too much time waiting for the enqueued element")
+	}
+}
+
+// producers and consumers: every element is dequeued exactly once
+func (suite *BurstAbsorberTestSuite) TestEnqueueDequeueMultipleGRs() {
+	var (
+		queue     = NewBurstAbsorber(burstAbsorberFrontCapacity, 0)
+		totalGRs  = 10
+		perGR     = 100
+		wg        sync.WaitGroup
+		results   = make(chan int, totalGRs*perGR)
+		delivered = make(map[int]int)
+	)
+
+	for g := 0; g < totalGRs; g++ {
+		wg.Add(2)
+		go func(producer int) {
+			defer wg.Done()
+			for i := 0; i < perGR; i++ {
+				suite.NoError(queue.Enqueue(producer*perGR + i))
+			}
+		}(g)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < perGR; i++ {
+				value, err := queue.DequeueOrWaitForNextElement()
+				suite.NoError(err)
+				results <- value.(int)
+			}
+		}()
+	}
+	wg.Wait()
+	close(results)
+
+	for value := range results {
+		delivered[value]++
+	}
+	suite.Len(delivered, totalGRs*perGR)
+	for value, times := range delivered {
+		suite.Equalf(1, times, "%v dequeued %v times", value, times)
+	}
+}
+
+// ***************************************************************************************
+// ** Run suite
+// ***************************************************************************************
+
+func TestBurstAbsorberTestSuite(t *testing.T) {
+	suite.Run(t, new(BurstAbsorberTestSuite))
+}
@@ -0,0 +1,153 @@
+package goconcurrentqueue
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// CircuitBreakerState is the state of a CircuitBreaker
+type CircuitBreakerState int
+
+const (
+	// CircuitBreakerClosed lets the workers consume
+	CircuitBreakerClosed CircuitBreakerState = iota
+	// CircuitBreakerOpen pauses the consumption until the cooldown is over
+	CircuitBreakerOpen
+)
+
+// CircuitBreakerStats is a snapshot of a CircuitBreaker's state, see CircuitBreaker.Stats
+type CircuitBreakerStats struct {
+	State CircuitBreakerState
+	// handler invocations and failures within the current window
+	Handled  int
+	Failures int
+	// number of times the breaker opened
+	Trips uint64
+	// end of the cooldown (open breaker), zero otherwise
+	OpenUntil time.Time
+}
+
+// CircuitBreaker pauses a Consume call (see ConsumeCircuitBreaker) once its handler fails too often, so a failing
+// downstream doesn't burn through the queue: once the handler's error rate over the last window invocations reaches
+// the threshold, the workers stop dequeuing for the cooldown period. The window starts over afterwards.
+// A CircuitBreaker could be shared by several Consume calls (i.e. queues feeding the same downstream).
+type CircuitBreaker struct {
+	mutex     sync.Mutex
+	threshold float64
+	window    int
+	cooldown  time.Duration
+	// outcomes of the current window (true == failed), in a ring
+	outcomes  []bool
+	next      int
+	failures  int
+	trips     uint64
+	openUntil time.Time
+}
+
+// NewCircuitBreaker returns a new closed CircuitBreaker opening for cooldown once the error rate over the last window
+// handler invocations reaches threshold. Returns error if threshold isn't within (0, 1], or window / cooldown aren't
+// positive.
+func NewCircuitBreaker(threshold float64, window int, cooldown time.Duration) (*CircuitBreaker, error) {
+	if threshold <= 0 || threshold > 1 {
+		return nil, NewQueueError(QueueErrorCodeInvalidConfig, fmt.Sprintf("invalid threshold: %v", threshold))
+	}
+	if window < 1 {
+		return nil, NewQueueError(QueueErrorCodeInvalidConfig, fmt.Sprintf("invalid window: %v", window))
+	}
+	if cooldown <= 0 {
+		return nil, NewQueueError(QueueErrorCodeInvalidConfig, fmt.Sprintf("invalid cooldown: %v", cooldown))
+	}
+
+	return &CircuitBreaker{
+		threshold: threshold,
+		window:    window,
+		cooldown:  cooldown,
+		outcomes:  make([]bool, 0, window),
+	}, nil
+}
+
+// Stats returns the breaker's state
+func (st *CircuitBreaker) Stats() CircuitBreakerStats {
+	return st.stats(time.Now())
+}
+
+func (st *CircuitBreaker) stats(now time.Time) CircuitBreakerStats {
+	st.mutex.Lock()
+	defer st.mutex.Unlock()
+
+	stats := CircuitBreakerStats{
+		Handled:  len(st.outcomes),
+		Failures: st.failures,
+		Trips:    st.trips,
+	}
+	if now.Before(st.openUntil) {
+		stats.State = CircuitBreakerOpen
+		stats.OpenUntil = st.openUntil
+	}
+
+	return stats
+}
+
+// record records a handler's outcome, opening the breaker if the error rate reaches the threshold
+func (st *CircuitBreaker) record(failed bool, now time.Time) {
+	st.mutex.Lock()
+	defer st.mutex.Unlock()
+
+	// outcomes of invocations started before the breaker opened
+	if now.Before(st.openUntil) {
+		return
+	}
+
+	if len(st.outcomes) < st.window {
+		st.outcomes = append(st.outcomes, failed)
+	} else {
+		if st.outcomes[st.next] {
+			st.failures--
+		}
+		st.outcomes[st.next] = failed
+		st.next = (st.next + 1) % st.window
+	}
+	if failed {
+		st.failures++
+	}
+
+	if len(st.outcomes) == st.window && float64(st.failures) >= st.threshold*float64(st.window) {
+		st.openUntil = now.Add(st.cooldown)
+		st.trips++
+		st.outcomes = st.outcomes[:0]
+		st.next = 0
+		st.failures = 0
+	}
+}
+
+// pause returns how long the consumption has to wait until the breaker closes (0 if it is closed)
+func (st *CircuitBreaker) pause(now time.Time) time.Duration {
+	st.mutex.Lock()
+	defer st.mutex.Unlock()
+
+	if now.Before(st.openUntil) {
+		return st.openUntil.Sub(now)
+	}
+
+	return 0
+}
+
+// wait waits until the breaker is closed. Returns false if ctx got done before.
+func (st *CircuitBreaker) wait(ctx context.Context) bool {
+	for {
+		delay := st.pause(time.Now())
+		if delay == 0 {
+			return true
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return false
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package goconcurrentqueue
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type CircuitBreakerTestSuite struct {
+	suite.Suite
+}
+
+// the breaker opens once the error rate over a full window reaches the threshold, the window starts over afterwards
+func (suite *CircuitBreakerTestSuite) TestRecord() {
+	breaker, err := NewCircuitBreaker(0.5, 4, time.Second)
+	suite.Require().NoError(err)
+	now := time.Now()
+
+	breaker.record(true, now)
+	breaker.record(true, now)
+	breaker.record(true, now)
+	suite.Equal(time.Duration(0), breaker.pause(now), "window not full yet")
+	breaker.record(false, now)
+	suite.Equal(time.Second, breaker.pause(now))
+
+	stats := breaker.stats(now)
+	suite.Equal(CircuitBreakerOpen, stats.State)
+	suite.Equal(uint64(1), stats.Trips)
+	suite.Equal(now.Add(time.Second), stats.OpenUntil)
+
+	// outcomes while open are ignored
+	breaker.record(true, now)
+	now = now.Add(time.Second)
+	suite.Equal(CircuitBreakerStats{State: CircuitBreakerClosed, Trips: 1}, breaker.stats(now))
+
+	// sliding window
+	for _, failed := range []bool{true, false, false, false, true} {
+		breaker.record(failed, now)
+	}
+	suite.Equal(time.Duration(0), breaker.pause(now))
+	breaker.record(true, now)
+	suite.Equal(time.Second, breaker.pause(now))
+}
+
+// invalid config
+func (suite *CircuitBreakerTestSuite) TestInvalid() {
+	for _, args := range []struct {
+		threshold float64
+		window    int
+		cooldown  time.Duration
+	}{{0, 1, time.Second}, {1.5, 1, time.Second}, {0.5, 0, time.Second}, {0.5, 1, 0}} {
+		_, err := NewCircuitBreaker(args.threshold, args.window, args.cooldown)
+		suite.Equal(QueueErrorCodeInvalidConfig, errorCode(err), args)
+	}
+}
+
+// Consume: a failing handler pauses the workers for the cooldown
+func (suite *CircuitBreakerTestSuite) TestConsume() {
+	var (
+		fifo        = NewFIFO()
+		errFailed   = errors.New("downstream failed")
+		cooldown    = 50 * time.Millisecond
+		ctx, cancel = context.WithTimeout(context.Background(), 2*time.Second)
+		handled     = make(chan time.Time, 10)
+	)
+	defer cancel()
+	breaker, err := NewCircuitBreaker(1, 2, cooldown)
+	suite.Require().NoError(err)
+	for i := 0; i < 3; i++ {
+		fifo.Enqueue(i)
+	}
+
+	go fifo.Consume(ctx, 1, func(value interface{}) error {
+		handled <- time.Now()
+		if value.(int) == 2 {
+			cancel()
+		}
+		return errFailed
+	}, ConsumeCircuitBreaker(breaker))
+
+	<-handled
+	second := <-handled
+	third := <-handled
+	suite.True(third.Sub(second) >= cooldown, "paused after 2 failures")
+	suite.Equal(uint64(1), breaker.Stats().Trips)
+}
+
+func TestCircuitBreakerTestSuite(t *testing.T) {
+	suite.Run(t, new(CircuitBreakerTestSuite))
+}
@@ -0,0 +1,178 @@
+package goconcurrentqueue
+
+import (
+	"fmt"
+	"time"
+)
+
+// Queue types (QueueConfig.Type)
+const (
+	QueueTypeFIFO               = "fifo"
+	QueueTypeFixedFIFO          = "fixed-fifo"
+	QueueTypeUnsynchronizedFIFO = "unsynchronized-fifo"
+	QueueTypeSPSC               = "spsc"
+	QueueTypeOrderingKeyFIFO    = "ordering-key-fifo"
+	QueueTypeBurstAbsorber      = "burst-absorber"
+	QueueTypeTTLFIFO            = "ttl-fifo"
+	QueueTypeDelayQueue         = "delay-queue"
+)
+
+var overflowPolicyNames = map[OverflowPolicy]string{
+	OverflowPolicyReject:     "reject",
+	OverflowPolicyDropOldest: "drop-oldest",
+	OverflowPolicyDropNewest: "drop-newest",
+	OverflowPolicyBlock:      "block",
+}
+
+// QueueConfig describes a queue's settings, so queues could be declared at config files (JSON) instead of code. See
+// NewFromConfig and the queues' Config method.
+// PriorityQueue can't be described: its comparator is code.
+type QueueConfig struct {
+	// one of the QueueTypeXXX constants
+	Type string `json:"type"`
+	// FixedFIFO, SPSCQueue: max number of elements. BurstAbsorber: front capacity.
+	Capacity int `json:"capacity,omitempty"`
+	// BurstAbsorber: overflow limit (0 means no limit)
+	OverflowCapacity int `json:"overflow_capacity,omitempty"`
+	// FixedFIFO: what to do at full capacity. BurstAbsorber: at the overflow limit (block isn't supported).
+	OverflowPolicy OverflowPolicy `json:"overflow_policy,omitempty"`
+	// FIFO: DequeueOrWaitForNextElement retries before parking (see FIFO.SetWaitSpins)
+	WaitSpins int `json:"wait_spins,omitempty"`
+	// TTLFIFO: Enqueue's TTL (see WithDefaultTTL)
+	DefaultTTL time.Duration `json:"default_ttl,omitempty"`
+}
+
+// NewFromConfig returns a new queue following the given configuration. Returns error if the configuration is invalid.
+func NewFromConfig(config QueueConfig) (Queue, error) {
+	if config.Capacity < 0 {
+		return nil, NewQueueError(QueueErrorCodeInvalidConfig, fmt.Sprintf("invalid capacity: %v", config.Capacity))
+	}
+	if config.OverflowCapacity < 0 {
+		return nil, NewQueueError(QueueErrorCodeInvalidConfig, fmt.Sprintf("invalid overflow capacity: %v", config.OverflowCapacity))
+	}
+	if config.WaitSpins < 0 {
+		return nil, NewQueueError(QueueErrorCodeInvalidConfig, fmt.Sprintf("invalid wait spins: %v", config.WaitSpins))
+	}
+	if config.DefaultTTL < 0 {
+		return nil, NewQueueError(QueueErrorCodeInvalidConfig, fmt.Sprintf("invalid TTL: %v", config.DefaultTTL))
+	}
+
+	switch config.Type {
+	case QueueTypeFIFO:
+		fifo := NewFIFO()
+		fifo.SetWaitSpins(config.WaitSpins)
+		return fifo, nil
+	case QueueTypeFixedFIFO:
+		if _, ok := overflowPolicyNames[config.OverflowPolicy]; !ok {
+			return nil, NewQueueError(QueueErrorCodeInvalidConfig, fmt.Sprintf("unknown overflow policy: %v", int(config.OverflowPolicy)))
+		}
+		return NewBoundedFIFO(config.Capacity, config.OverflowPolicy), nil
+	case QueueTypeUnsynchronizedFIFO:
+		return NewUnsynchronizedFIFO(), nil
+	case QueueTypeSPSC:
+		return NewSPSCQueue(config.Capacity), nil
+	case QueueTypeOrderingKeyFIFO:
+		return NewOrderingKeyFIFO(), nil
+	case QueueTypeBurstAbsorber:
+		queue, err := NewBurstAbsorberWithPolicy(config.Capacity, config.OverflowCapacity, config.OverflowPolicy)
+		if err != nil {
+			return nil, err
+		}
+		return queue, nil
+	case QueueTypeTTLFIFO:
+		ttlFIFO := NewTTLFIFO()
+		ttlFIFO.Reconfigure(WithDefaultTTL(config.DefaultTTL))
+		return ttlFIFO, nil
+	case QueueTypeDelayQueue:
+		return NewDelayQueue(), nil
+	}
+
+	return nil, NewQueueError(QueueErrorCodeInvalidConfig, fmt.Sprintf("unknown queue type: %q", config.Type))
+}
+
+// ***************************************************************************************
+// ** OverflowPolicy as text
+// ***************************************************************************************
+
+// String returns the policy's name
+func (st OverflowPolicy) String() string {
+	if name, ok := overflowPolicyNames[st]; ok {
+		return name
+	}
+
+	return fmt.Sprintf("OverflowPolicy(%d)", int(st))
+}
+
+// MarshalText encodes the policy as its name (i.e. "drop-oldest")
+func (st OverflowPolicy) MarshalText() ([]byte, error) {
+	if name, ok := overflowPolicyNames[st]; ok {
+		return []byte(name), nil
+	}
+
+	return nil, NewQueueError(QueueErrorCodeInvalidConfig, fmt.Sprintf("unknown overflow policy: %d", int(st)))
+}
+
+// UnmarshalText decodes the policy from its name
+func (st *OverflowPolicy) UnmarshalText(text []byte) error {
+	for policy, name := range overflowPolicyNames {
+		if name == string(text) {
+			*st = policy
+			return nil
+		}
+	}
+
+	return NewQueueError(QueueErrorCodeInvalidConfig, fmt.Sprintf("unknown overflow policy: %q", text))
+}
+
+// ***************************************************************************************
+// ** Config
+// ***************************************************************************************
+
+// Config returns the queue's configuration
+func (st *FIFO) Config() QueueConfig {
+	st.rwmutex.RLock()
+	defer st.rwmutex.RUnlock()
+
+	return QueueConfig{Type: QueueTypeFIFO, WaitSpins: st.waitSpins}
+}
+
+// Config returns the queue's configuration
+func (st *FixedFIFO) Config() QueueConfig {
+	return QueueConfig{Type: QueueTypeFixedFIFO, Capacity: st.GetCap(), OverflowPolicy: st.GetOverflowPolicy()}
+}
+
+// Config returns the queue's configuration
+func (st *UnsynchronizedFIFO) Config() QueueConfig {
+	return QueueConfig{Type: QueueTypeUnsynchronizedFIFO}
+}
+
+// Config returns the queue's configuration
+func (st *SPSCQueue) Config() QueueConfig {
+	return QueueConfig{Type: QueueTypeSPSC, Capacity: st.GetCap()}
+}
+
+// Config returns the queue's configuration
+func (st *OrderingKeyFIFO) Config() QueueConfig {
+	return QueueConfig{Type: QueueTypeOrderingKeyFIFO}
+}
+
+// Config returns the queue's configuration
+func (st *BurstAbsorber) Config() QueueConfig {
+	st.mutex.Lock()
+	defer st.mutex.Unlock()
+
+	return QueueConfig{Type: QueueTypeBurstAbsorber, Capacity: st.front.GetCap(), OverflowCapacity: st.overflowCapacity, OverflowPolicy: st.overflowPolicy}
+}
+
+// Config returns the queue's configuration
+func (st *TTLFIFO) Config() QueueConfig {
+	st.rwmutex.RLock()
+	defer st.rwmutex.RUnlock()
+
+	return QueueConfig{Type: QueueTypeTTLFIFO, DefaultTTL: st.defaultTTL}
+}
+
+// Config returns the queue's configuration
+func (st *DelayQueue) Config() QueueConfig {
+	return QueueConfig{Type: QueueTypeDelayQueue}
+}
@@ -0,0 +1,140 @@
+package goconcurrentqueue
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type QueueConfigTestSuite struct {
+	suite.Suite
+}
+
+// ***************************************************************************************
+// ** NewFromConfig
+// ***************************************************************************************
+
+// NewFromConfig returns the configured queue type
+func (suite *QueueConfigTestSuite) TestNewFromConfigTypes() {
+	configs := []QueueConfig{
+		{Type: QueueTypeFIFO, WaitSpins: 10},
+		{Type: QueueTypeFixedFIFO, Capacity: 5, OverflowPolicy: OverflowPolicyBlock},
+		{Type: QueueTypeUnsynchronizedFIFO},
+		{Type: QueueTypeSPSC, Capacity: 8},
+		{Type: QueueTypeOrderingKeyFIFO},
+		{Type: QueueTypeBurstAbsorber, Capacity: 4, OverflowCapacity: 16, OverflowPolicy: OverflowPolicyDropOldest},
+		{Type: QueueTypeTTLFIFO, DefaultTTL: time.Minute},
+		{Type: QueueTypeDelayQueue},
+	}
+
+	for _, config := range configs {
+		queue, err := NewFromConfig(config)
+		suite.NoErrorf(err, "no error expected for %v", config.Type)
+
+		configurable, ok := queue.(interface{ Config() QueueConfig })
+		suite.Truef(ok, "%v queue must expose its Config", config.Type)
+		suite.Equal(config, configurable.Config(), "Config must return the configuration the queue was created with")
+	}
+}
+
+// unknown queue type
+func (suite *QueueConfigTestSuite) TestNewFromConfigUnknownType() {
+	queue, err := NewFromConfig(QueueConfig{Type: "stack"})
+	suite.Nil(queue)
+	suite.Error(err, "error expected for an unknown queue type")
+	suite.Equal(QueueErrorCodeInvalidConfig, errorCode(err))
+}
+
+// unknown overflow policy
+func (suite *QueueConfigTestSuite) TestNewFromConfigUnknownOverflowPolicy() {
+	queue, err := NewFromConfig(QueueConfig{Type: QueueTypeFixedFIFO, Capacity: 1, OverflowPolicy: OverflowPolicy(100)})
+	suite.Nil(queue)
+	suite.Equal(QueueErrorCodeInvalidConfig, errorCode(err))
+}
+
+// BurstAbsorber doesn't block
+func (suite *QueueConfigTestSuite) TestNewFromConfigBurstAbsorberBlock() {
+	queue, err := NewFromConfig(QueueConfig{Type: QueueTypeBurstAbsorber, Capacity: 1, OverflowPolicy: OverflowPolicyBlock})
+	suite.Nil(queue)
+	suite.Equal(QueueErrorCodeInvalidConfig, errorCode(err))
+}
+
+// negative capacities / wait spins
+func (suite *QueueConfigTestSuite) TestNewFromConfigNegativeValues() {
+	configs := map[string]QueueConfig{
+		"capacity":          {Type: QueueTypeFixedFIFO, Capacity: -1},
+		"overflow capacity": {Type: QueueTypeBurstAbsorber, Capacity: 1, OverflowCapacity: -1},
+		"wait spins":        {Type: QueueTypeFIFO, WaitSpins: -1},
+		"default TTL":       {Type: QueueTypeTTLFIFO, DefaultTTL: -1},
+	}
+
+	for name, config := range configs {
+		queue, err := NewFromConfig(config)
+		suite.Nil(queue, name)
+		suite.Equal(QueueErrorCodeInvalidConfig, errorCode(err), name)
+	}
+}
+
+// Config reflects the changes made on a live queue
+func (suite *QueueConfigTestSuite) TestConfigAfterChanges() {
+	fixedFIFO := NewFixedFIFO(3)
+	fixedFIFO.SetOverflowPolicy(OverflowPolicyDropNewest)
+	suite.Equal(QueueConfig{Type: QueueTypeFixedFIFO, Capacity: 3, OverflowPolicy: OverflowPolicyDropNewest}, fixedFIFO.Config())
+
+	fifo := NewFIFO()
+	fifo.SetWaitSpins(50)
+	suite.Equal(QueueConfig{Type: QueueTypeFIFO, WaitSpins: 50}, fifo.Config())
+}
+
+// ***************************************************************************************
+// ** JSON
+// ***************************************************************************************
+
+// JSON documents get decoded into configurations
+func (suite *QueueConfigTestSuite) TestUnmarshalJSON() {
+	var config QueueConfig
+	err := json.Unmarshal([]byte(`{"type": "fixed-fifo", "capacity": 100, "overflow_policy": "drop-oldest"}`), &config)
+	suite.NoError(err)
+	suite.Equal(QueueConfig{Type: QueueTypeFixedFIFO, Capacity: 100, OverflowPolicy: OverflowPolicyDropOldest}, config)
+
+	queue, err := NewFromConfig(config)
+	suite.NoError(err)
+	suite.Equal(100, queue.GetCap())
+}
+
+// unknown overflow policy names are rejected
+func (suite *QueueConfigTestSuite) TestUnmarshalJSONUnknownOverflowPolicy() {
+	var config QueueConfig
+	err := json.Unmarshal([]byte(`{"type": "fixed-fifo", "overflow_policy": "drop-everything"}`), &config)
+	suite.Error(err, "error expected for an unknown overflow policy")
+}
+
+// Config -> JSON -> NewFromConfig gives an equivalent queue
+func (suite *QueueConfigTestSuite) TestJSONRoundTrip() {
+	bounded := NewBoundedFIFO(7, OverflowPolicyBlock)
+
+	data, err := json.Marshal(bounded.Config())
+	suite.NoError(err)
+	suite.JSONEq(`{"type": "fixed-fifo", "capacity": 7, "overflow_policy": "block"}`, string(data))
+
+	var config QueueConfig
+	suite.NoError(json.Unmarshal(data, &config))
+	queue, err := NewFromConfig(config)
+	suite.NoError(err)
+	suite.Equal(bounded.Config(), queue.(*FixedFIFO).Config())
+}
+
+// OverflowPolicy names
+func (suite *QueueConfigTestSuite) TestOverflowPolicyString() {
+	suite.Equal("reject", OverflowPolicyReject.String())
+	suite.Equal("drop-oldest", OverflowPolicyDropOldest.String())
+	suite.Equal("drop-newest", OverflowPolicyDropNewest.String())
+	suite.Equal("block", OverflowPolicyBlock.String())
+	suite.Equal("OverflowPolicy(100)", OverflowPolicy(100).String())
+}
+
+func TestQueueConfigTestSuite(t *testing.T) {
+	suite.Run(t, new(QueueConfigTestSuite))
+}
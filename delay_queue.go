@@ -0,0 +1,215 @@
+package goconcurrentqueue
+
+import (
+	"container/heap"
+	"context"
+	"sync"
+	"time"
+)
+
+// delayQueueElement is an element enqueued into a DelayQueue
+type delayQueueElement struct {
+	value interface{}
+	// the element is invisible to the dequeue operations until this moment
+	readyAt time.Time
+}
+
+// DelayQueue concurrent queue where every element has a visibility delay: it can't be dequeued until its delay
+// elapses. Ready elements are dequeued by ready time (enqueue order for equal ready times).
+type DelayQueue struct {
+	heap        priorityQueueHeap
+	rwmutex     sync.RWMutex
+	lockRWmutex sync.RWMutex
+	isLocked    bool
+	// closed (and replaced) every time the earliest element changes, to wake up DequeueOrWaitForNextElement callers
+	earliestChangedChan chan struct{}
+}
+
+// NewDelayQueue returns a new DelayQueue
+func NewDelayQueue() *DelayQueue {
+	ret := &DelayQueue{}
+	ret.initialize()
+
+	return ret
+}
+
+func (st *DelayQueue) initialize() {
+	st.heap = priorityQueueHeap{
+		elements: make([]priorityQueueElement, 0),
+		less: func(a, b interface{}) bool {
+			return a.(delayQueueElement).readyAt.Before(b.(delayQueueElement).readyAt)
+		},
+		stable: true,
+	}
+	st.earliestChangedChan = make(chan struct{})
+}
+
+// Enqueue enqueues an element with no delay. Returns error if queue is locked.
+func (st *DelayQueue) Enqueue(value interface{}) error {
+	return st.EnqueueWithDelay(value, 0)
+}
+
+// EnqueueWithDelay enqueues an element that can't be dequeued until the delay elapses. Returns error if queue is
+// locked.
+func (st *DelayQueue) EnqueueWithDelay(value interface{}, delay time.Duration) error {
+	if st.IsLocked() {
+		return ErrLockedQueue
+	}
+
+	element := delayQueueElement{
+		value:   value,
+		readyAt: time.Now().Add(delay),
+	}
+
+	st.rwmutex.Lock()
+	defer st.rwmutex.Unlock()
+
+	heap.Push(&st.heap, element)
+	// the waiting consumers must re-schedule their wake up if this element is the earliest one
+	if st.heap.elements[0].sequence == st.heap.sequence-1 {
+		close(st.earliestChangedChan)
+		st.earliestChangedChan = make(chan struct{})
+	}
+
+	return nil
+}
+
+// Dequeue dequeues the earliest ready element. Returns error if queue is locked or there is no ready element.
+func (st *DelayQueue) Dequeue() (interface{}, error) {
+	if st.IsLocked() {
+		return nil, ErrLockedQueue
+	}
+
+	st.rwmutex.Lock()
+	defer st.rwmutex.Unlock()
+
+	value, ok, _ := st.dequeueReady(time.Now())
+	if !ok {
+		return nil, ErrEmptyQueue
+	}
+
+	return value, nil
+}
+
+// DequeueOrWaitForNextElement dequeues the earliest ready element or waits until there is one: either the delay of an
+// enqueued element elapses or a new element with no delay gets enqueued.
+func (st *DelayQueue) DequeueOrWaitForNextElement() (interface{}, error) {
+	return st.DequeueOrWaitForNextElementWithContext(context.Background())
+}
+
+// DequeueOrWaitForNextElementWithContext works as DequeueOrWaitForNextElement, but it stops waiting once the context
+// is done, returning ctx.Err().
+func (st *DelayQueue) DequeueOrWaitForNextElementWithContext(ctx context.Context) (interface{}, error) {
+	for {
+		if st.IsLocked() {
+			return nil, ErrLockedQueue
+		}
+
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		st.rwmutex.Lock()
+		value, ok, wait := st.dequeueReady(time.Now())
+		earliestChangedChan := st.earliestChangedChan
+		st.rwmutex.Unlock()
+
+		if ok {
+			return value, nil
+		}
+
+		// wake up exactly when the earliest element becomes ready (no timer while the queue is empty)
+		var (
+			timer     *time.Timer
+			readyChan <-chan time.Time
+		)
+		if wait > 0 {
+			timer = time.NewTimer(wait)
+			readyChan = timer.C
+		}
+
+		select {
+		case <-readyChan:
+		case <-earliestChangedChan:
+		case <-ctx.Done():
+		}
+
+		if timer != nil {
+			timer.Stop()
+		}
+	}
+}
+
+// dequeueReady removes and returns the earliest element if it is ready at the given moment, otherwise returns the time
+// left until it gets ready (0 if the queue is empty). st.rwmutex must be locked by the caller.
+func (st *DelayQueue) dequeueReady(now time.Time) (interface{}, bool, time.Duration) {
+	if st.heap.Len() == 0 {
+		return nil, false, 0
+	}
+
+	earliest := st.heap.elements[0].value.(delayQueueElement)
+	if wait := earliest.readyAt.Sub(now); wait > 0 {
+		return nil, false, wait
+	}
+
+	heap.Pop(&st.heap)
+
+	return earliest.value, true, 0
+}
+
+// Clear atomically removes every element (ready or not), releasing the references. Returns error if queue is locked.
+func (st *DelayQueue) Clear() error {
+	if st.IsLocked() {
+		return ErrLockedQueue
+	}
+
+	st.rwmutex.Lock()
+	defer st.rwmutex.Unlock()
+
+	st.heap.elements = make([]priorityQueueElement, 0)
+	// the waiting consumers must not wake up for the removed elements
+	close(st.earliestChangedChan)
+	st.earliestChangedChan = make(chan struct{})
+
+	return nil
+}
+
+// GetLen returns the number of enqueued elements, ready or not
+func (st *DelayQueue) GetLen() int {
+	st.rwmutex.RLock()
+	defer st.rwmutex.RUnlock()
+
+	return st.heap.Len()
+}
+
+// GetCap returns the queue's capacity
+func (st *DelayQueue) GetCap() int {
+	st.rwmutex.RLock()
+	defer st.rwmutex.RUnlock()
+
+	return cap(st.heap.elements)
+}
+
+// Lock // Locks the queue. No enqueue/dequeue operations will be allowed after this point.
+func (st *DelayQueue) Lock() {
+	st.lockRWmutex.Lock()
+	defer st.lockRWmutex.Unlock()
+
+	st.isLocked = true
+}
+
+// Unlock unlocks the queue
+func (st *DelayQueue) Unlock() {
+	st.lockRWmutex.Lock()
+	defer st.lockRWmutex.Unlock()
+
+	st.isLocked = false
+}
+
+// IsLocked returns true whether the queue is locked
+func (st *DelayQueue) IsLocked() bool {
+	st.lockRWmutex.RLock()
+	defer st.lockRWmutex.RUnlock()
+
+	return st.isLocked
+}
@@ -0,0 +1,231 @@
+package goconcurrentqueue
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+const (
+	delayQueueTestDelay = 50 * time.Millisecond
+)
+
+type DelayQueueTestSuite struct {
+	suite.Suite
+	queue *DelayQueue
+}
+
+func (suite *DelayQueueTestSuite) SetupTest() {
+	suite.queue = NewDelayQueue()
+}
+
+// ***************************************************************************************
+// ** Enqueue && Dequeue
+// ***************************************************************************************
+
+// no delay: ready right away, in enqueue order
+func (suite *DelayQueueTestSuite) TestEnqueueNoDelay() {
+	for i := 0; i < 10; i++ {
+		suite.NoError(suite.queue.Enqueue(i))
+	}
+
+	for i := 0; i < 10; i++ {
+		value, err := suite.queue.Dequeue()
+		suite.NoError(err)
+		suite.Equal(i, value)
+	}
+}
+
+// delayed element is invisible until its delay elapses
+func (suite *DelayQueueTestSuite) TestEnqueueWithDelay() {
+	suite.NoError(suite.queue.EnqueueWithDelay(testValue, delayQueueTestDelay))
+	suite.Equal(1, suite.queue.GetLen())
+
+	_, err := suite.queue.Dequeue()
+	suite.Equal(ErrEmptyQueue, err)
+
+	time.Sleep(delayQueueTestDelay)
+	value, err := suite.queue.Dequeue()
+	suite.NoError(err)
+	suite.Equal(testValue, value)
+	suite.Equal(0, suite.queue.GetLen())
+}
+
+// ready elements are dequeued by ready time
+func (suite *DelayQueueTestSuite) TestDequeueByReadyTime() {
+	suite.queue.EnqueueWithDelay(2, 2*time.Millisecond)
+	suite.queue.EnqueueWithDelay(1, time.Millisecond)
+	suite.queue.Enqueue(0)
+	suite.queue.EnqueueWithDelay(3, time.Hour)
+
+	time.Sleep(5 * time.Millisecond)
+	for i := 0; i < 3; i++ {
+		value, err := suite.queue.Dequeue()
+		suite.NoError(err)
+		suite.Equal(i, value)
+	}
+
+	_, err := suite.queue.Dequeue()
+	suite.Equal(ErrEmptyQueue, err)
+	suite.Equal(1, suite.queue.GetLen())
+}
+
+// locked queue
+func (suite *DelayQueueTestSuite) TestLockedQueue() {
+	suite.queue.Enqueue(1)
+	suite.queue.Lock()
+	suite.True(suite.queue.IsLocked())
+
+	suite.Equal(ErrLockedQueue, suite.queue.Enqueue(2))
+	_, err := suite.queue.Dequeue()
+	suite.Equal(ErrLockedQueue, err)
+	_, err = suite.queue.DequeueOrWaitForNextElement()
+	suite.Equal(ErrLockedQueue, err)
+
+	suite.queue.Unlock()
+	suite.Equal(1, suite.queue.GetLen())
+}
+
+// ***************************************************************************************
+// ** DequeueOrWaitForNextElement
+// ***************************************************************************************
+
+// the waiter wakes up when the delayed element gets ready
+func (suite *DelayQueueTestSuite) TestDequeueOrWaitForNextElementDelayed() {
+	start := time.Now()
+	suite.queue.EnqueueWithDelay(testValue, delayQueueTestDelay)
+
+	value, err := suite.queue.DequeueOrWaitForNextElement()
+	suite.NoError(err)
+	suite.Equal(testValue, value)
+	suite.True(time.Since(start) >= delayQueueTestDelay, "element dequeued before its delay elapsed")
+}
+
+// an earlier element enqueued while waiting for a later one
+func (suite *DelayQueueTestSuite) TestDequeueOrWaitForNextElementEarlierElement() {
+	suite.queue.EnqueueWithDelay(1, time.Hour)
+
+	result := make(chan interface{}, 1)
+	go func() {
+		value, _ := suite.queue.DequeueOrWaitForNextElement()
+		result <- value
+	}()
+	time.Sleep(10 * time.Millisecond)
+	suite.queue.EnqueueWithDelay(2, 10*time.Millisecond)
+
+	select {
+	case value := <-result:
+		suite.Equal(2, value)
+	case <-time.After(2 * time.Second):
+		suite.FailNow("too much time waiting for the earlier element")
+	}
+}
+
+// empty queue: waits for the next enqueued element
+func (suite *DelayQueueTestSuite) TestDequeueOrWaitForNextElementEmptyQueue() {
+	result := make(chan interface{}, 1)
+	go func() {
+		value, _ := suite.queue.DequeueOrWaitForNextElement()
+		result <- value
+	}()
+	time.Sleep(10 * time.Millisecond)
+	suite.queue.Enqueue(testValue)
+
+	select {
+	case value := <-result:
+		suite.Equal(testValue, value)
+	case <-time.After(2 * time.Second):
+		suite.FailNow("too much time waiting for the enqueued element")
+	}
+}
+
+// context deadline exceeded before the element gets ready
+func (suite *DelayQueueTestSuite) TestDequeueOrWaitForNextElementWithContextDeadline() {
+	suite.queue.EnqueueWithDelay(testValue, time.Hour)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err := suite.queue.DequeueOrWaitForNextElementWithContext(ctx)
+	suite.Equal(context.DeadlineExceeded, err)
+	suite.Equal(1, suite.queue.GetLen())
+}
+
+// several waiters, delayed elements: every element gets dequeued once
+func (suite *DelayQueueTestSuite) TestDequeueOrWaitForNextElementMultipleGRs() {
+	var (
+		total    = 100
+		wg       sync.WaitGroup
+		mutex    sync.Mutex
+		dequeued = make(map[interface{}]int)
+	)
+
+	for i := 0; i < total; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			value, err := suite.queue.DequeueOrWaitForNextElement()
+			suite.NoError(err)
+			mutex.Lock()
+			dequeued[value]++
+			mutex.Unlock()
+		}()
+	}
+
+	for i := 0; i < total; i++ {
+		suite.queue.EnqueueWithDelay(i, time.Duration(i%10)*time.Millisecond)
+	}
+	wg.Wait()
+
+	suite.Len(dequeued, total)
+	suite.Equal(0, suite.queue.GetLen())
+}
+
+// ***************************************************************************************
+// ** Clear
+// ***************************************************************************************
+
+// every element (ready or not) gets removed
+func (suite *DelayQueueTestSuite) TestClear() {
+	suite.queue.Enqueue(1)
+	suite.queue.EnqueueWithDelay(2, time.Hour)
+
+	suite.NoError(suite.queue.Clear())
+	suite.Equal(0, suite.queue.GetLen())
+
+	suite.queue.Lock()
+	suite.Equal(ErrLockedQueue, suite.queue.Clear())
+}
+
+// a consumer waiting for a delayed element keeps waiting for the next one
+func (suite *DelayQueueTestSuite) TestClearWaitingConsumer() {
+	suite.queue.EnqueueWithDelay(1, 50*time.Millisecond)
+
+	result := make(chan interface{}, 1)
+	go func() {
+		value, _ := suite.queue.DequeueOrWaitForNextElement()
+		result <- value
+	}()
+	time.Sleep(10 * time.Millisecond)
+
+	suite.NoError(suite.queue.Clear())
+	suite.NoError(suite.queue.EnqueueWithDelay(2, 100*time.Millisecond))
+
+	select {
+	case value := <-result:
+		suite.Equal(2, value)
+	case <-time.After(2 * time.Second):
+		suite.FailNow("too much time waiting for the element")
+	}
+}
+
+// ***************************************************************************************
+// ** Run suite
+// ***************************************************************************************
+
+func TestDelayQueueTestSuite(t *testing.T) {
+	suite.Run(t, new(DelayQueueTestSuite))
+}
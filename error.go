@@ -9,6 +9,35 @@ const (
 	QueueErrorCodeIndexesMatch          = "indexes-match"
 	QueueErrorCodeIndexFirstPosition    = "index-first-position"
 	QueueErrorCodeIndexLastPosition     = "index-last-position"
+	QueueErrorCodeInvalidElementType    = "invalid-element-type"
+	QueueErrorCodeTimeout               = "timeout"
+	QueueErrorCodeClaimedElement        = "claimed-element"
+	QueueErrorCodeInvalidClaim          = "invalid-claim"
+	QueueErrorCodeConcurrentAccess      = "concurrent-access"
+	QueueErrorCodeInvalidConfig         = "invalid-config"
+	QueueErrorCodeUnknownGroup          = "unknown-group"
+	QueueErrorCodeDuplicatedName        = "duplicated-name"
+	QueueErrorCodeSampledOut            = "sampled-out"
+	QueueErrorCodeResultAlreadySet      = "result-already-set"
+	QueueErrorCodeInvalidTopic          = "invalid-topic"
+	QueueErrorCodeHandlerPanic          = "handler-panic"
+	QueueErrorCodeUnsupportedQueue      = "unsupported-queue"
+	QueueErrorCodeDuplicatedQueue       = "duplicated-queue"
+	QueueErrorCodeNoCompatibleWorker    = "no-compatible-worker"
+	QueueErrorCodeInvalidCost           = "invalid-cost"
+)
+
+// Shared errors returned by the hot paths (enqueue/dequeue), so failed operations don't allocate a new error every time.
+// Use errors.Is(err, ErrEmptyQueue) or compare Code() to check them.
+var (
+	ErrLockedQueue  = NewQueueError(QueueErrorCodeLockedQueue, "The queue is locked")
+	ErrEmptyQueue   = NewQueueError(QueueErrorCodeEmptyQueue, "empty queue")
+	ErrFullCapacity = NewQueueError(QueueErrorCodeFullCapacity, "FixedFIFO queue is at full capacity")
+	ErrTimeout      = NewQueueError(QueueErrorCodeTimeout, "timeout waiting for the next element")
+	// returned by SPSCQueue once it detects a second concurrent producer (or consumer)
+	ErrConcurrentAccess = NewQueueError(QueueErrorCodeConcurrentAccess, "SPSCQueue accessed by multiple producers / consumers at the same time")
+	// returned by FixedFIFO's sampling admission (SetSamplingAdmission)
+	ErrSampledOut = NewQueueError(QueueErrorCodeSampledOut, "element rejected by the sampling admission")
 )
 
 type QueueError struct {
@@ -30,3 +59,14 @@ func (st *QueueError) Error() string {
 func (st *QueueError) Code() string {
 	return st.code
 }
+
+// Is returns true whether target is a *QueueError with the same code. It makes errors.Is(err, ErrXXX) work no matter
+// which QueueError instance was returned.
+func (st *QueueError) Is(target error) bool {
+	queueError, ok := target.(*QueueError)
+	if !ok || st == nil || queueError == nil {
+		return false
+	}
+
+	return st.code == queueError.code
+}
@@ -0,0 +1,21 @@
+//go:build go1.13
+// +build go1.13
+
+package goconcurrentqueue
+
+import (
+	"errors"
+	"fmt"
+)
+
+// errors.Is compatibility, also through wrapped errors
+func (suite *QueueErrorTestSuite) TestErrorsIs() {
+	_, err := NewFIFO().Dequeue()
+	suite.True(errors.Is(err, ErrEmptyQueue))
+	suite.True(errors.Is(fmt.Errorf("wrapped: %w", err), ErrEmptyQueue))
+	suite.False(errors.Is(err, ErrLockedQueue))
+
+	fifo := NewFixedFIFO(1)
+	fifo.Lock()
+	suite.True(errors.Is(fifo.Enqueue(1), ErrLockedQueue))
+}
@@ -1,6 +1,7 @@
 package goconcurrentqueue
 
 import (
+	"fmt"
 	"testing"
 
 	"github.com/stretchr/testify/suite"
@@ -23,6 +24,39 @@ func (suite *QueueErrorTestSuite) TestInitialization() {
 	suite.Equal("message", queueError.Error())
 }
 
+// ***************************************************************************************
+// ** Is
+// ***************************************************************************************
+
+// QueueErrors with the same code match
+func (suite *QueueErrorTestSuite) TestIsSameCode() {
+	queueError := NewQueueError(QueueErrorCodeEmptyQueue, "any message")
+
+	suite.True(queueError.Is(ErrEmptyQueue), "errors sharing the code should match")
+	suite.False(queueError.Is(ErrLockedQueue), "errors with different codes should not match")
+}
+
+// non QueueError targets never match
+func (suite *QueueErrorTestSuite) TestIsDifferentType() {
+	suite.False(ErrEmptyQueue.Is(fmt.Errorf("empty-queue")))
+	suite.False(ErrEmptyQueue.Is(nil))
+}
+
+// shared errors don't allocate on the hot paths
+func (suite *QueueErrorTestSuite) TestSharedErrorsNoAllocs() {
+	fifo := NewFIFO()
+	allocs := testing.AllocsPerRun(100, func() {
+		fifo.Dequeue()
+	})
+	suite.Equal(float64(0), allocs, "Dequeue on an empty FIFO should not allocate")
+
+	fixedFIFO := NewFixedFIFO(1)
+	allocs = testing.AllocsPerRun(100, func() {
+		fixedFIFO.Dequeue()
+	})
+	suite.Equal(float64(0), allocs, "Dequeue on an empty FixedFIFO should not allocate")
+}
+
 // ***************************************************************************************
 // ** Run suite
 // ***************************************************************************************
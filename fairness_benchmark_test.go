@@ -0,0 +1,153 @@
+//go:build go1.13
+// +build go1.13
+
+package goconcurrentqueue
+
+import (
+	"fmt"
+	"runtime"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Fairness benchmarks: N producers and M consumers (DequeueOrWaitForNextElement) per implementation, reporting the
+// enqueue -> dequeue latency percentiles and how evenly the elements got spread among the consumers
+// (min-share = the least served consumer's elements / the most served consumer's elements, 1 means perfectly fair).
+//
+// go test -run xxx -bench Fairness
+
+const (
+	fairnessBenchmarkCapacity = 1024
+)
+
+type fairnessBenchmarkQueue struct {
+	name     string
+	newQueue func() Queue
+}
+
+var fairnessBenchmarkQueues = []fairnessBenchmarkQueue{
+	{name: "FIFO", newQueue: func() Queue { return NewFIFO() }},
+	{name: "FIFO-spin", newQueue: func() Queue {
+		fifo := NewFIFO()
+		fifo.SetWaitSpins(100)
+		return fifo
+	}},
+	{name: "FixedFIFO", newQueue: func() Queue { return NewFixedFIFO(fairnessBenchmarkCapacity) }},
+	{name: "BurstAbsorber", newQueue: func() Queue {
+		return NewBurstAbsorber(fairnessBenchmarkCapacity/2, fairnessBenchmarkCapacity/2)
+	}},
+	{name: "OrderingKeyFIFO", newQueue: func() Queue { return NewOrderingKeyFIFO() }},
+	{name: "StablePriorityQueue", newQueue: func() Queue {
+		return NewStablePriorityQueue(func(a, b interface{}) bool { return false })
+	}},
+	{name: "DelayQueue", newQueue: func() Queue { return NewDelayQueue() }},
+	{name: "TTLFIFO", newQueue: func() Queue { return NewTTLFIFO() }},
+}
+
+// fairnessBenchmarkElement carries its enqueue time, stop elements end the consumers
+type fairnessBenchmarkElement struct {
+	enqueuedAt time.Time
+	stop       bool
+}
+
+func BenchmarkFairness(b *testing.B) {
+	for _, implementation := range fairnessBenchmarkQueues {
+		for _, grs := range [][2]int{{1, 4}, {4, 4}, {4, 1}} {
+			producers, consumers := grs[0], grs[1]
+			b.Run(fmt.Sprintf("%v/%vP-%vC", implementation.name, producers, consumers), func(b *testing.B) {
+				benchmarkFairness(b, implementation.newQueue(), producers, consumers)
+			})
+		}
+	}
+}
+
+func benchmarkFairness(b *testing.B, queue Queue, producers, consumers int) {
+	var (
+		producersWG sync.WaitGroup
+		consumersWG sync.WaitGroup
+		latencies   = make([][]time.Duration, consumers)
+	)
+
+	// enqueues the element, yielding while the queue is at full capacity
+	enqueue := func(element fairnessBenchmarkElement) {
+		for queue.Enqueue(element) != nil {
+			runtime.Gosched()
+		}
+	}
+
+	b.ResetTimer()
+
+	for c := 0; c < consumers; c++ {
+		consumersWG.Add(1)
+		go func(consumer int) {
+			defer consumersWG.Done()
+			for {
+				value, err := queue.DequeueOrWaitForNextElement()
+				if err != nil {
+					b.Error(err)
+					return
+				}
+
+				element := value.(fairnessBenchmarkElement)
+				if element.stop {
+					return
+				}
+				latencies[consumer] = append(latencies[consumer], time.Since(element.enqueuedAt))
+			}
+		}(c)
+	}
+
+	for p := 0; p < producers; p++ {
+		producersWG.Add(1)
+		go func(producer int) {
+			defer producersWG.Done()
+			// b.N elements spread among the producers
+			for i := producer; i < b.N; i += producers {
+				enqueue(fairnessBenchmarkElement{enqueuedAt: time.Now()})
+			}
+		}(p)
+	}
+	producersWG.Wait()
+
+	for c := 0; c < consumers; c++ {
+		enqueue(fairnessBenchmarkElement{stop: true})
+	}
+	consumersWG.Wait()
+
+	b.StopTimer()
+	reportFairness(b, latencies)
+}
+
+// reportFairness reports the latency percentiles and the consumers' min-share
+func reportFairness(b *testing.B, latencies [][]time.Duration) {
+	var (
+		all      = make([]time.Duration, 0, b.N)
+		minShare = len(latencies[0])
+		maxShare = len(latencies[0])
+	)
+	for _, consumerLatencies := range latencies {
+		all = append(all, consumerLatencies...)
+		if len(consumerLatencies) < minShare {
+			minShare = len(consumerLatencies)
+		}
+		if len(consumerLatencies) > maxShare {
+			maxShare = len(consumerLatencies)
+		}
+	}
+
+	if len(all) == 0 {
+		return
+	}
+
+	sort.Slice(all, func(i, j int) bool { return all[i] < all[j] })
+	percentile := func(p float64) float64 {
+		return float64(all[int(float64(len(all)-1)*p)].Nanoseconds())
+	}
+
+	b.ReportMetric(percentile(0.5), "p50-ns")
+	b.ReportMetric(percentile(0.99), "p99-ns")
+	b.ReportMetric(float64(all[len(all)-1].Nanoseconds()), "max-ns")
+	b.ReportMetric(float64(minShare)/float64(maxShare), "min-share")
+}
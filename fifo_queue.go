@@ -1,7 +1,9 @@
 package goconcurrentqueue
 
 import (
+	"context"
 	"fmt"
+	"runtime"
 	"sync"
 	"time"
 )
@@ -9,16 +11,47 @@ import (
 const (
 	WaitForNextElementChanCapacity           = 1000
 	dequeueOrWaitForNextElementInvokeGapTime = 10
+	// TryLockFor's wait between tries
+	tryLockRetryGapTime = time.Millisecond
 )
 
+// serializes SwapQueues
+var swapQueuesMutex sync.Mutex
+
 // FIFO (First In First Out) concurrent queue
 type FIFO struct {
-	slice       []interface{}
-	rwmutex     sync.RWMutex
+	// enqueued elements
+	ring        ringBuffer
+	rwmutex     instrumentedMutex
 	lockRWmutex sync.RWMutex
 	isLocked    bool
 	// queue for watchers that will wait for next elements (if queue is empty at DequeueOrWaitForNextElement execution )
 	waitForNextElementChan chan chan interface{}
+	// async enqueues (EnqueueAsync)
+	async fifoAsync
+	// claimed elements (Claim)
+	claims int
+	// elements of the groups not sealed yet (EnqueueInGroup), lazy initialized, protected by rwmutex
+	groups map[string][]interface{}
+	// DequeueOrWaitForNextElement retries before parking (SetWaitSpins), protected by rwmutex
+	waitSpins int
+	// DequeueBatchOrWait callers waiting for enough elements and the channel closed (and replaced) to wake them up once
+	// elements get enqueued, protected by rwmutex
+	batchWaiters  int
+	batchWaitChan chan struct{}
+	// waiters tracking (SetWaiterTracking): the waiting consumers, oldest first, protected by rwmutex
+	trackWaiters        bool
+	captureWaiterStacks bool
+	waiters             []fifoWaiter
+	lastWaiterID        uint64
+	// Stats counters
+	counters *queueCounters
+	// queueing delay SLO tracking (SetWaitSLO)
+	waitSLO *waitSLOTracker
+	// lifecycle hooks (SetHooks), nil if there are none, protected by rwmutex
+	hooks *QueueHooks
+	// tests only: scripted interleavings
+	schedHook schedHook
 }
 
 // NewFIFO returns a new FIFO concurrent queue
@@ -30,152 +63,605 @@ func NewFIFO() *FIFO {
 }
 
 func (st *FIFO) initialize() {
-	st.slice = make([]interface{}, 0)
+	st.ring = ringBuffer{}
 	st.waitForNextElementChan = make(chan chan interface{}, WaitForNextElementChanCapacity)
+	st.batchWaitChan = make(chan struct{})
+	st.counters = newQueueCounters()
+	st.waitSLO = newWaitSLOTracker()
 }
 
 // Enqueue enqueues an element. Returns error if queue is locked.
 func (st *FIFO) Enqueue(value interface{}) error {
-	if st.isLocked {
-		return NewQueueError(QueueErrorCodeLockedQueue, "The queue is locked")
+	if st.IsLocked() {
+		st.counters.reject(1)
+		return ErrLockedQueue
+	}
+
+	st.schedHook.sched(schedPointEnqueue)
+	// lock the object to enqueue the element (or hand it to a listener)
+	st.rwmutex.Lock()
+	defer st.rwmutex.Unlock()
+
+	st.enqueueElement(value)
+
+	return nil
+}
+
+// EnqueueBatch enqueues all the elements, in order, under a single lock acquisition: no other producer's element gets
+// interleaved and the waiting consumers (DequeueOrWaitForNextElement) get the first elements, one per consumer.
+// Returns error if queue is locked (no element gets enqueued).
+func (st *FIFO) EnqueueBatch(values []interface{}) error {
+	if st.IsLocked() {
+		st.counters.reject(len(values))
+		return ErrLockedQueue
+	}
+
+	st.rwmutex.Lock()
+	defer st.rwmutex.Unlock()
+
+	for _, value := range values {
+		st.enqueueElement(value)
+	}
+
+	return nil
+}
+
+// enqueueElement hands the new element to the next listener (if any) or enqueues it.
+// st.rwmutex must be locked by the caller.
+func (st *FIFO) enqueueElement(value interface{}) {
+	at := st.waitSLO.stamp()
+	handedOver := st.handOverOrPush(value, at)
+	st.counters.enqueue(1, st.ring.length())
+	if handedOver {
+		st.counters.dequeue(1)
+		st.waitSLO.dequeued(at)
+		st.hooks.handedOver(value)
+		return
 	}
+	st.hooks.enqueued(value)
+}
+
+// handOverOrPush hands the element to the next listener (if any) or pushes it (enqueued at the given timestamp) at the
+// back of the queue. Returns true if the element got handed over. st.rwmutex must be locked by the caller.
+func (st *FIFO) handOverOrPush(value interface{}, at int64) bool {
+	if st.handOver(value) {
+		return true
+	}
+
+	// enqueue the element
+	st.ring.pushBackAt(value, at)
+	st.notifyBatchWaiters()
+
+	return false
+}
 
+// handOver hands the element to the next listener, if any (and it is ready). Returns true if the element got handed
+// over. st.rwmutex must be locked by the caller.
+func (st *FIFO) handOver(value interface{}) bool {
 	// check if there is a listener waiting for the next element (this element)
 	select {
 	case listener := <-st.waitForNextElementChan:
 		// send the element through the listener's channel instead of enqueue it
 		select {
 		case listener <- value:
+			return true
 		default:
 			// enqueue if listener is not ready
-
-			// lock the object to enqueue the element into the slice
-			st.rwmutex.Lock()
-			// enqueue the element
-			st.slice = append(st.slice, value)
-			defer st.rwmutex.Unlock()
 		}
 
 	default:
-		// lock the object to enqueue the element into the slice
-		st.rwmutex.Lock()
-		// enqueue the element
-		st.slice = append(st.slice, value)
-		defer st.rwmutex.Unlock()
 	}
 
-	return nil
+	return false
+}
+
+// notifyBatchWaiters wakes up the DequeueBatchOrWait callers (if any). st.rwmutex must be locked by the caller.
+func (st *FIFO) notifyBatchWaiters() {
+	if st.batchWaiters == 0 {
+		return
+	}
+
+	close(st.batchWaitChan)
+	st.batchWaitChan = make(chan struct{})
 }
 
 // Dequeue dequeues an element. Returns error if queue is locked or empty.
 func (st *FIFO) Dequeue() (interface{}, error) {
-	if st.isLocked {
-		return nil, NewQueueError(QueueErrorCodeLockedQueue, "The queue is locked")
+	if st.IsLocked() {
+		return nil, ErrLockedQueue
 	}
 
+	st.schedHook.sched(schedPointDequeue)
 	st.rwmutex.Lock()
 	defer st.rwmutex.Unlock()
 
-	length := len(st.slice)
+	elementToReturn, ok := st.dequeueFirst()
+	if !ok {
+		return nil, ErrEmptyQueue
+	}
+
+	return elementToReturn, nil
+}
+
+// dequeueFirst removes and returns the first (non claimed) element. st.rwmutex must be locked by the caller.
+func (st *FIFO) dequeueFirst() (interface{}, bool) {
+	return st.removeFirst(true)
+}
+
+// removeFirst removes and returns the first (non claimed) element, deliver means it gets to the consumer right away
+// (its future gets resolved). st.rwmutex must be locked by the caller.
+func (st *FIFO) removeFirst(deliver bool) (interface{}, bool) {
+	index := 0
+	if st.claims > 0 {
+		for index < st.ring.length() && isClaimed(st.ring.get(index)) {
+			index++
+		}
+	}
+	if index >= st.ring.length() {
+		return nil, false
+	}
+
+	var (
+		value interface{}
+		at    = st.ring.stamp(index)
+	)
+	if index == 0 {
+		value = st.ring.popFront()
+	} else {
+		value = st.ring.removeAt(index)
+	}
+	st.counters.dequeue(1)
+	st.waitSLO.dequeued(at)
+	if deliver {
+		st.hooks.dequeued(value, st.ring.length())
+	} else {
+		st.hooks.removed(value, st.ring.length())
+	}
+
+	return value, true
+}
+
+// DequeueUpTo atomically dequeues up to n elements (fewer if the queue has fewer), in order, under a single lock
+// acquisition: batch consumers don't pay a lock per element and no other consumer gets interleaved mid-batch.
+// Returns error if queue is locked or empty.
+func (st *FIFO) DequeueUpTo(n int) ([]interface{}, error) {
+	if n < 1 {
+		return nil, NewQueueError(QueueErrorCodeIndexOutOfBounds, fmt.Sprintf("invalid number of elements: %v", n))
+	}
+
+	return st.dequeueElements(n, true)
+}
+
+// dequeueElements dequeues up to max elements (under a single lock acquisition), deliver means they get to the
+// consumer right away (see removeFirst). Returns error if queue is locked or empty.
+func (st *FIFO) dequeueElements(max int, deliver bool) ([]interface{}, error) {
+	if st.IsLocked() {
+		return nil, ErrLockedQueue
+	}
+
+	st.rwmutex.Lock()
+	defer st.rwmutex.Unlock()
+
+	return st.popElements(max, deliver)
+}
+
+// popElements removes and returns up to max (non claimed) elements, deliver means they get to the consumer right away
+// (see removeFirst). Returns ErrEmptyQueue if there is none. st.rwmutex must be locked by the caller.
+func (st *FIFO) popElements(max int, deliver bool) ([]interface{}, error) {
+	length := st.ring.length()
 	if length == 0 {
-		return nil, NewQueueError(QueueErrorCodeEmptyQueue, "empty queue")
+		return nil, ErrEmptyQueue
 	}
 
-	elementToReturn := st.slice[0]
-	st.slice = st.slice[1:]
+	if st.claims > 0 {
+		return st.dequeueVisibleElements(max, deliver)
+	}
 
-	return elementToReturn, nil
+	if max > length {
+		max = length
+	}
+	elements := make([]interface{}, max)
+	for i := 0; i < max; i++ {
+		st.waitSLO.dequeued(st.ring.stamp(0))
+		elements[i] = st.ring.popFront()
+	}
+	st.counters.dequeue(max)
+	if deliver {
+		st.hooks.dequeuedAll(elements, st.ring.length())
+	} else {
+		st.hooks.removedAll(elements, st.ring.length())
+	}
+
+	return elements, nil
+}
+
+// dequeueVisibleElements dequeues up to max non claimed elements. st.rwmutex must be locked by the caller.
+func (st *FIFO) dequeueVisibleElements(max int, deliver bool) ([]interface{}, error) {
+	elements := make([]interface{}, 0)
+	for len(elements) < max {
+		value, ok := st.removeFirst(deliver)
+		if !ok {
+			break
+		}
+		elements = append(elements, value)
+	}
+
+	if len(elements) == 0 {
+		return nil, ErrEmptyQueue
+	}
+
+	return elements, nil
+}
+
+// requeueElements enqueues the given elements back at the front of the queue, keeping their order. Elements that
+// belonged to the queue (i.e. prefetched ones) are returned no matter whether the queue is locked, so no element gets
+// dropped. Their waits were already recorded (SetWaitSLO), they aren't tracked again.
+func (st *FIFO) requeueElements(elements []interface{}) {
+	st.rwmutex.Lock()
+	defer st.rwmutex.Unlock()
+
+	for i := len(elements) - 1; i >= 0; i-- {
+		st.ring.pushFront(elements[i])
+	}
+	// they weren't consumed after all
+	st.counters.undequeue(len(elements))
+	st.notifyBatchWaiters()
+	// listeners wait only while the queue is empty: hand them the first elements
+	st.handOverToListeners()
 }
 
 // DequeueOrWaitForNextElement dequeues an element (if exist) or waits until the next element gets enqueued and returns it.
 // Multiple calls to DequeueOrWaitForNextElement() would enqueue multiple "listeners" for future enqueued elements.
 func (st *FIFO) DequeueOrWaitForNextElement() (interface{}, error) {
+	return st.DequeueOrWaitForNextElementWithContext(context.Background())
+}
+
+// DequeueOrWaitForNextElementWithContext works as DequeueOrWaitForNextElement, but it stops waiting once the context
+// is done, returning ctx.Err(). The cancelled waiter's listener is removed, so no element gets handed over to it.
+func (st *FIFO) DequeueOrWaitForNextElementWithContext(ctx context.Context) (interface{}, error) {
+	if st.IsLocked() {
+		return nil, ErrLockedQueue
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if value, ok, err := st.spinForNextElement(ctx); ok || err != nil {
+		return value, err
+	}
+
+	st.schedHook.sched(schedPointWaitForNextElement)
+	value, waitChan, waiterID, err := st.dequeueOrAddListener(ctx)
+	if waitChan == nil {
+		return value, err
+	}
+	st.schedHook.sched(schedPointWaitForNextElementHandoff)
+
+	select {
+	// return the next enqueued element
+	case value := <-waitChan:
+		if waiterID != 0 {
+			st.rwmutex.Lock()
+			st.removeWaiter(waiterID)
+			st.rwmutex.Unlock()
+		}
+		return value, nil
+	case <-ctx.Done():
+		st.rwmutex.Lock()
+		defer st.rwmutex.Unlock()
+
+		st.removeWaiter(waiterID)
+		removeListener(st.waitForNextElementChan, waitChan)
+		// the element could have been handed over right before the listener's removal
+		select {
+		case value := <-waitChan:
+			return value, nil
+		default:
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// dequeueOrAddListener dequeues the first element or, if there is none, registers a listener (returning its channel
+// and waiter id) for the next enqueued element. The emptiness check and the listener registration happen under the
+// same lock Enqueue takes, so an element can't be enqueued in between (and get lost for this listener).
+func (st *FIFO) dequeueOrAddListener(ctx context.Context) (interface{}, chan interface{}, uint64, error) {
+	// deferred: the hooks invoked by dequeueFirst could panic
+	st.rwmutex.Lock()
+	defer st.rwmutex.Unlock()
+
+	if value, ok := st.dequeueFirst(); ok {
+		return value, nil, 0, nil
+	}
+
+	// channel to wait for next enqueued element (buffered, so Enqueue never blocks handing it over)
+	waitChan := make(chan interface{}, 1)
+
+	select {
+	// enqueue a watcher into the watchForNextElementChannel to wait for the next element
+	case st.waitForNextElementChan <- waitChan:
+		return nil, waitChan, st.addWaiter(ctx, "DequeueOrWaitForNextElement"), nil
+	default:
+		// too many watchers (waitForNextElementChanCapacity) enqueued waiting for next elements
+		return nil, nil, 0, NewQueueError(QueueErrorCodeEmptyQueue, "empty queue and can't wait for next element because there are too many DequeueOrWaitForNextElement() waiting")
+	}
+}
+
+// SetWaitSpins sets how many times DequeueOrWaitForNextElement retries (yielding the processor in between) before
+// parking the caller until the next element gets enqueued. Spinning trades CPU for latency under high load; 0 (the
+// default) parks right away.
+func (st *FIFO) SetWaitSpins(spins int) {
+	st.rwmutex.Lock()
+	defer st.rwmutex.Unlock()
+
+	st.waitSpins = spins
+}
+
+// spinForNextElement retries to dequeue an element up to waitSpins times. Returns false if there is still no element.
+func (st *FIFO) spinForNextElement(ctx context.Context) (interface{}, bool, error) {
+	st.rwmutex.RLock()
+	spins := st.waitSpins
+	st.rwmutex.RUnlock()
+
+	for i := 0; i < spins; i++ {
+		if value, ok := st.lockAndDequeueFirst(); ok {
+			return value, true, nil
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, false, err
+		}
+
+		runtime.Gosched()
+	}
+
+	return nil, false, nil
+}
+
+// lockAndDequeueFirst locks the queue and dequeues the first element, see dequeueFirst
+func (st *FIFO) lockAndDequeueFirst() (interface{}, bool) {
+	st.rwmutex.Lock()
+	defer st.rwmutex.Unlock()
+
+	return st.dequeueFirst()
+}
+
+// DequeueWithTimeout dequeues an element (if exist) or waits up to d until the next element gets enqueued and returns
+// it. Returns ErrTimeout if no element could be dequeued in time (d <= 0 means no waiting at all).
+func (st *FIFO) DequeueWithTimeout(d time.Duration) (interface{}, error) {
+	if d <= 0 {
+		value, err := st.Dequeue()
+		if err == ErrEmptyQueue {
+			return nil, ErrTimeout
+		}
+		return value, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), d)
+	defer cancel()
+
+	value, err := st.DequeueOrWaitForNextElementWithContext(ctx)
+	if err == context.DeadlineExceeded {
+		return nil, ErrTimeout
+	}
+
+	return value, err
+}
+
+// DequeueBatchOrWait waits until there are at least min elements (or until timeout), then dequeues up to max elements
+// at once (micro-batching). Once the timeout expires, the elements already enqueued (fewer than min) get dequeued;
+// ErrTimeout is returned if there is none.
+// Returns error if queue is locked or min / max are invalid (min < 1 or max < min).
+func (st *FIFO) DequeueBatchOrWait(min, max int, timeout time.Duration) ([]interface{}, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	elements, err := st.DequeueBatchOrWaitWithContext(ctx, min, max)
+	if err == context.DeadlineExceeded {
+		return nil, ErrTimeout
+	}
+
+	return elements, err
+}
+
+// DequeueBatchOrWaitWithContext works as DequeueBatchOrWait, but it waits until the context is done. Returns
+// ctx.Err() if there is no element by then.
+func (st *FIFO) DequeueBatchOrWaitWithContext(ctx context.Context, min, max int) ([]interface{}, error) {
+	if min < 1 || max < min {
+		return nil, NewQueueError(QueueErrorCodeIndexOutOfBounds, fmt.Sprintf("invalid batch size, min: %v, max: %v", min, max))
+	}
+
+	return st.dequeueBatchOrWait(ctx, min, max, true)
+}
+
+// dequeueBatchOrWait works as DequeueBatchOrWaitWithContext (valid min / max), deliver means the elements get to the
+// consumer right away (see removeFirst)
+func (st *FIFO) dequeueBatchOrWait(ctx context.Context, min, max int, deliver bool) ([]interface{}, error) {
 	for {
-		if st.isLocked {
-			return nil, NewQueueError(QueueErrorCodeLockedQueue, "The queue is locked")
+		if st.IsLocked() {
+			return nil, ErrLockedQueue
+		}
+
+		elements, waitChan, waiterID, err := st.popBatchOrAddWaiter(ctx, min, max, deliver)
+		if waitChan == nil {
+			return elements, err
+		}
+
+		select {
+		case <-waitChan:
+		case <-ctx.Done():
 		}
 
-		// get the slice's len
 		st.rwmutex.Lock()
-		length := len(st.slice)
+		st.batchWaiters--
+		st.removeWaiter(waiterID)
 		st.rwmutex.Unlock()
+	}
+}
 
-		if length == 0 {
-			// channel to wait for next enqueued element
-			waitChan := make(chan interface{})
-
-			select {
-			// enqueue a watcher into the watchForNextElementChannel to wait for the next element
-			case st.waitForNextElementChan <- waitChan:
-
-				// re-checks every i milliseconds (top: 10 times) ... the following verifies if an item was enqueued
-				// around the same time DequeueOrWaitForNextElement was invoked, meaning the waitChan wasn't yet sent over
-				// st.waitForNextElementChan
-				for i := 0; i < dequeueOrWaitForNextElementInvokeGapTime; i++ {
-					select {
-					case dequeuedItem := <-waitChan:
-						return dequeuedItem, nil
-					case <-time.After(time.Millisecond * time.Duration(i)):
-						if dequeuedItem, err := st.Dequeue(); err == nil {
-							return dequeuedItem, nil
-						}
-					}
-				}
-
-				// return the next enqueued element, if any
-				return <-waitChan, nil
-			default:
-				// too many watchers (waitForNextElementChanCapacity) enqueued waiting for next elements
-				return nil, NewQueueError(QueueErrorCodeEmptyQueue, "empty queue and can't wait for next element because there are too many DequeueOrWaitForNextElement() waiting")
-			}
+// popBatchOrAddWaiter dequeues up to max elements if there are min or more (or ctx is done, returning ctx.Err() if
+// there is none), otherwise it registers a batch waiter, returning the channel closed once elements get enqueued and
+// the waiter id. The elements check and the waiter registration happen under the same lock Enqueue takes, so no wake
+// up gets lost in between.
+func (st *FIFO) popBatchOrAddWaiter(ctx context.Context, min, max int, deliver bool) ([]interface{}, chan struct{}, uint64, error) {
+	// deferred: the hooks invoked by popElements could panic
+	st.rwmutex.Lock()
+	defer st.rwmutex.Unlock()
+
+	if ctxErr := ctx.Err(); ctxErr != nil || st.ring.length()-st.claims >= min {
+		elements, err := st.popElements(max, deliver)
+		if err == ErrEmptyQueue {
+			return nil, nil, 0, ctxErr
 		}
+		return elements, nil, 0, err
+	}
 
-		st.rwmutex.Lock()
+	st.batchWaiters++
 
-		// verify that at least 1 item resides on the queue
-		if len(st.slice) == 0 {
-			st.rwmutex.Unlock()
+	return nil, st.batchWaitChan, st.addWaiter(ctx, "DequeueBatchOrWait"), nil
+}
+
+// DequeueWithinBudget dequeues, from the head of the queue, the elements whose total cost (calculated by costFn) stays
+// within budget. Elements that would exceed the budget are skipped and kept at the queue (in the same position),
+// while the following ones are still considered: the whole queue gets scanned, so zero cost elements are dequeued
+// even once the budget is exhausted. Returns error if queue is locked or empty, or costFn returns a negative cost
+// (nothing gets dequeued then).
+func (st *FIFO) DequeueWithinBudget(costFn func(value interface{}) int, budget int) ([]interface{}, error) {
+	if st.IsLocked() {
+		return nil, ErrLockedQueue
+	}
+
+	st.rwmutex.Lock()
+	defer st.rwmutex.Unlock()
+
+	length := st.ring.length()
+	if length == 0 {
+		return nil, ErrEmptyQueue
+	}
+
+	// the costs are checked before anything gets dequeued
+	var (
+		fits      = make([]bool, length)
+		totalCost = 0
+	)
+	for i := 0; i < length; i++ {
+		value := st.ring.get(i)
+		if isClaimed(value) {
 			continue
 		}
-		elementToReturn := st.slice[0]
-		st.slice = st.slice[1:]
 
-		st.rwmutex.Unlock()
-		return elementToReturn, nil
+		cost := costFn(value)
+		if cost < 0 {
+			return nil, NewQueueError(QueueErrorCodeInvalidCost, fmt.Sprintf("invalid cost: %v", cost))
+		}
+		if totalCost+cost <= budget {
+			totalCost += cost
+			fits[i] = true
+		}
 	}
+
+	var (
+		dequeued = make([]interface{}, 0)
+		kept     = 0
+	)
+	for i := 0; i < length; i++ {
+		if fits[i] {
+			dequeued = append(dequeued, st.ring.get(i))
+			st.waitSLO.dequeued(st.ring.stamp(i))
+			continue
+		}
+
+		st.ring.move(kept, i)
+		kept++
+	}
+
+	// release the references to the dequeued elements
+	st.ring.truncate(kept)
+	st.counters.dequeue(len(dequeued))
+	st.hooks.dequeuedAll(dequeued, st.ring.length())
+
+	return dequeued, nil
 }
 
 // Get returns an element's value and keeps the element at the queue
 func (st *FIFO) Get(index int) (interface{}, error) {
-	if st.isLocked {
-		return nil, NewQueueError(QueueErrorCodeLockedQueue, "The queue is locked")
+	if st.IsLocked() {
+		return nil, ErrLockedQueue
 	}
 
 	st.rwmutex.RLock()
 	defer st.rwmutex.RUnlock()
 
-	if len(st.slice) <= index {
+	if index < 0 || st.ring.length() <= index {
 		return nil, NewQueueError(QueueErrorCodeIndexOutOfBounds, fmt.Sprintf("index out of bounds: %v", index))
 	}
 
-	return st.slice[index], nil
+	value := st.ring.get(index)
+	if isClaimed(value) {
+		return nil, NewQueueError(QueueErrorCodeClaimedElement, "the element is claimed")
+	}
+
+	return value, nil
+}
+
+// Peek returns the first (non claimed) element, the next one to be dequeued, keeping it at the queue. Returns error if
+// queue is locked or empty.
+func (st *FIFO) Peek() (interface{}, error) {
+	if st.IsLocked() {
+		return nil, ErrLockedQueue
+	}
+
+	st.rwmutex.RLock()
+	defer st.rwmutex.RUnlock()
+
+	for i := 0; i < st.ring.length(); i++ {
+		if value := st.ring.get(i); !isClaimed(value) {
+			return value, nil
+		}
+	}
+
+	return nil, ErrEmptyQueue
+}
+
+// PeekBack returns the last (non claimed) element, the latest enqueued one, keeping it at the queue. Returns error if
+// queue is locked or empty.
+func (st *FIFO) PeekBack() (interface{}, error) {
+	if st.IsLocked() {
+		return nil, ErrLockedQueue
+	}
+
+	st.rwmutex.RLock()
+	defer st.rwmutex.RUnlock()
+
+	for i := st.ring.length() - 1; i >= 0; i-- {
+		if value := st.ring.get(i); !isClaimed(value) {
+			return value, nil
+		}
+	}
+
+	return nil, ErrEmptyQueue
 }
 
 // Remove removes an element from the queue
 func (st *FIFO) Remove(index int) error {
-	if st.isLocked {
-		return NewQueueError(QueueErrorCodeLockedQueue, "The queue is locked")
+	if st.IsLocked() {
+		return ErrLockedQueue
 	}
 
 	st.rwmutex.Lock()
 	defer st.rwmutex.Unlock()
 
-	if len(st.slice) <= index {
+	if index < 0 || st.ring.length() <= index {
 		return NewQueueError(QueueErrorCodeIndexOutOfBounds, fmt.Sprintf("index out of bounds: %v", index))
 	}
 
+	if isClaimed(st.ring.get(index)) {
+		return NewQueueError(QueueErrorCodeClaimedElement, "the element is claimed")
+	}
+
 	// remove the element
-	st.slice = append(st.slice[:index], st.slice[index+1:]...)
+	st.ring.removeAt(index)
+	st.hooks.emptied(st.ring.length())
 
 	return nil
 }
@@ -183,38 +669,267 @@ func (st *FIFO) Remove(index int) error {
 // GetAll returns the entire list of elements from the queue
 // If limit (n) and offset (m) are different than nil, it will return an slice
 // with the last n elements starting from position m
+// The returned slice is a copy: modifying it doesn't affect the queue.
+// The elements are taken from a single consistent snapshot, in dequeue order at the time of the call: no concurrent
+// enqueue / dequeue shows up partially.
 func (st *FIFO) GetAll(limit, offset *int) (interface{}, error) {
-	if st.isLocked {
-		return nil, NewQueueError(QueueErrorCodeLockedQueue, "The queue is locked")
+	if st.IsLocked() {
+		return nil, ErrLockedQueue
 	}
 
-	st.rwmutex.Lock()
-	defer st.rwmutex.Unlock()
+	st.rwmutex.RLock()
+	defer st.rwmutex.RUnlock()
 
 	if limit == nil && offset == nil {
-		return st.slice, nil
+		if st.claims > 0 {
+			return visibleElements(st.ring.elements()), nil
+		}
+		return st.ring.elements(), nil
 	}
 
-	if *offset >= len(st.slice) || *offset < 0 || *limit < 0 {
+	length := st.ring.length()
+	if *offset >= length || *offset < 0 || *limit < 0 {
 		return nil, NewQueueError(QueueErrorCodeIndexOutOfBounds, "Offset index out of bounds")
 	}
 
-	if (*offset + *limit) >= len(st.slice) {
-		*limit = len(st.slice) - 1 - *offset
+	if (*offset + *limit) >= length {
+		*limit = length - 1 - *offset
 	}
 	low := *offset + 1
 	high := *offset + *limit + 1
-	limited := st.slice[low:high]
+	limited := st.ring.copyRange(low, high)
+	if st.claims > 0 {
+		limited = visibleElements(limited)
+	}
 
 	return limited, nil
 }
 
+// Count returns the number of enqueued elements matching the predicate, all evaluated over the same snapshot (see
+// GetAll)
+func (st *FIFO) Count(predicate func(value interface{}) bool) int {
+	st.rwmutex.RLock()
+	defer st.rwmutex.RUnlock()
+
+	total := 0
+	for i := 0; i < st.ring.length(); i++ {
+		if value := st.ring.get(i); !isClaimed(value) && predicate(value) {
+			total++
+		}
+	}
+
+	return total
+}
+
+// Where returns the enqueued elements matching the predicate (in dequeue order), all evaluated over the same snapshot
+// (see GetAll). The elements are kept at the queue.
+func (st *FIFO) Where(predicate func(value interface{}) bool) []interface{} {
+	st.rwmutex.RLock()
+	defer st.rwmutex.RUnlock()
+
+	ret := make([]interface{}, 0)
+	for i := 0; i < st.ring.length(); i++ {
+		if value := st.ring.get(i); !isClaimed(value) && predicate(value) {
+			ret = append(ret, value)
+		}
+	}
+
+	return ret
+}
+
+// RemoveWhere atomically removes every enqueued (non claimed) element matching the predicate, keeping the order of the
+// rest, and returns the number of removed elements. The predicate runs under the queue's lock: it must not access the
+// queue. Nothing gets removed if queue is locked.
+func (st *FIFO) RemoveWhere(predicate func(value interface{}) bool) int {
+	if st.IsLocked() {
+		return 0
+	}
+
+	st.rwmutex.Lock()
+	defer st.rwmutex.Unlock()
+
+	kept := 0
+	for i := 0; i < st.ring.length(); i++ {
+		if value := st.ring.get(i); isClaimed(value) || !predicate(value) {
+			st.ring.move(kept, i)
+			kept++
+		}
+	}
+	removed := st.ring.length() - kept
+	st.ring.truncate(kept)
+	if removed > 0 {
+		st.hooks.emptied(kept)
+	}
+
+	return removed
+}
+
+// Filter atomically keeps only the enqueued elements matching the predicate (claimed elements are kept as well), i.e.
+// to cancel a whole class of pending work. See RemoveWhere.
+func (st *FIFO) Filter(predicate func(value interface{}) bool) {
+	st.RemoveWhere(func(value interface{}) bool {
+		return !predicate(value)
+	})
+}
+
+// Apply atomically replaces every enqueued (non claimed) element with fn(element), keeping the order, i.e. to re-tag
+// or normalize the pending payloads after a schema change. fn runs under the queue's lock: it must not access the
+// queue. Returns error if queue is locked (nothing gets replaced).
+func (st *FIFO) Apply(fn func(value interface{}) interface{}) error {
+	if st.IsLocked() {
+		return ErrLockedQueue
+	}
+
+	st.rwmutex.Lock()
+	defer st.rwmutex.Unlock()
+
+	for i := 0; i < st.ring.length(); i++ {
+		if value := st.ring.get(i); !isClaimed(value) {
+			st.ring.set(i, fn(value))
+		}
+	}
+
+	return nil
+}
+
+// IndexOf returns the position (see Get) of the first enqueued (non claimed) element equal to value, or -1 if there is
+// none. equals(value, element) compares them, nil equals compares them using == (it
+// panics on uncomparable elements, i.e. slices).
+func (st *FIFO) IndexOf(value interface{}, equals func(a, b interface{}) bool) int {
+	st.rwmutex.RLock()
+	defer st.rwmutex.RUnlock()
+
+	for i := 0; i < st.ring.length(); i++ {
+		if element := st.ring.get(i); !isClaimed(element) && isEqual(equals, value, element) {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// Contains returns true whether there is an enqueued (non claimed) element equal to value, see IndexOf
+func (st *FIFO) Contains(value interface{}, equals func(a, b interface{}) bool) bool {
+	return st.IndexOf(value, equals) >= 0
+}
+
+// ForEach calls fn for every enqueued (non claimed) element, in dequeue order, along with its position, until fn
+// returns false. The position counts the claimed elements too, as Get / Remove / Claim do (the positions of the
+// elements after a claimed one aren't contiguous). The elements are taken from a single consistent snapshot (see
+// GetAll) and fn runs with no lock held, so it could access the queue.
+func (st *FIFO) ForEach(fn func(index int, value interface{}) bool) {
+	st.rwmutex.RLock()
+	elements := st.ring.elements()
+	st.rwmutex.RUnlock()
+
+	for i, value := range elements {
+		if isClaimed(value) {
+			continue
+		}
+		if !fn(i, value) {
+			return
+		}
+	}
+}
+
+// Clear atomically removes every element (claimed ones included: their claims become invalid), releasing the
+// references and the buffer. Returns error if queue is locked.
+func (st *FIFO) Clear() error {
+	if st.IsLocked() {
+		return ErrLockedQueue
+	}
+
+	st.rwmutex.Lock()
+	defer st.rwmutex.Unlock()
+
+	if st.ring.length() > 0 {
+		st.hooks.emptied(0)
+	}
+	st.ring = ringBuffer{}
+	st.claims = 0
+
+	return nil
+}
+
+// Drain atomically removes and returns every (non claimed) element, in order: everything that was enqueued at that
+// instant, no concurrent Enqueue gets in between. It works on locked queues too, so a queue could be locked (no more
+// enqueues) and then flushed at shutdown. Returns an empty slice if there are no elements.
+func (st *FIFO) Drain() []interface{} {
+	st.rwmutex.Lock()
+	defer st.rwmutex.Unlock()
+
+	if st.claims == 0 {
+		elements := st.ring.elements()
+		for i := 0; i < st.ring.length(); i++ {
+			st.waitSLO.dequeued(st.ring.stamp(i))
+		}
+		st.ring = ringBuffer{}
+		st.counters.dequeue(len(elements))
+		st.hooks.dequeuedAll(elements, 0)
+		return elements
+	}
+
+	// claimed elements stay until they get released
+	var (
+		elements = make([]interface{}, 0, st.ring.length()-st.claims)
+		claimed  ringBuffer
+	)
+	for i := 0; i < st.ring.length(); i++ {
+		if value := st.ring.get(i); isClaimed(value) {
+			claimed.pushBackAt(value, st.ring.stamp(i))
+		} else {
+			elements = append(elements, value)
+			st.waitSLO.dequeued(st.ring.stamp(i))
+		}
+	}
+	st.ring = claimed
+	st.counters.dequeue(len(elements))
+	st.hooks.dequeuedAll(elements, st.ring.length())
+
+	return elements
+}
+
+// snapshot returns a copy of the (non claimed) elements, in dequeue order, taken under a single lock acquisition. It
+// works on locked queues too.
+func (st *FIFO) snapshot() []interface{} {
+	st.rwmutex.RLock()
+	defer st.rwmutex.RUnlock()
+
+	if st.claims > 0 {
+		return visibleElements(st.ring.elements())
+	}
+
+	return st.ring.elements()
+}
+
+// replaceElements atomically replaces every element (claimed ones included: their claims become invalid) with the
+// given ones, in order: the waiting consumers get the first ones. Returns error if queue is locked.
+func (st *FIFO) replaceElements(elements []interface{}) error {
+	if st.IsLocked() {
+		return ErrLockedQueue
+	}
+
+	st.rwmutex.Lock()
+	defer st.rwmutex.Unlock()
+
+	if st.ring.length() > 0 {
+		st.hooks.emptied(0)
+	}
+	st.ring = ringBuffer{}
+	st.claims = 0
+	for _, value := range elements {
+		st.enqueueElement(value)
+	}
+
+	return nil
+}
+
 // GetLen returns the number of enqueued elements
 func (st *FIFO) GetLen() int {
 	st.rwmutex.RLock()
 	defer st.rwmutex.RUnlock()
 
-	return len(st.slice)
+	return st.ring.length()
 }
 
 // GetCap returns the queue's capacity
@@ -222,7 +937,31 @@ func (st *FIFO) GetCap() int {
 	st.rwmutex.RLock()
 	defer st.rwmutex.RUnlock()
 
-	return cap(st.slice)
+	return st.ring.capacity()
+}
+
+// Stats returns the queue's counters (enqueued, dequeued and rejected elements) and gauges (length, peak length and
+// waiting consumers), along with the lock contention (see SetLockInstrumentation) and the queueing delay SLO (see
+// SetWaitSLO). Elements given back by a Prefetcher don't count as dequeued.
+func (st *FIFO) Stats() QueueStats {
+	st.rwmutex.RLock()
+	defer st.rwmutex.RUnlock()
+
+	stats := st.counters.stats(st.ring.length(), len(st.waitForNextElementChan)+st.batchWaiters)
+	stats.Lock = st.rwmutex.stats()
+	stats.WaitSLO = st.waitSLO.stats()
+
+	return stats
+}
+
+// SetHooks sets the lifecycle hooks: invoked on every enqueued / dequeued element and every time the queue becomes
+// empty (FIFO is never full, OnFull is not invoked). They run while the queue's lock is held, see QueueHooks.
+// QueueHooks{} removes them.
+func (st *FIFO) SetHooks(hooks QueueHooks) {
+	st.rwmutex.Lock()
+	defer st.rwmutex.Unlock()
+
+	st.hooks = &hooks
 }
 
 // Lock // Locks the queue. No enqueue/dequeue operations will be allowed after this point.
@@ -233,12 +972,17 @@ func (st *FIFO) Lock() {
 	st.isLocked = true
 }
 
-// Unlock unlocks the queue
+// Unlock unlocks the queue. The DequeueOrWaitForNextElement callers still waiting get served, in order, from the
+// elements already enqueued (if any).
 func (st *FIFO) Unlock() {
 	st.lockRWmutex.Lock()
-	defer st.lockRWmutex.Unlock()
-
 	st.isLocked = false
+	st.lockRWmutex.Unlock()
+
+	st.rwmutex.Lock()
+	defer st.rwmutex.Unlock()
+
+	st.handOverToListeners()
 }
 
 // IsLocked returns true whether the queue is locked
@@ -249,16 +993,54 @@ func (st *FIFO) IsLocked() bool {
 	return st.isLocked
 }
 
+// TryLock locks the queue only if no operation is in progress: no consumer waiting for the next element(s), no claimed
+// element (Claim) and no pending async enqueue (EnqueueAsync). Maintenance tooling could take exclusive control of the
+// queue this way, or back off. Returns true whether the queue got locked.
+func (st *FIFO) TryLock() bool {
+	st.async.mutex.Lock()
+	asyncPending := st.async.completed < st.async.submitted
+	st.async.mutex.Unlock()
+	if asyncPending {
+		return false
+	}
+
+	// no operation gets in while checking
+	st.rwmutex.Lock()
+	defer st.rwmutex.Unlock()
+
+	if st.claims > 0 || len(st.waitForNextElementChan) > 0 || st.batchWaiters > 0 {
+		return false
+	}
+	st.Lock()
+
+	return true
+}
+
+// TryLockFor retries TryLock until the queue gets locked or d expires. Returns true whether the queue got locked.
+func (st *FIFO) TryLockFor(d time.Duration) bool {
+	deadline := time.Now().Add(d)
+	for {
+		if st.TryLock() {
+			return true
+		}
+		if !time.Now().Before(deadline) {
+			return false
+		}
+
+		time.Sleep(tryLockRetryGapTime)
+	}
+}
+
 // Swap swaps values from position a to position b and vice versa.
 func (st *FIFO) Swap(a int, b int) *QueueError {
-	if st.isLocked {
-		return NewQueueError(QueueErrorCodeLockedQueue, "The queue is locked")
+	if st.IsLocked() {
+		return ErrLockedQueue
 	}
 
 	st.rwmutex.Lock()
 	defer st.rwmutex.Unlock()
 
-	length := len(st.slice)
+	length := st.ring.length()
 	if length == 0 {
 		return NewQueueError(QueueErrorCodeEmptyQueue, "Empty queue")
 	}
@@ -271,7 +1053,7 @@ func (st *FIFO) Swap(a int, b int) *QueueError {
 		return NewQueueError(QueueErrorCodeIndexOutOfBounds, "Index out of bounds")
 	}
 
-	st.slice[a], st.slice[b] = st.slice[b], st.slice[a]
+	st.ring.swap(a, b)
 
 	return nil
 }
@@ -279,13 +1061,13 @@ func (st *FIFO) Swap(a int, b int) *QueueError {
 // MoveFrontWithId moves the element at index position to the front of the queue
 func (st *FIFO) MoveFrontWithId(index int) error {
 
-	if st.isLocked {
-		return NewQueueError(QueueErrorCodeLockedQueue, "The queue is locked")
+	if st.IsLocked() {
+		return ErrLockedQueue
 	}
 	st.rwmutex.Lock()
 	defer st.rwmutex.Unlock()
 
-	length := len(st.slice)
+	length := st.ring.length()
 	if length == 0 {
 		return NewQueueError(QueueErrorCodeEmptyQueue, "Empty queue")
 	}
@@ -301,7 +1083,7 @@ func (st *FIFO) MoveFrontWithId(index int) error {
 	// Moves the element all the way to the back of the queue.
 	// The element is moved one position at a time using bubble sort algorithm.
 	for i := index; i >= 1; i-- {
-		st.slice[i], st.slice[i-1] = st.slice[i-1], st.slice[i]
+		st.ring.swap(i, i-1)
 	}
 
 	return nil
@@ -310,13 +1092,13 @@ func (st *FIFO) MoveFrontWithId(index int) error {
 // MoveBackWithId moves the element at index position to the back of the queue
 func (st *FIFO) MoveBackWithId(index int) error {
 
-	if st.isLocked {
-		return NewQueueError(QueueErrorCodeLockedQueue, "The queue is locked")
+	if st.IsLocked() {
+		return ErrLockedQueue
 	}
 	st.rwmutex.Lock()
 	defer st.rwmutex.Unlock()
 
-	length := len(st.slice)
+	length := st.ring.length()
 	if length == 0 {
 		return NewQueueError(QueueErrorCodeEmptyQueue, "Empty queue")
 	}
@@ -332,10 +1114,153 @@ func (st *FIFO) MoveBackWithId(index int) error {
 	// Moves the element all the way to the front of the queue.
 	// The element is moved one position at a time using bubble sort algorithm.
 	for i := index; i < length-1; i++ {
-		st.slice[i], st.slice[i+1] = st.slice[i+1], st.slice[i]
+		st.ring.swap(i, i+1)
+	}
+
+	return nil
+}
+
+// Rotate atomically moves the first n elements to the back of the queue, keeping their order (round-robin replay).
+// A negative n moves the last -n elements to the front. n could be greater than the queue's length.
+// Returns error if queue is locked.
+func (st *FIFO) Rotate(n int) error {
+	if st.IsLocked() {
+		return ErrLockedQueue
+	}
+
+	st.rwmutex.Lock()
+	defer st.rwmutex.Unlock()
+
+	length := st.ring.length()
+	if length == 0 {
+		return nil
+	}
+
+	n %= length
+	if n < 0 {
+		n += length
+	}
+	if n == 0 {
+		return nil
+	}
+
+	// the freed head slots are reused for the tail, no reallocation
+	for i := 0; i < n; i++ {
+		at := st.ring.stamp(0)
+		st.ring.pushBackAt(st.ring.popFront(), at)
+	}
+
+	return nil
+}
+
+// Truncate atomically keeps the first n elements, removing the rest of them (retention).
+// Returns error if queue is locked or n is negative.
+func (st *FIFO) Truncate(n int) error {
+	if st.IsLocked() {
+		return ErrLockedQueue
+	}
+
+	if n < 0 {
+		return NewQueueError(QueueErrorCodeIndexOutOfBounds, "Index out of bounds")
+	}
+
+	st.rwmutex.Lock()
+	defer st.rwmutex.Unlock()
+
+	if n >= st.ring.length() {
+		return nil
+	}
+
+	for i := n; i < st.ring.length(); i++ {
+		if isClaimed(st.ring.get(i)) {
+			st.claims--
+		}
+	}
+	st.ring.truncate(n)
+	st.hooks.emptied(n)
+
+	return nil
+}
+
+// SwapQueues atomically exchanges the elements of both queues (double-buffering): a collector could grab the whole
+// current batch while producers keep enqueueing into a fresh queue. Both queues are locked for the time it takes to
+// swap the underlying buffers. Consumers waiting on a queue (DequeueOrWaitForNextElement) get the elements it receives.
+// Returns error if any of the queues is locked.
+func SwapQueues(a, b *FIFO) error {
+	if a == b {
+		return nil
+	}
+
+	if a.IsLocked() || b.IsLocked() {
+		return ErrLockedQueue
 	}
 
+	// a single swap at a time, so concurrent SwapQueues(a, b) and SwapQueues(b, a) can't deadlock
+	swapQueuesMutex.Lock()
+	defer swapQueuesMutex.Unlock()
+
+	// canonical order, so it can't deadlock with EnqueueAllQueues either
+	unlock := lockInOrder([]transactionalQueue{a, b})
+	defer unlock()
+
+	a.ring, b.ring = b.ring, a.ring
+	a.claims, b.claims = b.claims, a.claims
+	a.moveClaimedElements()
+	b.moveClaimedElements()
+	a.handOverToListeners()
+	b.handOverToListeners()
+	a.notifyBatchWaiters()
+	b.notifyBatchWaiters()
+
 	return nil
 }
 
+// moveClaimedElements updates the claimed elements' queue (after SwapQueues). st.rwmutex and swapQueuesMutex must be
+// locked by the caller.
+func (st *FIFO) moveClaimedElements() {
+	if st.claims == 0 {
+		return
+	}
 
+	for i := 0; i < st.ring.length(); i++ {
+		if element, ok := st.ring.get(i).(*claimedElement); ok {
+			element.queue = st
+		}
+	}
+}
+
+// handOverToListeners sends the enqueued (non claimed) elements to the waiting listeners (if any), in order.
+// st.rwmutex must be locked by the caller.
+func (st *FIFO) handOverToListeners() {
+	if len(st.waitForNextElementChan) == 0 || st.ring.length() == 0 {
+		return
+	}
+
+	var (
+		handedOver = make([]interface{}, 0)
+		kept       = 0
+	)
+	for i := 0; i < st.ring.length(); i++ {
+		if value := st.ring.get(i); !isClaimed(value) && st.handOver(value) {
+			handedOver = append(handedOver, value)
+			st.waitSLO.dequeued(st.ring.stamp(i))
+			continue
+		}
+		st.ring.move(kept, i)
+		kept++
+	}
+	st.ring.truncate(kept)
+	st.counters.dequeue(len(handedOver))
+	st.hooks.dequeuedAll(handedOver, st.ring.length())
+}
+
+// removeListener removes the listener from the listeners' queue, keeping the order of the rest of them. The lock
+// listeners are enqueued under must be held by the caller.
+func removeListener(listeners chan chan interface{}, listener chan interface{}) {
+	for i, total := 0, len(listeners); i < total; i++ {
+		current := <-listeners
+		if current != listener {
+			listeners <- current
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package goconcurrentqueue
+
+import (
+	"context"
+	"sync"
+)
+
+const (
+	// AsyncEnqueueChanCapacity is the max amount of pending async enqueues, EnqueueAsync blocks once it is reached
+	AsyncEnqueueChanCapacity = 1000
+	// max amount of async enqueues appended under a single lock acquisition
+	asyncEnqueueMaxBatchSize = 100
+)
+
+// asyncEnqueue is an element submitted by EnqueueAsync, waiting to be enqueued
+type asyncEnqueue struct {
+	value    interface{}
+	callback func(err error)
+}
+
+// fifoAsync holds the FIFO's async enqueues state
+type fifoAsync struct {
+	mutex sync.Mutex
+	// pending async enqueues (lazy initialized)
+	pending chan asyncEnqueue
+	// whether the goroutine appending the pending enqueues is running
+	running bool
+	// total submitted / completed (already enqueued) async enqueues
+	submitted int64
+	completed int64
+	// closed (and replaced) every time async enqueues get completed, used by Flush to wait for them
+	completedChan chan struct{}
+}
+
+// EnqueueAsync submits an element to be enqueued and returns immediately. A dedicated goroutine enqueues the submitted
+// elements in batches (one lock acquisition per batch) keeping the submission order, and invokes callback (optional)
+// with the enqueue's result: nil or ErrLockedQueue.
+// EnqueueAsync only blocks if there are AsyncEnqueueChanCapacity pending async enqueues.
+// There is no ordering guarantee between async enqueues and regular Enqueue calls.
+func (st *FIFO) EnqueueAsync(value interface{}, callback func(err error)) {
+	st.async.mutex.Lock()
+	if st.async.pending == nil {
+		st.async.pending = make(chan asyncEnqueue, AsyncEnqueueChanCapacity)
+	}
+	pending := st.async.pending
+	// counted before being sent to keep the following true: once EnqueueAsync returns, the element is enqueued before
+	// the async enqueues counted up to this point are completed (see Flush)
+	st.async.submitted++
+	st.async.mutex.Unlock()
+
+	pending <- asyncEnqueue{value: value, callback: callback}
+
+	// start the goroutine if it isn't running, it keeps running until no more pending async enqueues are left
+	st.async.mutex.Lock()
+	if !st.async.running {
+		st.async.running = true
+		go st.runAsyncEnqueues(pending)
+	}
+	st.async.mutex.Unlock()
+}
+
+// runAsyncEnqueues enqueues the pending async enqueues in batches, it returns once no pending async enqueues are left
+func (st *FIFO) runAsyncEnqueues(pending chan asyncEnqueue) {
+	batch := make([]asyncEnqueue, 0, asyncEnqueueMaxBatchSize)
+
+	for {
+		batch = batch[:0]
+
+	collect:
+		for len(batch) < asyncEnqueueMaxBatchSize {
+			select {
+			case element := <-pending:
+				batch = append(batch, element)
+			default:
+				break collect
+			}
+		}
+
+		if len(batch) == 0 {
+			// the check is made under the same lock EnqueueAsync takes to decide whether to start a new goroutine
+			st.async.mutex.Lock()
+			if len(pending) == 0 {
+				st.async.running = false
+				st.async.mutex.Unlock()
+				return
+			}
+			st.async.mutex.Unlock()
+			continue
+		}
+
+		err := st.enqueueAsyncBatch(batch)
+		st.completeAsyncEnqueues(len(batch))
+		for i := 0; i < len(batch); i++ {
+			if batch[i].callback != nil {
+				batch[i].callback(err)
+			}
+			// release the reference
+			batch[i] = asyncEnqueue{}
+		}
+	}
+}
+
+// completeAsyncEnqueues counts the given amount of async enqueues as completed and wakes up the Flush callers
+func (st *FIFO) completeAsyncEnqueues(total int) {
+	st.async.mutex.Lock()
+	defer st.async.mutex.Unlock()
+
+	st.async.completed += int64(total)
+	if st.async.completedChan != nil {
+		close(st.async.completedChan)
+		st.async.completedChan = nil
+	}
+}
+
+// Flush blocks until all async enqueues submitted (EnqueueAsync returned) before Flush was invoked get enqueued, or
+// until ctx is done (returning ctx.Err()).
+// Flush doesn't report the async enqueues' results, the callbacks do.
+func (st *FIFO) Flush(ctx context.Context) error {
+	st.async.mutex.Lock()
+	target := st.async.submitted
+
+	for st.async.completed < target {
+		if st.async.completedChan == nil {
+			st.async.completedChan = make(chan struct{})
+		}
+		completedChan := st.async.completedChan
+		st.async.mutex.Unlock()
+
+		select {
+		case <-completedChan:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+
+		st.async.mutex.Lock()
+	}
+	st.async.mutex.Unlock()
+
+	return nil
+}
+
+// enqueueAsyncBatch enqueues the batch's elements under a single lock acquisition
+func (st *FIFO) enqueueAsyncBatch(batch []asyncEnqueue) error {
+	if st.IsLocked() {
+		st.counters.reject(len(batch))
+		return ErrLockedQueue
+	}
+
+	st.rwmutex.Lock()
+	defer st.rwmutex.Unlock()
+
+	for i := 0; i < len(batch); i++ {
+		st.enqueueElement(batch[i].value)
+	}
+
+	return nil
+}
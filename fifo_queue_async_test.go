@@ -0,0 +1,211 @@
+package goconcurrentqueue
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type FIFOAsyncTestSuite struct {
+	suite.Suite
+	fifo *FIFO
+}
+
+func (suite *FIFOAsyncTestSuite) SetupTest() {
+	suite.fifo = NewFIFO()
+}
+
+// ***************************************************************************************
+// ** EnqueueAsync
+// ***************************************************************************************
+
+// single async enqueue, the callback gets invoked once the element is enqueued
+func (suite *FIFOAsyncTestSuite) TestEnqueueAsyncSingleGR() {
+	done := make(chan error, 1)
+	suite.fifo.EnqueueAsync(testValue, func(err error) {
+		done <- err
+	})
+
+	select {
+	case err := <-done:
+		suite.NoError(err)
+	case <-time.After(2 * time.Second):
+		suite.FailNow("too much time waiting for the callback")
+	}
+
+	suite.Equal(1, suite.fifo.GetLen())
+	value, err := suite.fifo.Dequeue()
+	suite.NoError(err)
+	suite.Equal(testValue, value)
+}
+
+// async enqueues keep the submission order
+func (suite *FIFOAsyncTestSuite) TestEnqueueAsyncOrderSingleGR() {
+	var (
+		total = AsyncEnqueueChanCapacity * 3
+		wg    sync.WaitGroup
+	)
+
+	wg.Add(total)
+	for i := 0; i < total; i++ {
+		suite.fifo.EnqueueAsync(i, func(err error) {
+			suite.NoError(err)
+			wg.Done()
+		})
+	}
+	wg.Wait()
+
+	suite.Equal(total, suite.fifo.GetLen())
+	for i := 0; i < total; i++ {
+		value, err := suite.fifo.Dequeue()
+		suite.NoError(err)
+		suite.Equal(i, value)
+	}
+}
+
+// nil callbacks are allowed
+func (suite *FIFOAsyncTestSuite) TestEnqueueAsyncNilCallback() {
+	suite.fifo.EnqueueAsync(1, nil)
+
+	done := make(chan struct{})
+	suite.fifo.EnqueueAsync(2, func(err error) {
+		close(done)
+	})
+	<-done
+
+	suite.Equal(2, suite.fifo.GetLen())
+}
+
+// locked queue: the callback gets the locked error
+func (suite *FIFOAsyncTestSuite) TestEnqueueAsyncLockedQueue() {
+	suite.fifo.Lock()
+
+	done := make(chan error, 1)
+	suite.fifo.EnqueueAsync(testValue, func(err error) {
+		done <- err
+	})
+
+	err := <-done
+	suite.Equal(ErrLockedQueue, err)
+	suite.Equal(0, suite.fifo.GetLen())
+}
+
+// async enqueued elements are handed to waiting listeners
+func (suite *FIFOAsyncTestSuite) TestEnqueueAsyncWaitForNextElement() {
+	result := make(chan interface{}, 1)
+	go func() {
+		value, _ := suite.fifo.DequeueOrWaitForNextElement()
+		result <- value
+	}()
+
+	suite.fifo.EnqueueAsync(testValue, nil)
+
+	select {
+	case value := <-result:
+		suite.Equal(testValue, value)
+	case <-time.After(2 * time.Second):
+		suite.FailNow("too much time waiting for the enqueued element")
+	}
+}
+
+// multiple GRs enqueueing asynchronously
+func (suite *FIFOAsyncTestSuite) TestEnqueueAsyncMultipleGRs() {
+	var (
+		totalGRs      = 50
+		elementsPerGR = 100
+		wg            sync.WaitGroup
+	)
+
+	wg.Add(totalGRs * elementsPerGR)
+	for g := 0; g < totalGRs; g++ {
+		go func() {
+			for i := 0; i < elementsPerGR; i++ {
+				suite.fifo.EnqueueAsync(i, func(err error) {
+					wg.Done()
+				})
+			}
+		}()
+	}
+	wg.Wait()
+
+	suite.Equal(totalGRs*elementsPerGR, suite.fifo.GetLen())
+}
+
+// ***************************************************************************************
+// ** Flush
+// ***************************************************************************************
+
+// Flush without async enqueues returns immediately
+func (suite *FIFOAsyncTestSuite) TestFlushNoAsyncEnqueues() {
+	suite.NoError(suite.fifo.Flush(context.Background()))
+}
+
+// after Flush, all previously submitted async enqueues are enqueued
+func (suite *FIFOAsyncTestSuite) TestFlushSingleGR() {
+	total := AsyncEnqueueChanCapacity * 2
+	for i := 0; i < total; i++ {
+		suite.fifo.EnqueueAsync(i, nil)
+	}
+
+	suite.NoError(suite.fifo.Flush(context.Background()))
+	suite.Equal(total, suite.fifo.GetLen())
+}
+
+// Flush from multiple GRs, each one right after its own async enqueues
+func (suite *FIFOAsyncTestSuite) TestFlushMultipleGRs() {
+	var (
+		totalGRs      = 20
+		elementsPerGR = 100
+		wg            sync.WaitGroup
+	)
+
+	for g := 0; g < totalGRs; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			before := suite.fifo.GetLen()
+			for i := 0; i < elementsPerGR; i++ {
+				suite.fifo.EnqueueAsync(i, nil)
+			}
+			suite.NoError(suite.fifo.Flush(context.Background()))
+			suite.True(suite.fifo.GetLen() >= before+elementsPerGR)
+		}()
+	}
+	wg.Wait()
+
+	suite.Equal(totalGRs*elementsPerGR, suite.fifo.GetLen())
+}
+
+// Flush gives up once the context is done
+func (suite *FIFOAsyncTestSuite) TestFlushContextDone() {
+	// block the async enqueues goroutine in a callback
+	var (
+		release = make(chan struct{})
+		blocked = make(chan struct{})
+	)
+	suite.fifo.EnqueueAsync(1, func(err error) {
+		close(blocked)
+		<-release
+	})
+	<-blocked
+	suite.fifo.EnqueueAsync(2, nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	suite.Equal(context.DeadlineExceeded, suite.fifo.Flush(ctx))
+
+	close(release)
+	suite.NoError(suite.fifo.Flush(context.Background()))
+	suite.Equal(2, suite.fifo.GetLen())
+}
+
+// ***************************************************************************************
+// ** Run suite
+// ***************************************************************************************
+
+func TestFIFOAsyncTestSuite(t *testing.T) {
+	suite.Run(t, new(FIFOAsyncTestSuite))
+}
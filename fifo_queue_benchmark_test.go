@@ -27,6 +27,19 @@ func BenchmarkFIFOEnqueue100SingleGR(b *testing.B) {
 	}
 }
 
+// single goroutine - enqueue a batch of 100 elements
+func BenchmarkFIFOEnqueueBatch100SingleGR(b *testing.B) {
+	fifo := NewFIFO()
+	batch := make([]interface{}, 100)
+	for c := 0; c < 100; c++ {
+		batch[c] = c
+	}
+
+	for i := 0; i < b.N; i++ {
+		fifo.EnqueueBatch(batch)
+	}
+}
+
 // multiple goroutines - enqueue 100 elements per gr
 func BenchmarkFIFOEnqueue100MultipleGRs(b *testing.B) {
 	fifo := NewFIFO()
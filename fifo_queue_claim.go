@@ -0,0 +1,131 @@
+package goconcurrentqueue
+
+// Claim is the exclusive ownership of an element kept at a FIFO queue: the claimed element stays at the queue (same
+// position) but it is invisible to everybody else, it can't be dequeued, got or removed until the claim gets
+// released. See FIFO.Claim.
+type Claim struct {
+	element *claimedElement
+}
+
+// claimedElement replaces a claimed element at the queue's buffer
+type claimedElement struct {
+	// queue the element belongs to (SwapQueues could move it), protected by swapQueuesMutex
+	queue *FIFO
+	value interface{}
+	// whether the claim was removed / released, protected by the queue's rwmutex
+	done bool
+}
+
+// Claim claims the element at the given position, so the consumer could inspect it and then decide whether to remove
+// it (Claim.Remove) or to give it back (Claim.Release). Claimed elements are still enqueued (GetLen counts them), but
+// the rest of the operations skip them: Dequeue returns the first non claimed element, Get / Remove return error,
+// GetAll / Where / Count ignore them.
+// Returns error if queue is locked, the index is out of bounds or the element is already claimed.
+func (st *FIFO) Claim(index int) (*Claim, error) {
+	if st.IsLocked() {
+		return nil, ErrLockedQueue
+	}
+
+	st.rwmutex.Lock()
+	defer st.rwmutex.Unlock()
+
+	if index < 0 || index >= st.ring.length() {
+		return nil, NewQueueError(QueueErrorCodeIndexOutOfBounds, "Index out of bounds")
+	}
+
+	value := st.ring.get(index)
+	if isClaimed(value) {
+		return nil, NewQueueError(QueueErrorCodeClaimedElement, "the element is claimed")
+	}
+
+	element := &claimedElement{
+		queue: st,
+		value: value,
+	}
+	st.ring.set(index, element)
+	st.claims++
+
+	return &Claim{element: element}, nil
+}
+
+// GetClaims returns the number of claimed elements
+func (st *FIFO) GetClaims() int {
+	st.rwmutex.RLock()
+	defer st.rwmutex.RUnlock()
+
+	return st.claims
+}
+
+// Value returns the claimed element
+func (st *Claim) Value() interface{} {
+	return st.element.value
+}
+
+// Remove removes the claimed element from the queue (no matter whether the queue is locked). Returns error if the
+// claim was already removed / released or the element is no longer at the queue (i.e. truncated).
+func (st *Claim) Remove() error {
+	return st.close(func(queue *FIFO, index int) {
+		at := queue.ring.stamp(index)
+		queue.ring.removeAt(index)
+		// the claimer consumed it
+		queue.counters.dequeue(1)
+		queue.waitSLO.dequeued(at)
+		queue.hooks.dequeued(st.element.value, queue.ring.length())
+	})
+}
+
+// Release gives the claimed element back, at its current position, making it visible again (no matter whether the
+// queue is locked). Returns error if the claim was already removed / released or the element is no longer at the queue
+// (i.e. truncated).
+func (st *Claim) Release() error {
+	return st.close(func(queue *FIFO, index int) {
+		queue.ring.set(index, st.element.value)
+		// the element could be the next one for the waiting consumers
+		queue.handOverToListeners()
+		queue.notifyBatchWaiters()
+	})
+}
+
+// close finds the claimed element at its queue and closes the claim: fn (invoked with the queue's lock held) removes
+// or releases the element.
+func (st *Claim) close(fn func(queue *FIFO, index int)) error {
+	// the queue's lock is taken before SwapQueues could move the element to another queue
+	swapQueuesMutex.Lock()
+	queue := st.element.queue
+	queue.rwmutex.Lock()
+	swapQueuesMutex.Unlock()
+	defer queue.rwmutex.Unlock()
+
+	if st.element.done {
+		return NewQueueError(QueueErrorCodeInvalidClaim, "the claim is no longer valid")
+	}
+
+	for i := 0; i < queue.ring.length(); i++ {
+		if queue.ring.get(i) == st.element {
+			st.element.done = true
+			queue.claims--
+			fn(queue, i)
+			return nil
+		}
+	}
+
+	return NewQueueError(QueueErrorCodeInvalidClaim, "the claim is no longer valid")
+}
+
+// isClaimed returns true whether the given element is a claimed one
+func isClaimed(value interface{}) bool {
+	_, ok := value.(*claimedElement)
+	return ok
+}
+
+// visibleElements returns a copy of the given elements, skipping the claimed ones
+func visibleElements(elements []interface{}) []interface{} {
+	ret := make([]interface{}, 0, len(elements))
+	for _, value := range elements {
+		if !isClaimed(value) {
+			ret = append(ret, value)
+		}
+	}
+
+	return ret
+}
@@ -0,0 +1,241 @@
+package goconcurrentqueue
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type FIFOClaimTestSuite struct {
+	suite.Suite
+	fifo *FIFO
+}
+
+func (suite *FIFOClaimTestSuite) SetupTest() {
+	suite.fifo = NewFIFO()
+	for i := 0; i < 5; i++ {
+		suite.fifo.Enqueue(i)
+	}
+}
+
+// ***************************************************************************************
+// ** Claim
+// ***************************************************************************************
+
+// claimed element is kept at the queue
+func (suite *FIFOClaimTestSuite) TestClaim() {
+	claim, err := suite.fifo.Claim(0)
+	suite.NoError(err)
+	suite.Equal(0, claim.Value())
+	suite.Equal(5, suite.fifo.GetLen())
+	suite.Equal(1, suite.fifo.GetClaims())
+}
+
+// out of bounds
+func (suite *FIFOClaimTestSuite) TestClaimOutOfBounds() {
+	for _, index := range []int{-1, 5} {
+		_, err := suite.fifo.Claim(index)
+		suite.Equal(QueueErrorCodeIndexOutOfBounds, errorCode(err))
+	}
+}
+
+// already claimed element
+func (suite *FIFOClaimTestSuite) TestClaimTwice() {
+	suite.fifo.Claim(1)
+
+	_, err := suite.fifo.Claim(1)
+	suite.Equal(QueueErrorCodeClaimedElement, errorCode(err))
+}
+
+// locked queue
+func (suite *FIFOClaimTestSuite) TestClaimLockedQueue() {
+	suite.fifo.Lock()
+
+	_, err := suite.fifo.Claim(0)
+	suite.Equal(ErrLockedQueue, err)
+}
+
+// ***************************************************************************************
+// ** Claimed elements are invisible
+// ***************************************************************************************
+
+// dequeue skips the claimed elements
+func (suite *FIFOClaimTestSuite) TestDequeueSkipsClaimed() {
+	suite.fifo.Claim(0)
+	suite.fifo.Claim(2)
+
+	for _, expected := range []int{1, 3, 4} {
+		value, err := suite.fifo.Dequeue()
+		suite.NoError(err)
+		suite.Equal(expected, value)
+	}
+
+	_, err := suite.fifo.Dequeue()
+	suite.Equal(ErrEmptyQueue, err)
+	suite.Equal(2, suite.fifo.GetLen())
+}
+
+// get / remove a claimed element
+func (suite *FIFOClaimTestSuite) TestGetRemoveClaimed() {
+	suite.fifo.Claim(3)
+
+	_, err := suite.fifo.Get(3)
+	suite.Equal(QueueErrorCodeClaimedElement, errorCode(err))
+	suite.Equal(QueueErrorCodeClaimedElement, errorCode(suite.fifo.Remove(3)))
+
+	value, err := suite.fifo.Get(4)
+	suite.NoError(err)
+	suite.Equal(4, value)
+}
+
+// snapshots skip the claimed elements
+func (suite *FIFOClaimTestSuite) TestSnapshotsSkipClaimed() {
+	suite.fifo.Claim(1)
+
+	all, err := suite.fifo.GetAll(nil, nil)
+	suite.NoError(err)
+	suite.Equal([]interface{}{0, 2, 3, 4}, all)
+	suite.Equal([]interface{}{0, 2, 3, 4}, suite.fifo.Where(func(interface{}) bool { return true }))
+	suite.Equal(4, suite.fifo.Count(func(interface{}) bool { return true }))
+}
+
+// batched dequeues skip the claimed elements
+func (suite *FIFOClaimTestSuite) TestBatchedDequeuesSkipClaimed() {
+	suite.fifo.Claim(0)
+
+	elements, err := suite.fifo.dequeueElements(2, true)
+	suite.NoError(err)
+	suite.Equal([]interface{}{1, 2}, elements)
+
+	elements, err = suite.fifo.DequeueWithinBudget(func(interface{}) int { return 1 }, 10)
+	suite.NoError(err)
+	suite.Equal([]interface{}{3, 4}, elements)
+	suite.Equal(1, suite.fifo.GetLen())
+}
+
+// only claimed elements: DequeueOrWaitForNextElement waits for the next one
+func (suite *FIFOClaimTestSuite) TestDequeueOrWaitForNextElementOnlyClaimed() {
+	fifo := NewFIFO()
+	fifo.Enqueue(1)
+	fifo.Claim(0)
+
+	result := make(chan interface{}, 1)
+	go func() {
+		value, _ := fifo.DequeueOrWaitForNextElement()
+		result <- value
+	}()
+	time.Sleep(10 * time.Millisecond)
+	fifo.Enqueue(2)
+
+	select {
+	case value := <-result:
+		suite.Equal(2, value)
+	case <-time.After(2 * time.Second):
+		suite.FailNow("too much time waiting for the enqueued element")
+	}
+}
+
+// ***************************************************************************************
+// ** Remove / Release
+// ***************************************************************************************
+
+// the claimed element gets removed
+func (suite *FIFOClaimTestSuite) TestRemove() {
+	claim, _ := suite.fifo.Claim(2)
+
+	suite.NoError(claim.Remove())
+	suite.Equal(4, suite.fifo.GetLen())
+	suite.Equal(0, suite.fifo.GetClaims())
+
+	all, _ := suite.fifo.GetAll(nil, nil)
+	suite.Equal([]interface{}{0, 1, 3, 4}, all)
+}
+
+// the claimed element gets visible again, at its current position
+func (suite *FIFOClaimTestSuite) TestRelease() {
+	claim, _ := suite.fifo.Claim(0)
+	suite.fifo.Enqueue(5)
+
+	suite.NoError(claim.Release())
+	suite.Equal(0, suite.fifo.GetClaims())
+
+	value, err := suite.fifo.Dequeue()
+	suite.NoError(err)
+	suite.Equal(0, value)
+}
+
+// released element goes to the waiting consumer
+func (suite *FIFOClaimTestSuite) TestReleaseWaitingConsumer() {
+	fifo := NewFIFO()
+	fifo.Enqueue(testValue)
+	claim, _ := fifo.Claim(0)
+
+	result := make(chan interface{}, 1)
+	go func() {
+		value, _ := fifo.DequeueOrWaitForNextElement()
+		result <- value
+	}()
+	time.Sleep(10 * time.Millisecond)
+
+	suite.NoError(claim.Release())
+
+	select {
+	case value := <-result:
+		suite.Equal(testValue, value)
+	case <-time.After(2 * time.Second):
+		suite.FailNow("too much time waiting for the released element")
+	}
+	suite.Equal(0, fifo.GetLen())
+}
+
+// closed claims are no longer valid
+func (suite *FIFOClaimTestSuite) TestClaimClosedTwice() {
+	claim, _ := suite.fifo.Claim(0)
+	suite.NoError(claim.Release())
+
+	suite.Equal(QueueErrorCodeInvalidClaim, errorCode(claim.Release()))
+	suite.Equal(QueueErrorCodeInvalidClaim, errorCode(claim.Remove()))
+}
+
+// truncated claimed element
+func (suite *FIFOClaimTestSuite) TestClaimTruncated() {
+	claim, _ := suite.fifo.Claim(4)
+	suite.NoError(suite.fifo.Truncate(2))
+
+	suite.Equal(0, suite.fifo.GetClaims())
+	suite.Equal(QueueErrorCodeInvalidClaim, errorCode(claim.Remove()))
+}
+
+// claims follow their elements to the other queue
+func (suite *FIFOClaimTestSuite) TestClaimSwapQueues() {
+	other := NewFIFO()
+	claim, _ := suite.fifo.Claim(0)
+
+	suite.NoError(SwapQueues(suite.fifo, other))
+	suite.Equal(0, suite.fifo.GetClaims())
+	suite.Equal(1, other.GetClaims())
+
+	suite.NoError(claim.Remove())
+	suite.Equal(4, other.GetLen())
+	suite.Equal(0, other.GetClaims())
+}
+
+// remove / release work on locked queues
+func (suite *FIFOClaimTestSuite) TestCloseLockedQueue() {
+	first, _ := suite.fifo.Claim(0)
+	second, _ := suite.fifo.Claim(1)
+	suite.fifo.Lock()
+
+	suite.NoError(first.Remove())
+	suite.NoError(second.Release())
+	suite.Equal(4, suite.fifo.GetLen())
+}
+
+// ***************************************************************************************
+// ** Run suite
+// ***************************************************************************************
+
+func TestFIFOClaimTestSuite(t *testing.T) {
+	suite.Run(t, new(FIFOClaimTestSuite))
+}
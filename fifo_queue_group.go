@@ -0,0 +1,71 @@
+package goconcurrentqueue
+
+import (
+	"fmt"
+)
+
+// EnqueueInGroup adds an element to the given group. Grouped elements are invisible to the consumers (and GetLen)
+// until the group gets sealed (SealGroup): then they get enqueued all together, contiguously and in order, so a
+// group is processed all-or-nothing. Returns error if queue is locked.
+func (st *FIFO) EnqueueInGroup(groupID string, value interface{}) error {
+	if st.IsLocked() {
+		st.counters.reject(1)
+		return ErrLockedQueue
+	}
+
+	st.rwmutex.Lock()
+	defer st.rwmutex.Unlock()
+
+	if st.groups == nil {
+		st.groups = make(map[string][]interface{})
+	}
+	st.groups[groupID] = append(st.groups[groupID], value)
+
+	return nil
+}
+
+// SealGroup enqueues the group's elements, in order, under a single lock acquisition (see EnqueueBatch): no other
+// element gets interleaved. Returns error if queue is locked (the group stays unsealed) or there is no such group.
+func (st *FIFO) SealGroup(groupID string) error {
+	if st.IsLocked() {
+		return ErrLockedQueue
+	}
+
+	st.rwmutex.Lock()
+	defer st.rwmutex.Unlock()
+
+	elements, ok := st.groups[groupID]
+	if !ok {
+		return NewQueueError(QueueErrorCodeUnknownGroup, fmt.Sprintf("unknown group: %v", groupID))
+	}
+	delete(st.groups, groupID)
+
+	for _, value := range elements {
+		st.enqueueElement(value)
+	}
+
+	return nil
+}
+
+// DiscardGroup drops the group's elements (none of them gets enqueued) and returns them. Returns error if there is no
+// such group.
+func (st *FIFO) DiscardGroup(groupID string) ([]interface{}, error) {
+	st.rwmutex.Lock()
+	defer st.rwmutex.Unlock()
+
+	elements, ok := st.groups[groupID]
+	if !ok {
+		return nil, NewQueueError(QueueErrorCodeUnknownGroup, fmt.Sprintf("unknown group: %v", groupID))
+	}
+	delete(st.groups, groupID)
+
+	return elements, nil
+}
+
+// GetGroupLen returns the number of elements at the given (not sealed yet) group
+func (st *FIFO) GetGroupLen(groupID string) int {
+	st.rwmutex.RLock()
+	defer st.rwmutex.RUnlock()
+
+	return len(st.groups[groupID])
+}
@@ -0,0 +1,153 @@
+package goconcurrentqueue
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type FIFOGroupTestSuite struct {
+	suite.Suite
+	fifo *FIFO
+}
+
+func (suite *FIFOGroupTestSuite) SetupTest() {
+	suite.fifo = NewFIFO()
+}
+
+// ***************************************************************************************
+// ** EnqueueInGroup && SealGroup
+// ***************************************************************************************
+
+// grouped elements are invisible until the group gets sealed
+func (suite *FIFOGroupTestSuite) TestEnqueueInGroup() {
+	for i := 0; i < 3; i++ {
+		suite.NoError(suite.fifo.EnqueueInGroup("g", i))
+	}
+	suite.Equal(0, suite.fifo.GetLen())
+	suite.Equal(3, suite.fifo.GetGroupLen("g"))
+
+	_, err := suite.fifo.Dequeue()
+	suite.Equal(ErrEmptyQueue, err)
+
+	suite.NoError(suite.fifo.SealGroup("g"))
+	suite.Equal(3, suite.fifo.GetLen())
+	suite.Equal(0, suite.fifo.GetGroupLen("g"))
+
+	elements, err := suite.fifo.DequeueUpTo(10)
+	suite.NoError(err)
+	suite.Equal([]interface{}{0, 1, 2}, elements)
+}
+
+// sealed groups get enqueued contiguously, no matter the elements enqueued in the meantime
+func (suite *FIFOGroupTestSuite) TestSealGroupContiguous() {
+	suite.fifo.EnqueueInGroup("a", "a1")
+	suite.fifo.Enqueue(1)
+	suite.fifo.EnqueueInGroup("b", "b1")
+	suite.fifo.EnqueueInGroup("a", "a2")
+	suite.fifo.Enqueue(2)
+	suite.fifo.EnqueueInGroup("b", "b2")
+
+	suite.NoError(suite.fifo.SealGroup("b"))
+	suite.NoError(suite.fifo.SealGroup("a"))
+
+	elements, err := suite.fifo.DequeueUpTo(10)
+	suite.NoError(err)
+	suite.Equal([]interface{}{1, 2, "b1", "b2", "a1", "a2"}, elements)
+}
+
+// unknown group
+func (suite *FIFOGroupTestSuite) TestSealUnknownGroup() {
+	suite.Equal(QueueErrorCodeUnknownGroup, errorCode(suite.fifo.SealGroup("g")))
+
+	suite.fifo.EnqueueInGroup("g", 1)
+	suite.NoError(suite.fifo.SealGroup("g"))
+	suite.Equal(QueueErrorCodeUnknownGroup, errorCode(suite.fifo.SealGroup("g")), "a group could be sealed only once")
+}
+
+// locked queue
+func (suite *FIFOGroupTestSuite) TestLockedQueue() {
+	suite.fifo.EnqueueInGroup("g", 1)
+	suite.fifo.Lock()
+
+	suite.Equal(ErrLockedQueue, suite.fifo.EnqueueInGroup("g", 2))
+	suite.Equal(ErrLockedQueue, suite.fifo.SealGroup("g"))
+	suite.Equal(1, suite.fifo.GetGroupLen("g"), "the group must stay unsealed")
+
+	suite.fifo.Unlock()
+	suite.NoError(suite.fifo.SealGroup("g"))
+	suite.Equal(1, suite.fifo.GetLen())
+}
+
+// waiting consumers get the sealed elements
+func (suite *FIFOGroupTestSuite) TestSealGroupWaitingConsumer() {
+	done := make(chan interface{}, 1)
+	go func() {
+		value, _ := suite.fifo.DequeueOrWaitForNextElement()
+		done <- value
+	}()
+
+	suite.fifo.EnqueueInGroup("g", 1)
+	suite.fifo.EnqueueInGroup("g", 2)
+	suite.NoError(suite.fifo.SealGroup("g"))
+
+	suite.Equal(1, <-done)
+}
+
+// concurrent groups: every group gets dequeued contiguously
+func (suite *FIFOGroupTestSuite) TestSealGroupMultipleGRs() {
+	var (
+		groups    = 10
+		groupSize = 50
+		wg        sync.WaitGroup
+	)
+
+	for g := 0; g < groups; g++ {
+		wg.Add(1)
+		go func(group int) {
+			defer wg.Done()
+			for i := 0; i < groupSize; i++ {
+				suite.fifo.EnqueueInGroup(string(rune('a'+group)), group)
+			}
+			suite.fifo.SealGroup(string(rune('a' + group)))
+		}(g)
+	}
+	wg.Wait()
+
+	elements, err := suite.fifo.DequeueUpTo(groups * groupSize)
+	suite.NoError(err)
+	suite.Len(elements, groups*groupSize)
+	for i := 0; i < len(elements); i += groupSize {
+		for j := i; j < i+groupSize; j++ {
+			suite.Equal(elements[i], elements[j], "group elements must be contiguous")
+		}
+	}
+}
+
+// ***************************************************************************************
+// ** DiscardGroup
+// ***************************************************************************************
+
+// discarded elements never get enqueued
+func (suite *FIFOGroupTestSuite) TestDiscardGroup() {
+	suite.fifo.EnqueueInGroup("g", 1)
+	suite.fifo.EnqueueInGroup("g", 2)
+
+	elements, err := suite.fifo.DiscardGroup("g")
+	suite.NoError(err)
+	suite.Equal([]interface{}{1, 2}, elements)
+	suite.Equal(QueueErrorCodeUnknownGroup, errorCode(suite.fifo.SealGroup("g")))
+	suite.Equal(0, suite.fifo.GetLen())
+
+	_, err = suite.fifo.DiscardGroup("g")
+	suite.Equal(QueueErrorCodeUnknownGroup, errorCode(err))
+}
+
+// ***************************************************************************************
+// ** Run suite
+// ***************************************************************************************
+
+func TestFIFOGroupTestSuite(t *testing.T) {
+	suite.Run(t, new(FIFOGroupTestSuite))
+}
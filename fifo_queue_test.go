@@ -1,7 +1,9 @@
 package goconcurrentqueue
 
 import (
+	"context"
 	"fmt"
+	"runtime"
 	"sync"
 	"testing"
 	"time"
@@ -158,6 +160,87 @@ func (suite *FIFOTestSuite) TestGetLenMultipleGRs() {
 	wg.Wait()
 }
 
+// ***************************************************************************************
+// ** EnqueueBatch
+// ***************************************************************************************
+
+// elements are enqueued in order
+func (suite *FIFOTestSuite) TestEnqueueBatchSingleGR() {
+	suite.NoError(suite.fifo.Enqueue(0))
+	suite.NoError(suite.fifo.EnqueueBatch([]interface{}{1, 2, 3}))
+	suite.NoError(suite.fifo.EnqueueBatch(nil))
+
+	suite.Equal([]interface{}{0, 1, 2, 3}, suite.fifo.ring.elements())
+}
+
+// locked queue: no element gets enqueued
+func (suite *FIFOTestSuite) TestEnqueueBatchLockedQueue() {
+	suite.fifo.Lock()
+
+	suite.Equal(ErrLockedQueue, suite.fifo.EnqueueBatch([]interface{}{1, 2}))
+	suite.Equal(0, suite.fifo.GetLen())
+}
+
+// every waiting consumer gets one of the first elements, the rest get enqueued
+func (suite *FIFOTestSuite) TestEnqueueBatchWaitingConsumers() {
+	const totalWaiters = 3
+
+	results := make(chan interface{}, totalWaiters)
+	for i := 0; i < totalWaiters; i++ {
+		go func() {
+			value, _ := suite.fifo.DequeueOrWaitForNextElement()
+			results <- value
+		}()
+	}
+	for len(suite.fifo.waitForNextElementChan) < totalWaiters {
+		time.Sleep(time.Millisecond)
+	}
+
+	suite.NoError(suite.fifo.EnqueueBatch([]interface{}{0, 1, 2, 3, 4}))
+
+	received := make([]interface{}, 0, totalWaiters)
+	for i := 0; i < totalWaiters; i++ {
+		select {
+		case value := <-results:
+			received = append(received, value)
+		case <-time.After(2 * time.Second):
+			suite.FailNow("too much time waiting for the enqueued elements")
+		}
+	}
+	suite.ElementsMatch([]interface{}{0, 1, 2}, received)
+	suite.Equal([]interface{}{3, 4}, suite.fifo.ring.elements())
+}
+
+// concurrent batches don't get interleaved
+func (suite *FIFOTestSuite) TestEnqueueBatchMultipleGRs() {
+	const (
+		totalGRs  = 10
+		batchSize = 100
+	)
+
+	var wg sync.WaitGroup
+	for i := 0; i < totalGRs; i++ {
+		wg.Add(1)
+		go func(producer int) {
+			defer wg.Done()
+			batch := make([]interface{}, batchSize)
+			for c := 0; c < batchSize; c++ {
+				batch[c] = producer
+			}
+			suite.NoError(suite.fifo.EnqueueBatch(batch))
+		}(i)
+	}
+	wg.Wait()
+
+	elements := suite.fifo.ring.elements()
+	suite.Equal(totalGRs*batchSize, len(elements))
+	for i := 0; i < len(elements); i += batchSize {
+		for c := i; c < i+batchSize; c++ {
+			suite.Equal(elements[i], elements[c], "batches interleaved")
+		}
+	}
+}
+
 // ***************************************************************************************
 // ** GetCap
 // ***************************************************************************************
@@ -165,12 +248,36 @@ func (suite *FIFOTestSuite) TestGetLenMultipleGRs() {
 // single GR getCapacity
 func (suite *FIFOTestSuite) TestGetCapSingleGR() {
 	// initial capacity
-	suite.Equal(cap(suite.fifo.slice), suite.fifo.GetCap(), "unexpected capacity")
+	suite.Equal(suite.fifo.ring.capacity(), suite.fifo.GetCap(), "unexpected capacity")
 
 	// checking after adding 2 items
 	suite.fifo.Enqueue(1)
 	suite.fifo.Enqueue(2)
-	suite.Equal(cap(suite.fifo.slice), suite.fifo.GetCap(), "unexpected capacity")
+	suite.Equal(suite.fifo.ring.capacity(), suite.fifo.GetCap(), "unexpected capacity")
+}
+
+// the dequeued elements' slots are reused and the capacity shrinks back once a backlog gets dequeued
+func (suite *FIFOTestSuite) TestGetCapAfterBacklog() {
+	total := ringBufferMinShrinkCapacity * 16
+	for i := 0; i < total; i++ {
+		suite.NoError(suite.fifo.Enqueue(i))
+	}
+	suite.True(suite.fifo.GetCap() >= total, "unexpected capacity")
+
+	for i := 0; i < total; i++ {
+		value, err := suite.fifo.Dequeue()
+		suite.NoError(err)
+		suite.Equal(i, value)
+	}
+	suite.Equal(ringBufferMinShrinkCapacity, suite.fifo.GetCap(), "the capacity must shrink back")
+
+	// steady enqueue / dequeue: no growth
+	for i := 0; i < total; i++ {
+		suite.NoError(suite.fifo.Enqueue(i))
+		_, err := suite.fifo.Dequeue()
+		suite.NoError(err)
+	}
+	suite.Equal(ringBufferMinShrinkCapacity, suite.fifo.GetCap(), "unexpected capacity")
 }
 
 // ***************************************************************************************
@@ -251,6 +358,76 @@ func (suite *FIFOTestSuite) TestGetMultipleGRs() {
 	suite.Equalf(totalElementsToEnqueue, total, "Expected len: %v", totalElementsToEnqueue)
 }
 
+// ***************************************************************************************
+// ** Peek / PeekBack
+// ***************************************************************************************
+
+// first and last elements, kept at the queue
+func (suite *FIFOTestSuite) TestPeekSingleGR() {
+	for i := 0; i < 3; i++ {
+		suite.fifo.Enqueue(i)
+	}
+
+	value, err := suite.fifo.Peek()
+	suite.NoError(err)
+	suite.Equal(0, value)
+
+	value, err = suite.fifo.PeekBack()
+	suite.NoError(err)
+	suite.Equal(2, value)
+	suite.Equal(3, suite.fifo.GetLen())
+
+	// the next peek follows the dequeues
+	suite.fifo.Dequeue()
+	value, _ = suite.fifo.Peek()
+	suite.Equal(1, value)
+}
+
+// empty queue
+func (suite *FIFOTestSuite) TestPeekEmptyQueue() {
+	_, err := suite.fifo.Peek()
+	suite.Equal(ErrEmptyQueue, err)
+
+	_, err = suite.fifo.PeekBack()
+	suite.Equal(ErrEmptyQueue, err)
+}
+
+// locked queue
+func (suite *FIFOTestSuite) TestPeekLockedQueue() {
+	suite.fifo.Enqueue(1)
+	suite.fifo.Lock()
+
+	_, err := suite.fifo.Peek()
+	suite.Equal(ErrLockedQueue, err)
+
+	_, err = suite.fifo.PeekBack()
+	suite.Equal(ErrLockedQueue, err)
+}
+
+// claimed elements are skipped
+func (suite *FIFOTestSuite) TestPeekClaimedElements() {
+	for i := 0; i < 3; i++ {
+		suite.fifo.Enqueue(i)
+	}
+	_, err := suite.fifo.Claim(0)
+	suite.NoError(err)
+	_, err = suite.fifo.Claim(2)
+	suite.NoError(err)
+
+	value, err := suite.fifo.Peek()
+	suite.NoError(err)
+	suite.Equal(1, value)
+
+	value, err = suite.fifo.PeekBack()
+	suite.NoError(err)
+	suite.Equal(1, value)
+
+	_, err = suite.fifo.Claim(1)
+	suite.NoError(err)
+	_, err = suite.fifo.Peek()
+	suite.Equal(ErrEmptyQueue, err)
+}
+
 // ***************************************************************************************
 // ** Remove
 // ***************************************************************************************
@@ -432,6 +609,106 @@ func (suite *FIFOTestSuite) TestDequeueMultipleGRs() {
 	suite.Equalf(totalElementsToDequeue, val, "The expected last element's value should be: %v", totalElementsToEnqueue-totalElementsToDequeue)
 }
 
+// ***************************************************************************************
+// ** DequeueUpTo
+// ***************************************************************************************
+
+// up to n elements, in order
+func (suite *FIFOTestSuite) TestDequeueUpToSingleGR() {
+	for i := 0; i < 5; i++ {
+		suite.fifo.Enqueue(i)
+	}
+
+	elements, err := suite.fifo.DequeueUpTo(3)
+	suite.NoError(err)
+	suite.Equal([]interface{}{0, 1, 2}, elements)
+
+	// fewer enqueued elements than requested
+	elements, err = suite.fifo.DequeueUpTo(3)
+	suite.NoError(err)
+	suite.Equal([]interface{}{3, 4}, elements)
+	suite.Equal(0, suite.fifo.GetLen())
+}
+
+// empty queue
+func (suite *FIFOTestSuite) TestDequeueUpToEmptyQueue() {
+	_, err := suite.fifo.DequeueUpTo(3)
+	suite.Equal(ErrEmptyQueue, err)
+}
+
+// locked queue
+func (suite *FIFOTestSuite) TestDequeueUpToLockedQueue() {
+	suite.fifo.Enqueue(1)
+	suite.fifo.Lock()
+
+	_, err := suite.fifo.DequeueUpTo(1)
+	suite.Equal(ErrLockedQueue, err)
+}
+
+// invalid n
+func (suite *FIFOTestSuite) TestDequeueUpToInvalidN() {
+	suite.fifo.Enqueue(1)
+
+	_, err := suite.fifo.DequeueUpTo(0)
+	suite.Equal(QueueErrorCodeIndexOutOfBounds, errorCode(err))
+	suite.Equal(1, suite.fifo.GetLen())
+}
+
+// claimed elements are skipped
+func (suite *FIFOTestSuite) TestDequeueUpToClaimedElements() {
+	for i := 0; i < 4; i++ {
+		suite.fifo.Enqueue(i)
+	}
+	_, err := suite.fifo.Claim(1)
+	suite.NoError(err)
+
+	elements, err := suite.fifo.DequeueUpTo(2)
+	suite.NoError(err)
+	suite.Equal([]interface{}{0, 2}, elements)
+}
+
+// concurrent batch consumers: every batch is made of consecutive elements and every element gets dequeued once
+func (suite *FIFOTestSuite) TestDequeueUpToMultipleGRs() {
+	const (
+		total     = 1000
+		totalGRs  = 10
+		batchSize = 7
+	)
+	for i := 0; i < total; i++ {
+		suite.fifo.Enqueue(i)
+	}
+
+	var (
+		wg       sync.WaitGroup
+		mutex    sync.Mutex
+		dequeued = make(map[interface{}]int)
+	)
+	for i := 0; i < totalGRs; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				elements, err := suite.fifo.DequeueUpTo(batchSize)
+				if err != nil {
+					return
+				}
+
+				for c := 1; c < len(elements); c++ {
+					suite.Equal(elements[c-1].(int)+1, elements[c], "batch interleaved")
+				}
+				mutex.Lock()
+				for _, value := range elements {
+					dequeued[value]++
+				}
+				mutex.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	suite.Equal(total, len(dequeued))
+}
+
 // ***************************************************************************************
 // ** DequeueOrWaitForNextElement
 // ***************************************************************************************
@@ -653,285 +930,1484 @@ func (suite *FIFOTestSuite) TestDequeueOrWaitForNextElementGapSingleGR() {
 }
 
 // ***************************************************************************************
-// ** Lock / Unlock / IsLocked
+// ** DequeueOrWaitForNextElementWithContext
 // ***************************************************************************************
 
-// single lock
-func (suite *FIFOTestSuite) TestLockSingleGR() {
-	suite.fifo.Lock()
-	suite.True(suite.fifo.isLocked == true, "fifo.isLocked has to be true after fifo.Lock()")
-}
+// element already enqueued: no waiting
+func (suite *FIFOTestSuite) TestDequeueOrWaitForNextElementWithContextEnqueued() {
+	suite.fifo.Enqueue(testValue)
 
-// single unlock
-func (suite *FIFOTestSuite) TestUnlockSingleGR() {
-	suite.fifo.Lock()
-	suite.fifo.Unlock()
-	suite.True(suite.fifo.isLocked == false, "fifo.isLocked has to be false after fifo.Unlock()")
+	value, err := suite.fifo.DequeueOrWaitForNextElementWithContext(context.Background())
+	suite.NoError(err)
+	suite.Equal(testValue, value)
 }
 
-// single isLocked
-func (suite *FIFOTestSuite) TestIsLockedSingleGR() {
-	suite.True(suite.fifo.isLocked == suite.fifo.IsLocked(), "fifo.IsLocked() has to be equal to fifo.isLocked")
+// element enqueued while waiting
+func (suite *FIFOTestSuite) TestDequeueOrWaitForNextElementWithContextWaiting() {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
 
-	suite.fifo.Lock()
-	suite.True(suite.fifo.isLocked == suite.fifo.IsLocked(), "fifo.IsLocked() has to be equal to fifo.isLocked")
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		suite.fifo.Enqueue(testValue)
+	}()
 
-	suite.fifo.Unlock()
-	suite.True(suite.fifo.isLocked == suite.fifo.IsLocked(), "fifo.IsLocked() has to be equal to fifo.isLocked")
+	value, err := suite.fifo.DequeueOrWaitForNextElementWithContext(ctx)
+	suite.NoError(err)
+	suite.Equal(testValue, value)
 }
 
-// ***************************************************************************************
-// ** Swap
-// ***************************************************************************************
-func (suite *FIFOTestSuite) TestSwapEmptyQueue() {
-	const (
-		a = 4
-		b = 4
-	)
-
-	err := suite.fifo.Swap(a, b)
+// already cancelled context
+func (suite *FIFOTestSuite) TestDequeueOrWaitForNextElementWithContextCancelled() {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
 
-	suite.EqualError(err, "Empty queue")
+	_, err := suite.fifo.DequeueOrWaitForNextElementWithContext(ctx)
+	suite.Equal(context.Canceled, err)
 }
 
-func (suite *FIFOTestSuite) TestSwapIndexOutOfBounds() {
-	const (
-		size = 2
-		a    = 4
-		b    = 3
-	)
-
-	for i := 0; i < size; i++ {
-		suite.fifo.Enqueue(i)
-	}
+// deadline exceeded while waiting: the next element is not handed over to the gone waiter
+func (suite *FIFOTestSuite) TestDequeueOrWaitForNextElementWithContextDeadline() {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
 
-	err := suite.fifo.Swap(a, b)
+	_, err := suite.fifo.DequeueOrWaitForNextElementWithContext(ctx)
+	suite.Equal(context.DeadlineExceeded, err)
 
-	suite.EqualError(err, "Index out of bounds")
+	suite.fifo.Enqueue(testValue)
+	suite.Equal(1, suite.fifo.GetLen())
+	value, err := suite.fifo.Dequeue()
+	suite.NoError(err)
+	suite.Equal(testValue, value)
 }
 
-func (suite *FIFOTestSuite) TestSwapSameIndex() {
-	const (
-		size = 10
-		a    = 4
-		b    = 4
+// cancelled waiters don't take elements from the remaining ones
+func (suite *FIFOTestSuite) TestDequeueOrWaitForNextElementWithContextRemainingWaiters() {
+	var (
+		totalWaiters = 3
+		results      = make(chan interface{}, totalWaiters)
+		ctx, cancel  = context.WithCancel(context.Background())
 	)
 
-	for i := 0; i < size; i++ {
-		suite.fifo.Enqueue(i)
+	for i := 0; i < totalWaiters; i++ {
+		waiterCtx := context.Background()
+		// the middle waiter gets cancelled
+		if i == 1 {
+			waiterCtx = ctx
+		}
+		go func() {
+			value, err := suite.fifo.DequeueOrWaitForNextElementWithContext(waiterCtx)
+			if err != nil {
+				value = err
+			}
+			results <- value
+		}()
+		time.Sleep(10 * time.Millisecond)
 	}
 
-	err := suite.fifo.Swap(a, b)
+	cancel()
+	suite.Equal(context.Canceled, <-results)
 
-	suite.EqualError(err, "Indexes are the same number")
+	suite.fifo.Enqueue(1)
+	suite.fifo.Enqueue(2)
+	var values []interface{}
+	for i := 0; i < totalWaiters-1; i++ {
+		select {
+		case value := <-results:
+			values = append(values, value)
+		case <-time.After(2 * time.Second):
+			suite.FailNow("too much time waiting for the enqueued element")
+		}
+	}
+	suite.ElementsMatch([]interface{}{1, 2}, values)
+	suite.Equal(0, suite.fifo.GetLen())
 }
 
-func (suite *FIFOTestSuite) TestSwapElements() {
+// ***************************************************************************************
+// ** SetWaitSpins
+// ***************************************************************************************
 
-	const (
-		size = 10
-		a    = 3
-		b    = 4
-	)
+// a spinning waiter gets the element enqueued while it spins, without registering a listener
+func (suite *FIFOTestSuite) TestWaitSpinsElementEnqueuedWhileSpinning() {
+	suite.fifo.SetWaitSpins(1000000)
 
-	for i := 0; i < size; i++ {
-		suite.fifo.Enqueue(i)
+	result := make(chan interface{}, 1)
+	go func() {
+		value, _ := suite.fifo.DequeueOrWaitForNextElement()
+		result <- value
+	}()
+	time.Sleep(10 * time.Millisecond)
+
+	suite.NoError(suite.fifo.Enqueue(testValue))
+
+	select {
+	case value := <-result:
+		suite.Equal(testValue, value)
+	case <-time.After(2 * time.Second):
+		suite.FailNow("too much time waiting for the enqueued element")
 	}
+	suite.Equal(0, len(suite.fifo.waitForNextElementChan), "no listener expected")
+}
 
-	oldValueFromA, _ := suite.fifo.Get(a)
-	oldValueFromB, _ := suite.fifo.Get(b)
+// once the spins are exhausted the waiter parks as usual
+func (suite *FIFOTestSuite) TestWaitSpinsExhausted() {
+	suite.fifo.SetWaitSpins(10)
 
-	suite.fifo.Swap(a, b)
+	result := make(chan interface{}, 1)
+	go func() {
+		value, _ := suite.fifo.DequeueOrWaitForNextElement()
+		result <- value
+	}()
+	for len(suite.fifo.waitForNextElementChan) == 0 {
+		time.Sleep(time.Millisecond)
+	}
 
-	newValueFromA, _ := suite.fifo.Get(a)
-	newValueFromB, _ := suite.fifo.Get(b)
+	suite.NoError(suite.fifo.Enqueue(testValue))
 
-	suite.Equal(oldValueFromA, newValueFromB)
-	suite.Equal(oldValueFromB, newValueFromA)
+	select {
+	case value := <-result:
+		suite.Equal(testValue, value)
+	case <-time.After(2 * time.Second):
+		suite.FailNow("too much time waiting for the enqueued element")
+	}
 }
 
-// ***************************************************************************************
-// ** Move elements inside an empty queue
-// ***************************************************************************************
-func (suite *FIFOTestSuite) TestMoveEmptyQueue() {
-	const (
-		top  = 10
-		back = 1
-	)
+// the context gets checked while spinning
+func (suite *FIFOTestSuite) TestWaitSpinsContextCancelled() {
+	suite.fifo.SetWaitSpins(1000000000)
 
-	suite.EqualError(suite.fifo.MoveFrontWithId(top), "Empty queue")
-	suite.EqualError(suite.fifo.MoveBackWithId(back), "Empty queue")
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err := suite.fifo.DequeueOrWaitForNextElementWithContext(ctx)
+	suite.Equal(context.DeadlineExceeded, err)
 }
 
 // ***************************************************************************************
-// ** Move elements to the front
+// ** DequeueWithTimeout
 // ***************************************************************************************
-func (suite *FIFOTestSuite) TestMoveFront() {
-	const (
-		size = 10
-		top  = 4
-	)
 
-	for i := 0; i < size; i++ {
-		suite.fifo.Enqueue(i + 1)
-	}
+// element already enqueued
+func (suite *FIFOTestSuite) TestDequeueWithTimeoutEnqueued() {
+	suite.fifo.Enqueue(testValue)
 
-	result := []interface{}{5, 1, 2, 3, 4, 6, 7, 8, 9, 10}
+	value, err := suite.fifo.DequeueWithTimeout(time.Second)
+	suite.NoError(err)
+	suite.Equal(testValue, value)
+}
 
-	suite.NoError(suite.fifo.MoveFrontWithId(top))
-	suite.Equal(result, suite.fifo.slice)
+// element enqueued while waiting
+func (suite *FIFOTestSuite) TestDequeueWithTimeoutWaiting() {
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		suite.fifo.Enqueue(testValue)
+	}()
+
+	value, err := suite.fifo.DequeueWithTimeout(2 * time.Second)
+	suite.NoError(err)
+	suite.Equal(testValue, value)
 }
 
-func (suite *FIFOTestSuite) TestMoveFrontOutOfBounds() {
+// no element in time
+func (suite *FIFOTestSuite) TestDequeueWithTimeoutExpired() {
+	_, err := suite.fifo.DequeueWithTimeout(10 * time.Millisecond)
+	suite.Equal(ErrTimeout, err)
+	suite.Equal(QueueErrorCodeTimeout, err.(*QueueError).Code())
+}
+
+// no waiting at all
+func (suite *FIFOTestSuite) TestDequeueWithTimeoutZero() {
+	_, err := suite.fifo.DequeueWithTimeout(0)
+	suite.Equal(ErrTimeout, err)
+
+	suite.fifo.Enqueue(testValue)
+	value, err := suite.fifo.DequeueWithTimeout(0)
+	suite.NoError(err)
+	suite.Equal(testValue, value)
+}
+
+// locked queue
+func (suite *FIFOTestSuite) TestDequeueWithTimeoutLockedQueue() {
+	suite.fifo.Lock()
+
+	_, err := suite.fifo.DequeueWithTimeout(time.Second)
+	suite.Equal(ErrLockedQueue, err)
+}
+
+// ***************************************************************************************
+// ** DequeueBatchOrWait
+// ***************************************************************************************
+
+// enough elements already enqueued: up to max, no waiting
+func (suite *FIFOTestSuite) TestDequeueBatchOrWaitEnqueued() {
+	for i := 0; i < 5; i++ {
+		suite.fifo.Enqueue(i)
+	}
+
+	elements, err := suite.fifo.DequeueBatchOrWait(2, 3, time.Second)
+	suite.NoError(err)
+	suite.Equal([]interface{}{0, 1, 2}, elements)
+}
+
+// waits until min elements get enqueued
+func (suite *FIFOTestSuite) TestDequeueBatchOrWaitMinElements() {
+	result := make(chan []interface{}, 1)
+	go func() {
+		elements, err := suite.fifo.DequeueBatchOrWait(3, 10, 2*time.Second)
+		suite.NoError(err)
+		result <- elements
+	}()
+
+	for i := 0; i < 3; i++ {
+		time.Sleep(5 * time.Millisecond)
+		select {
+		case <-result:
+			suite.FailNow("the batch must wait for min elements")
+		default:
+		}
+		suite.NoError(suite.fifo.Enqueue(i))
+	}
+
+	select {
+	case elements := <-result:
+		suite.Equal([]interface{}{0, 1, 2}, elements)
+	case <-time.After(time.Second):
+		suite.FailNow("too much time waiting for the batch")
+	}
+}
+
+// timeout: the elements already enqueued (fewer than min)
+func (suite *FIFOTestSuite) TestDequeueBatchOrWaitTimeoutPartialBatch() {
+	suite.fifo.Enqueue(1)
+
+	elements, err := suite.fifo.DequeueBatchOrWait(5, 10, 10*time.Millisecond)
+	suite.NoError(err)
+	suite.Equal([]interface{}{1}, elements)
+}
+
+// timeout and no elements
+func (suite *FIFOTestSuite) TestDequeueBatchOrWaitTimeout() {
+	_, err := suite.fifo.DequeueBatchOrWait(1, 10, 10*time.Millisecond)
+	suite.Equal(ErrTimeout, err)
+}
+
+// cancelled context and no elements
+func (suite *FIFOTestSuite) TestDequeueBatchOrWaitWithContextCancelled() {
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		cancel()
+	}()
+
+	_, err := suite.fifo.DequeueBatchOrWaitWithContext(ctx, 1, 1)
+	suite.Equal(context.Canceled, err)
+	suite.Equal(0, suite.fifo.batchWaiters)
+}
+
+// invalid min / max
+func (suite *FIFOTestSuite) TestDequeueBatchOrWaitInvalidSize() {
+	_, err := suite.fifo.DequeueBatchOrWait(0, 1, time.Second)
+	suite.Equal(QueueErrorCodeIndexOutOfBounds, errorCode(err))
+
+	_, err = suite.fifo.DequeueBatchOrWait(3, 2, time.Second)
+	suite.Equal(QueueErrorCodeIndexOutOfBounds, errorCode(err))
+}
+
+// locked queue
+func (suite *FIFOTestSuite) TestDequeueBatchOrWaitLockedQueue() {
+	suite.fifo.Lock()
+
+	_, err := suite.fifo.DequeueBatchOrWait(1, 1, time.Second)
+	suite.Equal(ErrLockedQueue, err)
+}
+
+// concurrent producers and batch consumers: every element gets dequeued once
+func (suite *FIFOTestSuite) TestDequeueBatchOrWaitMultipleGRs() {
+	const (
+		totalProducers = 4
+		totalConsumers = 4
+		perProducer    = 250
+		total          = totalProducers * perProducer
+	)
+
+	var (
+		wg       sync.WaitGroup
+		mutex    sync.Mutex
+		dequeued = make(map[interface{}]int)
+		done     = make(chan struct{})
+	)
+	for i := 0; i < totalConsumers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				elements, err := suite.fifo.DequeueBatchOrWait(10, 50, 20*time.Millisecond)
+				mutex.Lock()
+				for _, value := range elements {
+					dequeued[value]++
+				}
+				// the batch completing the elements
+				finished := len(elements) > 0 && len(dequeued) == total
+				mutex.Unlock()
+
+				if finished {
+					close(done)
+				}
+				select {
+				case <-done:
+					return
+				default:
+				}
+				if err != nil && err != ErrTimeout {
+					suite.FailNow(err.Error())
+				}
+			}
+		}()
+	}
+
+	for p := 0; p < totalProducers; p++ {
+		go func(producer int) {
+			for i := 0; i < perProducer; i++ {
+				suite.fifo.Enqueue(producer*perProducer + i)
+			}
+		}(p)
+	}
+	wg.Wait()
+
+	suite.Equal(total, len(dequeued))
+	for value, times := range dequeued {
+		suite.Equalf(1, times, "%v dequeued %v times", value, times)
+	}
+}
+
+// ***************************************************************************************
+// ** DequeueWithinBudget
+// ***************************************************************************************
+
+// locked queue
+func (suite *FIFOTestSuite) TestDequeueWithinBudgetLockSingleGR() {
+	suite.fifo.Enqueue(1)
+	suite.fifo.Lock()
+
+	result, err := suite.fifo.DequeueWithinBudget(func(value interface{}) int { return 1 }, 10)
+	suite.Nil(result)
+	suite.Equal(ErrLockedQueue, err)
+}
+
+// empty queue
+func (suite *FIFOTestSuite) TestDequeueWithinBudgetEmptyQueue() {
+	result, err := suite.fifo.DequeueWithinBudget(func(value interface{}) int { return 1 }, 10)
+	suite.Nil(result)
+	suite.Equal(ErrEmptyQueue, err)
+}
+
+// elements exceeding the budget are skipped and kept in order
+func (suite *FIFOTestSuite) TestDequeueWithinBudgetSkipsElements() {
+	// costs == values
+	for _, value := range []int{3, 5, 2, 4, 1} {
+		suite.fifo.Enqueue(value)
+	}
+
+	result, err := suite.fifo.DequeueWithinBudget(func(value interface{}) int { return value.(int) }, 6)
+	suite.NoError(err)
+	// 3 fits (3), 5 is skipped (8), 2 fits (5), 4 is skipped (9), 1 fits (6)
+	suite.Equal([]interface{}{3, 2, 1}, result)
+
+	suite.Equal(2, suite.fifo.GetLen())
+	first, _ := suite.fifo.Get(0)
+	second, _ := suite.fifo.Get(1)
+	suite.Equal(5, first)
+	suite.Equal(4, second)
+}
+
+// nothing fits the budget
+func (suite *FIFOTestSuite) TestDequeueWithinBudgetNothingFits() {
+	suite.fifo.Enqueue(10)
+
+	result, err := suite.fifo.DequeueWithinBudget(func(value interface{}) int { return value.(int) }, 5)
+	suite.NoError(err)
+	suite.Len(result, 0)
+	suite.Equal(1, suite.fifo.GetLen())
+}
+
+// exhausted budget keeps the remaining elements
+func (suite *FIFOTestSuite) TestDequeueWithinBudgetExhausted() {
+	for i := 0; i < 10; i++ {
+		suite.fifo.Enqueue(i)
+	}
+
+	result, err := suite.fifo.DequeueWithinBudget(func(value interface{}) int { return 1 }, 3)
+	suite.NoError(err)
+	suite.Equal([]interface{}{0, 1, 2}, result)
+	suite.Equal(7, suite.fifo.GetLen())
+
+	value, _ := suite.fifo.Dequeue()
+	suite.Equal(3, value)
+}
+
+// zero cost elements get dequeued once the budget is exhausted
+func (suite *FIFOTestSuite) TestDequeueWithinBudgetZeroCost() {
+	for _, value := range []int{2, 3, 0, 1, 0} {
+		suite.fifo.Enqueue(value)
+	}
+
+	result, err := suite.fifo.DequeueWithinBudget(func(value interface{}) int { return value.(int) }, 2)
+	suite.NoError(err)
+	suite.Equal([]interface{}{2, 0, 0}, result)
+	suite.Equal(2, suite.fifo.GetLen())
+	first, _ := suite.fifo.Get(0)
+	second, _ := suite.fifo.Get(1)
+	suite.Equal(3, first)
+	suite.Equal(1, second)
+}
+
+// negative costs: nothing gets dequeued
+func (suite *FIFOTestSuite) TestDequeueWithinBudgetNegativeCost() {
+	for _, value := range []int{1, -1, 1} {
+		suite.fifo.Enqueue(value)
+	}
+
+	result, err := suite.fifo.DequeueWithinBudget(func(value interface{}) int { return value.(int) }, 10)
+	suite.Nil(result)
+	suite.Equal(QueueErrorCodeInvalidCost, errorCode(err))
+	suite.Equal(3, suite.fifo.GetLen())
+}
+
+// ***************************************************************************************
+// ** Count / Where
+// ***************************************************************************************
+
+// count and list the elements matching a predicate
+func (suite *FIFOTestSuite) TestCountWhereSingleGR() {
+	for i := 0; i < 10; i++ {
+		suite.fifo.Enqueue(i)
+	}
+	isEven := func(value interface{}) bool { return value.(int)%2 == 0 }
+
+	suite.Equal(5, suite.fifo.Count(isEven))
+	suite.Equal([]interface{}{0, 2, 4, 6, 8}, suite.fifo.Where(isEven))
+	// elements are kept
+	suite.Equal(10, suite.fifo.GetLen())
+}
+
+// no matching elements
+func (suite *FIFOTestSuite) TestCountWhereNoMatches() {
+	suite.fifo.Enqueue(testValue)
+	none := func(value interface{}) bool { return false }
+
+	suite.Equal(0, suite.fifo.Count(none))
+	suite.Len(suite.fifo.Where(none), 0)
+}
+
+// Count / Where while other GRs enqueue
+func (suite *FIFOTestSuite) TestCountWhereMultipleGRs() {
+	var (
+		totalGRs = 50
+		wg       sync.WaitGroup
+	)
+	all := func(value interface{}) bool { return true }
+
+	for i := 0; i < totalGRs; i++ {
+		wg.Add(2)
+		go func(value int) {
+			defer wg.Done()
+			suite.fifo.Enqueue(value)
+		}(i)
+		go func() {
+			defer wg.Done()
+			suite.fifo.Where(all)
+			suite.fifo.Count(all)
+		}()
+	}
+	wg.Wait()
+
+	suite.Equal(totalGRs, suite.fifo.Count(all))
+}
+
+// ***************************************************************************************
+// ** Lock / Unlock / IsLocked
+// ***************************************************************************************
+
+// single lock
+func (suite *FIFOTestSuite) TestLockSingleGR() {
+	suite.fifo.Lock()
+	suite.True(suite.fifo.isLocked == true, "fifo.isLocked has to be true after fifo.Lock()")
+}
+
+// single unlock
+func (suite *FIFOTestSuite) TestUnlockSingleGR() {
+	suite.fifo.Lock()
+	suite.fifo.Unlock()
+	suite.True(suite.fifo.isLocked == false, "fifo.isLocked has to be false after fifo.Unlock()")
+}
+
+// single isLocked
+func (suite *FIFOTestSuite) TestIsLockedSingleGR() {
+	suite.True(suite.fifo.isLocked == suite.fifo.IsLocked(), "fifo.IsLocked() has to be equal to fifo.isLocked")
+
+	suite.fifo.Lock()
+	suite.True(suite.fifo.isLocked == suite.fifo.IsLocked(), "fifo.IsLocked() has to be equal to fifo.isLocked")
+
+	suite.fifo.Unlock()
+	suite.True(suite.fifo.isLocked == suite.fifo.IsLocked(), "fifo.IsLocked() has to be equal to fifo.isLocked")
+}
+
+// waiters registered before Lock keep waiting and get served after Unlock
+func (suite *FIFOTestSuite) TestUnlockWaitersKeepWaiting() {
+	result := make(chan interface{}, 1)
+	go func() {
+		value, _ := suite.fifo.DequeueOrWaitForNextElement()
+		result <- value
+	}()
+	time.Sleep(10 * time.Millisecond)
+
+	suite.fifo.Lock()
+	suite.Equal(ErrLockedQueue, suite.fifo.Enqueue(testValue))
+	suite.fifo.Unlock()
+	suite.NoError(suite.fifo.Enqueue(testValue))
+
+	select {
+	case value := <-result:
+		suite.Equal(testValue, value)
+	case <-time.After(2 * time.Second):
+		suite.FailNow("too much time waiting for the enqueued element")
+	}
+}
+
+// backlog accumulated while locked is handed over to the waiters, in order, on Unlock
+func (suite *FIFOTestSuite) TestUnlockServesWaitersFromBacklog() {
+	var (
+		totalWaiters = 3
+		results      = make([]chan interface{}, totalWaiters)
+	)
+
+	for i := 0; i < totalWaiters; i++ {
+		results[i] = make(chan interface{}, 1)
+		go func(result chan interface{}) {
+			value, _ := suite.fifo.DequeueOrWaitForNextElement()
+			result <- value
+		}(results[i])
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	suite.fifo.Lock()
+	// elements enqueued while locked (bypassing the listeners)
+	suite.fifo.rwmutex.Lock()
+	for i := 0; i < 4; i++ {
+		suite.fifo.ring.pushBack(i)
+	}
+	suite.fifo.rwmutex.Unlock()
+	suite.fifo.Unlock()
+
+	for i := 0; i < totalWaiters; i++ {
+		select {
+		case value := <-results[i]:
+			suite.Equal(i, value)
+		case <-time.After(2 * time.Second):
+			suite.FailNow("too much time waiting for the backlog")
+		}
+	}
+	suite.Equal(1, suite.fifo.GetLen())
+}
+
+// ***************************************************************************************
+// ** TryLock / TryLockFor
+// ***************************************************************************************
+
+// idle queue: it gets locked
+func (suite *FIFOTestSuite) TestTryLock() {
+	suite.fifo.Enqueue(testValue)
+	suite.True(suite.fifo.TryLock())
+	suite.True(suite.fifo.IsLocked())
+}
+
+// waiting consumers / claimed elements: busy queue
+func (suite *FIFOTestSuite) TestTryLockBusyQueue() {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		suite.fifo.DequeueOrWaitForNextElementWithContext(ctx)
+	}()
+	for i := 0; i < 1000 && suite.fifo.Stats().Waiters == 0; i++ {
+		time.Sleep(time.Millisecond)
+	}
+
+	suite.False(suite.fifo.TryLock())
+	suite.False(suite.fifo.IsLocked())
+	cancel()
+	<-done
+
+	suite.fifo.Enqueue(testValue)
+	claim, err := suite.fifo.Claim(0)
+	suite.Require().NoError(err)
+	suite.False(suite.fifo.TryLock())
+	suite.NoError(claim.Release())
+	suite.True(suite.fifo.TryLock())
+}
+
+// the queue gets locked once the operations in progress are done
+func (suite *FIFOTestSuite) TestTryLockFor() {
+	suite.fifo.Enqueue(testValue)
+	claim, err := suite.fifo.Claim(0)
+	suite.Require().NoError(err)
+
+	start := time.Now()
+	suite.False(suite.fifo.TryLockFor(10 * time.Millisecond))
+	suite.True(time.Since(start) >= 10*time.Millisecond)
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		claim.Remove()
+	}()
+	suite.True(suite.fifo.TryLockFor(2 * time.Second))
+	suite.True(suite.fifo.IsLocked())
+}
+
+// ***************************************************************************************
+// ** Swap
+// ***************************************************************************************
+func (suite *FIFOTestSuite) TestSwapEmptyQueue() {
+	const (
+		a = 4
+		b = 4
+	)
+
+	err := suite.fifo.Swap(a, b)
+
+	suite.EqualError(err, "Empty queue")
+}
+
+func (suite *FIFOTestSuite) TestSwapIndexOutOfBounds() {
+	const (
+		size = 2
+		a    = 4
+		b    = 3
+	)
+
+	for i := 0; i < size; i++ {
+		suite.fifo.Enqueue(i)
+	}
+
+	err := suite.fifo.Swap(a, b)
+
+	suite.EqualError(err, "Index out of bounds")
+}
+
+func (suite *FIFOTestSuite) TestSwapSameIndex() {
 	const (
 		size = 10
+		a    = 4
+		b    = 4
+	)
+
+	for i := 0; i < size; i++ {
+		suite.fifo.Enqueue(i)
+	}
+
+	err := suite.fifo.Swap(a, b)
+
+	suite.EqualError(err, "Indexes are the same number")
+}
+
+func (suite *FIFOTestSuite) TestSwapElements() {
+
+	const (
+		size = 10
+		a    = 3
+		b    = 4
+	)
+
+	for i := 0; i < size; i++ {
+		suite.fifo.Enqueue(i)
+	}
+
+	oldValueFromA, _ := suite.fifo.Get(a)
+	oldValueFromB, _ := suite.fifo.Get(b)
+
+	suite.fifo.Swap(a, b)
+
+	newValueFromA, _ := suite.fifo.Get(a)
+	newValueFromB, _ := suite.fifo.Get(b)
+
+	suite.Equal(oldValueFromA, newValueFromB)
+	suite.Equal(oldValueFromB, newValueFromA)
+}
+
+// ***************************************************************************************
+// ** Move elements inside an empty queue
+// ***************************************************************************************
+func (suite *FIFOTestSuite) TestMoveEmptyQueue() {
+	const (
 		top  = 10
+		back = 1
 	)
 
-	for i := 0; i < size; i++ {
-		suite.fifo.Enqueue(i + 1)
+	suite.EqualError(suite.fifo.MoveFrontWithId(top), "Empty queue")
+	suite.EqualError(suite.fifo.MoveBackWithId(back), "Empty queue")
+}
+
+// ***************************************************************************************
+// ** Move elements to the front
+// ***************************************************************************************
+func (suite *FIFOTestSuite) TestMoveFront() {
+	const (
+		size = 10
+		top  = 4
+	)
+
+	for i := 0; i < size; i++ {
+		suite.fifo.Enqueue(i + 1)
+	}
+
+	result := []interface{}{5, 1, 2, 3, 4, 6, 7, 8, 9, 10}
+
+	suite.NoError(suite.fifo.MoveFrontWithId(top))
+	suite.Equal(result, suite.fifo.ring.elements())
+}
+
+func (suite *FIFOTestSuite) TestMoveFrontOutOfBounds() {
+	const (
+		size = 10
+		top  = 10
+	)
+
+	for i := 0; i < size; i++ {
+		suite.fifo.Enqueue(i + 1)
+	}
+
+	suite.EqualError(suite.fifo.MoveFrontWithId(top), "Index is out of bounds")
+}
+
+func (suite *FIFOTestSuite) TestMoveFrontAlreadyInFront() {
+	const (
+		size = 10
+		top  = 0
+	)
+
+	for i := 0; i < size; i++ {
+		suite.fifo.Enqueue(i + 1)
+	}
+
+	suite.EqualError(suite.fifo.MoveFrontWithId(top), "Element already is in first position")
+}
+
+// ***************************************************************************************
+// ** Move elements to the back
+// ***************************************************************************************
+func (suite *FIFOTestSuite) TestMoveBack() {
+	const (
+		size = 10
+		back = 4
+	)
+
+	for i := 0; i < size; i++ {
+		suite.fifo.Enqueue(i + 1)
+	}
+
+	result := []interface{}{1, 2, 3, 4, 6, 7, 8, 9, 10, 5}
+
+	suite.NoError(suite.fifo.MoveBackWithId(back))
+	suite.Equal(result, suite.fifo.ring.elements())
+}
+
+func (suite *FIFOTestSuite) TestMoveBackOutOfBounds() {
+	const (
+		size = 10
+		back = 10
+	)
+
+	for i := 0; i < size; i++ {
+		suite.fifo.Enqueue(i + 1)
+	}
+
+	suite.EqualError(suite.fifo.MoveBackWithId(back), "Index is out of bounds")
+	suite.Error(suite.fifo.MoveBackWithId(back))
+}
+
+func (suite *FIFOTestSuite) TestMoveBackAlreadyInBack() {
+	const (
+		size = 10
+		back = 9
+	)
+
+	for i := 0; i < size; i++ {
+		suite.fifo.Enqueue(i + 1)
+	}
+
+	suite.EqualError(suite.fifo.MoveBackWithId(back), "Element already is in last position")
+}
+
+// ***************************************************************************************
+// ** Get all elements
+// ***************************************************************************************
+func (suite *FIFOTestSuite) TestGetAll() {
+	const (
+		size = 10
+	)
+	var slice []interface{}
+	for i := 0; i < size; i++ {
+		suite.fifo.Enqueue(i)
+		slice = append(slice, i)
+	}
+	result, err := suite.fifo.GetAll(nil, nil)
+	suite.NoError(err)
+	suite.Equal(slice, result)
+}
+
+func (suite *FIFOTestSuite) TestGetAllFiltered() {
+	const (
+		size = 10
+
+	)
+	var (
+		limit = 2
+		offset = 4
+	)
+	var slice []interface{}
+	for i := 0; i < size; i++ {
+		suite.fifo.Enqueue(i)
+		slice = append(slice, i)
+	}
+
+	expected := []interface{}{5, 6}
+
+	result, err := suite.fifo.GetAll(&limit, &offset)
+	suite.NoError(err)
+	suite.Equal(slice, suite.fifo.ring.elements())
+	suite.Equal(expected, result)
+}
+
+func (suite *FIFOTestSuite) TestGetAllFilteredOutOfBounds() {
+	const (
+		size = 10
+
+	)
+	var (
+		limit = 2
+		offset = 10
+	)
+	var slice []interface{}
+	for i := 0; i < size; i++ {
+		suite.fifo.Enqueue(i)
+		slice = append(slice, i)
+	}
+
+	_, err := suite.fifo.GetAll(&limit, &offset)
+	suite.EqualError(err, "Offset index out of bounds")
+	suite.Equal(slice, suite.fifo.ring.elements())
+}
+
+func (suite *FIFOTestSuite) TestGetAllFilteredWrongRange() {
+	const (
+		size = 10
+
+	)
+	var (
+		limit = -5
+		offset = -3
+	)
+	var slice []interface{}
+	for i := 0; i < size; i++ {
+		suite.fifo.Enqueue(i)
+		slice = append(slice, i)
+	}
+
+	_, err := suite.fifo.GetAll(&limit, &offset)
+	suite.EqualError(err, "Offset index out of bounds")
+	suite.Equal(slice, suite.fifo.ring.elements())
+}
+
+// ***************************************************************************************
+// ** Rotate / Truncate
+// ***************************************************************************************
+
+// first n elements moved to the back
+func (suite *FIFOTestSuite) TestRotate() {
+	for i := 0; i < 5; i++ {
+		suite.fifo.Enqueue(i)
+	}
+
+	suite.NoError(suite.fifo.Rotate(2))
+	suite.Equal([]interface{}{2, 3, 4, 0, 1}, suite.fifo.Where(func(interface{}) bool { return true }))
+
+	// n greater than the length
+	suite.NoError(suite.fifo.Rotate(6))
+	suite.Equal([]interface{}{3, 4, 0, 1, 2}, suite.fifo.Where(func(interface{}) bool { return true }))
+
+	// negative n: last elements moved to the front
+	suite.NoError(suite.fifo.Rotate(-3))
+	suite.Equal([]interface{}{0, 1, 2, 3, 4}, suite.fifo.Where(func(interface{}) bool { return true }))
+}
+
+// rotate an empty queue
+func (suite *FIFOTestSuite) TestRotateEmptyQueue() {
+	suite.NoError(suite.fifo.Rotate(3))
+	suite.Equal(0, suite.fifo.GetLen())
+}
+
+// rotate a locked queue
+func (suite *FIFOTestSuite) TestRotateLockedQueue() {
+	suite.fifo.Enqueue(testValue)
+	suite.fifo.Lock()
+
+	suite.Equal(ErrLockedQueue, suite.fifo.Rotate(1))
+}
+
+// only the first n elements remain
+func (suite *FIFOTestSuite) TestTruncate() {
+	for i := 0; i < 5; i++ {
+		suite.fifo.Enqueue(i)
+	}
+
+	suite.NoError(suite.fifo.Truncate(10))
+	suite.Equal(5, suite.fifo.GetLen())
+
+	suite.NoError(suite.fifo.Truncate(2))
+	suite.Equal([]interface{}{0, 1}, suite.fifo.Where(func(interface{}) bool { return true }))
+
+	suite.NoError(suite.fifo.Truncate(0))
+	suite.Equal(0, suite.fifo.GetLen())
+}
+
+// negative n
+func (suite *FIFOTestSuite) TestTruncateNegative() {
+	suite.fifo.Enqueue(testValue)
+
+	err := suite.fifo.Truncate(-1)
+	suite.Error(err)
+	suite.Equal(QueueErrorCodeIndexOutOfBounds, err.(*QueueError).Code())
+	suite.Equal(1, suite.fifo.GetLen())
+}
+
+// truncate a locked queue
+func (suite *FIFOTestSuite) TestTruncateLockedQueue() {
+	suite.fifo.Enqueue(testValue)
+	suite.fifo.Lock()
+
+	suite.Equal(ErrLockedQueue, suite.fifo.Truncate(0))
+}
+
+// ***************************************************************************************
+// ** SwapQueues
+// ***************************************************************************************
+
+// elements get exchanged
+func (suite *FIFOTestSuite) TestSwapQueues() {
+	other := NewFIFO()
+	for i := 0; i < 5; i++ {
+		suite.fifo.Enqueue(i)
 	}
+	other.Enqueue(testValue)
 
-	suite.EqualError(suite.fifo.MoveFrontWithId(top), "Index is out of bounds")
+	suite.NoError(SwapQueues(suite.fifo, other))
+	suite.Equal(1, suite.fifo.GetLen())
+	suite.Equal(5, other.GetLen())
+
+	value, err := suite.fifo.Dequeue()
+	suite.NoError(err)
+	suite.Equal(testValue, value)
+	for i := 0; i < 5; i++ {
+		value, err := other.Dequeue()
+		suite.NoError(err)
+		suite.Equal(i, value)
+	}
 }
 
-func (suite *FIFOTestSuite) TestMoveFrontAlreadyInFront() {
-	const (
-		size = 10
-		top  = 0
+// swapping a queue with itself is a no-op
+func (suite *FIFOTestSuite) TestSwapQueuesSameQueue() {
+	suite.fifo.Enqueue(testValue)
+
+	suite.NoError(SwapQueues(suite.fifo, suite.fifo))
+	suite.Equal(1, suite.fifo.GetLen())
+}
+
+// locked queue
+func (suite *FIFOTestSuite) TestSwapQueuesLockedQueue() {
+	other := NewFIFO()
+	suite.fifo.Enqueue(testValue)
+	other.Lock()
+
+	suite.Equal(ErrLockedQueue, SwapQueues(suite.fifo, other))
+	suite.Equal(1, suite.fifo.GetLen())
+	suite.Equal(0, other.GetLen())
+}
+
+// waiting consumers get the elements their queue receives
+func (suite *FIFOTestSuite) TestSwapQueuesWaitingConsumer() {
+	other := NewFIFO()
+	other.Enqueue(testValue)
+
+	result := make(chan interface{}, 1)
+	go func() {
+		value, _ := suite.fifo.DequeueOrWaitForNextElement()
+		result <- value
+	}()
+	time.Sleep(10 * time.Millisecond)
+
+	suite.NoError(SwapQueues(suite.fifo, other))
+
+	select {
+	case value := <-result:
+		suite.Equal(testValue, value)
+	case <-time.After(2 * time.Second):
+		suite.FailNow("too much time waiting for the swapped element")
+	}
+	suite.Equal(0, suite.fifo.GetLen())
+}
+
+// concurrent swaps in both directions don't deadlock
+func (suite *FIFOTestSuite) TestSwapQueuesConcurrent() {
+	var (
+		other = NewFIFO()
+		wg    sync.WaitGroup
 	)
+	suite.fifo.Enqueue(testValue)
 
-	for i := 0; i < size; i++ {
-		suite.fifo.Enqueue(i + 1)
+	for i := 0; i < 100; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			SwapQueues(suite.fifo, other)
+		}()
+		go func() {
+			defer wg.Done()
+			SwapQueues(other, suite.fifo)
+		}()
 	}
+	wg.Wait()
 
-	suite.EqualError(suite.fifo.MoveFrontWithId(top), "Element already is in first position")
+	suite.Equal(1, suite.fifo.GetLen()+other.GetLen())
 }
 
 // ***************************************************************************************
-// ** Move elements to the back
+// ** Snapshot consistency (GetAll / Where)
 // ***************************************************************************************
-func (suite *FIFOTestSuite) TestMoveBack() {
-	const (
-		size = 10
-		back = 4
-	)
 
-	for i := 0; i < size; i++ {
-		suite.fifo.Enqueue(i + 1)
+// the snapshot's order is the dequeue order
+func (suite *FIFOTestSuite) TestSnapshotOrderEqualsDequeueOrder() {
+	for i := 0; i < 10; i++ {
+		suite.fifo.Enqueue(i)
 	}
+	suite.fifo.Dequeue()
+	suite.fifo.MoveFrontWithId(5)
+	suite.fifo.Enqueue(10)
 
-	result := []interface{}{1, 2, 3, 4, 6, 7, 8, 9, 10, 5}
+	all, err := suite.fifo.GetAll(nil, nil)
+	suite.NoError(err)
+	where := suite.fifo.Where(func(interface{}) bool { return true })
+	suite.Equal(all, where)
 
-	suite.NoError(suite.fifo.MoveBackWithId(back))
-	suite.Equal(result, suite.fifo.slice)
+	for _, expected := range all.([]interface{}) {
+		value, err := suite.fifo.Dequeue()
+		suite.NoError(err)
+		suite.Equal(expected, value)
+	}
+	suite.Equal(0, suite.fifo.GetLen())
 }
 
-func (suite *FIFOTestSuite) TestMoveBackOutOfBounds() {
-	const (
-		size = 10
-		back = 10
+// concurrent enqueues / dequeues never show up partially: every snapshot is a run of consecutive elements
+func (suite *FIFOTestSuite) TestSnapshotConsistencyMultipleGRs() {
+	var (
+		total = 2000
+		wg    sync.WaitGroup
+		done  = make(chan struct{})
 	)
 
-	for i := 0; i < size; i++ {
-		suite.fifo.Enqueue(i + 1)
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < total; i++ {
+			suite.fifo.Enqueue(i)
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for dequeued := 0; dequeued < total; {
+			if _, err := suite.fifo.Dequeue(); err == nil {
+				dequeued++
+			}
+		}
+	}()
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	checkSnapshot := func(snapshot []interface{}) bool {
+		for i := 1; i < len(snapshot); i++ {
+			if snapshot[i].(int) != snapshot[i-1].(int)+1 {
+				return suite.Failf("inconsistent snapshot", "%v followed by %v", snapshot[i-1], snapshot[i])
+			}
+		}
+		return true
 	}
 
-	suite.EqualError(suite.fifo.MoveBackWithId(back), "Index is out of bounds")
-	suite.Error(suite.fifo.MoveBackWithId(back))
+	for {
+		select {
+		case <-done:
+			return
+		default:
+		}
+
+		all, err := suite.fifo.GetAll(nil, nil)
+		suite.NoError(err)
+		if !checkSnapshot(all.([]interface{})) || !checkSnapshot(suite.fifo.Where(func(interface{}) bool { return true })) {
+			return
+		}
+	}
 }
 
-func (suite *FIFOTestSuite) TestMoveBackAlreadyInBack() {
-	const (
-		size = 10
-		back = 9
+// ***************************************************************************************
+// ** ForEach
+// ***************************************************************************************
+
+// every element gets visited, in order, along with its position
+func (suite *FIFOTestSuite) TestForEach() {
+	for i := 0; i < 10; i++ {
+		suite.fifo.Enqueue(i * 10)
+	}
+
+	visited := 0
+	suite.fifo.ForEach(func(index int, value interface{}) bool {
+		suite.Equal(visited, index)
+		suite.Equal(index*10, value)
+		visited++
+		return true
+	})
+	suite.Equal(10, visited)
+}
+
+// fn returning false stops the iteration
+func (suite *FIFOTestSuite) TestForEachEarlyTermination() {
+	for i := 0; i < 10; i++ {
+		suite.fifo.Enqueue(i)
+	}
+
+	visited := make([]interface{}, 0)
+	suite.fifo.ForEach(func(index int, value interface{}) bool {
+		visited = append(visited, value)
+		return index < 2
+	})
+	suite.Equal([]interface{}{0, 1, 2}, visited)
+}
+
+// claimed elements get skipped, the positions are the ones Get takes
+func (suite *FIFOTestSuite) TestForEachClaimedElements() {
+	for i := 0; i < 3; i++ {
+		suite.fifo.Enqueue(i)
+	}
+	_, err := suite.fifo.Claim(1)
+	suite.NoError(err)
+
+	var (
+		visited = make([]interface{}, 0)
+		indexes = make([]int, 0)
 	)
+	suite.fifo.ForEach(func(index int, value interface{}) bool {
+		visited = append(visited, value)
+		indexes = append(indexes, index)
+		got, err := suite.fifo.Get(index)
+		suite.NoError(err)
+		suite.Equal(value, got)
+		return true
+	})
+	suite.Equal([]interface{}{0, 2}, visited)
+	suite.Equal([]int{0, 2}, indexes)
+}
 
-	for i := 0; i < size; i++ {
-		suite.fifo.Enqueue(i + 1)
+// fn could modify the queue, the iteration keeps going over the snapshot
+func (suite *FIFOTestSuite) TestForEachModifyingTheQueue() {
+	for i := 0; i < 3; i++ {
+		suite.fifo.Enqueue(i)
 	}
 
-	suite.EqualError(suite.fifo.MoveBackWithId(back), "Element already is in last position")
+	visited := make([]interface{}, 0)
+	suite.fifo.ForEach(func(index int, value interface{}) bool {
+		visited = append(visited, value)
+		suite.fifo.Dequeue()
+		suite.fifo.Enqueue(value.(int) + 100)
+		return true
+	})
+	suite.Equal([]interface{}{0, 1, 2}, visited)
+	suite.Equal(3, suite.fifo.GetLen())
 }
 
 // ***************************************************************************************
-// ** Get all elements
+// ** IndexOf && Contains
 // ***************************************************************************************
-func (suite *FIFOTestSuite) TestGetAll() {
-	const (
-		size = 10
-	)
-	var slice []interface{}
-	for i := 0; i < size; i++ {
+
+// first matching position, -1 if there is none
+func (suite *FIFOTestSuite) TestIndexOf() {
+	for _, value := range []int{5, 6, 7, 6} {
+		suite.fifo.Enqueue(value)
+	}
+
+	suite.Equal(1, suite.fifo.IndexOf(6, nil))
+	suite.Equal(-1, suite.fifo.IndexOf(8, nil))
+	suite.True(suite.fifo.Contains(7, nil))
+	suite.False(suite.fifo.Contains(8, nil))
+
+	// comparator
+	sameParity := func(a, b interface{}) bool { return a.(int)%2 == b.(int)%2 }
+	suite.Equal(0, suite.fifo.IndexOf(1, sameParity))
+	suite.Equal(1, suite.fifo.IndexOf(0, sameParity))
+}
+
+// claimed elements get skipped
+func (suite *FIFOTestSuite) TestIndexOfClaimedElements() {
+	suite.fifo.Enqueue(1)
+	suite.fifo.Enqueue(1)
+	_, err := suite.fifo.Claim(0)
+	suite.NoError(err)
+
+	suite.Equal(1, suite.fifo.IndexOf(1, nil))
+	value, err := suite.fifo.Get(suite.fifo.IndexOf(1, nil))
+	suite.NoError(err)
+	suite.Equal(1, value)
+}
+
+// ***************************************************************************************
+// ** RemoveWhere
+// ***************************************************************************************
+
+// matching elements get removed, the rest keep their order
+func (suite *FIFOTestSuite) TestRemoveWhere() {
+	for i := 0; i < 10; i++ {
 		suite.fifo.Enqueue(i)
-		slice = append(slice, i)
 	}
-	result, err := suite.fifo.GetAll(nil, nil)
+
+	removed := suite.fifo.RemoveWhere(func(value interface{}) bool { return value.(int)%2 == 0 })
+	suite.Equal(5, removed)
+	suite.Equal([]interface{}{1, 3, 5, 7, 9}, suite.fifo.Drain())
+
+	suite.Equal(0, suite.fifo.RemoveWhere(func(value interface{}) bool { return true }), "nothing to remove from an empty queue")
+}
+
+// claimed elements stay (same position)
+func (suite *FIFOTestSuite) TestRemoveWhereClaimedElements() {
+	for i := 0; i < 4; i++ {
+		suite.fifo.Enqueue(i)
+	}
+	claim, err := suite.fifo.Claim(2)
 	suite.NoError(err)
-	suite.Equal(slice, result)
+
+	suite.Equal(3, suite.fifo.RemoveWhere(func(value interface{}) bool { return true }))
+	suite.Equal(1, suite.fifo.GetLen())
+	suite.NoError(claim.Remove())
+	suite.Equal(0, suite.fifo.GetLen())
 }
 
-func (suite *FIFOTestSuite) TestGetAllFiltered() {
-	const (
-		size = 10
+// locked queue
+func (suite *FIFOTestSuite) TestRemoveWhereLockedQueue() {
+	suite.fifo.Enqueue(1)
+	suite.fifo.Lock()
 
-	)
+	suite.Equal(0, suite.fifo.RemoveWhere(func(value interface{}) bool { return true }))
+	suite.Equal(1, suite.fifo.GetLen())
+}
+
+// only the matching elements (and the claimed ones) are kept
+func (suite *FIFOTestSuite) TestFilter() {
+	for i := 0; i < 6; i++ {
+		suite.fifo.EnqueueBatch([]interface{}{"tenant-a", "tenant-b"})
+	}
+	_, err := suite.fifo.Claim(0)
+	suite.NoError(err)
+
+	suite.fifo.Filter(func(value interface{}) bool { return value != "tenant-a" })
+	suite.Equal(7, suite.fifo.GetLen())
+	suite.Equal(0, suite.fifo.Count(func(value interface{}) bool { return value == "tenant-a" }))
+	suite.Equal([]interface{}{"tenant-b", "tenant-b", "tenant-b", "tenant-b", "tenant-b", "tenant-b"}, suite.fifo.Drain())
+}
+
+// concurrent producers: every element gets either removed or left at the queue, exactly once
+func (suite *FIFOTestSuite) TestRemoveWhereMultipleGRs() {
 	var (
-		limit = 2
-		offset = 4
+		total   = 2000
+		wg      sync.WaitGroup
+		removed = 0
 	)
-	var slice []interface{}
-	for i := 0; i < size; i++ {
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < total; i++ {
+			suite.fifo.Enqueue(i)
+		}
+	}()
+
+	for i := 0; i < 10; i++ {
+		removed += suite.fifo.RemoveWhere(func(value interface{}) bool { return value.(int)%2 == 0 })
+		runtime.Gosched()
+	}
+	wg.Wait()
+	removed += suite.fifo.RemoveWhere(func(value interface{}) bool { return value.(int)%2 == 0 })
+
+	suite.Equal(total/2, removed)
+	suite.Equal(total/2, suite.fifo.GetLen())
+}
+
+// ***************************************************************************************
+// ** Apply
+// ***************************************************************************************
+
+// every element gets replaced, in place
+func (suite *FIFOTestSuite) TestApply() {
+	for i := 0; i < 3; i++ {
+		suite.fifo.Enqueue(map[string]interface{}{"id": i})
+	}
+
+	// schema migration: "id" -> "ID"
+	suite.NoError(suite.fifo.Apply(func(value interface{}) interface{} {
+		return map[string]interface{}{"ID": value.(map[string]interface{})["id"]}
+	}))
+	for i := 0; i < 3; i++ {
+		value, err := suite.fifo.Dequeue()
+		suite.NoError(err)
+		suite.Equal(map[string]interface{}{"ID": i}, value)
+	}
+}
+
+// claimed elements are left as they are
+func (suite *FIFOTestSuite) TestApplyClaimed() {
+	for i := 0; i < 3; i++ {
 		suite.fifo.Enqueue(i)
-		slice = append(slice, i)
 	}
+	claim, err := suite.fifo.Claim(1)
+	suite.Require().NoError(err)
 
-	expected := []interface{}{5, 6}
+	suite.NoError(suite.fifo.Apply(func(value interface{}) interface{} { return value.(int) * 10 }))
+	suite.NoError(claim.Release())
+	suite.Equal([]interface{}{0, 1, 20}, suite.fifo.Drain())
+}
 
-	result, err := suite.fifo.GetAll(&limit, &offset)
+// locked queue: nothing gets replaced
+func (suite *FIFOTestSuite) TestApplyLockedQueue() {
+	suite.fifo.Enqueue(1)
+	suite.fifo.Lock()
+
+	suite.Equal(ErrLockedQueue, suite.fifo.Apply(func(value interface{}) interface{} { return nil }))
+	suite.fifo.Unlock()
+	value, _ := suite.fifo.Dequeue()
+	suite.Equal(1, value)
+}
+
+// ***************************************************************************************
+// ** Clear
+// ***************************************************************************************
+
+// every element gets removed and the buffer released
+func (suite *FIFOTestSuite) TestClear() {
+	for i := 0; i < 100; i++ {
+		suite.fifo.Enqueue(i)
+	}
+	claim, err := suite.fifo.Claim(0)
 	suite.NoError(err)
-	suite.Equal(slice, suite.fifo.slice)
-	suite.Equal(expected, result)
+
+	suite.NoError(suite.fifo.Clear())
+	suite.Equal(0, suite.fifo.GetLen())
+	suite.Equal(0, suite.fifo.GetCap())
+	suite.Equal(0, suite.fifo.GetClaims())
+	suite.Equal(QueueErrorCodeInvalidClaim, errorCode(claim.Release()))
+
+	// the queue keeps working
+	suite.NoError(suite.fifo.Enqueue(testValue))
+	value, err := suite.fifo.Dequeue()
+	suite.NoError(err)
+	suite.Equal(testValue, value)
 }
 
-func (suite *FIFOTestSuite) TestGetAllFilteredOutOfBounds() {
-	const (
-		size = 10
+// locked queue
+func (suite *FIFOTestSuite) TestClearLockedQueue() {
+	suite.fifo.Enqueue(1)
+	suite.fifo.Lock()
 
-	)
-	var (
-		limit = 2
-		offset = 10
-	)
-	var slice []interface{}
-	for i := 0; i < size; i++ {
+	suite.Equal(ErrLockedQueue, suite.fifo.Clear())
+	suite.Equal(1, suite.fifo.GetLen())
+}
+
+// ***************************************************************************************
+// ** Drain
+// ***************************************************************************************
+
+// every element gets returned, in order
+func (suite *FIFOTestSuite) TestDrain() {
+	for i := 0; i < 100; i++ {
 		suite.fifo.Enqueue(i)
-		slice = append(slice, i)
 	}
 
-	_, err := suite.fifo.GetAll(&limit, &offset)
-	suite.EqualError(err, "Offset index out of bounds")
-	suite.Equal(slice, suite.fifo.slice)
+	elements := suite.fifo.Drain()
+	suite.Len(elements, 100)
+	for i, value := range elements {
+		suite.Equal(i, value)
+	}
+	suite.Equal(0, suite.fifo.GetLen())
+
+	suite.Equal([]interface{}{}, suite.fifo.Drain(), "empty slice expected for an empty queue")
 }
 
-func (suite *FIFOTestSuite) TestGetAllFilteredWrongRange() {
-	const (
-		size = 10
+// claimed elements stay at the queue
+func (suite *FIFOTestSuite) TestDrainClaimedElements() {
+	for i := 0; i < 5; i++ {
+		suite.fifo.Enqueue(i)
+	}
+	claim, err := suite.fifo.Claim(2)
+	suite.NoError(err)
 
-	)
+	suite.Equal([]interface{}{0, 1, 3, 4}, suite.fifo.Drain())
+	suite.Equal(1, suite.fifo.GetLen())
+
+	suite.NoError(claim.Release())
+	value, err := suite.fifo.Dequeue()
+	suite.NoError(err)
+	suite.Equal(2, value)
+}
+
+// locked queues get drained too
+func (suite *FIFOTestSuite) TestDrainLockedQueue() {
+	suite.fifo.Enqueue(1)
+	suite.fifo.Lock()
+
+	suite.Equal([]interface{}{1}, suite.fifo.Drain())
+	suite.Equal(0, suite.fifo.GetLen())
+}
+
+// concurrent producers: every element gets either drained or left at the queue, exactly once
+func (suite *FIFOTestSuite) TestDrainMultipleGRs() {
 	var (
-		limit = -5
-		offset = -3
+		producers = 4
+		perGR     = 1000
+		wg        sync.WaitGroup
+		drained   []interface{}
 	)
-	var slice []interface{}
-	for i := 0; i < size; i++ {
-		suite.fifo.Enqueue(i)
-		slice = append(slice, i)
+
+	for p := 0; p < producers; p++ {
+		wg.Add(1)
+		go func(producer int) {
+			defer wg.Done()
+			for i := 0; i < perGR; i++ {
+				suite.fifo.Enqueue(producer*perGR + i)
+				if i%100 == 0 {
+					runtime.Gosched()
+				}
+			}
+		}(p)
 	}
 
-	_, err := suite.fifo.GetAll(&limit, &offset)
-	suite.EqualError(err, "Offset index out of bounds")
-	suite.Equal(slice, suite.fifo.slice)
+	for i := 0; i < 10; i++ {
+		drained = append(drained, suite.fifo.Drain()...)
+		runtime.Gosched()
+	}
+	wg.Wait()
+	drained = append(drained, suite.fifo.Drain()...)
+
+	seen := make(map[interface{}]bool)
+	for _, value := range drained {
+		suite.False(seen[value], "element drained twice: %v", value)
+		seen[value] = true
+	}
+	suite.Len(seen, producers*perGR)
 }
 
 // ***************************************************************************************
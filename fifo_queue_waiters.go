@@ -0,0 +1,107 @@
+package goconcurrentqueue
+
+import (
+	"context"
+	"runtime/debug"
+	"time"
+)
+
+// WaiterInfo describes a consumer blocked on a queue waiting for the next element(s), see FIFO.Waiters
+type WaiterInfo struct {
+	// caller-supplied label (WithWaiterLabel), "" if there is none
+	Label string
+	// the waiting operation: "DequeueOrWaitForNextElement" or "DequeueBatchOrWait"
+	Operation string
+	// when it started waiting
+	Since time.Time
+	// the waiter's stack trace, only if captured (SetWaiterTracking)
+	Stack string
+}
+
+// waiterLabelKey is the context key of the waiters' labels
+type waiterLabelKey struct{}
+
+// WithWaiterLabel returns a copy of ctx carrying the given label: a consumer waiting with it (i.e.
+// DequeueOrWaitForNextElementWithContext) shows up under that label at Waiters, so a stuck service could be diagnosed
+// by listing who is blocked on which queue.
+func WithWaiterLabel(ctx context.Context, label string) context.Context {
+	return context.WithValue(ctx, waiterLabelKey{}, label)
+}
+
+// fifoWaiter is a waiter tracked by a FIFO
+type fifoWaiter struct {
+	id   uint64
+	info WaiterInfo
+}
+
+// SetWaiterTracking enables (or disables) the waiters tracking: the consumers blocked waiting for the next element(s)
+// get recorded, see Waiters. captureStacks records their stack traces as well (expensive: it is captured on every
+// wait). Disabled by default, it costs an extra lock acquisition per wait.
+func (st *FIFO) SetWaiterTracking(enabled bool, captureStacks bool) {
+	st.rwmutex.Lock()
+	defer st.rwmutex.Unlock()
+
+	st.trackWaiters = enabled
+	st.captureWaiterStacks = enabled && captureStacks
+	if !enabled {
+		st.waiters = nil
+	}
+}
+
+// Waiters returns the consumers waiting for the next element(s) at this moment, the longest waiting first. Returns nil
+// if the waiters tracking is disabled (see SetWaiterTracking).
+func (st *FIFO) Waiters() []WaiterInfo {
+	st.rwmutex.RLock()
+	defer st.rwmutex.RUnlock()
+
+	if !st.trackWaiters {
+		return nil
+	}
+
+	waiters := make([]WaiterInfo, len(st.waiters))
+	for i, waiter := range st.waiters {
+		waiters[i] = waiter.info
+	}
+
+	return waiters
+}
+
+// addWaiter records a waiter (if the tracking is enabled) and returns its id, 0 if it isn't tracked. st.rwmutex must
+// be locked by the caller.
+func (st *FIFO) addWaiter(ctx context.Context, operation string) uint64 {
+	if !st.trackWaiters {
+		return 0
+	}
+
+	label, _ := ctx.Value(waiterLabelKey{}).(string)
+	waiter := fifoWaiter{
+		info: WaiterInfo{
+			Label:     label,
+			Operation: operation,
+			Since:     time.Now(),
+		},
+	}
+	if st.captureWaiterStacks {
+		waiter.info.Stack = string(debug.Stack())
+	}
+
+	st.lastWaiterID++
+	waiter.id = st.lastWaiterID
+	st.waiters = append(st.waiters, waiter)
+
+	return waiter.id
+}
+
+// removeWaiter removes the waiter with the given id (0 means not tracked). st.rwmutex must be locked by the caller.
+func (st *FIFO) removeWaiter(id uint64) {
+	if id == 0 {
+		return
+	}
+
+	for i, waiter := range st.waiters {
+		if waiter.id == id {
+			st.waiters = append(st.waiters[:i], st.waiters[i+1:]...)
+			return
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package goconcurrentqueue
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type FIFOWaitersTestSuite struct {
+	suite.Suite
+	fifo *FIFO
+}
+
+func (suite *FIFOWaitersTestSuite) SetupTest() {
+	suite.fifo = NewFIFO()
+}
+
+// waitForWaiters waits until the queue reports the given waiters
+func (suite *FIFOWaitersTestSuite) waitForWaiters(waiters int) {
+	for i := 0; i < 1000 && len(suite.fifo.Waiters()) != waiters; i++ {
+		time.Sleep(time.Millisecond)
+	}
+	suite.Require().Equal(waiters, len(suite.fifo.Waiters()))
+}
+
+// disabled by default
+func (suite *FIFOWaitersTestSuite) TestDisabled() {
+	suite.Nil(suite.fifo.Waiters())
+}
+
+// labeled waiters, oldest first, removed once they get an element
+func (suite *FIFOWaitersTestSuite) TestWaiters() {
+	suite.fifo.SetWaiterTracking(true, false)
+	suite.Equal(0, len(suite.fifo.Waiters()))
+
+	var wg sync.WaitGroup
+	for i, label := range []string{"worker-1", "worker-2"} {
+		wg.Add(1)
+		go func(label string) {
+			defer wg.Done()
+			suite.fifo.DequeueOrWaitForNextElementWithContext(WithWaiterLabel(context.Background(), label))
+		}(label)
+		suite.waitForWaiters(i + 1)
+	}
+
+	waiters := suite.fifo.Waiters()
+	suite.Equal("worker-1", waiters[0].Label)
+	suite.Equal("worker-2", waiters[1].Label)
+	suite.Equal("DequeueOrWaitForNextElement", waiters[0].Operation)
+	suite.False(waiters[0].Since.After(waiters[1].Since))
+	suite.Equal("", waiters[0].Stack)
+
+	suite.fifo.Enqueue(1)
+	suite.waitForWaiters(1)
+	suite.Equal("worker-2", suite.fifo.Waiters()[0].Label)
+
+	suite.fifo.Enqueue(2)
+	wg.Wait()
+	suite.Equal(0, len(suite.fifo.Waiters()))
+}
+
+// unlabeled waiters, stack traces, context done
+func (suite *FIFOWaitersTestSuite) TestStacks() {
+	suite.fifo.SetWaiterTracking(true, true)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		suite.fifo.DequeueOrWaitForNextElementWithContext(ctx)
+	}()
+	suite.waitForWaiters(1)
+
+	waiter := suite.fifo.Waiters()[0]
+	suite.Equal("", waiter.Label)
+	suite.True(strings.Contains(waiter.Stack, "DequeueOrWaitForNextElementWithContext"), waiter.Stack)
+
+	cancel()
+	<-done
+	suite.Equal(0, len(suite.fifo.Waiters()))
+}
+
+// batch waiters
+func (suite *FIFOWaitersTestSuite) TestBatchWaiters() {
+	suite.fifo.SetWaiterTracking(true, false)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		suite.fifo.DequeueBatchOrWaitWithContext(WithWaiterLabel(context.Background(), "batcher"), 2, 2)
+	}()
+	suite.waitForWaiters(1)
+	suite.Equal(WaiterInfo{Label: "batcher", Operation: "DequeueBatchOrWait", Since: suite.fifo.Waiters()[0].Since}, suite.fifo.Waiters()[0])
+
+	suite.fifo.EnqueueBatch([]interface{}{1, 2})
+	<-done
+	suite.Equal(0, len(suite.fifo.Waiters()))
+
+	// disabling it forgets the waiters
+	suite.fifo.SetWaiterTracking(false, false)
+	suite.Nil(suite.fifo.Waiters())
+}
+
+func TestFIFOWaitersTestSuite(t *testing.T) {
+	suite.Run(t, new(FIFOWaitersTestSuite))
+}
@@ -1,11 +1,69 @@
 package goconcurrentqueue
 
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"runtime"
+	"sync/atomic"
+	"time"
+)
+
+// OverflowPolicy defines what a FixedFIFO does when an element gets enqueued at full capacity
+type OverflowPolicy int
+
+const (
+	// OverflowPolicyReject rejects the new element: Enqueue returns ErrFullCapacity (default)
+	OverflowPolicyReject OverflowPolicy = iota
+	// OverflowPolicyDropOldest evicts the oldest element to make room for the new one
+	OverflowPolicyDropOldest
+	// OverflowPolicyDropNewest silently drops the new element
+	OverflowPolicyDropNewest
+	// OverflowPolicyBlock makes Enqueue wait until there is a free slot
+	OverflowPolicyBlock
+)
+
 // Fixed capacity FIFO (First In First Out) concurrent queue
 type FixedFIFO struct {
-	queue    chan interface{}
+	queue    chan fixedFIFOElement
 	lockChan chan struct{}
+	// serializes enqueues against listener registrations, so no enqueued element gets lost for a new listener
+	mutex instrumentedMutex
 	// queue for watchers that will wait for next elements (if queue is empty at DequeueOrWaitForNextElement execution )
 	waitForNextElementChan chan chan interface{}
+	// signaled every time an element gets dequeued, to wake up EnqueueWithContext callers waiting for a free slot
+	spaceAvailableChan chan struct{}
+	// what to do at full capacity, protected by mutex
+	overflowPolicy OverflowPolicy
+	// closed (and replaced) every time the overflow policy changes, so the waiting EnqueueWithContext callers retry
+	// following the new policy, protected by mutex
+	overflowPolicyChangedChan chan struct{}
+	// elements dropped at full capacity (drop-oldest / drop-newest policies), protected by mutex
+	evictions       uint64
+	evictionHandler func(value interface{})
+	// sampling admission (SetSamplingAdmission): fraction of the enqueues rejected above depth (0 == disabled) and the
+	// random source, protected by mutex
+	samplingDepth    int
+	samplingFraction float64
+	samplingRandom   func() float64
+	// Stats counters
+	counters *queueCounters
+	// queueing delay SLO tracking (SetWaitSLO)
+	waitSLO *waitSLOTracker
+	// lifecycle hooks (SetHooks)
+	hooks atomicQueueHooks
+	// lock-free Dequeue calls receiving from the channel, and whether a snapshot is being taken (set under mutex):
+	// Dequeue calls wait for the snapshot instead of finding its elements moved out (see beginLockFreeDequeue)
+	dequeuers    int32
+	snapshotting int32
+	// tests only: scripted interleavings
+	schedHook schedHook
+}
+
+// fixedFIFOElement is an enqueued element along with its enqueue timestamp (see waitSLOTracker.stamp)
+type fixedFIFOElement struct {
+	value interface{}
+	at    int64
 }
 
 func NewFixedFIFO(capacity int) *FixedFIFO {
@@ -15,102 +73,600 @@ func NewFixedFIFO(capacity int) *FixedFIFO {
 	return queue
 }
 
+// NewKeepLatestFixedFIFO returns a new FixedFIFO that always retains the newest capacity elements: enqueueing into a
+// full queue silently evicts the oldest element (telemetry / sampling buffers). See GetEvictions.
+func NewKeepLatestFixedFIFO(capacity int) *FixedFIFO {
+	return NewBoundedFIFO(capacity, OverflowPolicyDropOldest)
+}
+
+// NewRendezvousFIFO returns a new zero capacity FixedFIFO: Enqueue blocks until a consumer is waiting for the element
+// (DequeueOrWaitForNextElement) and hands it over, like an unbuffered channel. EnqueueWithContext bounds the wait.
+// Dequeue never finds an element.
+func NewRendezvousFIFO() *FixedFIFO {
+	return NewBoundedFIFO(0, OverflowPolicyBlock)
+}
+
+// NewBoundedFIFO returns a new FixedFIFO holding up to maxLength elements, following the given policy once it is at
+// full capacity.
+func NewBoundedFIFO(maxLength int, policy OverflowPolicy) *FixedFIFO {
+	queue := NewFixedFIFO(maxLength)
+	queue.overflowPolicy = policy
+
+	return queue
+}
+
 func (st *FixedFIFO) initialize(capacity int) {
-	st.queue = make(chan interface{}, capacity)
+	st.queue = make(chan fixedFIFOElement, capacity)
 	st.lockChan = make(chan struct{}, 1)
+	st.spaceAvailableChan = make(chan struct{}, 1)
 	st.waitForNextElementChan = make(chan chan interface{}, WaitForNextElementChanCapacity)
+	st.overflowPolicyChangedChan = make(chan struct{})
+	st.counters = newQueueCounters()
+	st.waitSLO = newWaitSLOTracker()
+	st.samplingRandom = rand.Float64
 }
 
-// Enqueue enqueues an element. Returns error if queue is locked or it is at full capacity.
+// Enqueue enqueues an element. Returns error if queue is locked or it is at full capacity (unless the overflow policy
+// drops an element or waits for a free slot instead).
 func (st *FixedFIFO) Enqueue(value interface{}) error {
+	if st.GetOverflowPolicy() == OverflowPolicyBlock {
+		return st.countRejected(st.enqueueOrWait(context.Background(), value, true))
+	}
+
+	return st.countRejected(st.tryEnqueue(value))
+}
+
+// countRejected counts the element as rejected if err is a locked queue or a full capacity error. Returns err.
+func (st *FixedFIFO) countRejected(err error) error {
+	if err == ErrLockedQueue || err == ErrFullCapacity || err == ErrSampledOut {
+		st.counters.reject(1)
+	}
+
+	return err
+}
+
+// tryEnqueue enqueues an element, no waiting
+func (st *FixedFIFO) tryEnqueue(value interface{}) error {
 	if st.IsLocked() {
-		return NewQueueError(QueueErrorCodeLockedQueue, "The queue is locked")
+		return ErrLockedQueue
+	}
+
+	st.schedHook.sched(schedPointEnqueue)
+	evicted, evictionHandler, err := st.enqueue(value)
+
+	// the handler is invoked out of the lock, so it could access the queue
+	for _, element := range evicted {
+		evictionHandler(element)
 	}
 
+	return err
+}
+
+// EnqueueWithContext enqueues an element, waiting until there is a free slot if the queue is at full capacity
+// (backpressure). Returns error if queue is locked or ctx.Err() if the context gets done before the element is enqueued.
+func (st *FixedFIFO) EnqueueWithContext(ctx context.Context, value interface{}) error {
+	return st.countRejected(st.enqueueOrWait(ctx, value, false))
+}
+
+// enqueueOrWait enqueues an element, waiting until there is a free slot. whileBlocking means it only waits while the
+// overflow policy is OverflowPolicyBlock (Enqueue), once the policy changes the element is enqueued following it.
+func (st *FixedFIFO) enqueueOrWait(ctx context.Context, value interface{}, whileBlocking bool) error {
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		st.mutex.Lock()
+		overflowPolicyChangedChan := st.overflowPolicyChangedChan
+		st.mutex.Unlock()
+
+		err := st.tryEnqueue(value)
+		if err != ErrFullCapacity {
+			if err == nil && (st.GetLen() < st.GetCap() || len(st.waitForNextElementChan) > 0) {
+				// pass the signal on to the next waiting producer, there is room (or a waiting consumer) for it
+				st.notifySpaceAvailable()
+			}
+			return err
+		}
+
+		select {
+		case <-st.spaceAvailableChan:
+		case <-overflowPolicyChangedChan:
+			if whileBlocking && st.GetOverflowPolicy() != OverflowPolicyBlock {
+				return st.tryEnqueue(value)
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// notifySpaceAvailable wakes up an EnqueueWithContext caller waiting for a free slot (if any)
+func (st *FixedFIFO) notifySpaceAvailable() {
+	select {
+	case st.spaceAvailableChan <- struct{}{}:
+	default:
+	}
+}
+
+// enqueue enqueues the element under the lock and returns the dropped elements (drop-oldest / drop-newest policies,
+// only if there is an eviction handler) along with the handler
+func (st *FixedFIFO) enqueue(value interface{}) ([]interface{}, func(value interface{}), error) {
+	st.mutex.Lock()
+	defer st.mutex.Unlock()
+
+	return st.enqueueLocked(value, true)
+}
+
+// enqueueLocked works as enqueue, sample means the element goes through the sampling admission (if any). st.mutex
+// must be locked by the caller.
+func (st *FixedFIFO) enqueueLocked(value interface{}, sample bool) ([]interface{}, func(value interface{}), error) {
+	element := fixedFIFOElement{value: value, at: st.waitSLO.stamp()}
+
 	// check if there is a listener waiting for the next element (this element)
 	select {
 	case listener := <-st.waitForNextElementChan:
 		// send the element through the listener's channel instead of enqueue it
-		listener <- value
+		select {
+		case listener <- value:
+			st.counters.enqueue(1, len(st.queue))
+			st.counters.dequeue(1)
+			st.waitSLO.dequeued(element.at)
+			st.hooks.load().handedOver(value)
+			return nil, nil, nil
+		default:
+			// enqueue the element following the "normal way" if the listener is not ready
+		}
+
+	default:
+	}
 
+	if sample && st.sampledOut() {
+		return nil, nil, ErrSampledOut
+	}
+
+	if st.overflowPolicy == OverflowPolicyDropOldest {
+		return st.enqueueKeepingLatest(element), st.evictionHandler, nil
+	}
+
+	// enqueue the element following the "normal way"
+	select {
+	case st.queue <- element:
+		st.enqueued(value, false)
 	default:
-		// enqueue the element following the "normal way"
+		if st.overflowPolicy == OverflowPolicyDropNewest {
+			return st.evict(nil, value), st.evictionHandler, nil
+		}
+		return nil, nil, ErrFullCapacity
+	}
+
+	return nil, nil, nil
+}
+
+// sampledOut returns true whether the sampling admission (if any) rejects the next element. st.mutex must be locked
+// by the caller.
+func (st *FixedFIFO) sampledOut() bool {
+	return st.samplingFraction > 0 && len(st.queue) >= st.samplingDepth && st.samplingRandom() < st.samplingFraction
+}
+
+// enqueued counts the element enqueued into the queue and invokes the hooks: OnFull if the element filled the queue
+// up (no element got evicted to make room for it, the queue wasn't full). st.mutex must be locked by the caller.
+func (st *FixedFIFO) enqueued(value interface{}, evicted bool) {
+	st.counters.enqueue(1, len(st.queue))
+
+	hooks := st.hooks.load()
+	hooks.enqueued(value)
+	if !evicted {
+		hooks.filled(len(st.queue), cap(st.queue))
+	}
+}
+
+// evict counts the dropped element and appends it to evicted if there is an eviction handler. st.mutex must be locked
+// by the caller.
+func (st *FixedFIFO) evict(evicted []interface{}, value interface{}) []interface{} {
+	st.evictions++
+	failDroppedFuture(value)
+	if st.evictionHandler != nil {
+		evicted = append(evicted, value)
+	}
+
+	return evicted
+}
+
+// enqueueKeepingLatest enqueues the element, evicting the oldest ones while the queue is full. Returns the evicted
+// elements if there is an eviction handler. st.mutex must be locked by the caller.
+func (st *FixedFIFO) enqueueKeepingLatest(element fixedFIFOElement) []interface{} {
+	var (
+		evicted   []interface{}
+		evictions = st.evictions
+	)
+
+	// no room at all: the new element is the one evicted
+	if cap(st.queue) == 0 {
+		return st.evict(evicted, element.value)
+	}
+
+	for {
+		select {
+		case st.queue <- element:
+			st.enqueued(element.value, st.evictions != evictions)
+			return evicted
+		default:
+		}
+
+		// a concurrent Dequeue could free a slot first, so the eviction is non-blocking
 		select {
-		case st.queue <- value:
+		case oldest := <-st.queue:
+			evicted = st.evict(evicted, oldest.value)
 		default:
-			return NewQueueError(QueueErrorCodeFullCapacity, "FixedFIFO queue is at full capacity")
 		}
 	}
+}
+
+// SetEvictionHandler sets the function invoked with every element dropped at full capacity (drop-oldest / drop-newest
+// policies), i.e. to log them or to enqueue them into a side queue. The handler runs at the Enqueue caller's goroutine,
+// after the element got enqueued. nil removes the handler.
+func (st *FixedFIFO) SetEvictionHandler(handler func(value interface{})) {
+	st.mutex.Lock()
+	defer st.mutex.Unlock()
+
+	st.evictionHandler = handler
+}
+
+// SetSamplingAdmission makes Enqueue reject (ErrSampledOut) the given fraction of the new elements, at random, once
+// the queue holds depth elements or more: overload gets smoothed out (telemetry pipelines) instead of accepting
+// everything until the queue is at full capacity. Elements handed over to waiting consumers are always accepted.
+// fraction 0 disables it. Returns error if depth is negative or fraction isn't within [0, 1].
+func (st *FixedFIFO) SetSamplingAdmission(depth int, fraction float64) error {
+	if depth < 0 {
+		return NewQueueError(QueueErrorCodeInvalidConfig, fmt.Sprintf("invalid depth: %v", depth))
+	}
+	if fraction < 0 || fraction > 1 {
+		return NewQueueError(QueueErrorCodeInvalidConfig, fmt.Sprintf("invalid fraction: %v", fraction))
+	}
+
+	st.mutex.Lock()
+	defer st.mutex.Unlock()
+
+	st.samplingDepth = depth
+	st.samplingFraction = fraction
 
 	return nil
 }
 
+// SetOverflowPolicy changes the overflow policy on a live queue (i.e. to stop blocking the producers during an
+// incident). The Enqueue / EnqueueWithContext callers waiting for a free slot retry following the new policy. See
+// Reconfigure.
+func (st *FixedFIFO) SetOverflowPolicy(policy OverflowPolicy) {
+	st.mutex.Lock()
+	defer st.mutex.Unlock()
+
+	st.setOverflowPolicy(policy)
+}
+
+// setOverflowPolicy changes the overflow policy, waking up the producers waiting for a free slot. st.mutex must be
+// locked by the caller.
+func (st *FixedFIFO) setOverflowPolicy(policy OverflowPolicy) {
+	if st.overflowPolicy == policy {
+		return
+	}
+
+	st.overflowPolicy = policy
+	close(st.overflowPolicyChangedChan)
+	st.overflowPolicyChangedChan = make(chan struct{})
+}
+
+// GetOverflowPolicy returns the overflow policy
+func (st *FixedFIFO) GetOverflowPolicy() OverflowPolicy {
+	st.mutex.Lock()
+	defer st.mutex.Unlock()
+
+	return st.overflowPolicy
+}
+
+// GetEvictions returns the number of elements dropped at full capacity (drop-oldest / drop-newest policies)
+func (st *FixedFIFO) GetEvictions() uint64 {
+	st.mutex.Lock()
+	defer st.mutex.Unlock()
+
+	return st.evictions
+}
+
 // Dequeue dequeues an element. Returns error if: queue is locked, queue is empty or internal channel is closed.
 func (st *FixedFIFO) Dequeue() (interface{}, error) {
 	if st.IsLocked() {
-		return nil, NewQueueError(QueueErrorCodeLockedQueue, "The queue is locked")
+		return nil, ErrLockedQueue
 	}
 
+	st.schedHook.sched(schedPointDequeue)
+	st.beginLockFreeDequeue()
 	select {
-	case value, ok := <-st.queue:
+	case element, ok := <-st.queue:
+		st.endLockFreeDequeue()
 		if ok {
-			return value, nil
+			st.counters.dequeue(1)
+			st.waitSLO.dequeued(element.at)
+			st.hooks.load().dequeued(element.value, len(st.queue))
+			st.notifySpaceAvailable()
+			return element.value, nil
 		}
 		return nil, NewQueueError(QueueErrorCodeInternalChannelClosed, "internal channel is closed")
 	default:
-		return nil, NewQueueError(QueueErrorCodeEmptyQueue, "empty queue")
+		st.endLockFreeDequeue()
+		return nil, ErrEmptyQueue
+	}
+}
+
+// beginLockFreeDequeue registers a lock-free Dequeue call about to receive from the channel. If a snapshot is being
+// taken it waits until it is done (snapshots hold st.mutex), so the call doesn't find the elements moved out.
+func (st *FixedFIFO) beginLockFreeDequeue() {
+	for {
+		atomic.AddInt32(&st.dequeuers, 1)
+		if atomic.LoadInt32(&st.snapshotting) == 0 {
+			return
+		}
+
+		atomic.AddInt32(&st.dequeuers, -1)
+		st.mutex.Lock()
+		st.mutex.Unlock()
 	}
 }
 
+// endLockFreeDequeue unregisters a lock-free Dequeue call (see beginLockFreeDequeue)
+func (st *FixedFIFO) endLockFreeDequeue() {
+	atomic.AddInt32(&st.dequeuers, -1)
+}
+
 // DequeueOrWaitForNextElement dequeues an element (if exist) or waits until the next element gets enqueued and returns it.
 // Multiple calls to DequeueOrWaitForNextElement() would enqueue multiple "listeners" for future enqueued elements.
 func (st *FixedFIFO) DequeueOrWaitForNextElement() (interface{}, error) {
+	return st.DequeueOrWaitForNextElementWithContext(context.Background())
+}
+
+// DequeueOrWaitForNextElementWithContext works as DequeueOrWaitForNextElement, but it stops waiting once the context
+// is done, returning ctx.Err(). The cancelled waiter's listener is removed, so no element gets handed over to it.
+func (st *FixedFIFO) DequeueOrWaitForNextElementWithContext(ctx context.Context) (interface{}, error) {
 	if st.IsLocked() {
-		return nil, NewQueueError(QueueErrorCodeLockedQueue, "The queue is locked")
+		return nil, ErrLockedQueue
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
 	}
 
+	// the emptiness check and the listener registration happen under the same lock Enqueue takes, so an element can't
+	// be enqueued in between (and get lost for this listener)
+	st.schedHook.sched(schedPointWaitForNextElement)
+	st.mutex.Lock()
+
 	select {
-	case value, ok := <-st.queue:
+	case element, ok := <-st.queue:
+		st.mutex.Unlock()
 		if ok {
-			return value, nil
+			st.counters.dequeue(1)
+			st.waitSLO.dequeued(element.at)
+			st.hooks.load().dequeued(element.value, len(st.queue))
+			st.notifySpaceAvailable()
+			return element.value, nil
 		}
 		return nil, NewQueueError(QueueErrorCodeInternalChannelClosed, "internal channel is closed")
 
 	// queue is empty, add a listener to wait until next enqueued element is ready
 	default:
-		// channel to wait for next enqueued element
-		waitChan := make(chan interface{})
+		// channel to wait for next enqueued element (buffered, so Enqueue never blocks handing it over)
+		waitChan := make(chan interface{}, 1)
 
 		select {
 		// enqueue a watcher into the watchForNextElementChannel to wait for the next element
 		case st.waitForNextElementChan <- waitChan:
+			st.mutex.Unlock()
+			// a producer waiting for a free slot could hand the element over to this listener (zero capacity queues)
+			st.notifySpaceAvailable()
+			st.schedHook.sched(schedPointWaitForNextElementHandoff)
+
+			select {
 			// return the next enqueued element, if any
-			return <-waitChan, nil
+			case value := <-waitChan:
+				return value, nil
+			case <-ctx.Done():
+				st.mutex.Lock()
+				defer st.mutex.Unlock()
+
+				removeListener(st.waitForNextElementChan, waitChan)
+				// the element could have been handed over right before the listener's removal
+				select {
+				case value := <-waitChan:
+					return value, nil
+				default:
+					return nil, ctx.Err()
+				}
+			}
 		default:
+			st.mutex.Unlock()
 			// too many watchers (waitForNextElementChanCapacity) enqueued waiting for next elements
 			return nil, NewQueueError(QueueErrorCodeEmptyQueue, "empty queue and can't wait for next element")
 		}
+	}
+}
+
+// DequeueWithTimeout dequeues an element (if exist) or waits up to d until the next element gets enqueued and returns
+// it. Returns ErrTimeout if no element could be dequeued in time (d <= 0 means no waiting at all).
+func (st *FixedFIFO) DequeueWithTimeout(d time.Duration) (interface{}, error) {
+	if d <= 0 {
+		value, err := st.Dequeue()
+		if err == ErrEmptyQueue {
+			return nil, ErrTimeout
+		}
+		return value, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), d)
+	defer cancel()
+
+	value, err := st.DequeueOrWaitForNextElementWithContext(ctx)
+	if err == context.DeadlineExceeded {
+		return nil, ErrTimeout
+	}
+
+	return value, err
+}
+
+// Clear atomically removes every element. Returns error if queue is locked.
+func (st *FixedFIFO) Clear() error {
+	if st.IsLocked() {
+		return ErrLockedQueue
+	}
+
+	// no element gets enqueued in the meantime
+	st.mutex.Lock()
+	removed := 0
+	for len(st.queue) > 0 {
+		// concurrent Dequeue calls could take the last elements in the meantime
+		select {
+		case <-st.queue:
+			removed++
+		default:
+		}
+	}
+	st.mutex.Unlock()
+
+	if removed > 0 {
+		st.hooks.load().emptied(0)
+		st.notifySpaceAvailable()
+	}
+
+	return nil
+}
+
+// Drain atomically removes and returns every element, in order: everything that was enqueued at that instant, no
+// concurrent Enqueue gets in between. It works on locked queues too (flush at shutdown). Returns an empty slice if
+// there are no elements.
+func (st *FixedFIFO) Drain() []interface{} {
+	// no element gets enqueued in the meantime
+	st.mutex.Lock()
+	elements := make([]interface{}, 0, len(st.queue))
+	for len(st.queue) > 0 {
+		// concurrent Dequeue calls could take the last elements in the meantime
+		select {
+		case element := <-st.queue:
+			elements = append(elements, element.value)
+			st.waitSLO.dequeued(element.at)
+		default:
+		}
+	}
+	st.mutex.Unlock()
+
+	if len(elements) > 0 {
+		st.counters.dequeue(len(elements))
+		st.hooks.load().dequeuedAll(elements, 0)
+		st.notifySpaceAvailable()
+	}
+
+	return elements
+}
+
+// snapshot returns a copy of the elements, in dequeue order: nothing gets enqueued or dequeued while it is taken (the
+// elements are moved out and back in). It works on locked queues too.
+func (st *FixedFIFO) snapshot() []interface{} {
+	st.mutex.Lock()
+	defer st.mutex.Unlock()
+
+	// the lock-free Dequeue calls already receiving finish, the new ones wait for the snapshot
+	atomic.StoreInt32(&st.snapshotting, 1)
+	defer atomic.StoreInt32(&st.snapshotting, 0)
+	for atomic.LoadInt32(&st.dequeuers) > 0 {
+		runtime.Gosched()
+	}
 
-		//return nil, NewQueueError(QueueErrorCodeEmptyQueue, "empty queue")
+	var (
+		entries  = make([]fixedFIFOElement, 0, len(st.queue))
+		elements = make([]interface{}, 0, len(st.queue))
+	)
+	for len(st.queue) > 0 {
+		element := <-st.queue
+		entries = append(entries, element)
+		elements = append(elements, element.value)
+	}
+	// no enqueue got in, so there is room for every element
+	for _, element := range entries {
+		st.queue <- element
 	}
+
+	return elements
+}
+
+// replaceElements atomically replaces every element with the given ones, in order: the waiting consumers get the first
+// ones. Returns error if queue is locked or there are more elements than the queue's capacity.
+func (st *FixedFIFO) replaceElements(elements []interface{}) error {
+	if len(elements) > st.GetCap() {
+		return NewQueueError(QueueErrorCodeFullCapacity, fmt.Sprintf("%v elements for a capacity of %v", len(elements), st.GetCap()))
+	}
+
+	if st.IsLocked() {
+		return ErrLockedQueue
+	}
+
+	// no element gets enqueued in the meantime
+	st.mutex.Lock()
+	defer st.mutex.Unlock()
+
+	removed := 0
+	for len(st.queue) > 0 {
+		// concurrent Dequeue calls could take the last elements in the meantime
+		select {
+		case <-st.queue:
+			removed++
+		default:
+		}
+	}
+	if removed > 0 {
+		st.hooks.load().emptied(0)
+	}
+
+	// the queue is empty, there is room for every element
+	for _, value := range elements {
+		st.queue <- fixedFIFOElement{value: value, at: st.waitSLO.stamp()}
+		st.enqueued(value, false)
+	}
+	st.handOverToListeners()
+	if removed > len(elements) {
+		st.notifySpaceAvailable()
+	}
+
+	return nil
 }
 
 // GetLen returns queue's length (total enqueued elements)
 func (st *FixedFIFO) GetLen() int {
-	st.Lock()
-	defer st.Unlock()
-
 	return len(st.queue)
 }
 
 // GetCap returns the queue's capacity
 func (st *FixedFIFO) GetCap() int {
-	st.Lock()
-	defer st.Unlock()
-
 	return cap(st.queue)
 }
 
+// Stats returns the queue's counters (enqueued, dequeued and rejected elements) and gauges (length, peak length and
+// waiting consumers), along with the lock contention (see SetLockInstrumentation) and the queueing delay SLO (see
+// SetWaitSLO). Elements dropped by the overflow policy aren't rejected, see GetEvictions.
+func (st *FixedFIFO) Stats() QueueStats {
+	stats := st.counters.stats(len(st.queue), len(st.waitForNextElementChan))
+	stats.WaitSLO = st.waitSLO.stats()
+
+	st.mutex.RLock()
+	stats.Lock = st.mutex.stats()
+	st.mutex.RUnlock()
+
+	return stats
+}
+
+// SetHooks sets the lifecycle hooks: invoked on every enqueued / dequeued element, every time the queue becomes empty
+// and every time an enqueue fills it up (not while the drop-oldest policy keeps it full), see QueueHooks. Elements
+// dropped by the overflow policy don't fire OnDequeue, see SetEvictionHandler. QueueHooks{} removes them.
+func (st *FixedFIFO) SetHooks(hooks QueueHooks) {
+	st.hooks.store(hooks)
+}
+
 func (st *FixedFIFO) Lock() {
 	// non-blocking fill the channel
 	select {
@@ -119,12 +675,39 @@ func (st *FixedFIFO) Lock() {
 	}
 }
 
+// Unlock unlocks the queue. The DequeueOrWaitForNextElement callers still waiting get served, in order, from the
+// elements already enqueued (if any).
 func (st *FixedFIFO) Unlock() {
 	// non-blocking flush the channel
 	select {
 	case <-st.lockChan:
 	default:
 	}
+
+	st.mutex.Lock()
+	defer st.mutex.Unlock()
+
+	st.handOverToListeners()
+}
+
+// handOverToListeners sends the enqueued elements to the waiting listeners (if any), in order. st.mutex must be
+// locked by the caller.
+func (st *FixedFIFO) handOverToListeners() {
+	for len(st.waitForNextElementChan) > 0 {
+		var element fixedFIFOElement
+		select {
+		case element = <-st.queue:
+		default:
+			return
+		}
+
+		listener := <-st.waitForNextElementChan
+		listener <- element.value
+		st.counters.dequeue(1)
+		st.waitSLO.dequeued(element.at)
+		st.hooks.load().dequeued(element.value, len(st.queue))
+		st.notifySpaceAvailable()
+	}
 }
 
 func (st *FixedFIFO) IsLocked() bool {
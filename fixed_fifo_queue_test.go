@@ -1,6 +1,8 @@
 package goconcurrentqueue
 
 import (
+	"context"
+	"runtime"
 	"sync"
 	"testing"
 	"time"
@@ -398,7 +400,10 @@ func (suite *FixedFIFOTestSuite) TestDequeueOrWaitForNextElementMultiGR() {
 	// enqueue all needed elements
 	for i := 0; i < WaitForNextElementChanCapacity; i++ {
 		wg.Add(1)
-		suite.fifo.Enqueue(i)
+		// the queue could get full if the GRs aren't waiting yet, retry until they dequeue some elements
+		for suite.fifo.Enqueue(i) != nil {
+			runtime.Gosched()
+		}
 		// save the enqueued value as index
 		mp[i] = 0
 	}
@@ -436,6 +441,660 @@ func (suite *FixedFIFOTestSuite) TestDequeueOrWaitForNextElementClosedChannel()
 	suite.Equalf(QueueErrorCodeInternalChannelClosed, customError.Code(), "Expected code: '%v'", QueueErrorCodeInternalChannelClosed)
 }
 
+// ***************************************************************************************
+// ** DequeueOrWaitForNextElementWithContext
+// ***************************************************************************************
+
+// element already enqueued: no waiting
+func (suite *FixedFIFOTestSuite) TestDequeueOrWaitForNextElementWithContextEnqueued() {
+	suite.fifo.Enqueue(testValue)
+
+	value, err := suite.fifo.DequeueOrWaitForNextElementWithContext(context.Background())
+	suite.NoError(err)
+	suite.Equal(testValue, value)
+}
+
+// element enqueued while waiting
+func (suite *FixedFIFOTestSuite) TestDequeueOrWaitForNextElementWithContextWaiting() {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		suite.fifo.Enqueue(testValue)
+	}()
+
+	value, err := suite.fifo.DequeueOrWaitForNextElementWithContext(ctx)
+	suite.NoError(err)
+	suite.Equal(testValue, value)
+}
+
+// already cancelled context
+func (suite *FixedFIFOTestSuite) TestDequeueOrWaitForNextElementWithContextCancelled() {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := suite.fifo.DequeueOrWaitForNextElementWithContext(ctx)
+	suite.Equal(context.Canceled, err)
+}
+
+// deadline exceeded while waiting: the next element is not handed over to the gone waiter
+func (suite *FixedFIFOTestSuite) TestDequeueOrWaitForNextElementWithContextDeadline() {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err := suite.fifo.DequeueOrWaitForNextElementWithContext(ctx)
+	suite.Equal(context.DeadlineExceeded, err)
+
+	suite.fifo.Enqueue(testValue)
+	suite.Equal(1, suite.fifo.GetLen())
+	value, err := suite.fifo.Dequeue()
+	suite.NoError(err)
+	suite.Equal(testValue, value)
+}
+
+// cancelled waiters don't take elements from the remaining ones
+func (suite *FixedFIFOTestSuite) TestDequeueOrWaitForNextElementWithContextRemainingWaiters() {
+	var (
+		totalWaiters = 3
+		results      = make(chan interface{}, totalWaiters)
+		ctx, cancel  = context.WithCancel(context.Background())
+	)
+
+	for i := 0; i < totalWaiters; i++ {
+		waiterCtx := context.Background()
+		// the middle waiter gets cancelled
+		if i == 1 {
+			waiterCtx = ctx
+		}
+		go func() {
+			value, err := suite.fifo.DequeueOrWaitForNextElementWithContext(waiterCtx)
+			if err != nil {
+				value = err
+			}
+			results <- value
+		}()
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	cancel()
+	suite.Equal(context.Canceled, <-results)
+
+	suite.fifo.Enqueue(1)
+	suite.fifo.Enqueue(2)
+	var values []interface{}
+	for i := 0; i < totalWaiters-1; i++ {
+		select {
+		case value := <-results:
+			values = append(values, value)
+		case <-time.After(2 * time.Second):
+			suite.FailNow("too much time waiting for the enqueued element")
+		}
+	}
+	suite.ElementsMatch([]interface{}{1, 2}, values)
+	suite.Equal(0, suite.fifo.GetLen())
+}
+
+// ***************************************************************************************
+// ** DequeueWithTimeout
+// ***************************************************************************************
+
+// element already enqueued
+func (suite *FixedFIFOTestSuite) TestDequeueWithTimeoutEnqueued() {
+	suite.fifo.Enqueue(testValue)
+
+	value, err := suite.fifo.DequeueWithTimeout(time.Second)
+	suite.NoError(err)
+	suite.Equal(testValue, value)
+}
+
+// element enqueued while waiting
+func (suite *FixedFIFOTestSuite) TestDequeueWithTimeoutWaiting() {
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		suite.fifo.Enqueue(testValue)
+	}()
+
+	value, err := suite.fifo.DequeueWithTimeout(2 * time.Second)
+	suite.NoError(err)
+	suite.Equal(testValue, value)
+}
+
+// no element in time
+func (suite *FixedFIFOTestSuite) TestDequeueWithTimeoutExpired() {
+	_, err := suite.fifo.DequeueWithTimeout(10 * time.Millisecond)
+	suite.Equal(ErrTimeout, err)
+	suite.Equal(QueueErrorCodeTimeout, err.(*QueueError).Code())
+}
+
+// no waiting at all
+func (suite *FixedFIFOTestSuite) TestDequeueWithTimeoutZero() {
+	_, err := suite.fifo.DequeueWithTimeout(0)
+	suite.Equal(ErrTimeout, err)
+
+	suite.fifo.Enqueue(testValue)
+	value, err := suite.fifo.DequeueWithTimeout(0)
+	suite.NoError(err)
+	suite.Equal(testValue, value)
+}
+
+// locked queue
+func (suite *FixedFIFOTestSuite) TestDequeueWithTimeoutLockedQueue() {
+	suite.fifo.Lock()
+
+	_, err := suite.fifo.DequeueWithTimeout(time.Second)
+	suite.Equal(ErrLockedQueue, err)
+}
+
+// ***************************************************************************************
+// ** EnqueueWithContext
+// ***************************************************************************************
+
+// free slot: no waiting
+func (suite *FixedFIFOTestSuite) TestEnqueueWithContext() {
+	suite.NoError(suite.fifo.EnqueueWithContext(context.Background(), testValue))
+	suite.Equal(1, suite.fifo.GetLen())
+}
+
+// full queue: waits until an element gets dequeued
+func (suite *FixedFIFOTestSuite) TestEnqueueWithContextFullQueue() {
+	fifo := NewFixedFIFO(1)
+	fifo.Enqueue(1)
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		fifo.Dequeue()
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	suite.NoError(fifo.EnqueueWithContext(ctx, 2))
+
+	value, err := fifo.Dequeue()
+	suite.NoError(err)
+	suite.Equal(2, value)
+}
+
+// full queue: context deadline exceeded while waiting
+func (suite *FixedFIFOTestSuite) TestEnqueueWithContextDeadline() {
+	fifo := NewFixedFIFO(1)
+	fifo.Enqueue(1)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	suite.Equal(context.DeadlineExceeded, fifo.EnqueueWithContext(ctx, 2))
+	suite.Equal(1, fifo.GetLen())
+}
+
+// locked queue
+func (suite *FixedFIFOTestSuite) TestEnqueueWithContextLockedQueue() {
+	suite.fifo.Lock()
+	suite.Equal(ErrLockedQueue, suite.fifo.EnqueueWithContext(context.Background(), testValue))
+}
+
+// zero capacity: waits until a consumer waits for the element
+func (suite *FixedFIFOTestSuite) TestEnqueueWithContextZeroCapacity() {
+	fifo := NewFixedFIFO(0)
+
+	result := make(chan interface{}, 1)
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		value, _ := fifo.DequeueOrWaitForNextElement()
+		result <- value
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	suite.NoError(fifo.EnqueueWithContext(ctx, testValue))
+	suite.Equal(testValue, <-result)
+}
+
+// rendezvous: Enqueue waits until a consumer waits for the element
+func (suite *FixedFIFOTestSuite) TestRendezvous() {
+	var (
+		fifo     = NewRendezvousFIFO()
+		enqueued = make(chan struct{})
+	)
+
+	go func() {
+		defer close(enqueued)
+		suite.NoError(fifo.Enqueue(testValue))
+	}()
+
+	select {
+	case <-enqueued:
+		suite.FailNow("Enqueue must wait for a consumer")
+	case <-time.After(10 * time.Millisecond):
+	}
+	_, err := fifo.Dequeue()
+	suite.Equal(ErrEmptyQueue, err, "no element is ever kept at the queue")
+
+	value, err := fifo.DequeueOrWaitForNextElement()
+	suite.NoError(err)
+	suite.Equal(testValue, value)
+	<-enqueued
+	suite.Equal(0, fifo.GetLen())
+}
+
+// rendezvous: several producers and consumers meet, no one is left waiting
+func (suite *FixedFIFOTestSuite) TestRendezvousMultipleGRs() {
+	var (
+		fifo     = NewRendezvousFIFO()
+		wg       sync.WaitGroup
+		totalGRs = 10
+		perGR    = 100
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	for gr := 0; gr < totalGRs; gr++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < perGR; i++ {
+				suite.NoError(fifo.EnqueueWithContext(ctx, i))
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for i := 0; i < perGR; i++ {
+				_, err := fifo.DequeueOrWaitForNextElementWithContext(ctx)
+				suite.NoError(err)
+			}
+		}()
+	}
+	wg.Wait()
+	suite.Equal(uint64(totalGRs*perGR), fifo.Stats().Dequeued)
+}
+
+// rendezvous: no consumer shows up in time
+func (suite *FixedFIFOTestSuite) TestRendezvousDeadline() {
+	fifo := NewRendezvousFIFO()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	suite.Equal(context.DeadlineExceeded, fifo.EnqueueWithContext(ctx, testValue))
+
+	fifo.Lock()
+	suite.Equal(ErrLockedQueue, fifo.Enqueue(testValue))
+}
+
+// several blocked producers: every element gets enqueued once there is room
+func (suite *FixedFIFOTestSuite) TestEnqueueWithContextMultipleGRs() {
+	var (
+		fifo     = NewFixedFIFO(2)
+		wg       sync.WaitGroup
+		totalGRs = 10
+		perGR    = 100
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	for gr := 0; gr < totalGRs; gr++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < perGR; i++ {
+				suite.NoError(fifo.EnqueueWithContext(ctx, i))
+			}
+		}()
+	}
+
+	for i := 0; i < totalGRs*perGR; i++ {
+		if _, err := fifo.DequeueOrWaitForNextElementWithContext(ctx); err != nil {
+			suite.FailNow("too much time waiting for the producers")
+		}
+	}
+	wg.Wait()
+	suite.Equal(0, fifo.GetLen())
+}
+
+// ***************************************************************************************
+// ** Keep-latest mode
+// ***************************************************************************************
+
+// the newest elements are retained, the oldest ones get evicted
+func (suite *FixedFIFOTestSuite) TestKeepLatestEvictsOldest() {
+	fifo := NewKeepLatestFixedFIFO(3)
+	for i := 0; i < 10; i++ {
+		suite.NoError(fifo.Enqueue(i))
+	}
+
+	suite.Equal(3, fifo.GetLen())
+	suite.Equal(uint64(7), fifo.GetEvictions())
+	for i := 7; i < 10; i++ {
+		value, err := fifo.Dequeue()
+		suite.NoError(err)
+		suite.Equal(i, value)
+	}
+}
+
+// no evictions while there is room
+func (suite *FixedFIFOTestSuite) TestKeepLatestNotFull() {
+	fifo := NewKeepLatestFixedFIFO(3)
+	fifo.Enqueue(1)
+	fifo.Enqueue(2)
+
+	suite.Equal(2, fifo.GetLen())
+	suite.Equal(uint64(0), fifo.GetEvictions())
+	suite.Equal(uint64(0), suite.fifo.GetEvictions())
+}
+
+// zero capacity: every new element gets evicted
+func (suite *FixedFIFOTestSuite) TestKeepLatestZeroCapacity() {
+	fifo := NewKeepLatestFixedFIFO(0)
+
+	suite.NoError(fifo.Enqueue(testValue))
+	suite.Equal(0, fifo.GetLen())
+	suite.Equal(uint64(1), fifo.GetEvictions())
+}
+
+// evicted elements are sent to the handler, in order
+func (suite *FixedFIFOTestSuite) TestKeepLatestEvictionHandler() {
+	var (
+		fifo    = NewKeepLatestFixedFIFO(3)
+		evicted = NewFIFO()
+	)
+	fifo.SetEvictionHandler(func(value interface{}) {
+		suite.NoError(evicted.Enqueue(value))
+	})
+
+	for i := 0; i < 5; i++ {
+		suite.NoError(fifo.Enqueue(i))
+	}
+
+	suite.Equal(2, evicted.GetLen())
+	for i := 0; i < 2; i++ {
+		value, err := evicted.Dequeue()
+		suite.NoError(err)
+		suite.Equal(i, value)
+	}
+
+	// handler removed
+	fifo.SetEvictionHandler(nil)
+	suite.NoError(fifo.Enqueue(5))
+	suite.Equal(0, evicted.GetLen())
+	suite.Equal(uint64(3), fifo.GetEvictions())
+}
+
+// the handler could access the queue
+func (suite *FixedFIFOTestSuite) TestKeepLatestEvictionHandlerAccessingQueue() {
+	var (
+		fifo   = NewKeepLatestFixedFIFO(1)
+		length int
+	)
+	fifo.SetEvictionHandler(func(value interface{}) {
+		length = fifo.GetLen()
+		fifo.GetEvictions()
+	})
+
+	fifo.Enqueue(1)
+	fifo.Enqueue(2)
+	suite.Equal(1, length)
+}
+
+// concurrent producers and consumers: the queue never goes beyond its capacity
+func (suite *FixedFIFOTestSuite) TestKeepLatestMultipleGRs() {
+	var (
+		fifo        = NewKeepLatestFixedFIFO(10)
+		wg          sync.WaitGroup
+		total       = 1000
+		totalGRs    = 4
+		dequeued    uint64
+		dequeuedMtx sync.Mutex
+	)
+
+	for gr := 0; gr < totalGRs; gr++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < total; i++ {
+				suite.NoError(fifo.Enqueue(i))
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for i := 0; i < total; i++ {
+				if _, err := fifo.Dequeue(); err == nil {
+					dequeuedMtx.Lock()
+					dequeued++
+					dequeuedMtx.Unlock()
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	suite.True(fifo.GetLen() <= 10)
+	suite.Equal(uint64(total*totalGRs), dequeued+fifo.GetEvictions()+uint64(fifo.GetLen()))
+}
+
+// ***************************************************************************************
+// ** Overflow policies
+// ***************************************************************************************
+
+// reject: full capacity error
+func (suite *FixedFIFOTestSuite) TestBoundedFIFOReject() {
+	fifo := NewBoundedFIFO(2, OverflowPolicyReject)
+	fifo.Enqueue(1)
+	fifo.Enqueue(2)
+
+	suite.Equal(ErrFullCapacity, fifo.Enqueue(3))
+	suite.Equal(2, fifo.GetLen())
+	suite.Equal(uint64(0), fifo.GetEvictions())
+}
+
+// drop oldest: the newest elements are kept
+func (suite *FixedFIFOTestSuite) TestBoundedFIFODropOldest() {
+	fifo := NewBoundedFIFO(2, OverflowPolicyDropOldest)
+	for i := 0; i < 4; i++ {
+		suite.NoError(fifo.Enqueue(i))
+	}
+
+	suite.Equal(uint64(2), fifo.GetEvictions())
+	for _, expected := range []int{2, 3} {
+		value, err := fifo.Dequeue()
+		suite.NoError(err)
+		suite.Equal(expected, value)
+	}
+}
+
+// drop newest: the oldest elements are kept
+func (suite *FixedFIFOTestSuite) TestBoundedFIFODropNewest() {
+	var (
+		fifo    = NewBoundedFIFO(2, OverflowPolicyDropNewest)
+		dropped []interface{}
+	)
+	fifo.SetEvictionHandler(func(value interface{}) {
+		dropped = append(dropped, value)
+	})
+
+	for i := 0; i < 4; i++ {
+		suite.NoError(fifo.Enqueue(i))
+	}
+
+	suite.Equal(uint64(2), fifo.GetEvictions())
+	suite.Equal([]interface{}{2, 3}, dropped)
+	for _, expected := range []int{0, 1} {
+		value, err := fifo.Dequeue()
+		suite.NoError(err)
+		suite.Equal(expected, value)
+	}
+}
+
+// block: Enqueue waits until there is a free slot
+func (suite *FixedFIFOTestSuite) TestBoundedFIFOBlock() {
+	fifo := NewBoundedFIFO(1, OverflowPolicyBlock)
+	suite.NoError(fifo.Enqueue(1))
+
+	done := make(chan error, 1)
+	go func() {
+		done <- fifo.Enqueue(2)
+	}()
+
+	select {
+	case <-done:
+		suite.FailNow("Enqueue must wait for a free slot")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	value, err := fifo.Dequeue()
+	suite.NoError(err)
+	suite.Equal(1, value)
+
+	select {
+	case err := <-done:
+		suite.NoError(err)
+	case <-time.After(2 * time.Second):
+		suite.FailNow("too much time waiting for the blocked Enqueue")
+	}
+	suite.Equal(1, fifo.GetLen())
+}
+
+// block: locked queue doesn't wait
+func (suite *FixedFIFOTestSuite) TestBoundedFIFOBlockLockedQueue() {
+	fifo := NewBoundedFIFO(1, OverflowPolicyBlock)
+	fifo.Lock()
+
+	suite.Equal(ErrLockedQueue, fifo.Enqueue(1))
+}
+
+// the policy changes on a live queue
+func (suite *FixedFIFOTestSuite) TestSetOverflowPolicy() {
+	fifo := NewBoundedFIFO(1, OverflowPolicyReject)
+	fifo.Enqueue(1)
+	suite.Equal(ErrFullCapacity, fifo.Enqueue(2))
+
+	fifo.SetOverflowPolicy(OverflowPolicyDropOldest)
+	suite.Equal(OverflowPolicyDropOldest, fifo.GetOverflowPolicy())
+	suite.NoError(fifo.Enqueue(2))
+
+	value, err := fifo.Dequeue()
+	suite.NoError(err)
+	suite.Equal(2, value)
+}
+
+// producers blocked at full capacity follow the new policy
+func (suite *FixedFIFOTestSuite) TestSetOverflowPolicyBlockedProducers() {
+	fifo := NewBoundedFIFO(1, OverflowPolicyBlock)
+	fifo.Enqueue(1)
+
+	var (
+		enqueueDone = make(chan error, 1)
+		ctxDone     = make(chan error, 1)
+	)
+	go func() {
+		enqueueDone <- fifo.Enqueue(2)
+	}()
+	go func() {
+		ctxDone <- fifo.EnqueueWithContext(context.Background(), 3)
+	}()
+	time.Sleep(10 * time.Millisecond)
+
+	fifo.SetOverflowPolicy(OverflowPolicyReject)
+
+	// Enqueue stops waiting
+	select {
+	case err := <-enqueueDone:
+		suite.Equal(ErrFullCapacity, err)
+	case <-time.After(2 * time.Second):
+		suite.FailNow("Enqueue must follow the new policy")
+	}
+
+	// EnqueueWithContext keeps waiting for a free slot
+	select {
+	case <-ctxDone:
+		suite.FailNow("EnqueueWithContext must keep waiting")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	fifo.Dequeue()
+	select {
+	case err := <-ctxDone:
+		suite.NoError(err)
+	case <-time.After(2 * time.Second):
+		suite.FailNow("EnqueueWithContext must get the free slot")
+	}
+}
+
+// ***************************************************************************************
+// ** Sampling admission
+// ***************************************************************************************
+
+// under depth everything gets accepted, above it the given fraction gets rejected
+func (suite *FixedFIFOTestSuite) TestSamplingAdmission() {
+	fifo := NewFixedFIFO(1000)
+	suite.NoError(fifo.SetSamplingAdmission(10, 0.5))
+
+	for i := 0; i < 10; i++ {
+		suite.NoError(fifo.Enqueue(i))
+	}
+
+	sampledOut := 0
+	for i := 0; i < 400; i++ {
+		if err := fifo.Enqueue(i); err != nil {
+			suite.Equal(ErrSampledOut, err)
+			sampledOut++
+		}
+	}
+	// ~200 expected
+	suite.True(sampledOut > 100 && sampledOut < 300, "sampled out: %v", sampledOut)
+	suite.Equal(410-sampledOut, fifo.GetLen())
+	suite.Equal(uint64(sampledOut), fifo.Stats().Rejected)
+}
+
+// deterministic random source
+func (suite *FixedFIFOTestSuite) TestSamplingAdmissionThreshold() {
+	fifo := NewFixedFIFO(10)
+	fifo.samplingRandom = func() float64 { return 0.25 }
+
+	suite.NoError(fifo.SetSamplingAdmission(1, 0.2))
+	suite.NoError(fifo.Enqueue(1))
+	suite.NoError(fifo.Enqueue(2), "0.25 isn't within the rejected 20%")
+
+	suite.NoError(fifo.SetSamplingAdmission(1, 0.3))
+	suite.Equal(ErrSampledOut, fifo.Enqueue(3))
+	suite.Equal(ErrSampledOut, fifo.EnqueueWithContext(context.Background(), 3))
+
+	// disabled
+	suite.NoError(fifo.SetSamplingAdmission(1, 0))
+	suite.NoError(fifo.Enqueue(3))
+}
+
+// waiting consumers get the elements no matter the depth
+func (suite *FixedFIFOTestSuite) TestSamplingAdmissionWaitingConsumer() {
+	fifo := NewFixedFIFO(10)
+	fifo.samplingRandom = func() float64 { return 0 }
+	suite.NoError(fifo.SetSamplingAdmission(0, 1))
+	suite.Equal(ErrSampledOut, fifo.Enqueue(1))
+
+	result := make(chan interface{})
+	go func() {
+		value, _ := fifo.DequeueOrWaitForNextElement()
+		result <- value
+	}()
+	for i := 0; i < 1000 && fifo.Stats().Waiters == 0; i++ {
+		time.Sleep(time.Millisecond)
+	}
+
+	suite.NoError(fifo.Enqueue(2))
+	suite.Equal(2, <-result)
+}
+
+// invalid depth / fraction
+func (suite *FixedFIFOTestSuite) TestSamplingAdmissionInvalidConfig() {
+	for _, config := range []struct {
+		depth    int
+		fraction float64
+	}{{-1, 0.5}, {1, -0.1}, {1, 1.1}} {
+		err := suite.fifo.SetSamplingAdmission(config.depth, config.fraction)
+		suite.Error(err)
+		suite.Equal(QueueErrorCodeInvalidConfig, err.(*QueueError).Code())
+	}
+}
+
 // ***************************************************************************************
 // ** Lock / Unlock / IsLocked
 // ***************************************************************************************
@@ -463,3 +1122,116 @@ func (suite *FixedFIFOTestSuite) TestUnlockSingleGR() {
 	suite.fifo.Unlock()
 	suite.True(suite.fifo.IsLocked() == false, "fifo.isLocked has to be false after fifo.Unlock()")
 }
+
+// waiters registered before Lock keep waiting and get served after Unlock
+func (suite *FixedFIFOTestSuite) TestUnlockWaitersKeepWaiting() {
+	result := make(chan interface{}, 1)
+	go func() {
+		value, _ := suite.fifo.DequeueOrWaitForNextElement()
+		result <- value
+	}()
+	time.Sleep(10 * time.Millisecond)
+
+	suite.fifo.Lock()
+	suite.Equal(ErrLockedQueue, suite.fifo.Enqueue(testValue))
+	suite.fifo.Unlock()
+	suite.NoError(suite.fifo.Enqueue(testValue))
+
+	select {
+	case value := <-result:
+		suite.Equal(testValue, value)
+	case <-time.After(2 * time.Second):
+		suite.FailNow("too much time waiting for the enqueued element")
+	}
+}
+
+// backlog accumulated while locked is handed over to the waiters, in order, on Unlock
+func (suite *FixedFIFOTestSuite) TestUnlockServesWaitersFromBacklog() {
+	var (
+		totalWaiters = 3
+		results      = make([]chan interface{}, totalWaiters)
+	)
+
+	for i := 0; i < totalWaiters; i++ {
+		results[i] = make(chan interface{}, 1)
+		go func(result chan interface{}) {
+			value, _ := suite.fifo.DequeueOrWaitForNextElement()
+			result <- value
+		}(results[i])
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	suite.fifo.Lock()
+	// elements enqueued while locked (bypassing the listeners)
+	for i := 0; i < totalWaiters+1; i++ {
+		suite.fifo.queue <- fixedFIFOElement{value: i}
+	}
+	suite.fifo.Unlock()
+
+	for i := 0; i < totalWaiters; i++ {
+		select {
+		case value := <-results[i]:
+			suite.Equal(i, value)
+		case <-time.After(2 * time.Second):
+			suite.FailNow("too much time waiting for the backlog")
+		}
+	}
+	suite.Equal(1, suite.fifo.GetLen())
+}
+
+// ***************************************************************************************
+// ** Clear
+// ***************************************************************************************
+
+// every element gets removed
+func (suite *FixedFIFOTestSuite) TestClear() {
+	for i := 0; i < fixedFIFOQueueCapacity; i++ {
+		suite.fifo.Enqueue(i)
+	}
+
+	suite.NoError(suite.fifo.Clear())
+	suite.Equal(0, suite.fifo.GetLen())
+
+	suite.fifo.Lock()
+	suite.Equal(ErrLockedQueue, suite.fifo.Clear())
+}
+
+// every element gets returned, in order, no matter whether the queue is locked
+func (suite *FixedFIFOTestSuite) TestDrain() {
+	for i := 0; i < fixedFIFOQueueCapacity; i++ {
+		suite.fifo.Enqueue(i)
+	}
+	suite.fifo.Lock()
+
+	elements := suite.fifo.Drain()
+	suite.Len(elements, fixedFIFOQueueCapacity)
+	for i, value := range elements {
+		suite.Equal(i, value)
+	}
+	suite.Equal(0, suite.fifo.GetLen())
+	suite.Equal([]interface{}{}, suite.fifo.Drain())
+}
+
+// a producer blocked at full capacity gets a free slot
+func (suite *FixedFIFOTestSuite) TestClearBlockedProducer() {
+	fifo := NewBoundedFIFO(1, OverflowPolicyBlock)
+	suite.NoError(fifo.Enqueue(1))
+
+	done := make(chan error, 1)
+	go func() {
+		done <- fifo.Enqueue(2)
+	}()
+	time.Sleep(10 * time.Millisecond)
+
+	suite.NoError(fifo.Clear())
+
+	select {
+	case err := <-done:
+		suite.NoError(err)
+	case <-time.After(2 * time.Second):
+		suite.FailNow("the producer must get the free slot")
+	}
+	value, err := fifo.Dequeue()
+	suite.NoError(err)
+	suite.Equal(2, value)
+}
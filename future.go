@@ -0,0 +1,152 @@
+package goconcurrentqueue
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// Future is an element enqueued by EnqueueWithFuture (FIFO, FixedFIFO): the consumers dequeue the *Future itself
+// (Value returns the enqueued value) while the producer keeps it to know when the element got dequeued and, once the
+// consumer sets it (SetResult), to get its result (Wait): request / response over a queue.
+// Elements dropped by a FixedFIFO's overflow policy (drop-oldest / drop-newest) fail their futures: Wait returns
+// ErrFullCapacity, Dequeued never gets closed. Futures of elements that never get dequeued otherwise (i.e. cleared)
+// are never resolved.
+type Future struct {
+	value interface{}
+	// closed once the element gets dequeued
+	dequeuedChan chan struct{}
+	dequeuedOnce sync.Once
+	// closed once the result gets set, protecting result and err from then on
+	resultChan chan struct{}
+	resultOnce sync.Once
+	result     interface{}
+	err        error
+}
+
+// newFuture returns a new Future for the given value
+func newFuture(value interface{}) *Future {
+	return &Future{
+		value:        value,
+		dequeuedChan: make(chan struct{}),
+		resultChan:   make(chan struct{}),
+	}
+}
+
+// Value returns the enqueued value
+func (st *Future) Value() interface{} {
+	return st.value
+}
+
+// Dequeued returns a channel closed once the element gets dequeued (or handed over to a waiting consumer)
+func (st *Future) Dequeued() <-chan struct{} {
+	return st.dequeuedChan
+}
+
+// IsDequeued returns true whether the element got dequeued
+func (st *Future) IsDequeued() bool {
+	select {
+	case <-st.dequeuedChan:
+		return true
+	default:
+		return false
+	}
+}
+
+// SetResult sets the element's result and its error, waking up the Wait callers. The element counts as dequeued from
+// then on. Returns error if the result was already set.
+func (st *Future) SetResult(value interface{}, err error) error {
+	set := false
+	st.resultOnce.Do(func() {
+		st.result = value
+		st.err = err
+		set = true
+		close(st.resultChan)
+	})
+	if !set {
+		return NewQueueError(QueueErrorCodeResultAlreadySet, "the future's result was already set")
+	}
+	st.resolveDequeued()
+
+	return nil
+}
+
+// Done returns a channel closed once the result gets set (SetResult)
+func (st *Future) Done() <-chan struct{} {
+	return st.resultChan
+}
+
+// Wait waits until the consumer sets the result (SetResult) and returns it along with its error. Returns ctx.Err() if
+// the context gets done first.
+func (st *Future) Wait(ctx context.Context) (interface{}, error) {
+	select {
+	case <-st.resultChan:
+		return st.result, st.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// SetResult sets the result of an element enqueued by EnqueueWithFuture: handle is the dequeued element (the *Future),
+// so the consumers don't need to know about the Future type. See Future.SetResult. Returns error if handle isn't a
+// *Future or its result was already set.
+func SetResult(handle interface{}, value interface{}, err error) error {
+	future, ok := handle.(*Future)
+	if !ok {
+		return NewQueueError(QueueErrorCodeInvalidElementType, fmt.Sprintf("the handle is not a *Future: %T", handle))
+	}
+
+	return future.SetResult(value, err)
+}
+
+// resolveDequeued closes the dequeued channel (once)
+func (st *Future) resolveDequeued() {
+	st.dequeuedOnce.Do(func() {
+		close(st.dequeuedChan)
+	})
+}
+
+// fail sets the error as the result of an element that won't be dequeued (dropped), the dequeued channel stays open
+func (st *Future) fail(err error) {
+	st.resultOnce.Do(func() {
+		st.err = err
+		close(st.resultChan)
+	})
+}
+
+// failDroppedFuture fails value if it is a *Future, invoked by the queues on every dropped element
+func failDroppedFuture(value interface{}) {
+	if future, ok := value.(*Future); ok {
+		future.fail(ErrFullCapacity)
+	}
+}
+
+// resolveDequeuedFuture resolves value if it is a *Future, invoked by the queues on every dequeued element
+func resolveDequeuedFuture(value interface{}) {
+	if future, ok := value.(*Future); ok {
+		future.resolveDequeued()
+	}
+}
+
+// EnqueueWithFuture enqueues value wrapped into a Future (the element the consumers get) and returns it, so the
+// producer could learn when the element gets dequeued. Returns error if queue is locked.
+func (st *FIFO) EnqueueWithFuture(value interface{}) (*Future, error) {
+	future := newFuture(value)
+	if err := st.Enqueue(future); err != nil {
+		return nil, err
+	}
+
+	return future, nil
+}
+
+// EnqueueWithFuture enqueues value wrapped into a Future (the element the consumers get) and returns it, so the
+// producer could learn when the element gets dequeued. Returns error if queue is locked or it is at full capacity
+// (see Enqueue). The future fails if the overflow policy drops the element.
+func (st *FixedFIFO) EnqueueWithFuture(value interface{}) (*Future, error) {
+	future := newFuture(value)
+	if err := st.Enqueue(future); err != nil {
+		return nil, err
+	}
+
+	return future, nil
+}
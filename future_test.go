@@ -0,0 +1,243 @@
+package goconcurrentqueue
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type FutureTestSuite struct {
+	suite.Suite
+}
+
+// the consumers get the future, resolved once dequeued
+func (suite *FutureTestSuite) TestFIFO() {
+	fifo := NewFIFO()
+
+	future, err := fifo.EnqueueWithFuture(1)
+	suite.Require().NoError(err)
+	suite.Equal(1, future.Value())
+	suite.False(future.IsDequeued())
+
+	value, err := fifo.Dequeue()
+	suite.NoError(err)
+	suite.Equal(future, value)
+	suite.True(future.IsDequeued())
+	<-future.Dequeued()
+}
+
+// batch dequeues, drains and claims resolve the futures too
+func (suite *FutureTestSuite) TestFIFODequeuePaths() {
+	var (
+		fifo    = NewFIFO()
+		futures = make([]*Future, 4)
+	)
+	for i := range futures {
+		futures[i], _ = fifo.EnqueueWithFuture(i)
+	}
+
+	fifo.DequeueUpTo(1)
+	suite.True(futures[0].IsDequeued())
+	suite.False(futures[1].IsDequeued())
+
+	claim, err := fifo.Claim(0)
+	suite.Require().NoError(err)
+	suite.False(futures[1].IsDequeued(), "claimed elements are still at the queue")
+	suite.NoError(claim.Remove())
+	suite.True(futures[1].IsDequeued())
+
+	fifo.Drain()
+	suite.True(futures[2].IsDequeued())
+	suite.True(futures[3].IsDequeued())
+}
+
+// elements handed over to waiting consumers
+func (suite *FutureTestSuite) TestFIFOWaitingConsumer() {
+	var (
+		fifo   = NewFIFO()
+		result = make(chan interface{})
+	)
+
+	go func() {
+		value, _ := fifo.DequeueOrWaitForNextElement()
+		result <- value
+	}()
+	for i := 0; i < 1000 && fifo.Stats().Waiters == 0; i++ {
+		time.Sleep(time.Millisecond)
+	}
+
+	future, err := fifo.EnqueueWithFuture(1)
+	suite.Require().NoError(err)
+	suite.Equal(future, <-result)
+	suite.True(future.IsDequeued())
+}
+
+// locked queue: no future
+func (suite *FutureTestSuite) TestFIFOLockedQueue() {
+	fifo := NewFIFO()
+	fifo.Lock()
+
+	future, err := fifo.EnqueueWithFuture(1)
+	suite.Equal(ErrLockedQueue, err)
+	suite.Nil(future)
+}
+
+// FixedFIFO: dequeue paths and full capacity
+func (suite *FutureTestSuite) TestFixedFIFO() {
+	fifo := NewFixedFIFO(2)
+
+	first, err := fifo.EnqueueWithFuture(1)
+	suite.Require().NoError(err)
+	second, err := fifo.EnqueueWithFuture(2)
+	suite.Require().NoError(err)
+	_, err = fifo.EnqueueWithFuture(3)
+	suite.Equal(ErrFullCapacity, err)
+
+	value, err := fifo.Dequeue()
+	suite.NoError(err)
+	suite.Equal(first, value)
+	suite.True(first.IsDequeued())
+	suite.False(second.IsDequeued())
+
+	fifo.Drain()
+	suite.True(second.IsDequeued())
+}
+
+// FixedFIFO: elements dropped by the overflow policy fail their futures
+func (suite *FutureTestSuite) TestFixedFIFODropped() {
+	for _, policy := range []OverflowPolicy{OverflowPolicyDropNewest, OverflowPolicyDropOldest} {
+		var (
+			fifo       = NewBoundedFIFO(1, policy)
+			first, _   = fifo.EnqueueWithFuture(1)
+			second, _  = fifo.EnqueueWithFuture(2)
+			dropped    = second
+			ctx, stop  = context.WithTimeout(context.Background(), 2*time.Second)
+			kept, kerr = fifo.Dequeue()
+		)
+		if policy == OverflowPolicyDropOldest {
+			dropped = first
+		}
+
+		_, err := dropped.Wait(ctx)
+		stop()
+		suite.Equal(ErrFullCapacity, err, policy.String())
+		suite.False(dropped.IsDequeued(), policy.String())
+		suite.NoError(kerr)
+		suite.True(kept.(*Future).IsDequeued(), policy.String())
+	}
+}
+
+// prefetched elements get delivered once the Prefetcher returns them, the ones returned by Close aren't
+func (suite *FutureTestSuite) TestPrefetcher() {
+	var (
+		fifo       = NewFIFO()
+		first, _   = fifo.EnqueueWithFuture(1)
+		second, _  = fifo.EnqueueWithFuture(2)
+		prefetcher = NewPrefetcher(fifo, 2)
+	)
+
+	value, err := prefetcher.Dequeue()
+	suite.NoError(err)
+	suite.Equal(first, value)
+	suite.True(first.IsDequeued())
+	suite.False(second.IsDequeued(), "prefetched, not delivered")
+
+	prefetcher.Close()
+	suite.False(second.IsDequeued())
+	value, err = fifo.Dequeue()
+	suite.NoError(err)
+	suite.Equal(second, value)
+	suite.True(second.IsDequeued())
+}
+
+// ToChannel: the element not delivered through the channel goes back to the queue unresolved
+func (suite *FutureTestSuite) TestToChannel() {
+	var (
+		fifo        = NewFIFO()
+		ctx, cancel = context.WithCancel(context.Background())
+		ch          = fifo.ToChannel(ctx, 0)
+		futures     = make([]*Future, 3)
+	)
+	for i := range futures {
+		futures[i], _ = fifo.EnqueueWithFuture(i)
+	}
+
+	suite.Equal(futures[0], <-ch)
+	suite.True(futures[0].IsDequeued())
+
+	cancel()
+	for i := 0; i < 1000 && fifo.GetLen() < 2; i++ {
+		// the pump's element is on its way back
+		time.Sleep(time.Millisecond)
+	}
+	_, ok := <-ch
+	suite.False(ok)
+	suite.Equal(2, fifo.GetLen())
+	suite.False(futures[1].IsDequeued())
+	suite.False(futures[2].IsDequeued())
+}
+
+// request / response: the consumer sets the result the producer waits for
+func (suite *FutureTestSuite) TestSetResultWait() {
+	fifo := NewFIFO()
+
+	go func() {
+		handle, err := fifo.DequeueOrWaitForNextElement()
+		suite.NoError(err)
+		suite.NoError(SetResult(handle, handle.(*Future).Value().(int)*2, nil))
+	}()
+
+	future, err := fifo.EnqueueWithFuture(21)
+	suite.Require().NoError(err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	result, err := future.Wait(ctx)
+	suite.NoError(err)
+	suite.Equal(42, result)
+	suite.True(future.IsDequeued())
+	<-future.Done()
+}
+
+// the consumer's error gets propagated, the result can't be set twice
+func (suite *FutureTestSuite) TestSetResultError() {
+	var (
+		fifo        = NewFIFO()
+		errConsumer = errors.New("consumer failed")
+	)
+	future, _ := fifo.EnqueueWithFuture(1)
+	handle, _ := fifo.Dequeue()
+
+	suite.NoError(SetResult(handle, nil, errConsumer))
+	err := SetResult(handle, 1, nil)
+	suite.Error(err)
+	suite.Equal(QueueErrorCodeResultAlreadySet, err.(*QueueError).Code())
+
+	result, err := future.Wait(context.Background())
+	suite.Equal(errConsumer, err)
+	suite.Nil(result)
+}
+
+// invalid handle
+func (suite *FutureTestSuite) TestSetResultInvalidHandle() {
+	err := SetResult(1, 1, nil)
+	suite.Error(err)
+	suite.Equal(QueueErrorCodeInvalidElementType, err.(*QueueError).Code())
+}
+
+// context done before the result gets set
+func (suite *FutureTestSuite) TestWaitContext() {
+	future, _ := NewFIFO().EnqueueWithFuture(1)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err := future.Wait(ctx)
+	suite.Equal(context.DeadlineExceeded, err)
+}
+
+func TestFutureTestSuite(t *testing.T) {
+	suite.Run(t, new(FutureTestSuite))
+}
@@ -0,0 +1,167 @@
+package goconcurrentqueue
+
+import (
+	"context"
+	"sync"
+)
+
+// GateMarker is a marker enqueued into a Gate's control queue: it lets Count more elements of the gated queue through.
+type GateMarker struct {
+	Count int
+}
+
+// Gate releases the elements of a gated queue (i.e. stage 2 input) only after the corresponding markers appear at a
+// control queue (i.e. stage 1 signals), barrier / fork-join style: stage 2 doesn't start on a batch before stage 1
+// completes it.
+//
+// Every element found at the control queue is a permit to dequeue elements from the gated queue: a GateMarker lets
+// GateMarker.Count elements through, any other value lets 1 element through (counting semantic). Permits don't expire,
+// they wait for the gated elements to show up. Permits could also be granted directly, see Open.
+//
+// Gate is a Queue itself: Enqueue goes into the gated queue, Dequeue only returns gated elements having a permit.
+type Gate struct {
+	control Queue
+	gated   Queue
+	// elements allowed to go through
+	permits int
+	// serializes the permits' refills and consumption
+	mutex       sync.Mutex
+	lockRWmutex sync.RWMutex
+	isLocked    bool
+}
+
+// NewGate returns a new Gate releasing the gated queue's elements as markers appear at the control queue
+func NewGate(control Queue, gated Queue) *Gate {
+	ret := &Gate{}
+	ret.initialize(control, gated)
+
+	return ret
+}
+
+func (st *Gate) initialize(control Queue, gated Queue) {
+	st.control = control
+	st.gated = gated
+}
+
+// Open lets n more elements through, no matter the control queue
+func (st *Gate) Open(n int) {
+	if n < 1 {
+		return
+	}
+
+	st.mutex.Lock()
+	defer st.mutex.Unlock()
+
+	st.permits += n
+}
+
+// GetPermits returns the number of elements allowed to go through, not counting the markers still at the control
+// queue
+func (st *Gate) GetPermits() int {
+	st.mutex.Lock()
+	defer st.mutex.Unlock()
+
+	return st.permits
+}
+
+// Enqueue enqueues an element into the gated queue. Returns error if queue is locked or the gated queue rejects the
+// element.
+func (st *Gate) Enqueue(value interface{}) error {
+	if st.IsLocked() {
+		return ErrLockedQueue
+	}
+
+	return st.gated.Enqueue(value)
+}
+
+// Dequeue dequeues an element from the gated queue if there is a permit for it. Returns error if queue is locked, the
+// gated queue is empty or the gate is closed (no permits, no markers).
+func (st *Gate) Dequeue() (interface{}, error) {
+	if st.IsLocked() {
+		return nil, ErrLockedQueue
+	}
+
+	st.mutex.Lock()
+	defer st.mutex.Unlock()
+
+	if st.permits == 0 {
+		st.collectMarkers()
+		if st.permits == 0 {
+			return nil, ErrEmptyQueue
+		}
+	}
+
+	value, err := st.gated.Dequeue()
+	if err != nil {
+		return nil, err
+	}
+	st.permits--
+
+	return value, nil
+}
+
+// collectMarkers turns the markers found at the control queue into permits, until there is at least one.
+// st.mutex must be locked by the caller.
+func (st *Gate) collectMarkers() {
+	for st.permits == 0 {
+		value, err := st.control.Dequeue()
+		if err != nil {
+			return
+		}
+
+		if marker, ok := value.(GateMarker); ok {
+			if marker.Count > 0 {
+				st.permits += marker.Count
+			}
+			continue
+		}
+		st.permits++
+	}
+}
+
+// DequeueOrWaitForNextElement dequeues an element (if exist and there is a permit for it) or waits until there is one
+// and returns it. The queues are polled (yielding first, then sleeping a bit between polls).
+func (st *Gate) DequeueOrWaitForNextElement() (interface{}, error) {
+	return st.DequeueOrWaitForNextElementWithContext(context.Background())
+}
+
+// DequeueOrWaitForNextElementWithContext works as DequeueOrWaitForNextElement, but it stops waiting once the context
+// is done, returning ctx.Err().
+func (st *Gate) DequeueOrWaitForNextElementWithContext(ctx context.Context) (interface{}, error) {
+	return pollForNextElement(ctx, st.Dequeue)
+}
+
+// GetLen returns the number of gated elements (released or not)
+func (st *Gate) GetLen() int {
+	return st.gated.GetLen()
+}
+
+// GetCap returns the gated queue's capacity
+func (st *Gate) GetCap() int {
+	return st.gated.GetCap()
+}
+
+// Lock // Locks the gate (the underlying queues remain unlocked). No enqueue/dequeue operations will be allowed
+// through the gate after this point.
+func (st *Gate) Lock() {
+	st.lockRWmutex.Lock()
+	defer st.lockRWmutex.Unlock()
+
+	st.isLocked = true
+}
+
+// Unlock unlocks the gate
+func (st *Gate) Unlock() {
+	st.lockRWmutex.Lock()
+	defer st.lockRWmutex.Unlock()
+
+	st.isLocked = false
+}
+
+// IsLocked returns true whether the gate is locked
+func (st *Gate) IsLocked() bool {
+	st.lockRWmutex.RLock()
+	defer st.lockRWmutex.RUnlock()
+
+	return st.isLocked
+}
@@ -0,0 +1,183 @@
+package goconcurrentqueue
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type GateTestSuite struct {
+	suite.Suite
+	control *FIFO
+	gated   *FIFO
+	gate    *Gate
+}
+
+func (suite *GateTestSuite) SetupTest() {
+	suite.control = NewFIFO()
+	suite.gated = NewFIFO()
+	suite.gate = NewGate(suite.control, suite.gated)
+}
+
+// ***************************************************************************************
+// ** Initialization
+// ***************************************************************************************
+
+// no elements, no permits at initialization
+func (suite *GateTestSuite) TestNoElementsAtInitialization() {
+	suite.Equal(0, suite.gate.GetLen())
+	suite.Equal(0, suite.gate.GetPermits())
+	suite.False(suite.gate.IsLocked())
+
+	var queue Queue = suite.gate
+	suite.NotNil(queue)
+}
+
+// ***************************************************************************************
+// ** Dequeue
+// ***************************************************************************************
+
+// closed gate: the elements stay at the gated queue
+func (suite *GateTestSuite) TestDequeueClosedGate() {
+	suite.NoError(suite.gate.Enqueue(1))
+
+	_, err := suite.gate.Dequeue()
+	suite.Equal(ErrEmptyQueue, err)
+	suite.Equal(1, suite.gate.GetLen())
+}
+
+// a marker lets its count of elements through
+func (suite *GateTestSuite) TestDequeueMarker() {
+	for i := 0; i < 5; i++ {
+		suite.gate.Enqueue(i)
+	}
+	suite.control.Enqueue(GateMarker{Count: 3})
+
+	for i := 0; i < 3; i++ {
+		value, err := suite.gate.Dequeue()
+		suite.NoError(err)
+		suite.Equal(i, value)
+	}
+	_, err := suite.gate.Dequeue()
+	suite.Equal(ErrEmptyQueue, err, "the gate must close once the marker's count is consumed")
+	suite.Equal(2, suite.gate.GetLen())
+}
+
+// any other control element lets 1 element through
+func (suite *GateTestSuite) TestDequeueCountingControlElements() {
+	for i := 0; i < 3; i++ {
+		suite.gate.Enqueue(i)
+	}
+	suite.control.Enqueue("stage 1 done")
+	suite.control.Enqueue("stage 1 done")
+
+	elements := make([]interface{}, 0)
+	for {
+		value, err := suite.gate.Dequeue()
+		if err != nil {
+			break
+		}
+		elements = append(elements, value)
+	}
+	suite.Equal([]interface{}{0, 1}, elements)
+	suite.Equal(0, suite.control.GetLen())
+}
+
+// permits wait for the gated elements to show up
+func (suite *GateTestSuite) TestPermitsBeforeElements() {
+	suite.control.Enqueue(GateMarker{Count: 2})
+
+	_, err := suite.gate.Dequeue()
+	suite.Equal(ErrEmptyQueue, err)
+	suite.Equal(2, suite.gate.GetPermits())
+
+	suite.gate.Enqueue(1)
+	value, err := suite.gate.Dequeue()
+	suite.NoError(err)
+	suite.Equal(1, value)
+	suite.Equal(1, suite.gate.GetPermits())
+}
+
+// Open grants permits directly
+func (suite *GateTestSuite) TestOpen() {
+	suite.gate.Enqueue(1)
+	suite.gate.Open(1)
+	suite.gate.Open(-1)
+	suite.Equal(1, suite.gate.GetPermits())
+
+	value, err := suite.gate.Dequeue()
+	suite.NoError(err)
+	suite.Equal(1, value)
+}
+
+// locked gate
+func (suite *GateTestSuite) TestLock() {
+	suite.gate.Open(1)
+	suite.gate.Lock()
+	suite.True(suite.gate.IsLocked())
+
+	suite.Equal(ErrLockedQueue, suite.gate.Enqueue(1))
+	_, err := suite.gate.Dequeue()
+	suite.Equal(ErrLockedQueue, err)
+	_, err = suite.gate.DequeueOrWaitForNextElement()
+	suite.Equal(ErrLockedQueue, err)
+
+	suite.gate.Unlock()
+	suite.NoError(suite.gate.Enqueue(1))
+}
+
+// ***************************************************************************************
+// ** DequeueOrWaitForNextElement
+// ***************************************************************************************
+
+// stage 2 waits until stage 1 completes the batch
+func (suite *GateTestSuite) TestDequeueOrWaitForNextElementStages() {
+	var (
+		batch = 10
+		wg    sync.WaitGroup
+	)
+
+	// stage 1
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < batch; i++ {
+			suite.gate.Enqueue(i)
+		}
+		time.Sleep(10 * time.Millisecond)
+		suite.control.Enqueue(GateMarker{Count: batch})
+	}()
+
+	for i := 0; i < batch; i++ {
+		value, err := suite.gate.DequeueOrWaitForNextElement()
+		suite.NoError(err)
+		suite.Equal(i, value)
+		if i == 0 {
+			// the whole batch was there before the first element got released
+			suite.Equal(batch-1, suite.gate.GetLen())
+		}
+	}
+	wg.Wait()
+}
+
+// context done while waiting for a permit
+func (suite *GateTestSuite) TestDequeueOrWaitForNextElementWithContext() {
+	suite.gate.Enqueue(1)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err := suite.gate.DequeueOrWaitForNextElementWithContext(ctx)
+	suite.Equal(context.DeadlineExceeded, err)
+	suite.Equal(1, suite.gate.GetLen())
+}
+
+// ***************************************************************************************
+// ** Run suite
+// ***************************************************************************************
+
+func TestGateTestSuite(t *testing.T) {
+	suite.Run(t, new(GateTestSuite))
+}
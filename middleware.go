@@ -0,0 +1,125 @@
+package goconcurrentqueue
+
+import (
+	"context"
+)
+
+// EnqueueFunc enqueues an element, wait means EnqueueWithContext (Enqueue otherwise). ctx is the EnqueueWithContext
+// caller's context, context.Background() for Enqueue. See QueueMiddleware.
+type EnqueueFunc func(ctx context.Context, value interface{}, wait bool) error
+
+// DequeueFunc dequeues an element, wait means DequeueOrWaitForNextElement (Dequeue otherwise). ctx is the
+// DequeueOrWaitForNextElementWithContext caller's context, context.Background() for the rest. See QueueMiddleware.
+type DequeueFunc func(ctx context.Context, wait bool) (interface{}, error)
+
+// QueueMiddleware wraps a queue's enqueue / dequeue operations, so cross-cutting concerns (logging, metrics,
+// validation, tracing) compose through Chain instead of each one being a bespoke Queue wrapper.
+// A middleware could change the element, return an error without calling next (i.e. validation) or act on next's
+// results. The operations get the caller's context, so request-scoped values (tenant, trace) could be read.
+type QueueMiddleware interface {
+	// WrapEnqueue returns the enqueue operation wrapping next
+	WrapEnqueue(next EnqueueFunc) EnqueueFunc
+	// WrapDequeue returns the dequeue operation wrapping next
+	WrapDequeue(next DequeueFunc) DequeueFunc
+}
+
+// MiddlewareFuncs builds a QueueMiddleware out of functions, nil ones leave the operation as it is
+type MiddlewareFuncs struct {
+	Enqueue func(next EnqueueFunc) EnqueueFunc
+	Dequeue func(next DequeueFunc) DequeueFunc
+}
+
+// WrapEnqueue returns the enqueue operation wrapping next
+func (st MiddlewareFuncs) WrapEnqueue(next EnqueueFunc) EnqueueFunc {
+	if st.Enqueue == nil {
+		return next
+	}
+
+	return st.Enqueue(next)
+}
+
+// WrapDequeue returns the dequeue operation wrapping next
+func (st MiddlewareFuncs) WrapDequeue(next DequeueFunc) DequeueFunc {
+	if st.Dequeue == nil {
+		return next
+	}
+
+	return st.Dequeue(next)
+}
+
+// chainedQueue is a queue whose enqueues / dequeues go through middlewares, see Chain
+type chainedQueue struct {
+	Queue
+	enqueue EnqueueFunc
+	dequeue DequeueFunc
+}
+
+// Chain returns queue with its Enqueue, Dequeue and DequeueOrWaitForNextElement operations (and their WithContext
+// variants) going through the given middlewares: mws[0] is the outermost one (the first to get the call and the last
+// to get the result). The rest of the operations (GetLen, Lock, ...) go straight to queue.
+// The WithContext variants call the queue's ones if it has them (i.e. FixedFIFO.EnqueueWithContext), its plain
+// operations otherwise.
+func Chain(queue Queue, mws ...QueueMiddleware) Queue {
+	ret := &chainedQueue{
+		Queue: queue,
+		enqueue: func(ctx context.Context, value interface{}, wait bool) error {
+			if wait {
+				if contextQueue, ok := queue.(interface {
+					EnqueueWithContext(ctx context.Context, value interface{}) error
+				}); ok {
+					return contextQueue.EnqueueWithContext(ctx, value)
+				}
+			}
+
+			return queue.Enqueue(value)
+		},
+		dequeue: func(ctx context.Context, wait bool) (interface{}, error) {
+			if !wait {
+				return queue.Dequeue()
+			}
+
+			if contextQueue, ok := queue.(interface {
+				DequeueOrWaitForNextElementWithContext(ctx context.Context) (interface{}, error)
+			}); ok {
+				return contextQueue.DequeueOrWaitForNextElementWithContext(ctx)
+			}
+
+			return queue.DequeueOrWaitForNextElement()
+		},
+	}
+
+	for i := len(mws) - 1; i >= 0; i-- {
+		ret.enqueue = mws[i].WrapEnqueue(ret.enqueue)
+		ret.dequeue = mws[i].WrapDequeue(ret.dequeue)
+	}
+
+	return ret
+}
+
+// Enqueue enqueues an element through the middlewares
+func (st *chainedQueue) Enqueue(value interface{}) error {
+	return st.enqueue(context.Background(), value, false)
+}
+
+// EnqueueWithContext enqueues an element through the middlewares, which get ctx. Queues with no EnqueueWithContext
+// get a plain Enqueue.
+func (st *chainedQueue) EnqueueWithContext(ctx context.Context, value interface{}) error {
+	return st.enqueue(ctx, value, true)
+}
+
+// Dequeue dequeues an element through the middlewares
+func (st *chainedQueue) Dequeue() (interface{}, error) {
+	return st.dequeue(context.Background(), false)
+}
+
+// DequeueOrWaitForNextElement dequeues an element (if exist) or waits until the next element gets enqueued, through
+// the middlewares
+func (st *chainedQueue) DequeueOrWaitForNextElement() (interface{}, error) {
+	return st.dequeue(context.Background(), true)
+}
+
+// DequeueOrWaitForNextElementWithContext works as DequeueOrWaitForNextElement, the middlewares get ctx. Queues with
+// no DequeueOrWaitForNextElementWithContext don't stop waiting once the context is done.
+func (st *chainedQueue) DequeueOrWaitForNextElementWithContext(ctx context.Context) (interface{}, error) {
+	return st.dequeue(ctx, true)
+}
@@ -0,0 +1,144 @@
+package goconcurrentqueue
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type MiddlewareTestSuite struct {
+	suite.Suite
+}
+
+// tracingMiddleware appends "<name>:<operation>" to calls every time it gets invoked
+func tracingMiddleware(name string, calls *[]string) QueueMiddleware {
+	return MiddlewareFuncs{
+		Enqueue: func(next EnqueueFunc) EnqueueFunc {
+			return func(ctx context.Context, value interface{}, wait bool) error {
+				*calls = append(*calls, name+":enqueue")
+				return next(ctx, value, wait)
+			}
+		},
+		Dequeue: func(next DequeueFunc) DequeueFunc {
+			return func(ctx context.Context, wait bool) (interface{}, error) {
+				*calls = append(*calls, fmt.Sprintf("%v:dequeue:%v", name, wait))
+				return next(ctx, wait)
+			}
+		},
+	}
+}
+
+// no middlewares: the queue as it is
+func (suite *MiddlewareTestSuite) TestNoMiddlewares() {
+	fifo := NewFIFO()
+	queue := Chain(fifo)
+
+	suite.NoError(queue.Enqueue(1))
+	suite.Equal(1, queue.GetLen())
+	value, err := queue.Dequeue()
+	suite.NoError(err)
+	suite.Equal(1, value)
+
+	queue.Lock()
+	suite.True(fifo.IsLocked())
+}
+
+// the first middleware is the outermost one
+func (suite *MiddlewareTestSuite) TestOrder() {
+	calls := make([]string, 0)
+	queue := Chain(NewFIFO(), tracingMiddleware("a", &calls), tracingMiddleware("b", &calls))
+
+	queue.Enqueue(1)
+	queue.Dequeue()
+	queue.Enqueue(2)
+	queue.DequeueOrWaitForNextElement()
+
+	suite.Equal([]string{
+		"a:enqueue", "b:enqueue",
+		"a:dequeue:false", "b:dequeue:false",
+		"a:enqueue", "b:enqueue",
+		"a:dequeue:true", "b:dequeue:true",
+	}, calls)
+}
+
+// validation: rejected elements never reach the queue
+func (suite *MiddlewareTestSuite) TestValidation() {
+	var (
+		fifo       = NewFIFO()
+		errInvalid = NewQueueError(QueueErrorCodeInvalidElementType, "int elements only")
+		validation = MiddlewareFuncs{
+			Enqueue: func(next EnqueueFunc) EnqueueFunc {
+				return func(ctx context.Context, value interface{}, wait bool) error {
+					if _, ok := value.(int); !ok {
+						return errInvalid
+					}
+					return next(ctx, value, wait)
+				}
+			},
+		}
+		queue = Chain(fifo, validation)
+	)
+
+	suite.Equal(errInvalid, queue.Enqueue("1"))
+	suite.NoError(queue.Enqueue(1))
+	suite.Equal(1, fifo.GetLen())
+
+	// nil Dequeue: untouched
+	value, err := queue.Dequeue()
+	suite.NoError(err)
+	suite.Equal(1, value)
+	_, err = queue.Dequeue()
+	suite.Equal(ErrEmptyQueue, err)
+}
+
+// tenantKey is the context key the tests' request-scoped values are stored at
+type tenantKey struct{}
+
+// the WithContext variants pass the caller's context to the middlewares and to the queue
+func (suite *MiddlewareTestSuite) TestContext() {
+	var (
+		tenants = make([]interface{}, 0)
+		tenant  = MiddlewareFuncs{
+			Enqueue: func(next EnqueueFunc) EnqueueFunc {
+				return func(ctx context.Context, value interface{}, wait bool) error {
+					tenants = append(tenants, ctx.Value(tenantKey{}))
+					return next(ctx, value, wait)
+				}
+			},
+			Dequeue: func(next DequeueFunc) DequeueFunc {
+				return func(ctx context.Context, wait bool) (interface{}, error) {
+					tenants = append(tenants, ctx.Value(tenantKey{}))
+					return next(ctx, wait)
+				}
+			},
+		}
+		fixedFIFO = NewFixedFIFO(1)
+		queue     = Chain(fixedFIFO, tenant).(interface {
+			Queue
+			EnqueueWithContext(ctx context.Context, value interface{}) error
+			DequeueOrWaitForNextElementWithContext(ctx context.Context) (interface{}, error)
+		})
+		ctx = context.WithValue(context.Background(), tenantKey{}, "acme")
+	)
+
+	suite.NoError(queue.EnqueueWithContext(ctx, 1))
+	suite.Equal(ErrFullCapacity, queue.Enqueue(2), "the plain Enqueue doesn't wait")
+	value, err := queue.DequeueOrWaitForNextElementWithContext(ctx)
+	suite.NoError(err)
+	suite.Equal(1, value)
+	suite.Equal([]interface{}{"acme", nil, "acme"}, tenants)
+
+	// the queue's WithContext variants get the context
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = queue.DequeueOrWaitForNextElementWithContext(cancelled)
+	suite.Equal(context.Canceled, err)
+	fixedFIFO.Enqueue(3)
+	suite.Equal(context.Canceled, queue.EnqueueWithContext(cancelled, 4))
+}
+
+func TestMiddlewareTestSuite(t *testing.T) {
+	suite.Run(t, new(MiddlewareTestSuite))
+}
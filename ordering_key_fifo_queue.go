@@ -0,0 +1,209 @@
+package goconcurrentqueue
+
+import (
+	"sync"
+)
+
+// orderingKeyElement is an element enqueued into an OrderingKeyFIFO
+type orderingKeyElement struct {
+	key   string
+	value interface{}
+}
+
+// OrderingKeyFIFO (First In First Out) concurrent queue with ordering keys.
+// Elements enqueued with the same ordering key are dequeued in the same order they were enqueued and never
+// concurrently: once an element is dequeued, no other element sharing its key is dequeued until Done(key) gets
+// invoked. Elements with different keys (or with no key) don't wait for each other, so the overall dequeue order is
+// flexible: a key in process lets the following elements (with other keys) go first.
+type OrderingKeyFIFO struct {
+	slice   []orderingKeyElement
+	rwmutex sync.RWMutex
+	// keys being processed (dequeued but not done yet)
+	inProcess   map[string]struct{}
+	lockRWmutex sync.RWMutex
+	isLocked    bool
+	// closed (and replaced) every time an element becomes available, to wake up DequeueOrWaitForNextElement callers
+	availableChan chan struct{}
+}
+
+// NewOrderingKeyFIFO returns a new OrderingKeyFIFO concurrent queue
+func NewOrderingKeyFIFO() *OrderingKeyFIFO {
+	ret := &OrderingKeyFIFO{}
+	ret.initialize()
+
+	return ret
+}
+
+func (st *OrderingKeyFIFO) initialize() {
+	st.slice = make([]orderingKeyElement, 0)
+	st.inProcess = make(map[string]struct{})
+	st.availableChan = make(chan struct{})
+}
+
+// Enqueue enqueues an element with no ordering key. Returns error if queue is locked.
+func (st *OrderingKeyFIFO) Enqueue(value interface{}) error {
+	return st.EnqueueWithOrderingKey("", value)
+}
+
+// EnqueueWithOrderingKey enqueues an element with the given ordering key ("" means no ordering key). Returns error if
+// queue is locked.
+func (st *OrderingKeyFIFO) EnqueueWithOrderingKey(key string, value interface{}) error {
+	if st.IsLocked() {
+		return ErrLockedQueue
+	}
+
+	st.rwmutex.Lock()
+	defer st.rwmutex.Unlock()
+
+	st.slice = append(st.slice, orderingKeyElement{key: key, value: value})
+	st.notifyAvailable()
+
+	return nil
+}
+
+// Dequeue dequeues the first element whose ordering key isn't in process. Returns error if queue is locked or there is
+// no element ready to be dequeued.
+// Done(key) must be invoked once the element is processed, see DequeueWithOrderingKey.
+func (st *OrderingKeyFIFO) Dequeue() (interface{}, error) {
+	value, _, err := st.DequeueWithOrderingKey()
+	return value, err
+}
+
+// DequeueWithOrderingKey dequeues the first element whose ordering key isn't in process and returns it along with its
+// ordering key. Returns error if queue is locked or there is no element ready to be dequeued.
+// The key remains in process (no other element with the same key gets dequeued) until Done(key) gets invoked.
+func (st *OrderingKeyFIFO) DequeueWithOrderingKey() (interface{}, string, error) {
+	if st.IsLocked() {
+		return nil, "", ErrLockedQueue
+	}
+
+	st.rwmutex.Lock()
+	defer st.rwmutex.Unlock()
+
+	element, ok := st.dequeueReady()
+	if !ok {
+		return nil, "", ErrEmptyQueue
+	}
+
+	return element.value, element.key, nil
+}
+
+// DequeueOrWaitForNextElement dequeues the first element whose ordering key isn't in process or waits until there is
+// one (either enqueued or released by Done).
+func (st *OrderingKeyFIFO) DequeueOrWaitForNextElement() (interface{}, error) {
+	value, _, err := st.DequeueWithOrderingKeyOrWaitForNextElement()
+	return value, err
+}
+
+// DequeueWithOrderingKeyOrWaitForNextElement works as DequeueOrWaitForNextElement, also returning the element's
+// ordering key.
+func (st *OrderingKeyFIFO) DequeueWithOrderingKeyOrWaitForNextElement() (interface{}, string, error) {
+	for {
+		if st.IsLocked() {
+			return nil, "", ErrLockedQueue
+		}
+
+		st.rwmutex.Lock()
+		element, ok := st.dequeueReady()
+		availableChan := st.availableChan
+		st.rwmutex.Unlock()
+
+		if ok {
+			return element.value, element.key, nil
+		}
+
+		// wait until an element gets enqueued or a key gets released
+		<-availableChan
+	}
+}
+
+// Done releases the ordering key, so the next element with the same key could be dequeued. Keys not in process are
+// ignored.
+func (st *OrderingKeyFIFO) Done(key string) {
+	st.rwmutex.Lock()
+	defer st.rwmutex.Unlock()
+
+	if _, ok := st.inProcess[key]; !ok {
+		return
+	}
+
+	delete(st.inProcess, key)
+	st.notifyAvailable()
+}
+
+// IsInProcess returns true whether an element with the given ordering key was dequeued and Done(key) wasn't invoked yet
+func (st *OrderingKeyFIFO) IsInProcess(key string) bool {
+	st.rwmutex.RLock()
+	defer st.rwmutex.RUnlock()
+
+	_, ok := st.inProcess[key]
+	return ok
+}
+
+// dequeueReady removes and returns the first element whose ordering key isn't in process, marking its key as in
+// process. st.rwmutex must be locked by the caller.
+func (st *OrderingKeyFIFO) dequeueReady() (orderingKeyElement, bool) {
+	for i := 0; i < len(st.slice); i++ {
+		element := st.slice[i]
+		if element.key != "" {
+			if _, inProcess := st.inProcess[element.key]; inProcess {
+				continue
+			}
+			st.inProcess[element.key] = struct{}{}
+		}
+
+		copy(st.slice[i:], st.slice[i+1:])
+		st.slice[len(st.slice)-1] = orderingKeyElement{}
+		st.slice = st.slice[:len(st.slice)-1]
+
+		return element, true
+	}
+
+	return orderingKeyElement{}, false
+}
+
+// notifyAvailable wakes up the DequeueOrWaitForNextElement callers. st.rwmutex must be locked by the caller.
+func (st *OrderingKeyFIFO) notifyAvailable() {
+	close(st.availableChan)
+	st.availableChan = make(chan struct{})
+}
+
+// GetLen returns the number of enqueued elements
+func (st *OrderingKeyFIFO) GetLen() int {
+	st.rwmutex.RLock()
+	defer st.rwmutex.RUnlock()
+
+	return len(st.slice)
+}
+
+// GetCap returns the queue's capacity
+func (st *OrderingKeyFIFO) GetCap() int {
+	st.rwmutex.RLock()
+	defer st.rwmutex.RUnlock()
+
+	return cap(st.slice)
+}
+
+// Lock // Locks the queue. No enqueue/dequeue operations will be allowed after this point.
+func (st *OrderingKeyFIFO) Lock() {
+	st.lockRWmutex.Lock()
+	defer st.lockRWmutex.Unlock()
+
+	st.isLocked = true
+}
+
+// Unlock unlocks the queue
+func (st *OrderingKeyFIFO) Unlock() {
+	st.lockRWmutex.Lock()
+	defer st.lockRWmutex.Unlock()
+
+	st.isLocked = false
+}
+
+// IsLocked returns true whether the queue is locked
+func (st *OrderingKeyFIFO) IsLocked() bool {
+	st.lockRWmutex.RLock()
+	defer st.lockRWmutex.RUnlock()
+
+	return st.isLocked
+}
@@ -0,0 +1,212 @@
+package goconcurrentqueue
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type OrderingKeyFIFOTestSuite struct {
+	suite.Suite
+	fifo *OrderingKeyFIFO
+}
+
+func (suite *OrderingKeyFIFOTestSuite) SetupTest() {
+	suite.fifo = NewOrderingKeyFIFO()
+}
+
+// ***************************************************************************************
+// ** Queue initialization
+// ***************************************************************************************
+
+// no elements at initialization
+func (suite *OrderingKeyFIFOTestSuite) TestNoElementsAtInitialization() {
+	suite.Equal(0, suite.fifo.GetLen())
+	suite.False(suite.fifo.IsLocked(), "Queue must be unlocked at initialization")
+}
+
+// ***************************************************************************************
+// ** Enqueue && Dequeue
+// ***************************************************************************************
+
+// locked queue
+func (suite *OrderingKeyFIFOTestSuite) TestLockSingleGR() {
+	suite.fifo.Lock()
+	suite.Equal(ErrLockedQueue, suite.fifo.EnqueueWithOrderingKey("a", 1))
+
+	_, err := suite.fifo.Dequeue()
+	suite.Equal(ErrLockedQueue, err)
+
+	_, err = suite.fifo.DequeueOrWaitForNextElement()
+	suite.Equal(ErrLockedQueue, err)
+}
+
+// elements with no ordering key behave as a regular FIFO
+func (suite *OrderingKeyFIFOTestSuite) TestNoOrderingKeySingleGR() {
+	for i := 0; i < 10; i++ {
+		suite.NoError(suite.fifo.Enqueue(i))
+	}
+
+	for i := 0; i < 10; i++ {
+		value, err := suite.fifo.Dequeue()
+		suite.NoError(err)
+		suite.Equal(i, value)
+	}
+
+	_, err := suite.fifo.Dequeue()
+	suite.Equal(ErrEmptyQueue, err)
+}
+
+// an element with a key in process isn't dequeued until Done(key), elements with other keys go first
+func (suite *OrderingKeyFIFOTestSuite) TestOrderingKeyInProcessSingleGR() {
+	suite.fifo.EnqueueWithOrderingKey("a", "a1")
+	suite.fifo.EnqueueWithOrderingKey("a", "a2")
+	suite.fifo.EnqueueWithOrderingKey("b", "b1")
+	suite.fifo.Enqueue("no key")
+
+	value, key, err := suite.fifo.DequeueWithOrderingKey()
+	suite.NoError(err)
+	suite.Equal("a1", value)
+	suite.Equal("a", key)
+	suite.True(suite.fifo.IsInProcess("a"))
+
+	// "a2" must wait for Done("a")
+	value, _ = suite.fifo.Dequeue()
+	suite.Equal("b1", value)
+	value, _ = suite.fifo.Dequeue()
+	suite.Equal("no key", value)
+
+	_, err = suite.fifo.Dequeue()
+	suite.Equal(ErrEmptyQueue, err, "no element should be ready while its key is in process")
+	suite.Equal(1, suite.fifo.GetLen())
+
+	suite.fifo.Done("a")
+	suite.False(suite.fifo.IsInProcess("a"))
+	value, _ = suite.fifo.Dequeue()
+	suite.Equal("a2", value)
+}
+
+// Done over a key not in process does nothing
+func (suite *OrderingKeyFIFOTestSuite) TestDoneUnknownKey() {
+	suite.fifo.Done("unknown")
+	suite.False(suite.fifo.IsInProcess("unknown"))
+}
+
+// ***************************************************************************************
+// ** DequeueOrWaitForNextElement
+// ***************************************************************************************
+
+// a waiter gets woken up by Done when the only enqueued element's key was in process
+func (suite *OrderingKeyFIFOTestSuite) TestDequeueOrWaitForNextElementDone() {
+	suite.fifo.EnqueueWithOrderingKey("a", "a1")
+	suite.fifo.EnqueueWithOrderingKey("a", "a2")
+	suite.fifo.Dequeue()
+
+	result := make(chan interface{}, 1)
+	go func() {
+		value, _ := suite.fifo.DequeueOrWaitForNextElement()
+		result <- value
+	}()
+
+	select {
+	case <-result:
+		suite.FailNow("no element should be dequeued while its key is in process")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	suite.fifo.Done("a")
+	select {
+	case value := <-result:
+		suite.Equal("a2", value)
+	case <-time.After(2 * time.Second):
+		suite.FailNow("too much time waiting for the released element")
+	}
+}
+
+// a waiter gets woken up by the next enqueued element
+func (suite *OrderingKeyFIFOTestSuite) TestDequeueOrWaitForNextElementEnqueue() {
+	result := make(chan interface{}, 1)
+	go func() {
+		value, _ := suite.fifo.DequeueOrWaitForNextElement()
+		result <- value
+	}()
+
+	suite.fifo.EnqueueWithOrderingKey("a", testValue)
+	select {
+	case value := <-result:
+		suite.Equal(testValue, value)
+	case <-time.After(2 * time.Second):
+		suite.FailNow("too much time waiting for the enqueued element")
+	}
+}
+
+// multiple consumers: elements sharing a key are processed in order and never concurrently
+func (suite *OrderingKeyFIFOTestSuite) TestOrderingKeysMultipleGRs() {
+	var (
+		totalKeys        = 10
+		elementsPerKey   = 100
+		totalConsumers   = 8
+		wg               sync.WaitGroup
+		mutex            sync.Mutex
+		processing       = make(map[string]bool)
+		lastSequence     = make(map[string]int)
+		totalDelivered   = 0
+		expectedElements = totalKeys * elementsPerKey
+	)
+
+	for i := 0; i < elementsPerKey; i++ {
+		for k := 0; k < totalKeys; k++ {
+			suite.fifo.EnqueueWithOrderingKey(fmt.Sprintf("key%v", k), i)
+		}
+	}
+
+	for c := 0; c < totalConsumers; c++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				mutex.Lock()
+				if totalDelivered == expectedElements {
+					mutex.Unlock()
+					return
+				}
+				mutex.Unlock()
+
+				value, key, err := suite.fifo.DequeueWithOrderingKey()
+				if err != nil {
+					time.Sleep(time.Microsecond)
+					continue
+				}
+
+				mutex.Lock()
+				suite.Falsef(processing[key], "key %v processed concurrently", key)
+				processing[key] = true
+				if last, ok := lastSequence[key]; ok {
+					suite.Equalf(last+1, value, "key %v out of order", key)
+				}
+				lastSequence[key] = value.(int)
+				mutex.Unlock()
+
+				mutex.Lock()
+				processing[key] = false
+				totalDelivered++
+				mutex.Unlock()
+				suite.fifo.Done(key)
+			}
+		}()
+	}
+	wg.Wait()
+
+	suite.Equal(0, suite.fifo.GetLen())
+}
+
+// ***************************************************************************************
+// ** Run suite
+// ***************************************************************************************
+
+func TestOrderingKeyFIFOTestSuite(t *testing.T) {
+	suite.Run(t, new(OrderingKeyFIFOTestSuite))
+}
@@ -0,0 +1,50 @@
+package goconcurrentqueue
+
+import (
+	"context"
+	"runtime"
+	"time"
+)
+
+const (
+	// pollForNextElement yields this many times before it starts sleeping between polls
+	pollWaitSpinTries = 100
+	pollWaitGapTime   = 50 * time.Microsecond
+)
+
+// pollForNextElement calls dequeue until it returns something other than ErrEmptyQueue (yielding first, then sleeping
+// a bit between calls) or the context is done (returning ctx.Err()). It is the DequeueOrWaitForNextElement of the
+// queues that have no listeners to be notified through (i.e. wait-free or composed of other queues).
+func pollForNextElement(ctx context.Context, dequeue func() (interface{}, error)) (interface{}, error) {
+	var ticker *time.Ticker
+	defer func() {
+		if ticker != nil {
+			ticker.Stop()
+		}
+	}()
+
+	for tries := 0; ; tries++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		value, err := dequeue()
+		if err != ErrEmptyQueue {
+			return value, err
+		}
+
+		if tries < pollWaitSpinTries {
+			runtime.Gosched()
+			continue
+		}
+
+		if ticker == nil {
+			ticker = time.NewTicker(pollWaitGapTime)
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package goconcurrentqueue
+
+// Prefetcher is a consumer's local buffer in front of a shared FIFO queue. Every time the buffer runs out of elements
+// it gets refilled with up to batchSize elements dequeued from the shared queue under a single lock acquisition, so
+// fast consumers don't contend for the shared queue's lock for each element.
+// A Prefetcher belongs to a single consumer: it is NOT concurrent-safe. Close it on consumer shutdown to return the
+// prefetched (not yet dequeued) elements to the front of the shared queue.
+type Prefetcher struct {
+	source    *FIFO
+	batchSize int
+	// prefetched elements, from buffer[head] on
+	buffer []interface{}
+	head   int
+	closed bool
+}
+
+// NewPrefetcher returns a new Prefetcher over the given shared queue, prefetching up to batchSize elements at once
+func NewPrefetcher(source *FIFO, batchSize int) *Prefetcher {
+	if batchSize < 1 {
+		batchSize = 1
+	}
+
+	return &Prefetcher{
+		source:    source,
+		batchSize: batchSize,
+		buffer:    make([]interface{}, 0, batchSize),
+	}
+}
+
+// Dequeue dequeues an element from the local buffer, refilling it from the shared queue if it is empty.
+// Returns error if the Prefetcher is closed, or the shared queue is locked or empty.
+func (st *Prefetcher) Dequeue() (interface{}, error) {
+	if st.closed {
+		return nil, ErrLockedQueue
+	}
+
+	if st.GetLen() == 0 {
+		elements, err := st.source.dequeueElements(st.batchSize, false)
+		if err != nil {
+			return nil, err
+		}
+		st.buffer = append(st.buffer[:0], elements...)
+		st.head = 0
+	}
+
+	// delivered now, not when prefetched (Close could return it)
+	value := st.next()
+	resolveDequeuedFuture(value)
+
+	return value, nil
+}
+
+// DequeueOrWaitForNextElement dequeues an element from the local buffer, refilling it from the shared queue if it is
+// empty, or waits until the next element gets enqueued into the shared queue.
+func (st *Prefetcher) DequeueOrWaitForNextElement() (interface{}, error) {
+	value, err := st.Dequeue()
+	if err != ErrEmptyQueue {
+		return value, err
+	}
+
+	return st.source.DequeueOrWaitForNextElement()
+}
+
+// next removes and returns the local buffer's first element
+func (st *Prefetcher) next() interface{} {
+	value := st.buffer[st.head]
+	// release the reference
+	st.buffer[st.head] = nil
+	st.head++
+
+	return value
+}
+
+// GetLen returns the number of prefetched elements (local buffer)
+func (st *Prefetcher) GetLen() int {
+	return len(st.buffer) - st.head
+}
+
+// Close returns the prefetched (not yet dequeued) elements to the front of the shared queue, in the same order they
+// were prefetched, and closes the Prefetcher. The elements are returned even if the shared queue is locked, so
+// stopping a consumer never drops or reorders work. The returned error is always nil (io.Closer compatible).
+func (st *Prefetcher) Close() error {
+	st.closed = true
+
+	if st.GetLen() > 0 {
+		st.source.requeueElements(st.buffer[st.head:])
+		for st.GetLen() > 0 {
+			st.next()
+		}
+	}
+
+	return nil
+}
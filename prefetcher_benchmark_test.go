@@ -0,0 +1,46 @@
+package goconcurrentqueue
+
+import (
+	"testing"
+)
+
+// multiple goroutines - dequeue 100 elements per gr straight from the shared FIFO
+func BenchmarkPrefetcherBaselineFIFODequeue100MultipleGRs(b *testing.B) {
+	b.StopTimer()
+	fifo := NewFIFO()
+
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			b.StopTimer()
+			for c := 0; c < 100; c++ {
+				fifo.Enqueue(c)
+			}
+
+			b.StartTimer()
+			for c := 0; c < 100; c++ {
+				fifo.Dequeue()
+			}
+		}
+	})
+}
+
+// multiple goroutines - dequeue 100 elements per gr through a Prefetcher (batches of 50)
+func BenchmarkPrefetcherDequeue100MultipleGRs(b *testing.B) {
+	b.StopTimer()
+	fifo := NewFIFO()
+
+	b.RunParallel(func(pb *testing.PB) {
+		prefetcher := NewPrefetcher(fifo, 50)
+		for pb.Next() {
+			b.StopTimer()
+			for c := 0; c < 100; c++ {
+				fifo.Enqueue(c)
+			}
+
+			b.StartTimer()
+			for c := 0; c < 100; c++ {
+				prefetcher.Dequeue()
+			}
+		}
+	})
+}
@@ -0,0 +1,215 @@
+package goconcurrentqueue
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+const (
+	prefetcherBatchSize = 10
+)
+
+type PrefetcherTestSuite struct {
+	suite.Suite
+	fifo       *FIFO
+	prefetcher *Prefetcher
+}
+
+func (suite *PrefetcherTestSuite) SetupTest() {
+	suite.fifo = NewFIFO()
+	suite.prefetcher = NewPrefetcher(suite.fifo, prefetcherBatchSize)
+}
+
+// ***************************************************************************************
+// ** Dequeue
+// ***************************************************************************************
+
+// empty shared queue
+func (suite *PrefetcherTestSuite) TestDequeueEmptyQueue() {
+	_, err := suite.prefetcher.Dequeue()
+	suite.Equal(ErrEmptyQueue, err)
+}
+
+// locked shared queue
+func (suite *PrefetcherTestSuite) TestDequeueLockedQueue() {
+	suite.fifo.Enqueue(1)
+	suite.fifo.Lock()
+
+	_, err := suite.prefetcher.Dequeue()
+	suite.Equal(ErrLockedQueue, err)
+}
+
+// elements are prefetched in batches and dequeued in order
+func (suite *PrefetcherTestSuite) TestDequeueBatchesSingleGR() {
+	total := prefetcherBatchSize*2 + 5
+	for i := 0; i < total; i++ {
+		suite.fifo.Enqueue(i)
+	}
+
+	value, err := suite.prefetcher.Dequeue()
+	suite.NoError(err)
+	suite.Equal(0, value)
+	suite.Equal(prefetcherBatchSize-1, suite.prefetcher.GetLen())
+	suite.Equal(total-prefetcherBatchSize, suite.fifo.GetLen())
+
+	for i := 1; i < total; i++ {
+		value, err := suite.prefetcher.Dequeue()
+		suite.NoError(err)
+		suite.Equal(i, value)
+	}
+	suite.Equal(0, suite.prefetcher.GetLen())
+	suite.Equal(0, suite.fifo.GetLen())
+}
+
+// ***************************************************************************************
+// ** DequeueOrWaitForNextElement
+// ***************************************************************************************
+
+// waits for the next element enqueued into the shared queue
+func (suite *PrefetcherTestSuite) TestDequeueOrWaitForNextElement() {
+	result := make(chan interface{}, 1)
+	go func() {
+		value, _ := suite.prefetcher.DequeueOrWaitForNextElement()
+		result <- value
+	}()
+
+	time.Sleep(10 * time.Millisecond)
+	suite.fifo.Enqueue(testValue)
+
+	select {
+	case value := <-result:
+		suite.Equal(testValue, value)
+	case <-time.After(2 * time.Second):
+		suite.FailNow("too much time waiting for the enqueued element")
+	}
+}
+
+// ***************************************************************************************
+// ** Close
+// ***************************************************************************************
+
+// prefetched elements go back to the shared queue
+func (suite *PrefetcherTestSuite) TestCloseReturnsElements() {
+	for i := 0; i < prefetcherBatchSize; i++ {
+		suite.fifo.Enqueue(i)
+	}
+	suite.prefetcher.Dequeue()
+
+	suite.NoError(suite.prefetcher.Close())
+	suite.Equal(0, suite.prefetcher.GetLen())
+	suite.Equal(prefetcherBatchSize-1, suite.fifo.GetLen())
+
+	_, err := suite.prefetcher.Dequeue()
+	suite.Error(err, "closed Prefetcher")
+}
+
+// prefetched elements go back to the front of the shared queue, in order
+func (suite *PrefetcherTestSuite) TestCloseReturnsElementsToTheFront() {
+	for i := 0; i < prefetcherBatchSize*2; i++ {
+		suite.fifo.Enqueue(i)
+	}
+	suite.prefetcher.Dequeue()
+	// enqueued after the prefetching
+	suite.fifo.Enqueue(testValue)
+
+	suite.NoError(suite.prefetcher.Close())
+
+	for i := 1; i < prefetcherBatchSize*2; i++ {
+		value, err := suite.fifo.Dequeue()
+		suite.NoError(err)
+		suite.Equal(i, value)
+	}
+	value, err := suite.fifo.Dequeue()
+	suite.NoError(err)
+	suite.Equal(testValue, value)
+}
+
+// prefetched elements go back even if the shared queue is locked
+func (suite *PrefetcherTestSuite) TestCloseLockedQueue() {
+	for i := 0; i < prefetcherBatchSize; i++ {
+		suite.fifo.Enqueue(i)
+	}
+	suite.prefetcher.Dequeue()
+	suite.fifo.Lock()
+
+	suite.NoError(suite.prefetcher.Close())
+	suite.Equal(prefetcherBatchSize-1, suite.fifo.GetLen())
+
+	suite.fifo.Unlock()
+	value, err := suite.fifo.Dequeue()
+	suite.NoError(err)
+	suite.Equal(1, value)
+}
+
+// prefetched elements are handed to the shared queue's waiting consumers
+func (suite *PrefetcherTestSuite) TestCloseHandsElementsToWaiters() {
+	suite.fifo.Enqueue(1)
+	suite.fifo.Enqueue(2)
+	suite.prefetcher.Dequeue()
+
+	result := make(chan interface{}, 1)
+	go func() {
+		value, _ := suite.fifo.DequeueOrWaitForNextElement()
+		result <- value
+	}()
+	time.Sleep(10 * time.Millisecond)
+
+	suite.NoError(suite.prefetcher.Close())
+
+	select {
+	case value := <-result:
+		suite.Equal(2, value)
+	case <-time.After(2 * time.Second):
+		suite.FailNow("too much time waiting for the returned element")
+	}
+	suite.Equal(0, suite.fifo.GetLen())
+}
+
+// several consumers, each one with its own Prefetcher: no element lost or duplicated
+func (suite *PrefetcherTestSuite) TestMultipleConsumers() {
+	var (
+		total     = 5000
+		consumers = 8
+		wg        sync.WaitGroup
+		mutex     sync.Mutex
+		delivered = make(map[int]int)
+	)
+
+	for i := 0; i < total; i++ {
+		suite.fifo.Enqueue(i)
+	}
+
+	for c := 0; c < consumers; c++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			prefetcher := NewPrefetcher(suite.fifo, prefetcherBatchSize)
+			for {
+				value, err := prefetcher.Dequeue()
+				if err != nil {
+					return
+				}
+				mutex.Lock()
+				delivered[value.(int)]++
+				mutex.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	suite.Len(delivered, total)
+	for value, times := range delivered {
+		suite.Equalf(1, times, "%v dequeued %v times", value, times)
+	}
+}
+
+// ***************************************************************************************
+// ** Run suite
+// ***************************************************************************************
+
+func TestPrefetcherTestSuite(t *testing.T) {
+	suite.Run(t, new(PrefetcherTestSuite))
+}
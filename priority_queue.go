@@ -0,0 +1,449 @@
+package goconcurrentqueue
+
+import (
+	"container/heap"
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+var (
+	// serializes Meld
+	meldQueuesMutex sync.Mutex
+	// origin of the aging queues' enqueue times (see NewAgingPriorityQueue)
+	priorityAgingEpoch = time.Now()
+)
+
+// PriorityQueue concurrent queue backed by a binary heap: Dequeue always returns the highest priority element, the
+// one that goes before every other element according to the less comparator given at construction.
+type PriorityQueue struct {
+	heap        priorityQueueHeap
+	rwmutex     sync.RWMutex
+	lockRWmutex sync.RWMutex
+	isLocked    bool
+	// queue for watchers that will wait for next elements (if queue is empty at DequeueOrWaitForNextElement execution )
+	waitForNextElementChan chan chan interface{}
+}
+
+// priorityQueueElement is an element enqueued into a PriorityQueue
+type priorityQueueElement struct {
+	value interface{}
+	// enqueue order, the tie-breaker for stable queues
+	sequence uint64
+	// aging queues only: enqueue time (since priorityAgingEpoch) and the sort key (see priorityQueueHeap.key)
+	at  time.Duration
+	key float64
+}
+
+// priorityQueueHeap implements heap.Interface
+type priorityQueueHeap struct {
+	elements []priorityQueueElement
+	less     func(a, b interface{}) bool
+	// whether elements with equal priority are dequeued in enqueue order
+	stable   bool
+	sequence uint64
+	// aging queues only (instead of less): the elements' base priority and how much it grows per waited second
+	priority  func(value interface{}) float64
+	agingRate float64
+}
+
+func (st *priorityQueueHeap) Len() int {
+	return len(st.elements)
+}
+
+func (st *priorityQueueHeap) Less(i, j int) bool {
+	a, b := st.elements[i], st.elements[j]
+	if st.priority != nil {
+		if a.key != b.key {
+			return a.key > b.key
+		}
+		return a.sequence < b.sequence
+	}
+	if !st.stable {
+		return st.less(a.value, b.value)
+	}
+
+	switch {
+	case st.less(a.value, b.value):
+		return true
+	case st.less(b.value, a.value):
+		return false
+	default:
+		// equal priority
+		return a.sequence < b.sequence
+	}
+}
+
+func (st *priorityQueueHeap) Swap(i, j int) {
+	st.elements[i], st.elements[j] = st.elements[j], st.elements[i]
+}
+
+func (st *priorityQueueHeap) Push(value interface{}) {
+	element := priorityQueueElement{value: value, sequence: st.sequence}
+	if st.priority != nil {
+		element.at = time.Since(priorityAgingEpoch)
+		element.key = st.key(element)
+	}
+	st.elements = append(st.elements, element)
+	st.sequence++
+}
+
+// key returns the aging element's sort key: its effective priority at any time t is
+// priority + agingRate * (t - at) = key + agingRate * t, every element gains the same, so the keys keep the heap
+// ordered as time goes by
+func (st *priorityQueueHeap) key(element priorityQueueElement) float64 {
+	return st.priority(element.value) - st.agingRate*element.at.Seconds()
+}
+
+func (st *priorityQueueHeap) Pop() interface{} {
+	last := len(st.elements) - 1
+	value := st.elements[last].value
+	// release the reference
+	st.elements[last] = priorityQueueElement{}
+	st.elements = st.elements[:last]
+
+	return value
+}
+
+// NewPriorityQueue returns a new PriorityQueue. less(a, b) must return true whether a has higher priority than b (a
+// gets dequeued first).
+func NewPriorityQueue(less func(a, b interface{}) bool) *PriorityQueue {
+	ret := &PriorityQueue{}
+	ret.initialize(less)
+
+	return ret
+}
+
+// NewPriorityQueueFromSlice returns a new PriorityQueue holding the given elements (the slice is copied). The heap
+// gets built in O(n), much faster than enqueueing the elements one by one (i.e. to restore a large backlog).
+func NewPriorityQueueFromSlice(items []interface{}, less func(a, b interface{}) bool) *PriorityQueue {
+	ret := NewPriorityQueue(less)
+
+	ret.heap.elements = make([]priorityQueueElement, len(items))
+	for i, value := range items {
+		ret.heap.elements[i] = priorityQueueElement{value: value, sequence: uint64(i)}
+	}
+	ret.heap.sequence = uint64(len(items))
+	heap.Init(&ret.heap)
+
+	return ret
+}
+
+// NewStablePriorityQueue returns a new PriorityQueue that dequeues the elements with equal priority (neither
+// less(a, b) nor less(b, a)) in the same order they were enqueued.
+func NewStablePriorityQueue(less func(a, b interface{}) bool) *PriorityQueue {
+	ret := NewPriorityQueue(less)
+	ret.heap.stable = true
+
+	return ret
+}
+
+// NewAgingPriorityQueue returns a new PriorityQueue whose elements' effective priority grows the longer they wait, so
+// low priority elements don't starve under a sustained load of high priority ones: priority(value) plus agingRate per
+// waited second, the highest effective priority gets dequeued first (equal ones in enqueue order). I.e. with
+// agingRate 1 an element that waited 10s goes before a new one whose priority is up to 10 higher. priority runs under
+// the queue's lock, it must not access the queue. Returns error if agingRate is negative.
+func NewAgingPriorityQueue(priority func(value interface{}) float64, agingRate float64) (*PriorityQueue, error) {
+	if agingRate < 0 {
+		return nil, NewQueueError(QueueErrorCodeInvalidConfig, fmt.Sprintf("invalid aging rate: %v", agingRate))
+	}
+
+	ret := NewPriorityQueue(nil)
+	ret.heap.priority = priority
+	ret.heap.agingRate = agingRate
+
+	return ret, nil
+}
+
+func (st *PriorityQueue) initialize(less func(a, b interface{}) bool) {
+	st.heap = priorityQueueHeap{
+		elements: make([]priorityQueueElement, 0),
+		less:     less,
+	}
+	st.waitForNextElementChan = make(chan chan interface{}, WaitForNextElementChanCapacity)
+}
+
+// Enqueue enqueues an element. Returns error if queue is locked.
+func (st *PriorityQueue) Enqueue(value interface{}) error {
+	if st.IsLocked() {
+		return ErrLockedQueue
+	}
+
+	st.rwmutex.Lock()
+	defer st.rwmutex.Unlock()
+
+	// listeners wait only while the queue is empty, so this element is the highest priority one
+	select {
+	case listener := <-st.waitForNextElementChan:
+		select {
+		case listener <- value:
+			return nil
+		default:
+		}
+	default:
+	}
+
+	heap.Push(&st.heap, value)
+
+	return nil
+}
+
+// Dequeue dequeues the highest priority element. Returns error if queue is locked or empty.
+func (st *PriorityQueue) Dequeue() (interface{}, error) {
+	if st.IsLocked() {
+		return nil, ErrLockedQueue
+	}
+
+	st.rwmutex.Lock()
+	defer st.rwmutex.Unlock()
+
+	if st.heap.Len() == 0 {
+		return nil, ErrEmptyQueue
+	}
+
+	return heap.Pop(&st.heap), nil
+}
+
+// DequeueOrWaitForNextElement dequeues the highest priority element (if exist) or waits until the next element gets
+// enqueued and returns it.
+// Multiple calls to DequeueOrWaitForNextElement() would enqueue multiple "listeners" for future enqueued elements.
+func (st *PriorityQueue) DequeueOrWaitForNextElement() (interface{}, error) {
+	return st.DequeueOrWaitForNextElementWithContext(context.Background())
+}
+
+// DequeueOrWaitForNextElementWithContext works as DequeueOrWaitForNextElement, but it stops waiting once the context
+// is done, returning ctx.Err().
+func (st *PriorityQueue) DequeueOrWaitForNextElementWithContext(ctx context.Context) (interface{}, error) {
+	if st.IsLocked() {
+		return nil, ErrLockedQueue
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	st.rwmutex.Lock()
+
+	if st.heap.Len() > 0 {
+		value := heap.Pop(&st.heap)
+		st.rwmutex.Unlock()
+
+		return value, nil
+	}
+
+	// channel to wait for next enqueued element (buffered, so Enqueue never blocks handing it over)
+	waitChan := make(chan interface{}, 1)
+
+	select {
+	case st.waitForNextElementChan <- waitChan:
+		st.rwmutex.Unlock()
+
+		select {
+		case value := <-waitChan:
+			return value, nil
+		case <-ctx.Done():
+			st.rwmutex.Lock()
+			defer st.rwmutex.Unlock()
+
+			removeListener(st.waitForNextElementChan, waitChan)
+			// the element could have been handed over right before the listener's removal
+			select {
+			case value := <-waitChan:
+				return value, nil
+			default:
+				return nil, ctx.Err()
+			}
+		}
+	default:
+		st.rwmutex.Unlock()
+
+		// too many watchers (waitForNextElementChanCapacity) enqueued waiting for next elements
+		return nil, NewQueueError(QueueErrorCodeEmptyQueue, "empty queue and can't wait for next element because there are too many DequeueOrWaitForNextElement() waiting")
+	}
+}
+
+// PeekTopK returns the k highest priority elements (or all of them if there are fewer), in dequeue order, keeping them
+// at the queue. The heap isn't modified: it takes O(k log k). Returns error if queue is locked.
+func (st *PriorityQueue) PeekTopK(k int) ([]interface{}, error) {
+	if st.IsLocked() {
+		return nil, ErrLockedQueue
+	}
+
+	st.rwmutex.RLock()
+	defer st.rwmutex.RUnlock()
+
+	if k > st.heap.Len() {
+		k = st.heap.Len()
+	}
+	if k <= 0 {
+		return make([]interface{}, 0), nil
+	}
+
+	// the next element is always among the children of the already picked ones
+	candidates := &priorityQueuePeekHeap{source: &st.heap, indexes: []int{0}}
+	ret := make([]interface{}, 0, k)
+	for len(ret) < k {
+		index := heap.Pop(candidates).(int)
+		ret = append(ret, st.heap.elements[index].value)
+
+		for _, child := range []int{2*index + 1, 2*index + 2} {
+			if child < st.heap.Len() {
+				heap.Push(candidates, child)
+			}
+		}
+	}
+
+	return ret, nil
+}
+
+// priorityQueuePeekHeap implements heap.Interface over positions of a priorityQueueHeap (PeekTopK)
+type priorityQueuePeekHeap struct {
+	source  *priorityQueueHeap
+	indexes []int
+}
+
+func (st *priorityQueuePeekHeap) Len() int {
+	return len(st.indexes)
+}
+
+func (st *priorityQueuePeekHeap) Less(i, j int) bool {
+	return st.source.Less(st.indexes[i], st.indexes[j])
+}
+
+func (st *priorityQueuePeekHeap) Swap(i, j int) {
+	st.indexes[i], st.indexes[j] = st.indexes[j], st.indexes[i]
+}
+
+func (st *priorityQueuePeekHeap) Push(value interface{}) {
+	st.indexes = append(st.indexes, value.(int))
+}
+
+func (st *priorityQueuePeekHeap) Pop() interface{} {
+	last := len(st.indexes) - 1
+	value := st.indexes[last]
+	st.indexes = st.indexes[:last]
+
+	return value
+}
+
+// Meld atomically moves every element of other into the queue (i.e. to consolidate shards of prioritized work), other
+// ends up empty. The elements get prioritized by the queue's comparator; for stable queues, other's elements go after
+// the queue's ones with equal priority (keeping their relative order). For aging queues other's elements keep their
+// waited time (the ones from a non aging queue start aging at the meld). Both queues are locked for the time it takes
+// to merge the heaps, O(n + m). Consumers waiting on the queue (DequeueOrWaitForNextElement) get the highest priority
+// elements.
+// Returns error if any of the queues is locked.
+func (st *PriorityQueue) Meld(other *PriorityQueue) error {
+	if st == other {
+		return nil
+	}
+
+	if st.IsLocked() || other.IsLocked() {
+		return ErrLockedQueue
+	}
+
+	// a single meld at a time, so concurrent a.Meld(b) and b.Meld(a) can't deadlock
+	meldQueuesMutex.Lock()
+	defer meldQueuesMutex.Unlock()
+
+	st.rwmutex.Lock()
+	defer st.rwmutex.Unlock()
+	other.rwmutex.Lock()
+	defer other.rwmutex.Unlock()
+
+	if other.heap.Len() == 0 {
+		return nil
+	}
+
+	now := time.Since(priorityAgingEpoch)
+	for _, element := range other.heap.elements {
+		element.sequence += st.heap.sequence
+		if st.heap.priority != nil {
+			if other.heap.priority == nil {
+				element.at = now
+			}
+			element.key = st.heap.key(element)
+		}
+		st.heap.elements = append(st.heap.elements, element)
+	}
+	st.heap.sequence += other.heap.sequence
+	heap.Init(&st.heap)
+
+	other.heap.elements = make([]priorityQueueElement, 0)
+
+	st.handOverToListeners()
+
+	return nil
+}
+
+// handOverToListeners sends the highest priority elements to the waiting listeners (if any). st.rwmutex must be
+// locked by the caller.
+func (st *PriorityQueue) handOverToListeners() {
+	for st.heap.Len() > 0 {
+		select {
+		case listener := <-st.waitForNextElementChan:
+			select {
+			case listener <- st.heap.elements[0].value:
+				heap.Pop(&st.heap)
+			default:
+			}
+		default:
+			return
+		}
+	}
+}
+
+// Clear atomically removes every element, releasing the references. Returns error if queue is locked.
+func (st *PriorityQueue) Clear() error {
+	if st.IsLocked() {
+		return ErrLockedQueue
+	}
+
+	st.rwmutex.Lock()
+	defer st.rwmutex.Unlock()
+
+	st.heap.elements = make([]priorityQueueElement, 0)
+
+	return nil
+}
+
+// GetLen returns the number of enqueued elements
+func (st *PriorityQueue) GetLen() int {
+	st.rwmutex.RLock()
+	defer st.rwmutex.RUnlock()
+
+	return st.heap.Len()
+}
+
+// GetCap returns the queue's capacity
+func (st *PriorityQueue) GetCap() int {
+	st.rwmutex.RLock()
+	defer st.rwmutex.RUnlock()
+
+	return cap(st.heap.elements)
+}
+
+// Lock // Locks the queue. No enqueue/dequeue operations will be allowed after this point.
+func (st *PriorityQueue) Lock() {
+	st.lockRWmutex.Lock()
+	defer st.lockRWmutex.Unlock()
+
+	st.isLocked = true
+}
+
+// Unlock unlocks the queue
+func (st *PriorityQueue) Unlock() {
+	st.lockRWmutex.Lock()
+	defer st.lockRWmutex.Unlock()
+
+	st.isLocked = false
+}
+
+// IsLocked returns true whether the queue is locked
+func (st *PriorityQueue) IsLocked() bool {
+	st.lockRWmutex.RLock()
+	defer st.lockRWmutex.RUnlock()
+
+	return st.isLocked
+}
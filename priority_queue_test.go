@@ -0,0 +1,592 @@
+package goconcurrentqueue
+
+import (
+	"context"
+	"math/rand"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type PriorityQueueTestSuite struct {
+	suite.Suite
+	queue *PriorityQueue
+}
+
+// lower ints first
+func priorityQueueTestLess(a, b interface{}) bool {
+	return a.(int) < b.(int)
+}
+
+func (suite *PriorityQueueTestSuite) SetupTest() {
+	suite.queue = NewPriorityQueue(priorityQueueTestLess)
+}
+
+// ***************************************************************************************
+// ** Queue initialization
+// ***************************************************************************************
+
+// no elements at initialization
+func (suite *PriorityQueueTestSuite) TestNoElementsAtInitialization() {
+	suite.Equal(0, suite.queue.GetLen())
+}
+
+// unlocked at initialization
+func (suite *PriorityQueueTestSuite) TestNoLockedAtInitialization() {
+	suite.False(suite.queue.IsLocked())
+}
+
+// ***************************************************************************************
+// ** Enqueue && Dequeue
+// ***************************************************************************************
+
+// elements get dequeued by priority
+func (suite *PriorityQueueTestSuite) TestDequeueByPriority() {
+	values := rand.New(rand.NewSource(0)).Perm(100)
+	for _, value := range values {
+		suite.NoError(suite.queue.Enqueue(value))
+	}
+	suite.Equal(len(values), suite.queue.GetLen())
+
+	for i := 0; i < len(values); i++ {
+		value, err := suite.queue.Dequeue()
+		suite.NoError(err)
+		suite.Equal(i, value)
+	}
+	suite.Equal(0, suite.queue.GetLen())
+}
+
+// custom comparator: higher ints first
+func (suite *PriorityQueueTestSuite) TestCustomComparator() {
+	queue := NewPriorityQueue(func(a, b interface{}) bool {
+		return a.(int) > b.(int)
+	})
+	for _, value := range []int{3, 1, 5, 2, 4} {
+		queue.Enqueue(value)
+	}
+
+	for i := 5; i > 0; i-- {
+		value, err := queue.Dequeue()
+		suite.NoError(err)
+		suite.Equal(i, value)
+	}
+}
+
+// empty queue
+func (suite *PriorityQueueTestSuite) TestDequeueEmptyQueue() {
+	_, err := suite.queue.Dequeue()
+	suite.Equal(ErrEmptyQueue, err)
+}
+
+// locked queue
+func (suite *PriorityQueueTestSuite) TestLockedQueue() {
+	suite.queue.Enqueue(1)
+	suite.queue.Lock()
+	suite.True(suite.queue.IsLocked())
+
+	suite.Equal(ErrLockedQueue, suite.queue.Enqueue(2))
+	_, err := suite.queue.Dequeue()
+	suite.Equal(ErrLockedQueue, err)
+	_, err = suite.queue.DequeueOrWaitForNextElement()
+	suite.Equal(ErrLockedQueue, err)
+
+	suite.queue.Unlock()
+	suite.False(suite.queue.IsLocked())
+	suite.Equal(1, suite.queue.GetLen())
+}
+
+// concurrent producers and consumers: every element gets dequeued once
+func (suite *PriorityQueueTestSuite) TestEnqueueDequeueMultipleGRs() {
+	var (
+		totalGRs = 10
+		perGR    = 500
+		wg       sync.WaitGroup
+		mutex    sync.Mutex
+		values   []int
+	)
+
+	for gr := 0; gr < totalGRs; gr++ {
+		wg.Add(1)
+		go func(gr int) {
+			defer wg.Done()
+			for i := 0; i < perGR; i++ {
+				suite.NoError(suite.queue.Enqueue(gr*perGR + i))
+			}
+		}(gr)
+	}
+
+	for gr := 0; gr < totalGRs; gr++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < perGR; i++ {
+				value, err := suite.queue.DequeueOrWaitForNextElement()
+				suite.NoError(err)
+				mutex.Lock()
+				values = append(values, value.(int))
+				mutex.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	sort.Ints(values)
+	suite.Len(values, totalGRs*perGR)
+	for i, value := range values {
+		suite.Equal(i, value)
+	}
+}
+
+// ***************************************************************************************
+// ** NewPriorityQueueFromSlice
+// ***************************************************************************************
+
+// the heapified elements are dequeued by priority
+func (suite *PriorityQueueTestSuite) TestFromSliceDequeueByPriority() {
+	const total = 1000
+	items := make([]interface{}, total)
+	for i, value := range rand.New(rand.NewSource(0)).Perm(total) {
+		items[i] = value
+	}
+
+	queue := NewPriorityQueueFromSlice(items, priorityQueueTestLess)
+	suite.Equal(total, queue.GetLen())
+
+	for i := 0; i < total; i++ {
+		value, err := queue.Dequeue()
+		suite.NoError(err)
+		suite.Equal(i, value)
+	}
+}
+
+// the given slice is copied: modifying it doesn't affect the queue
+func (suite *PriorityQueueTestSuite) TestFromSliceCopy() {
+	items := []interface{}{3, 1, 2}
+	queue := NewPriorityQueueFromSlice(items, priorityQueueTestLess)
+	items[0] = 0
+
+	value, err := queue.Dequeue()
+	suite.NoError(err)
+	suite.Equal(1, value)
+}
+
+// elements enqueued afterwards are merged by priority
+func (suite *PriorityQueueTestSuite) TestFromSliceEnqueue() {
+	queue := NewPriorityQueueFromSlice([]interface{}{4, 2}, priorityQueueTestLess)
+	suite.NoError(queue.Enqueue(3))
+	suite.NoError(queue.Enqueue(1))
+
+	for i := 1; i <= 4; i++ {
+		value, err := queue.Dequeue()
+		suite.NoError(err)
+		suite.Equal(i, value)
+	}
+}
+
+// empty slice
+func (suite *PriorityQueueTestSuite) TestFromSliceEmpty() {
+	queue := NewPriorityQueueFromSlice(nil, priorityQueueTestLess)
+	suite.Equal(0, queue.GetLen())
+
+	_, err := queue.Dequeue()
+	suite.Equal(ErrEmptyQueue, err)
+}
+
+// ***************************************************************************************
+// ** Stable priority queue
+// ***************************************************************************************
+
+type priorityQueueTestElement struct {
+	priority int
+	sequence int
+}
+
+func priorityQueueTestElementLess(a, b interface{}) bool {
+	return a.(priorityQueueTestElement).priority < b.(priorityQueueTestElement).priority
+}
+
+// equal priority elements are dequeued in enqueue order
+func (suite *PriorityQueueTestSuite) TestStableFIFOTieBreaking() {
+	var (
+		queue      = NewStablePriorityQueue(priorityQueueTestElementLess)
+		random     = rand.New(rand.NewSource(0))
+		total      = 1000
+		priorities = 5
+	)
+
+	for i := 0; i < total; i++ {
+		suite.NoError(queue.Enqueue(priorityQueueTestElement{priority: random.Intn(priorities), sequence: i}))
+	}
+
+	previous := priorityQueueTestElement{priority: -1}
+	for i := 0; i < total; i++ {
+		value, err := queue.Dequeue()
+		suite.NoError(err)
+
+		element := value.(priorityQueueTestElement)
+		suite.True(element.priority >= previous.priority, "priority order broken")
+		if element.priority == previous.priority {
+			suite.True(element.sequence > previous.sequence, "enqueue order broken for equal priorities")
+		}
+		previous = element
+	}
+}
+
+// interleaved enqueues and dequeues keep the enqueue order for equal priorities
+func (suite *PriorityQueueTestSuite) TestStableInterleaved() {
+	queue := NewStablePriorityQueue(priorityQueueTestElementLess)
+
+	queue.Enqueue(priorityQueueTestElement{priority: 1, sequence: 0})
+	queue.Enqueue(priorityQueueTestElement{priority: 1, sequence: 1})
+	queue.Enqueue(priorityQueueTestElement{priority: 0, sequence: 2})
+
+	value, _ := queue.Dequeue()
+	suite.Equal(2, value.(priorityQueueTestElement).sequence)
+
+	queue.Enqueue(priorityQueueTestElement{priority: 1, sequence: 3})
+	for _, expected := range []int{0, 1, 3} {
+		value, err := queue.Dequeue()
+		suite.NoError(err)
+		suite.Equal(expected, value.(priorityQueueTestElement).sequence)
+	}
+}
+
+// ***************************************************************************************
+// ** DequeueOrWaitForNextElement
+// ***************************************************************************************
+
+// highest priority element, no waiting
+func (suite *PriorityQueueTestSuite) TestDequeueOrWaitForNextElementEnqueued() {
+	suite.queue.Enqueue(2)
+	suite.queue.Enqueue(1)
+
+	value, err := suite.queue.DequeueOrWaitForNextElement()
+	suite.NoError(err)
+	suite.Equal(1, value)
+}
+
+// element enqueued while waiting
+func (suite *PriorityQueueTestSuite) TestDequeueOrWaitForNextElementWaiting() {
+	result := make(chan interface{}, 1)
+	go func() {
+		value, _ := suite.queue.DequeueOrWaitForNextElement()
+		result <- value
+	}()
+	time.Sleep(10 * time.Millisecond)
+
+	suite.queue.Enqueue(7)
+
+	select {
+	case value := <-result:
+		suite.Equal(7, value)
+	case <-time.After(2 * time.Second):
+		suite.FailNow("too much time waiting for the enqueued element")
+	}
+	suite.Equal(0, suite.queue.GetLen())
+}
+
+// context deadline exceeded while waiting: the next element is not handed over to the gone waiter
+func (suite *PriorityQueueTestSuite) TestDequeueOrWaitForNextElementWithContextDeadline() {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err := suite.queue.DequeueOrWaitForNextElementWithContext(ctx)
+	suite.Equal(context.DeadlineExceeded, err)
+
+	suite.queue.Enqueue(1)
+	suite.Equal(1, suite.queue.GetLen())
+}
+
+// ***************************************************************************************
+// ** PeekTopK
+// ***************************************************************************************
+
+// the k highest priority elements, in dequeue order, kept at the queue
+func (suite *PriorityQueueTestSuite) TestPeekTopK() {
+	const total = 100
+	for _, value := range rand.New(rand.NewSource(0)).Perm(total) {
+		suite.NoError(suite.queue.Enqueue(value))
+	}
+
+	for _, k := range []int{1, 5, 50, total} {
+		top, err := suite.queue.PeekTopK(k)
+		suite.NoError(err)
+
+		expected := make([]interface{}, k)
+		for i := 0; i < k; i++ {
+			expected[i] = i
+		}
+		suite.Equal(expected, top)
+	}
+	suite.Equal(total, suite.queue.GetLen())
+
+	// the heap isn't perturbed
+	for i := 0; i < total; i++ {
+		value, err := suite.queue.Dequeue()
+		suite.NoError(err)
+		suite.Equal(i, value)
+	}
+}
+
+// k out of range
+func (suite *PriorityQueueTestSuite) TestPeekTopKOutOfRange() {
+	suite.queue.Enqueue(2)
+	suite.queue.Enqueue(1)
+
+	top, err := suite.queue.PeekTopK(10)
+	suite.NoError(err)
+	suite.Equal([]interface{}{1, 2}, top)
+
+	top, err = suite.queue.PeekTopK(0)
+	suite.NoError(err)
+	suite.Equal([]interface{}{}, top)
+
+	top, err = NewPriorityQueue(priorityQueueTestLess).PeekTopK(3)
+	suite.NoError(err)
+	suite.Equal([]interface{}{}, top)
+}
+
+// stable queues: equal priority elements in enqueue order
+func (suite *PriorityQueueTestSuite) TestPeekTopKStable() {
+	queue := NewStablePriorityQueue(priorityQueueTestElementLess)
+	for i := 0; i < 5; i++ {
+		queue.Enqueue(priorityQueueTestElement{priority: 1, sequence: i})
+	}
+	queue.Enqueue(priorityQueueTestElement{priority: 0, sequence: 5})
+
+	top, err := queue.PeekTopK(3)
+	suite.NoError(err)
+	suite.Equal([]interface{}{
+		priorityQueueTestElement{priority: 0, sequence: 5},
+		priorityQueueTestElement{priority: 1, sequence: 0},
+		priorityQueueTestElement{priority: 1, sequence: 1},
+	}, top)
+}
+
+// locked queue
+func (suite *PriorityQueueTestSuite) TestPeekTopKLockedQueue() {
+	suite.queue.Enqueue(1)
+	suite.queue.Lock()
+
+	_, err := suite.queue.PeekTopK(1)
+	suite.Equal(ErrLockedQueue, err)
+}
+
+// ***************************************************************************************
+// ** Meld
+// ***************************************************************************************
+
+// melded elements are dequeued by priority and the other queue ends up empty
+func (suite *PriorityQueueTestSuite) TestMeld() {
+	other := NewPriorityQueue(priorityQueueTestLess)
+	for i := 0; i < 10; i++ {
+		if i%2 == 0 {
+			suite.NoError(suite.queue.Enqueue(i))
+		} else {
+			suite.NoError(other.Enqueue(i))
+		}
+	}
+
+	suite.NoError(suite.queue.Meld(other))
+	suite.Equal(10, suite.queue.GetLen())
+	suite.Equal(0, other.GetLen())
+
+	for i := 0; i < 10; i++ {
+		value, err := suite.queue.Dequeue()
+		suite.NoError(err)
+		suite.Equal(i, value)
+	}
+}
+
+// equal priority elements: the queue's ones first, then other's ones, each in enqueue order
+func (suite *PriorityQueueTestSuite) TestMeldStable() {
+	var (
+		queue = NewStablePriorityQueue(priorityQueueTestElementLess)
+		other = NewStablePriorityQueue(priorityQueueTestElementLess)
+	)
+	for i := 0; i < 3; i++ {
+		suite.NoError(other.Enqueue(priorityQueueTestElement{priority: 1, sequence: 10 + i}))
+		suite.NoError(other.Enqueue(priorityQueueTestElement{priority: 1, sequence: 10 + i}))
+	}
+	queue.Enqueue(priorityQueueTestElement{priority: 1, sequence: 0})
+	queue.Enqueue(priorityQueueTestElement{priority: 1, sequence: 1})
+
+	suite.NoError(queue.Meld(other))
+	queue.Enqueue(priorityQueueTestElement{priority: 1, sequence: 20})
+
+	for _, expected := range []int{0, 1, 10, 10, 11, 11, 12, 12, 20} {
+		value, err := queue.Dequeue()
+		suite.NoError(err)
+		suite.Equal(expected, value.(priorityQueueTestElement).sequence)
+	}
+}
+
+// locked queues
+func (suite *PriorityQueueTestSuite) TestMeldLockedQueue() {
+	other := NewPriorityQueue(priorityQueueTestLess)
+	other.Enqueue(1)
+
+	other.Lock()
+	suite.Equal(ErrLockedQueue, suite.queue.Meld(other))
+	other.Unlock()
+
+	suite.queue.Lock()
+	suite.Equal(ErrLockedQueue, suite.queue.Meld(other))
+	suite.queue.Unlock()
+
+	suite.Equal(0, suite.queue.GetLen())
+	suite.Equal(1, other.GetLen())
+}
+
+// melding a queue with itself is a no-op
+func (suite *PriorityQueueTestSuite) TestMeldSameQueue() {
+	suite.queue.Enqueue(1)
+
+	suite.NoError(suite.queue.Meld(suite.queue))
+	suite.Equal(1, suite.queue.GetLen())
+}
+
+// a consumer waiting on the (empty) queue gets the highest priority melded element
+func (suite *PriorityQueueTestSuite) TestMeldWaitingConsumer() {
+	result := make(chan interface{}, 1)
+	go func() {
+		value, _ := suite.queue.DequeueOrWaitForNextElement()
+		result <- value
+	}()
+	time.Sleep(10 * time.Millisecond)
+
+	other := NewPriorityQueue(priorityQueueTestLess)
+	other.Enqueue(3)
+	other.Enqueue(1)
+	other.Enqueue(2)
+	suite.NoError(suite.queue.Meld(other))
+
+	select {
+	case value := <-result:
+		suite.Equal(1, value)
+	case <-time.After(2 * time.Second):
+		suite.FailNow("too much time waiting for the melded element")
+	}
+	suite.Equal(2, suite.queue.GetLen())
+}
+
+// concurrent a.Meld(b) and b.Meld(a) don't deadlock and no element gets lost
+func (suite *PriorityQueueTestSuite) TestMeldConcurrent() {
+	var (
+		other = NewPriorityQueue(priorityQueueTestLess)
+		wg    sync.WaitGroup
+		total = 100
+	)
+	for i := 0; i < total; i++ {
+		suite.queue.Enqueue(i)
+		other.Enqueue(total + i)
+	}
+
+	for i := 0; i < 50; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			suite.NoError(suite.queue.Meld(other))
+		}()
+		go func() {
+			defer wg.Done()
+			suite.NoError(other.Meld(suite.queue))
+		}()
+	}
+	wg.Wait()
+
+	suite.Equal(2*total, suite.queue.GetLen()+other.GetLen())
+}
+
+// ***************************************************************************************
+// ** Aging
+// ***************************************************************************************
+
+// the waited time outweighs the priority difference: low priority elements don't starve
+func (suite *PriorityQueueTestSuite) TestAging() {
+	queue, err := NewAgingPriorityQueue(func(value interface{}) float64 {
+		return float64(value.(int))
+	}, 1000)
+	suite.Require().NoError(err)
+
+	queue.Enqueue(1)
+	time.Sleep(20 * time.Millisecond)
+	queue.Enqueue(5)
+	queue.Enqueue(10)
+
+	for _, expected := range []int{1, 10, 5} {
+		value, err := queue.Dequeue()
+		suite.NoError(err)
+		suite.Equal(expected, value)
+	}
+}
+
+// no aging: highest priority first, equal ones in enqueue order
+func (suite *PriorityQueueTestSuite) TestAgingZeroRate() {
+	queue, err := NewAgingPriorityQueue(func(value interface{}) float64 {
+		return float64(len(value.(string)))
+	}, 0)
+	suite.Require().NoError(err)
+
+	for _, value := range []string{"a", "ccc", "b", "dd", "c"} {
+		queue.Enqueue(value)
+	}
+	top, err := queue.PeekTopK(5)
+	suite.NoError(err)
+	suite.Equal([]interface{}{"ccc", "dd", "a", "b", "c"}, top)
+
+	_, err = NewAgingPriorityQueue(nil, -1)
+	suite.Equal(QueueErrorCodeInvalidConfig, errorCode(err))
+}
+
+// melded elements keep their waited time, the non aging ones start aging at the meld
+func (suite *PriorityQueueTestSuite) TestAgingMeld() {
+	priority := func(value interface{}) float64 {
+		return float64(value.(int))
+	}
+	var (
+		queue, _ = NewAgingPriorityQueue(priority, 1000)
+		aging, _ = NewAgingPriorityQueue(priority, 1)
+		plain    = NewPriorityQueue(priorityQueueTestLess)
+	)
+	aging.Enqueue(1)
+	plain.Enqueue(2)
+	time.Sleep(20 * time.Millisecond)
+	queue.Enqueue(10)
+
+	suite.NoError(queue.Meld(aging))
+	suite.NoError(queue.Meld(plain))
+	top, err := queue.PeekTopK(3)
+	suite.NoError(err)
+	suite.Equal([]interface{}{1, 10, 2}, top, "2 starts aging at the meld")
+}
+
+// ***************************************************************************************
+// ** Clear
+// ***************************************************************************************
+
+// every element gets removed
+func (suite *PriorityQueueTestSuite) TestClear() {
+	for i := 0; i < 3; i++ {
+		suite.queue.Enqueue(i)
+	}
+
+	suite.NoError(suite.queue.Clear())
+	suite.Equal(0, suite.queue.GetLen())
+	_, err := suite.queue.Dequeue()
+	suite.Equal(ErrEmptyQueue, err)
+
+	suite.queue.Lock()
+	suite.Equal(ErrLockedQueue, suite.queue.Clear())
+}
+
+// ***************************************************************************************
+// ** Run suite
+// ***************************************************************************************
+
+func TestPriorityQueueTestSuite(t *testing.T) {
+	suite.Run(t, new(PriorityQueueTestSuite))
+}
@@ -0,0 +1,201 @@
+package goconcurrentqueue
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// PrioritySelector presents several queues as a single Queue to the consumer, always serving the higher priority
+// queues first: queues[0] (the highest priority) is drained before queues[1] gets served, and so on. It is an easy way
+// to get priority semantics out of plain FIFO queues.
+//
+// Strict priority could starve the lower priority queues, SetWeights limits how many elements in a row a queue gets
+// served before the lower ones get their turn (weighted round).
+//
+// Producers usually enqueue into the underlying queues directly (each of them keeps its own order and locking).
+type PrioritySelector struct {
+	// highest priority first
+	queues []Queue
+	// max consecutive elements served per queue and round (0 == no limit), nil == strict priority
+	weights []int
+	// elements served per queue during the current round
+	served []int
+	// serializes the dequeues (rounds)
+	mutex       sync.Mutex
+	lockRWmutex sync.RWMutex
+	isLocked    bool
+}
+
+// NewPrioritySelector returns a new PrioritySelector over the given queues, sorted by priority (highest first)
+func NewPrioritySelector(queues ...Queue) *PrioritySelector {
+	ret := &PrioritySelector{}
+	ret.initialize(queues)
+
+	return ret
+}
+
+func (st *PrioritySelector) initialize(queues []Queue) {
+	st.queues = queues
+	st.served = make([]int, len(queues))
+}
+
+// SetWeights sets the max number of elements in a row each queue (same order as the queues) gets served per round
+// before the lower priority queues get their turn, i.e. weights 3, 1 serves A A A B A A A B ... while both queues have
+// elements. A 0 weight means no limit. Returns error if there isn't a non negative weight per queue.
+func (st *PrioritySelector) SetWeights(weights ...int) error {
+	if len(weights) != len(st.queues) {
+		return NewQueueError(QueueErrorCodeInvalidConfig, fmt.Sprintf("%v weights for %v queues", len(weights), len(st.queues)))
+	}
+	for _, weight := range weights {
+		if weight < 0 {
+			return NewQueueError(QueueErrorCodeInvalidConfig, fmt.Sprintf("invalid weight: %v", weight))
+		}
+	}
+
+	st.mutex.Lock()
+	defer st.mutex.Unlock()
+
+	st.weights = append([]int(nil), weights...)
+	// new round
+	for i := range st.served {
+		st.served[i] = 0
+	}
+
+	return nil
+}
+
+// GetQueue returns the queue at the given priority (0 == highest). Returns error if the index is out of bounds.
+func (st *PrioritySelector) GetQueue(index int) (Queue, error) {
+	if index < 0 || index >= len(st.queues) {
+		return nil, NewQueueError(QueueErrorCodeIndexOutOfBounds, fmt.Sprintf("index out of bounds: %v", index))
+	}
+
+	return st.queues[index], nil
+}
+
+// Enqueue enqueues an element into the lowest priority queue (the default lane). Returns error if queue is locked or
+// there are no queues.
+func (st *PrioritySelector) Enqueue(value interface{}) error {
+	return st.EnqueueWithPriority(len(st.queues)-1, value)
+}
+
+// EnqueueWithPriority enqueues an element into the queue at the given priority (0 == highest). Returns error if queue
+// is locked, the index is out of bounds or the underlying queue rejects the element.
+func (st *PrioritySelector) EnqueueWithPriority(index int, value interface{}) error {
+	if st.IsLocked() {
+		return ErrLockedQueue
+	}
+
+	queue, err := st.GetQueue(index)
+	if err != nil {
+		return err
+	}
+
+	return queue.Enqueue(value)
+}
+
+// Dequeue dequeues an element from the highest priority queue having elements (and turn, see SetWeights). Returns
+// error if queue is locked or every queue is empty.
+func (st *PrioritySelector) Dequeue() (interface{}, error) {
+	if st.IsLocked() {
+		return nil, ErrLockedQueue
+	}
+
+	st.mutex.Lock()
+	defer st.mutex.Unlock()
+
+	value, ok := st.dequeue()
+	if !ok {
+		return nil, ErrEmptyQueue
+	}
+
+	return value, nil
+}
+
+// dequeue dequeues the next element following the priorities and weights. Queues returning error (empty, locked) are
+// skipped. st.mutex must be locked by the caller.
+func (st *PrioritySelector) dequeue() (interface{}, bool) {
+	for {
+		for i, queue := range st.queues {
+			if st.weights != nil && st.weights[i] > 0 && st.served[i] >= st.weights[i] {
+				// no more turns for this queue until the next round
+				continue
+			}
+
+			if value, err := queue.Dequeue(); err == nil {
+				st.served[i]++
+				return value, true
+			}
+		}
+
+		// the queues having turns are empty: the ones out of turns get a new round (if anything was served)
+		newRound := false
+		for i := range st.served {
+			if st.served[i] > 0 {
+				st.served[i] = 0
+				newRound = true
+			}
+		}
+		if !newRound {
+			return nil, false
+		}
+	}
+}
+
+// DequeueOrWaitForNextElement dequeues an element (if exist) or waits until the next element gets enqueued into any
+// of the queues and returns it. The underlying queues are polled (yielding first, then sleeping a bit between polls).
+func (st *PrioritySelector) DequeueOrWaitForNextElement() (interface{}, error) {
+	return st.DequeueOrWaitForNextElementWithContext(context.Background())
+}
+
+// DequeueOrWaitForNextElementWithContext works as DequeueOrWaitForNextElement, but it stops waiting once the context
+// is done, returning ctx.Err().
+func (st *PrioritySelector) DequeueOrWaitForNextElementWithContext(ctx context.Context) (interface{}, error) {
+	return pollForNextElement(ctx, st.Dequeue)
+}
+
+// GetLen returns the number of enqueued elements (all the queues)
+func (st *PrioritySelector) GetLen() int {
+	length := 0
+	for _, queue := range st.queues {
+		length += queue.GetLen()
+	}
+
+	return length
+}
+
+// GetCap returns the queues' capacity
+func (st *PrioritySelector) GetCap() int {
+	capacity := 0
+	for _, queue := range st.queues {
+		capacity += queue.GetCap()
+	}
+
+	return capacity
+}
+
+// Lock // Locks the selector (the underlying queues remain unlocked). No enqueue/dequeue operations will be allowed
+// through the selector after this point.
+func (st *PrioritySelector) Lock() {
+	st.lockRWmutex.Lock()
+	defer st.lockRWmutex.Unlock()
+
+	st.isLocked = true
+}
+
+// Unlock unlocks the selector
+func (st *PrioritySelector) Unlock() {
+	st.lockRWmutex.Lock()
+	defer st.lockRWmutex.Unlock()
+
+	st.isLocked = false
+}
+
+// IsLocked returns true whether the selector is locked
+func (st *PrioritySelector) IsLocked() bool {
+	st.lockRWmutex.RLock()
+	defer st.lockRWmutex.RUnlock()
+
+	return st.isLocked
+}
@@ -0,0 +1,172 @@
+package goconcurrentqueue
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type PrioritySelectorTestSuite struct {
+	suite.Suite
+	high     *FIFO
+	low      *FIFO
+	selector *PrioritySelector
+}
+
+func (suite *PrioritySelectorTestSuite) SetupTest() {
+	suite.high = NewFIFO()
+	suite.low = NewFIFO()
+	suite.selector = NewPrioritySelector(suite.high, suite.low)
+}
+
+// dequeueAll dequeues every element through the selector
+func (suite *PrioritySelectorTestSuite) dequeueAll() []interface{} {
+	elements := make([]interface{}, 0)
+	for {
+		value, err := suite.selector.Dequeue()
+		if err != nil {
+			suite.Equal(ErrEmptyQueue, err)
+			return elements
+		}
+		elements = append(elements, value)
+	}
+}
+
+// ***************************************************************************************
+// ** Initialization
+// ***************************************************************************************
+
+// no elements at initialization
+func (suite *PrioritySelectorTestSuite) TestNoElementsAtInitialization() {
+	suite.Equal(0, suite.selector.GetLen())
+	suite.False(suite.selector.IsLocked())
+
+	var queue Queue = suite.selector
+	suite.NotNil(queue)
+}
+
+// ***************************************************************************************
+// ** Enqueue
+// ***************************************************************************************
+
+// Enqueue goes to the lowest priority queue
+func (suite *PrioritySelectorTestSuite) TestEnqueue() {
+	suite.NoError(suite.selector.Enqueue(1))
+	suite.NoError(suite.selector.EnqueueWithPriority(0, 2))
+
+	suite.Equal(1, suite.low.GetLen())
+	suite.Equal(1, suite.high.GetLen())
+	suite.Equal(2, suite.selector.GetLen())
+}
+
+// invalid priority
+func (suite *PrioritySelectorTestSuite) TestEnqueueWithPriorityOutOfBounds() {
+	suite.Equal(QueueErrorCodeIndexOutOfBounds, errorCode(suite.selector.EnqueueWithPriority(2, 1)))
+	suite.Equal(QueueErrorCodeIndexOutOfBounds, errorCode(suite.selector.EnqueueWithPriority(-1, 1)))
+	suite.Equal(QueueErrorCodeIndexOutOfBounds, errorCode(NewPrioritySelector().Enqueue(1)))
+}
+
+// ***************************************************************************************
+// ** Dequeue
+// ***************************************************************************************
+
+// the higher priority queue gets drained first
+func (suite *PrioritySelectorTestSuite) TestDequeueStrictPriority() {
+	for i := 0; i < 3; i++ {
+		suite.low.Enqueue(i)
+		suite.high.Enqueue(10 + i)
+	}
+
+	suite.Equal([]interface{}{10, 11, 12, 0, 1, 2}, suite.dequeueAll())
+}
+
+// weights limit the consecutive elements per queue
+func (suite *PrioritySelectorTestSuite) TestDequeueWeights() {
+	suite.NoError(suite.selector.SetWeights(3, 1))
+	for i := 0; i < 7; i++ {
+		suite.high.Enqueue("A")
+	}
+	for i := 0; i < 3; i++ {
+		suite.low.Enqueue("B")
+	}
+
+	suite.Equal([]interface{}{"A", "A", "A", "B", "A", "A", "A", "B", "A", "B"}, suite.dequeueAll())
+}
+
+// an empty lower priority queue doesn't stop the higher priority one
+func (suite *PrioritySelectorTestSuite) TestDequeueWeightsEmptyLowPriority() {
+	suite.NoError(suite.selector.SetWeights(2, 1))
+	for i := 0; i < 5; i++ {
+		suite.high.Enqueue(i)
+	}
+
+	suite.Equal([]interface{}{0, 1, 2, 3, 4}, suite.dequeueAll())
+}
+
+// invalid weights
+func (suite *PrioritySelectorTestSuite) TestSetWeightsInvalid() {
+	suite.Equal(QueueErrorCodeInvalidConfig, errorCode(suite.selector.SetWeights(1)))
+	suite.Equal(QueueErrorCodeInvalidConfig, errorCode(suite.selector.SetWeights(1, -1)))
+}
+
+// underlying locked queues get skipped
+func (suite *PrioritySelectorTestSuite) TestDequeueLockedUnderlyingQueue() {
+	suite.high.Enqueue(1)
+	suite.low.Enqueue(2)
+	suite.high.Lock()
+
+	suite.Equal([]interface{}{2}, suite.dequeueAll())
+}
+
+// locked selector
+func (suite *PrioritySelectorTestSuite) TestLock() {
+	suite.high.Enqueue(1)
+	suite.selector.Lock()
+	suite.True(suite.selector.IsLocked())
+
+	suite.Equal(ErrLockedQueue, suite.selector.Enqueue(1))
+	_, err := suite.selector.Dequeue()
+	suite.Equal(ErrLockedQueue, err)
+	_, err = suite.selector.DequeueOrWaitForNextElement()
+	suite.Equal(ErrLockedQueue, err)
+
+	suite.selector.Unlock()
+	value, err := suite.selector.Dequeue()
+	suite.NoError(err)
+	suite.Equal(1, value)
+}
+
+// ***************************************************************************************
+// ** DequeueOrWaitForNextElement
+// ***************************************************************************************
+
+// waits until an element gets enqueued into any queue
+func (suite *PrioritySelectorTestSuite) TestDequeueOrWaitForNextElement() {
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		suite.low.Enqueue(testValue)
+	}()
+
+	value, err := suite.selector.DequeueOrWaitForNextElement()
+	suite.NoError(err)
+	suite.Equal(testValue, value)
+}
+
+// context done while waiting
+func (suite *PrioritySelectorTestSuite) TestDequeueOrWaitForNextElementWithContext() {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err := suite.selector.DequeueOrWaitForNextElementWithContext(ctx)
+	suite.Equal(context.DeadlineExceeded, err)
+}
+
+// ***************************************************************************************
+// ** Run suite
+// ***************************************************************************************
+
+func TestPrioritySelectorTestSuite(t *testing.T) {
+	suite.Run(t, new(PrioritySelectorTestSuite))
+}
@@ -0,0 +1,270 @@
+package goconcurrentqueue
+
+import (
+	"fmt"
+	"math/rand"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Property based tests: random operation sequences run against every Queue's implementation listed at
+// propertyTestQueues, checking the invariants every queue must keep. New implementations only need to be added to
+// the list.
+
+const (
+	propertyTestSeeds           = 30
+	propertyTestOperations      = 500
+	propertyTestProducers       = 4
+	propertyTestConsumers       = 4
+	propertyTestElementsPerGR   = 250
+	propertyTestFixedFIFOCap    = 16
+	propertyTestMaxEnqueueTries = 1000000
+)
+
+type propertyTestQueue struct {
+	name     string
+	newQueue func() Queue
+	// 0 means no capacity limit
+	capacity int
+	// whether the queue could be accessed by multiple GRs
+	concurrent bool
+}
+
+var propertyTestQueues = []propertyTestQueue{
+	{name: "FIFO", newQueue: func() Queue { return NewFIFO() }, concurrent: true},
+	{name: "FixedFIFO", newQueue: func() Queue { return NewFixedFIFO(propertyTestFixedFIFOCap) }, capacity: propertyTestFixedFIFOCap, concurrent: true},
+	{name: "UnsynchronizedFIFO", newQueue: func() Queue { return NewUnsynchronizedFIFO() }},
+	{name: "OrderingKeyFIFO", newQueue: func() Queue { return NewOrderingKeyFIFO() }, concurrent: true},
+	{name: "BurstAbsorber", newQueue: func() Queue { return NewBurstAbsorber(propertyTestFixedFIFOCap/2, propertyTestFixedFIFOCap/2) }, capacity: propertyTestFixedFIFOCap, concurrent: true},
+	// prioritized by enqueue order, so it must behave as a FIFO queue
+	{name: "PriorityQueue", newQueue: func() Queue { return NewPriorityQueue(propertyTestLess) }, concurrent: true},
+	// every element with the same priority, so it must behave as a FIFO queue
+	{name: "StablePriorityQueue", newQueue: func() Queue { return NewStablePriorityQueue(func(a, b interface{}) bool { return false }) }, concurrent: true},
+	// no delays, so it must behave as a FIFO queue
+	{name: "DelayQueue", newQueue: func() Queue { return NewDelayQueue() }, concurrent: true},
+	{name: "TTLFIFO", newQueue: func() Queue { return NewTTLFIFO() }, concurrent: true},
+	// every element enqueued into the same (lowest priority) queue, so it must behave as a FIFO queue
+	{name: "PrioritySelector", newQueue: func() Queue { return NewPrioritySelector(NewFIFO(), NewFIFO()) }, concurrent: true},
+	// never overloaded, so it must behave as a FIFO queue
+	{name: "AdaptiveLIFO", newQueue: func() Queue { return NewAdaptiveLIFO(time.Hour) }, concurrent: true},
+	// pass-through middleware, so it must behave as the wrapped queue
+	{name: "Chain", newQueue: func() Queue { return Chain(NewFIFO(), MiddlewareFuncs{}) }, concurrent: true},
+	// a single producer and a single consumer only
+	{name: "SPSCQueue", newQueue: func() Queue { return NewSPSCQueue(propertyTestFixedFIFOCap) }, capacity: propertyTestFixedFIFOCap},
+}
+
+// propertyTestLess prioritizes the elements by enqueue order (per producer, for the concurrent tests)
+func propertyTestLess(a, b interface{}) bool {
+	switch a := a.(type) {
+	case int:
+		return a < b.(int)
+	case propertyTestElement:
+		return a.sequence < b.(propertyTestElement).sequence
+	}
+
+	return false
+}
+
+// ***************************************************************************************
+// ** Sequential operations vs a model
+// ***************************************************************************************
+
+// random sequences of Enqueue / Dequeue / Lock / Unlock / GetLen must behave exactly as a plain slice model
+func TestPropertySequentialOperations(t *testing.T) {
+	for _, implementation := range propertyTestQueues {
+		for seed := int64(0); seed < propertyTestSeeds; seed++ {
+			checkSequentialOperations(t, implementation, seed)
+		}
+	}
+}
+
+func checkSequentialOperations(t *testing.T, implementation propertyTestQueue, seed int64) {
+	var (
+		random   = rand.New(rand.NewSource(seed))
+		queue    = implementation.newQueue()
+		model    []int
+		locked   bool
+		nextItem int
+	)
+
+	fail := func(step int, format string, args ...interface{}) {
+		t.Fatalf("%v (seed %v, step %v): %v", implementation.name, seed, step, fmt.Sprintf(format, args...))
+	}
+
+	for step := 0; step < propertyTestOperations; step++ {
+		switch operation := random.Intn(10); {
+		// enqueue (most frequent operation)
+		case operation < 5:
+			err := queue.Enqueue(nextItem)
+			switch {
+			case locked:
+				if errorCode(err) != QueueErrorCodeLockedQueue {
+					fail(step, "enqueue into a locked queue, expected locked error, got: %v", err)
+				}
+			case implementation.capacity > 0 && len(model) == implementation.capacity:
+				if errorCode(err) != QueueErrorCodeFullCapacity {
+					fail(step, "enqueue into a full queue, expected full capacity error, got: %v", err)
+				}
+			default:
+				if err != nil {
+					fail(step, "unexpected enqueue error: %v", err)
+				}
+				model = append(model, nextItem)
+			}
+			nextItem++
+
+		// dequeue
+		case operation < 8:
+			value, err := queue.Dequeue()
+			switch {
+			case locked:
+				if errorCode(err) != QueueErrorCodeLockedQueue {
+					fail(step, "dequeue from a locked queue, expected locked error, got: %v", err)
+				}
+			case len(model) == 0:
+				if errorCode(err) != QueueErrorCodeEmptyQueue {
+					fail(step, "dequeue from an empty queue, expected empty error, got: %v", err)
+				}
+			default:
+				if err != nil || value != model[0] {
+					fail(step, "expected dequeued value %v, got: %v (error: %v)", model[0], value, err)
+				}
+				model = model[1:]
+			}
+
+		// lock / unlock
+		case operation == 8:
+			if locked {
+				queue.Unlock()
+			} else {
+				queue.Lock()
+			}
+			locked = !locked
+			if queue.IsLocked() != locked {
+				fail(step, "expected locked state: %v", locked)
+			}
+
+		// non blocking DequeueOrWaitForNextElement (only if there are elements)
+		case len(model) > 0 && !locked:
+			value, err := queue.DequeueOrWaitForNextElement()
+			if err != nil || value != model[0] {
+				fail(step, "expected value %v, got: %v (error: %v)", model[0], value, err)
+			}
+			model = model[1:]
+		}
+
+		if length := queue.GetLen(); length != len(model) {
+			fail(step, "expected len %v, got: %v", len(model), length)
+		}
+	}
+}
+
+// ***************************************************************************************
+// ** Concurrent producers / consumers
+// ***************************************************************************************
+
+type propertyTestElement struct {
+	producer int
+	sequence int
+}
+
+// concurrent producers and consumers: every element gets delivered exactly once and each consumer sees the elements
+// of every producer in the same order they were enqueued
+func TestPropertyConcurrentProducersConsumers(t *testing.T) {
+	for _, implementation := range propertyTestQueues {
+		if !implementation.concurrent {
+			continue
+		}
+
+		for seed := int64(0); seed < propertyTestSeeds/10; seed++ {
+			checkConcurrentProducersConsumers(t, implementation, seed)
+		}
+	}
+}
+
+func checkConcurrentProducersConsumers(t *testing.T, implementation propertyTestQueue, seed int64) {
+	var (
+		queue     = implementation.newQueue()
+		wg        sync.WaitGroup
+		mutex     sync.Mutex
+		delivered = make(map[propertyTestElement]int)
+		total     = propertyTestProducers * propertyTestElementsPerGR
+		random    = rand.New(rand.NewSource(seed))
+	)
+
+	for p := 0; p < propertyTestProducers; p++ {
+		wg.Add(1)
+		// random pauses make every seed a different interleaving
+		pauseEvery := random.Intn(10) + 1
+		go func(producer int) {
+			defer wg.Done()
+			for i := 0; i < propertyTestElementsPerGR; i++ {
+				element := propertyTestElement{producer: producer, sequence: i}
+				for tries := 0; queue.Enqueue(element) != nil; tries++ {
+					// full capacity: wait for the consumers
+					if tries == propertyTestMaxEnqueueTries {
+						t.Errorf("%v (seed %v): can't enqueue %v", implementation.name, seed, element)
+						return
+					}
+					runtime.Gosched()
+				}
+				if i%pauseEvery == 0 {
+					runtime.Gosched()
+				}
+			}
+		}(p)
+	}
+
+	for c := 0; c < propertyTestConsumers; c++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			lastSequence := make(map[int]int)
+			for {
+				mutex.Lock()
+				if len(delivered) == total {
+					mutex.Unlock()
+					return
+				}
+				mutex.Unlock()
+
+				value, err := queue.Dequeue()
+				if err != nil {
+					runtime.Gosched()
+					continue
+				}
+
+				element := value.(propertyTestElement)
+				if last, ok := lastSequence[element.producer]; ok && last >= element.sequence {
+					t.Errorf("%v (seed %v): producer %v order broken, %v dequeued after %v", implementation.name, seed, element.producer, element.sequence, last)
+				}
+				lastSequence[element.producer] = element.sequence
+
+				mutex.Lock()
+				delivered[element]++
+				mutex.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if len(delivered) != total {
+		t.Fatalf("%v (seed %v): %v elements delivered, expected %v", implementation.name, seed, len(delivered), total)
+	}
+	for element, times := range delivered {
+		if times != 1 {
+			t.Fatalf("%v (seed %v): %v delivered %v times", implementation.name, seed, element, times)
+		}
+	}
+}
+
+// errorCode returns the QueueError's code ("" for any other error)
+func errorCode(err error) string {
+	if queueError, ok := err.(*QueueError); ok {
+		return queueError.Code()
+	}
+
+	return ""
+}
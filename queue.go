@@ -1,6 +1,12 @@
 package goconcurrentqueue
 
 // Queue interface with basic && common queue functions
+//
+// Concurrent-safe implementations (FIFO, FixedFIFO) guarantee that:
+//   - Enqueue of an element happens before the Dequeue / DequeueOrWaitForNextElement that returns it, so anything
+//     written before Enqueue is visible to the goroutine that gets the element.
+//   - Every enqueued element is returned at most once, either by a dequeue or by handing it to a waiting listener.
+//   - Lock / Unlock happen before any operation that observes the new locked state (IsLocked or the locked error).
 type Queue interface {
 	// Enqueue element
 	Enqueue(interface{}) error
@@ -21,3 +27,12 @@ type Queue interface {
 	// Return true whether the queue is locked
 	IsLocked() bool
 }
+
+// isEqual compares a and b using equals, or == if equals is nil
+func isEqual(equals func(a, b interface{}) bool, a, b interface{}) bool {
+	if equals == nil {
+		return a == b
+	}
+
+	return equals(a, b)
+}
@@ -0,0 +1,117 @@
+package goconcurrentqueue
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	// ToChannel / FromChannel wait before retrying a dequeue / enqueue that failed (i.e. locked queue)
+	channelRetryGapTime = time.Millisecond
+)
+
+// ToChannel returns a channel view of the queue, for consumers built around select loops: an internal pump dequeues
+// the elements (waiting for the next ones) and sends them through the returned channel, with the given buffer, until
+// ctx is done. The channel gets closed then; the elements already buffered can still be received, the one the pump
+// couldn't deliver goes back to the front of the queue. While the queue is locked the pump just waits.
+func (st *FIFO) ToChannel(ctx context.Context, buffer int) <-chan interface{} {
+	if buffer < 0 {
+		buffer = 0
+	}
+
+	ch := make(chan interface{}, buffer)
+	go st.pumpToChannel(ctx, ch)
+
+	return ch
+}
+
+// pumpToChannel sends the dequeued elements through ch until ctx is done, then it closes ch
+func (st *FIFO) pumpToChannel(ctx context.Context, ch chan<- interface{}) {
+	defer close(ch)
+
+	for {
+		// the element gets delivered (its future resolved) once it is sent, it could go back to the queue otherwise
+		elements, err := st.dequeueBatchOrWait(ctx, 1, 1, false)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+
+			// locked queue: retry in a while
+			select {
+			case <-time.After(channelRetryGapTime):
+				continue
+			case <-ctx.Done():
+				return
+			}
+		}
+
+		value := elements[0]
+		select {
+		case ch <- value:
+			resolveDequeuedFuture(value)
+		case <-ctx.Done():
+			// not delivered, it isn't lost
+			st.requeueElements([]interface{}{value})
+			return
+		}
+	}
+}
+
+// FromChannel enqueues the elements received from ch, in order, until ch gets closed (returning nil) or ctx is done
+// (returning ctx.Err()): bursty producers get buffered behind the queue. While the queue is locked it waits and
+// retries; the element being retried once ctx is done gets dropped. Returns the number of enqueued elements.
+func (st *FIFO) FromChannel(ctx context.Context, ch <-chan interface{}) (int, error) {
+	return fromChannel(ctx, ch, func(ctx context.Context, value interface{}) error {
+		return st.Enqueue(value)
+	})
+}
+
+// FromChannel enqueues the elements received from ch, in order, until ch gets closed (returning nil) or ctx is done
+// (returning ctx.Err()): bursty producers get buffered behind the queue. At full capacity it waits for a free slot
+// (see EnqueueWithContext), so the channel backs up, unless the overflow policy drops elements. Elements rejected by
+// the sampling admission are skipped. While the queue is locked it waits and retries; the element being retried (or
+// waiting for a slot) once ctx is done gets dropped. Returns the number of enqueued elements.
+func (st *FixedFIFO) FromChannel(ctx context.Context, ch <-chan interface{}) (int, error) {
+	return fromChannel(ctx, ch, st.EnqueueWithContext)
+}
+
+// fromChannel enqueues the elements received from ch until ch gets closed or ctx is done, retrying while the queue is
+// locked
+func fromChannel(ctx context.Context, ch <-chan interface{}, enqueue func(ctx context.Context, value interface{}) error) (int, error) {
+	enqueued := 0
+
+	for {
+		var value interface{}
+		select {
+		case received, ok := <-ch:
+			if !ok {
+				return enqueued, nil
+			}
+			value = received
+		case <-ctx.Done():
+			return enqueued, ctx.Err()
+		}
+
+		for {
+			err := enqueue(ctx, value)
+			if err == nil {
+				enqueued++
+				break
+			}
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return enqueued, ctxErr
+			}
+			if err != ErrLockedQueue {
+				// i.e. sampled out
+				break
+			}
+
+			select {
+			case <-time.After(channelRetryGapTime):
+			case <-ctx.Done():
+				return enqueued, ctx.Err()
+			}
+		}
+	}
+}
@@ -0,0 +1,235 @@
+package goconcurrentqueue
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type QueueChannelTestSuite struct {
+	suite.Suite
+	fifo *FIFO
+}
+
+func (suite *QueueChannelTestSuite) SetupTest() {
+	suite.fifo = NewFIFO()
+}
+
+// receive receives the next element from ch, failing if it takes too long
+func (suite *QueueChannelTestSuite) receive(ch <-chan interface{}) interface{} {
+	select {
+	case value, ok := <-ch:
+		suite.Require().True(ok, "the channel must be open")
+		return value
+	case <-time.After(2 * time.Second):
+		suite.FailNow("too much time waiting for the element")
+		return nil
+	}
+}
+
+// waitForClose waits until ch gets closed, returning the elements received in the meantime
+func (suite *QueueChannelTestSuite) waitForClose(ch <-chan interface{}) []interface{} {
+	elements := make([]interface{}, 0)
+	for {
+		select {
+		case value, ok := <-ch:
+			if !ok {
+				return elements
+			}
+			elements = append(elements, value)
+		case <-time.After(2 * time.Second):
+			suite.FailNow("the channel must be closed")
+			return nil
+		}
+	}
+}
+
+// ***************************************************************************************
+// ** ToChannel
+// ***************************************************************************************
+
+// the enqueued elements (before and after) come through the channel, in order
+func (suite *QueueChannelTestSuite) TestToChannel() {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	suite.fifo.Enqueue(1)
+	ch := suite.fifo.ToChannel(ctx, 0)
+	suite.Equal(1, suite.receive(ch))
+
+	suite.fifo.Enqueue(2)
+	suite.fifo.Enqueue(3)
+	suite.Equal(2, suite.receive(ch))
+	suite.Equal(3, suite.receive(ch))
+
+	// select loop
+	suite.fifo.Enqueue(4)
+	select {
+	case value := <-ch:
+		suite.Equal(4, value)
+	case <-time.After(2 * time.Second):
+		suite.FailNow("too much time waiting for the element")
+	}
+}
+
+// context done: the channel gets closed, the undelivered element goes back to the queue
+func (suite *QueueChannelTestSuite) TestToChannelContextDone() {
+	ctx, cancel := context.WithCancel(context.Background())
+	for i := 0; i < 3; i++ {
+		suite.fifo.Enqueue(i)
+	}
+
+	ch := suite.fifo.ToChannel(ctx, 1)
+	suite.Equal(0, suite.receive(ch))
+	// 1 buffered, 2 waiting to be sent
+	for i := 0; i < 1000 && suite.fifo.GetLen() > 0; i++ {
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+
+	elements := suite.waitForClose(ch)
+	suite.Equal(3-1-len(elements), suite.fifo.GetLen(), "no element gets lost")
+	if suite.fifo.GetLen() > 0 {
+		value, _ := suite.fifo.Dequeue()
+		suite.Equal(2, value)
+	}
+}
+
+// empty queue: the pump stops waiting once the context is done
+func (suite *QueueChannelTestSuite) TestToChannelEmptyQueue() {
+	ctx, cancel := context.WithCancel(context.Background())
+	ch := suite.fifo.ToChannel(ctx, -1)
+
+	cancel()
+	suite.Equal(0, len(suite.waitForClose(ch)))
+}
+
+// locked queue: the pump waits until it gets unlocked
+func (suite *QueueChannelTestSuite) TestToChannelLockedQueue() {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	suite.fifo.Enqueue(1)
+	suite.fifo.Lock()
+	ch := suite.fifo.ToChannel(ctx, 0)
+
+	select {
+	case <-ch:
+		suite.FailNow("no element comes out of a locked queue")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	suite.fifo.Unlock()
+	suite.Equal(1, suite.receive(ch))
+}
+
+// ***************************************************************************************
+// ** FromChannel
+// ***************************************************************************************
+
+// every element gets enqueued, in order, until the channel gets closed
+func (suite *QueueChannelTestSuite) TestFromChannel() {
+	ch := make(chan interface{})
+	go func() {
+		for i := 0; i < 100; i++ {
+			ch <- i
+		}
+		close(ch)
+	}()
+
+	enqueued, err := suite.fifo.FromChannel(context.Background(), ch)
+	suite.NoError(err)
+	suite.Equal(100, enqueued)
+	suite.Equal(100, suite.fifo.GetLen())
+	for i := 0; i < 100; i++ {
+		value, _ := suite.fifo.Dequeue()
+		suite.Equal(i, value)
+	}
+}
+
+// context done while waiting for the next element
+func (suite *QueueChannelTestSuite) TestFromChannelContextDone() {
+	ch := make(chan interface{}, 1)
+	ch <- 1
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	enqueued, err := suite.fifo.FromChannel(ctx, ch)
+	suite.Equal(context.DeadlineExceeded, err)
+	suite.Equal(1, enqueued)
+}
+
+// locked queue: it retries until it gets unlocked
+func (suite *QueueChannelTestSuite) TestFromChannelLockedQueue() {
+	ch := make(chan interface{}, 1)
+	ch <- 1
+	close(ch)
+
+	suite.fifo.Lock()
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		suite.fifo.Unlock()
+	}()
+
+	enqueued, err := suite.fifo.FromChannel(context.Background(), ch)
+	suite.NoError(err)
+	suite.Equal(1, enqueued)
+	suite.Equal(1, suite.fifo.GetLen())
+}
+
+// FixedFIFO: full capacity backs up the channel until there is room
+func (suite *QueueChannelTestSuite) TestFixedFIFOFromChannel() {
+	var (
+		fifo     = NewFixedFIFO(2)
+		ch       = make(chan interface{})
+		consumed = make(chan []interface{})
+	)
+
+	go func() {
+		for i := 0; i < 10; i++ {
+			ch <- i
+		}
+		close(ch)
+	}()
+	go func() {
+		elements := make([]interface{}, 0)
+		for len(elements) < 10 {
+			value, _ := fifo.DequeueOrWaitForNextElement()
+			elements = append(elements, value)
+		}
+		consumed <- elements
+	}()
+
+	enqueued, err := fifo.FromChannel(context.Background(), ch)
+	suite.NoError(err)
+	suite.Equal(10, enqueued)
+	suite.Equal([]interface{}{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}, <-consumed)
+}
+
+// FixedFIFO: sampled out elements are skipped
+func (suite *QueueChannelTestSuite) TestFixedFIFOFromChannelSampledOut() {
+	var (
+		fifo = NewFixedFIFO(10)
+		ch   = make(chan interface{}, 5)
+	)
+	suite.NoError(fifo.SetSamplingAdmission(2, 1))
+	for i := 0; i < 5; i++ {
+		ch <- i
+	}
+	close(ch)
+
+	enqueued, err := fifo.FromChannel(context.Background(), ch)
+	suite.NoError(err)
+	suite.Equal(2, enqueued)
+	suite.Equal(2, fifo.GetLen())
+}
+
+// ***************************************************************************************
+// ** Run suite
+// ***************************************************************************************
+
+func TestQueueChannelTestSuite(t *testing.T) {
+	suite.Run(t, new(QueueChannelTestSuite))
+}
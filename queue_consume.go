@@ -0,0 +1,434 @@
+package goconcurrentqueue
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	// Consume keeps up to this many handler errors (ConsumeError.Errors), the rest only get counted
+	consumeMaxErrors = 100
+)
+
+// ConsumeError is the aggregate of the errors returned (or panics recovered) by a Consume handler, along with the
+// elements no worker could handle (see ConsumeWorkers)
+type ConsumeError struct {
+	// first errors, in the order they happened (up to consumeMaxErrors)
+	Errors []error
+	// number of failed handler invocations
+	Total int
+}
+
+func (st *ConsumeError) Error() string {
+	if st.Total == 1 {
+		return fmt.Sprintf("consume: 1 handler error: %v", st.Errors[0])
+	}
+
+	return fmt.Sprintf("consume: %v handler errors, first: %v", st.Total, st.Errors[0])
+}
+
+// AffinityElement is implemented by the elements that only the Consume workers with a matching capability must
+// handle (i.e. "gpu", "eu-west"), see ConsumeWorkers. An empty affinity means any worker.
+type AffinityElement interface {
+	Affinity() string
+}
+
+// ConsumeOption is a Consume setting (i.e. ConsumeWorkers)
+type ConsumeOption func(options *consumeOptions) error
+
+// consumeOptions are the settings given to Consume
+type consumeOptions struct {
+	// workers per capability (ConsumeWorkers)
+	capabilities map[string]int
+	// elements processed at once (ConsumeMaxInFlight), 0 means no limit
+	maxInFlight int
+	// pauses the consumption while the handler fails too often (ConsumeCircuitBreaker)
+	breaker *CircuitBreaker
+	// elastic workers (ConsumeAutoscaler)
+	autoscaler *autoscaler
+}
+
+// ConsumeWorkers adds workers handling the elements whose affinity (AffinityElement) is capability, along with the
+// elements with no affinity. Returns error (at Consume) if capability is empty or workers isn't positive.
+func ConsumeWorkers(capability string, workers int) ConsumeOption {
+	return func(options *consumeOptions) error {
+		if capability == "" {
+			return NewQueueError(QueueErrorCodeInvalidConfig, "empty capability")
+		}
+		if workers < 1 {
+			return NewQueueError(QueueErrorCodeInvalidConfig, fmt.Sprintf("invalid workers: %v", workers))
+		}
+		if options.capabilities == nil {
+			options.capabilities = make(map[string]int)
+		}
+		options.capabilities[capability] += workers
+
+		return nil
+	}
+}
+
+// ConsumeMaxInFlight limits the elements being processed at once to max, no matter the number of workers (i.e. the
+// capability workers, see ConsumeWorkers, outnumber what this queue's downstream could take). The workers wait for a
+// free slot before dequeuing, so the rest of the elements stay at the queue. Returns error (at Consume) if max isn't
+// positive.
+func ConsumeMaxInFlight(max int) ConsumeOption {
+	return func(options *consumeOptions) error {
+		if max < 1 {
+			return NewQueueError(QueueErrorCodeInvalidConfig, fmt.Sprintf("invalid max in-flight: %v", max))
+		}
+		options.maxInFlight = max
+
+		return nil
+	}
+}
+
+// ConsumeCircuitBreaker pauses the consumption while the breaker is open: the workers don't dequeue until the
+// breaker's cooldown is over (the elements already dequeued get handled). The handler's outcomes feed the breaker.
+// Returns error (at Consume) if breaker is nil.
+func ConsumeCircuitBreaker(breaker *CircuitBreaker) ConsumeOption {
+	return func(options *consumeOptions) error {
+		if breaker == nil {
+			return NewQueueError(QueueErrorCodeInvalidConfig, "nil circuit breaker")
+		}
+		options.breaker = breaker
+
+		return nil
+	}
+}
+
+// Consume runs workers goroutines (at least 1) dequeuing the elements (waiting for the next ones) and invoking the
+// handler with each of them, until ctx is done. It returns once every worker is done: nil if every handler succeeded,
+// otherwise a *ConsumeError aggregating the handler errors. A failed handler doesn't stop the workers, the element is
+// not requeued. Panicking handlers are recovered and reported as QueueErrorCodeHandlerPanic errors. While the queue
+// is locked the workers just wait. See ConsumeWorkers (affinity dispatching), ConsumeMaxInFlight,
+// ConsumeCircuitBreaker and ConsumeAutoscaler.
+// Returns error if any option is invalid (nothing gets consumed).
+func (st *FIFO) Consume(ctx context.Context, workers int, handler func(value interface{}) error, opts ...ConsumeOption) error {
+	return consume(ctx, workers, handler, st, opts)
+}
+
+// Consume runs workers goroutines (at least 1) dequeuing the elements (waiting for the next ones) and invoking the
+// handler with each of them, until ctx is done. It returns once every worker is done: nil if every handler succeeded,
+// otherwise a *ConsumeError aggregating the handler errors. A failed handler doesn't stop the workers, the element is
+// not requeued. Panicking handlers are recovered and reported as QueueErrorCodeHandlerPanic errors. While the queue
+// is locked the workers just wait. See ConsumeWorkers (affinity dispatching), ConsumeMaxInFlight,
+// ConsumeCircuitBreaker and ConsumeAutoscaler.
+// Returns error if any option is invalid (nothing gets consumed).
+func (st *FixedFIFO) Consume(ctx context.Context, workers int, handler func(value interface{}) error, opts ...ConsumeOption) error {
+	return consume(ctx, workers, handler, st, opts)
+}
+
+// consumable is a queue Consume could run on
+type consumable interface {
+	DequeueOrWaitForNextElementWithContext(ctx context.Context) (interface{}, error)
+	GetLen() int
+	// the elements' waits (autoscaler's age target)
+	waits() *waitSLOTracker
+}
+
+func (st *FIFO) waits() *waitSLOTracker {
+	return st.waitSLO
+}
+
+func (st *FixedFIFO) waits() *waitSLOTracker {
+	return st.waitSLO
+}
+
+// consumer runs Consume's workers
+type consumer struct {
+	ctx     context.Context
+	handler func(value interface{}) error
+	queue   consumable
+	options consumeOptions
+	wg      sync.WaitGroup
+	// a slot per element being processed (ConsumeMaxInFlight), nil means no limit
+	inFlight chan struct{}
+	// handler errors
+	mutex    sync.Mutex
+	consumed ConsumeError
+}
+
+// consume runs the workers until ctx is done, aggregating the handler errors
+func consume(ctx context.Context, workers int, handler func(value interface{}) error, queue consumable, opts []ConsumeOption) error {
+	st := &consumer{
+		ctx:     ctx,
+		handler: handler,
+		queue:   queue,
+	}
+	for _, opt := range opts {
+		if err := opt(&st.options); err != nil {
+			return err
+		}
+	}
+	if st.options.autoscaler != nil && len(st.options.capabilities) > 0 {
+		return NewQueueError(QueueErrorCodeInvalidConfig, "ConsumeAutoscaler can't be combined with ConsumeWorkers")
+	}
+	if workers < 1 {
+		workers = 1
+	}
+	if st.options.maxInFlight > 0 {
+		st.inFlight = make(chan struct{}, st.options.maxInFlight)
+	}
+
+	if st.options.autoscaler != nil {
+		st.autoscale(workers)
+	} else if len(st.options.capabilities) == 0 {
+		st.wg.Add(workers)
+		for i := 0; i < workers; i++ {
+			go st.work()
+		}
+	} else {
+		st.dispatch(workers)
+	}
+	st.wg.Wait()
+
+	if st.consumed.Total == 0 {
+		return nil
+	}
+
+	return &st.consumed
+}
+
+// work dequeues and handles elements until ctx is done
+func (st *consumer) work() {
+	defer st.wg.Done()
+
+	for {
+		value, ok := st.next(st.ctx)
+		if !ok {
+			return
+		}
+		st.handle(value)
+	}
+}
+
+// autoscale runs the initial workers and adds new ones (every Interval) while the backlog / age exceed their targets,
+// until ctx is done (see ConsumeAutoscaler)
+func (st *consumer) autoscale(workers int) {
+	var (
+		scaler = st.options.autoscaler
+		waits  = st.queue.waits()
+	)
+	if scaler.config.TargetAge > 0 {
+		waits.addStamper(1)
+		defer waits.addStamper(-1)
+	}
+
+	workers = scaler.start(workers)
+	st.wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go st.workElastic()
+	}
+
+	ticker := time.NewTicker(scaler.config.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-st.ctx.Done():
+			return
+		}
+
+		if scaler.grow(st.queue.GetLen(), waits.lastWaitTime()) {
+			st.wg.Add(1)
+			go st.workElastic()
+		}
+	}
+}
+
+// workElastic works as work, but the worker exits once it waited IdleTimeout for an element (unless the autoscaler
+// keeps it, see ConsumeAutoscaler)
+func (st *consumer) workElastic() {
+	defer st.wg.Done()
+
+	idleTimeout := st.options.autoscaler.config.IdleTimeout
+	for {
+		ctx, cancel := st.ctx, context.CancelFunc(func() {})
+		if idleTimeout > 0 {
+			ctx, cancel = context.WithTimeout(st.ctx, idleTimeout)
+		}
+		value, ok := st.next(ctx)
+		cancel()
+		if ok {
+			st.handle(value)
+			continue
+		}
+
+		if st.ctx.Err() != nil || st.options.autoscaler.shrink() {
+			return
+		}
+	}
+}
+
+// acquire waits for an in-flight slot (if limited, see ConsumeMaxInFlight). Returns false once ctx is done.
+func (st *consumer) acquire(ctx context.Context) bool {
+	if st.inFlight == nil {
+		return true
+	}
+
+	select {
+	case st.inFlight <- struct{}{}:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// release frees an in-flight slot (if limited)
+func (st *consumer) release() {
+	if st.inFlight != nil {
+		<-st.inFlight
+	}
+}
+
+// next waits for the circuit breaker to close (if any) and for an in-flight slot, and dequeues the next element,
+// retrying while the queue is locked. Returns false once ctx (Consume's one or a worker's idle timeout) is done. The
+// slot is released once the element gets handled.
+func (st *consumer) next(ctx context.Context) (interface{}, bool) {
+	if st.options.breaker != nil && !st.options.breaker.wait(ctx) {
+		return nil, false
+	}
+	if !st.acquire(ctx) {
+		return nil, false
+	}
+
+	for {
+		// an element handed over right before ctx got done gets handled anyway
+		value, err := st.queue.DequeueOrWaitForNextElementWithContext(ctx)
+		if err == nil {
+			return value, true
+		}
+		if ctx.Err() != nil {
+			st.release()
+			return nil, false
+		}
+
+		// locked queue (or too many waiting consumers): retry in a while
+		select {
+		case <-time.After(channelRetryGapTime):
+		case <-ctx.Done():
+			st.release()
+			return nil, false
+		}
+	}
+}
+
+// dispatch dequeues the elements and hands them over, in order, to the workers with the capability matching their
+// affinity (any worker for the elements with no affinity). Every capability buffers up to as many elements as workers
+// it has, an element whose capability is busy and full holds the ones behind it (backpressure). Elements whose
+// affinity no worker has fail with a QueueErrorCodeNoCompatibleWorker error.
+// The workers keep going until the dispatcher is done and their lanes are empty, so no dequeued element is left
+// unhandled.
+func (st *consumer) dispatch(workers int) {
+	lanes := map[string]chan interface{}{"": make(chan interface{}, workers)}
+	for capability, capabilityWorkers := range st.options.capabilities {
+		lanes[capability] = make(chan interface{}, capabilityWorkers)
+	}
+
+	st.wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go st.workLanes(nil, lanes[""])
+	}
+	for capability, capabilityWorkers := range st.options.capabilities {
+		st.wg.Add(capabilityWorkers)
+		for i := 0; i < capabilityWorkers; i++ {
+			go st.workLanes(lanes[capability], lanes[""])
+		}
+	}
+
+	defer func() {
+		for _, lane := range lanes {
+			close(lane)
+		}
+	}()
+	for {
+		value, ok := st.next(st.ctx)
+		if !ok {
+			return
+		}
+
+		affinity := ""
+		if element, ok := value.(AffinityElement); ok {
+			affinity = element.Affinity()
+		}
+		lane, ok := lanes[affinity]
+		if !ok {
+			st.failed(NewQueueError(QueueErrorCodeNoCompatibleWorker, fmt.Sprintf("no worker with capability %q", affinity)))
+			st.release()
+			continue
+		}
+		lane <- value
+	}
+}
+
+// workLanes handles the elements dispatched to the worker's capability (own) or to any worker (shared), until both
+// lanes get closed
+func (st *consumer) workLanes(own chan interface{}, shared chan interface{}) {
+	defer st.wg.Done()
+
+	for own != nil || shared != nil {
+		// the capability's elements first: nobody else could handle them
+		select {
+		case value, ok := <-own:
+			if ok {
+				st.handle(value)
+				continue
+			}
+			own = nil
+		default:
+		}
+
+		select {
+		case value, ok := <-own:
+			if !ok {
+				own = nil
+				continue
+			}
+			st.handle(value)
+		case value, ok := <-shared:
+			if !ok {
+				shared = nil
+				continue
+			}
+			st.handle(value)
+		}
+	}
+}
+
+// handle invokes the handler with the element, recording its error (if any) and its outcome (circuit breaker), and
+// releases its in-flight slot
+func (st *consumer) handle(value interface{}) {
+	defer st.release()
+
+	err := handle(st.handler, value)
+	if st.options.breaker != nil {
+		st.options.breaker.record(err != nil, time.Now())
+	}
+	if err != nil {
+		st.failed(err)
+	}
+}
+
+// failed records a handler error
+func (st *consumer) failed(err error) {
+	st.mutex.Lock()
+	defer st.mutex.Unlock()
+
+	st.consumed.Total++
+	if len(st.consumed.Errors) < consumeMaxErrors {
+		st.consumed.Errors = append(st.consumed.Errors, err)
+	}
+}
+
+// handle invokes the handler, turning its panic (if any) into an error
+func handle(handler func(value interface{}) error, value interface{}) (err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = NewQueueError(QueueErrorCodeHandlerPanic, fmt.Sprintf("handler panicked: %v", recovered))
+		}
+	}()
+
+	return handler(value)
+}
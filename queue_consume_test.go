@@ -0,0 +1,262 @@
+package goconcurrentqueue
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type QueueConsumeTestSuite struct {
+	suite.Suite
+}
+
+// consumeUntil runs consume in a goroutine, cancelling it once n elements got handled (or it took too long)
+func (suite *QueueConsumeTestSuite) consumeUntil(n int, consume func(ctx context.Context, handler func(value interface{}) error) error, handler func(value interface{}) error) ([]interface{}, error) {
+	var (
+		ctx, cancel = context.WithTimeout(context.Background(), 2*time.Second)
+		mutex       sync.Mutex
+		handled     = make([]interface{}, 0)
+		done        = make(chan error, 1)
+	)
+	defer cancel()
+
+	go func() {
+		done <- consume(ctx, func(value interface{}) error {
+			mutex.Lock()
+			handled = append(handled, value)
+			if len(handled) == n {
+				cancel()
+			}
+			mutex.Unlock()
+
+			return handler(value)
+		})
+	}()
+
+	select {
+	case err := <-done:
+		if ctx.Err() == context.DeadlineExceeded {
+			suite.FailNow("too much time waiting for the elements to be handled")
+		}
+
+		mutex.Lock()
+		defer mutex.Unlock()
+		return handled, err
+	case <-time.After(3 * time.Second):
+		suite.FailNow("Consume didn't return once the context got done")
+		return nil, nil
+	}
+}
+
+// every element gets handled once, by several workers
+func (suite *QueueConsumeTestSuite) TestFIFOConsume() {
+	fifo := NewFIFO()
+	for i := 0; i < 100; i++ {
+		fifo.Enqueue(i)
+	}
+
+	handled, err := suite.consumeUntil(100, func(ctx context.Context, handler func(value interface{}) error) error {
+		return fifo.Consume(ctx, 4, handler)
+	}, func(value interface{}) error {
+		return nil
+	})
+
+	suite.NoError(err)
+	suite.Equal(100, len(handled))
+	suite.Equal(0, fifo.GetLen())
+}
+
+// workers wait for the next elements
+func (suite *QueueConsumeTestSuite) TestFixedFIFOConsumeWaits() {
+	fifo := NewFixedFIFO(10)
+	go func() {
+		for i := 0; i < 20; i++ {
+			fifo.EnqueueWithContext(context.Background(), i)
+			time.Sleep(time.Millisecond)
+		}
+	}()
+
+	handled, err := suite.consumeUntil(20, func(ctx context.Context, handler func(value interface{}) error) error {
+		return fifo.Consume(ctx, 0, handler)
+	}, func(value interface{}) error {
+		return nil
+	})
+
+	suite.NoError(err)
+	suite.Equal(20, len(handled))
+}
+
+// handler errors and panics get aggregated, the workers keep going
+func (suite *QueueConsumeTestSuite) TestConsumeErrors() {
+	var (
+		fifo     = NewFIFO()
+		errOdd   = errors.New("odd")
+		panicked = 0
+	)
+	for i := 0; i < 10; i++ {
+		fifo.Enqueue(i)
+	}
+
+	handled, err := suite.consumeUntil(10, func(ctx context.Context, handler func(value interface{}) error) error {
+		return fifo.Consume(ctx, 1, handler)
+	}, func(value interface{}) error {
+		if value.(int) == 4 {
+			panic("four")
+		}
+		if value.(int)%2 == 1 {
+			return errOdd
+		}
+		return nil
+	})
+
+	suite.Equal(10, len(handled))
+	suite.Require().IsType(&ConsumeError{}, err)
+	consumeError := err.(*ConsumeError)
+	suite.Equal(6, consumeError.Total)
+	suite.Equal(6, len(consumeError.Errors))
+	for _, handlerErr := range consumeError.Errors {
+		if queueError, ok := handlerErr.(*QueueError); ok {
+			suite.Equal(QueueErrorCodeHandlerPanic, queueError.Code())
+			panicked++
+			continue
+		}
+		suite.Equal(errOdd, handlerErr)
+	}
+	suite.Equal(1, panicked)
+}
+
+// locked queue: the workers wait until it gets unlocked
+func (suite *QueueConsumeTestSuite) TestConsumeLockedQueue() {
+	fifo := NewFIFO()
+	fifo.Enqueue(1)
+	fifo.Lock()
+	time.AfterFunc(20*time.Millisecond, fifo.Unlock)
+
+	handled, err := suite.consumeUntil(1, func(ctx context.Context, handler func(value interface{}) error) error {
+		return fifo.Consume(ctx, 2, handler)
+	}, func(value interface{}) error {
+		return nil
+	})
+
+	suite.NoError(err)
+	suite.Equal([]interface{}{1}, handled)
+}
+
+// affinityElement is an element only the workers with the capability handle
+type affinityElement struct {
+	id         int
+	capability string
+}
+
+func (st affinityElement) Affinity() string {
+	return st.capability
+}
+
+// the elements with affinity only get handled by the workers with the capability, the rest by any worker
+func (suite *QueueConsumeTestSuite) TestConsumeWorkers() {
+	var (
+		fifo      = NewFIFO()
+		mutex     sync.Mutex
+		active    = make(map[string]int)
+		maxActive = make(map[string]int)
+	)
+	for i := 0; i < 6; i++ {
+		fifo.Enqueue(affinityElement{id: i, capability: "gpu"})
+	}
+	fifo.Enqueue(affinityElement{capability: "tpu"})
+	for i := 0; i < 6; i++ {
+		fifo.Enqueue(affinityElement{id: i})
+	}
+
+	handled, err := suite.consumeUntil(12, func(ctx context.Context, handler func(value interface{}) error) error {
+		return fifo.Consume(ctx, 3, handler, ConsumeWorkers("gpu", 1))
+	}, func(value interface{}) error {
+		capability := value.(affinityElement).capability
+		mutex.Lock()
+		active[capability]++
+		if active[capability] > maxActive[capability] {
+			maxActive[capability] = active[capability]
+		}
+		mutex.Unlock()
+
+		time.Sleep(5 * time.Millisecond)
+
+		mutex.Lock()
+		active[capability]--
+		mutex.Unlock()
+		return nil
+	})
+
+	suite.Equal(12, len(handled))
+	suite.Equal(1, maxActive["gpu"], "a single gpu worker")
+	suite.True(maxActive[""] > 1, "the elements with no affinity are handled by any worker")
+	suite.Require().IsType(&ConsumeError{}, err)
+	suite.Equal(1, err.(*ConsumeError).Total)
+	suite.Equal(QueueErrorCodeNoCompatibleWorker, errorCode(err.(*ConsumeError).Errors[0]))
+}
+
+// no more elements than max get processed at once, the rest stay at the queue
+func (suite *QueueConsumeTestSuite) TestConsumeMaxInFlight() {
+	var (
+		fifo      = NewFIFO()
+		mutex     sync.Mutex
+		active    = 0
+		maxActive = 0
+		maxLen    = 0
+	)
+	for i := 0; i < 10; i++ {
+		fifo.Enqueue(i)
+	}
+
+	handled, err := suite.consumeUntil(10, func(ctx context.Context, handler func(value interface{}) error) error {
+		return fifo.Consume(ctx, 8, handler, ConsumeMaxInFlight(2))
+	}, func(value interface{}) error {
+		mutex.Lock()
+		active++
+		if active > maxActive {
+			maxActive = active
+		}
+		if value == 0 {
+			maxLen = fifo.GetLen()
+		}
+		mutex.Unlock()
+
+		time.Sleep(5 * time.Millisecond)
+
+		mutex.Lock()
+		active--
+		mutex.Unlock()
+		return nil
+	})
+
+	suite.NoError(err)
+	suite.Equal(10, len(handled))
+	suite.Equal(2, maxActive)
+	suite.True(maxLen >= 8, "the waiting workers don't dequeue")
+}
+
+// invalid options: nothing gets consumed
+func (suite *QueueConsumeTestSuite) TestConsumeInvalidOptions() {
+	fifo := NewFIFO()
+	fifo.Enqueue(1)
+
+	for _, opt := range []ConsumeOption{ConsumeWorkers("", 1), ConsumeWorkers("gpu", 0), ConsumeMaxInFlight(0), ConsumeCircuitBreaker(nil)} {
+		err := fifo.Consume(context.Background(), 1, func(value interface{}) error {
+			return nil
+		}, opt)
+		suite.Equal(QueueErrorCodeInvalidConfig, errorCode(err))
+	}
+	suite.Equal(1, fifo.GetLen())
+}
+
+// ***************************************************************************************
+// ** Run suite
+// ***************************************************************************************
+
+func TestQueueConsumeTestSuite(t *testing.T) {
+	suite.Run(t, new(QueueConsumeTestSuite))
+}
@@ -0,0 +1,60 @@
+package goconcurrentqueue
+
+import (
+	"expvar"
+	"sync"
+)
+
+// expvarMutex serializes the name checks + registrations (expvar.Publish panics on duplicated names)
+var expvarMutex sync.Mutex
+
+// expvarQueueState is the JSON published under expvar for a queue
+type expvarQueueState struct {
+	Len      int    `json:"len"`
+	Cap      int    `json:"cap"`
+	Enqueued uint64 `json:"enqueued"`
+	Dequeued uint64 `json:"dequeued"`
+	Rejected uint64 `json:"rejected"`
+	PeakLen  int    `json:"peak_len"`
+	Waiters  int    `json:"waiters"`
+	Locked   bool   `json:"locked"`
+}
+
+// PublishExpvar registers the queue's length, capacity and Stats counters under expvar with the given name, so they
+// show up on /debug/vars. The values are read on every request. Returns error if name is already registered.
+func (st *FIFO) PublishExpvar(name string) error {
+	return publishExpvar(name, st, st.Stats)
+}
+
+// PublishExpvar registers the queue's length, capacity and Stats counters under expvar with the given name, so they
+// show up on /debug/vars. The values are read on every request. Returns error if name is already registered.
+func (st *FixedFIFO) PublishExpvar(name string) error {
+	return publishExpvar(name, st, st.Stats)
+}
+
+// publishExpvar registers the queue's state under expvar
+func publishExpvar(name string, queue Queue, stats func() QueueStats) error {
+	expvarMutex.Lock()
+	defer expvarMutex.Unlock()
+
+	if expvar.Get(name) != nil {
+		return NewQueueError(QueueErrorCodeDuplicatedName, "expvar name already registered: "+name)
+	}
+
+	expvar.Publish(name, expvar.Func(func() interface{} {
+		snapshot := stats()
+
+		return expvarQueueState{
+			Len:      snapshot.Len,
+			Cap:      queue.GetCap(),
+			Enqueued: snapshot.Enqueued,
+			Dequeued: snapshot.Dequeued,
+			Rejected: snapshot.Rejected,
+			PeakLen:  snapshot.PeakLen,
+			Waiters:  snapshot.Waiters,
+			Locked:   queue.IsLocked(),
+		}
+	}))
+
+	return nil
+}
@@ -0,0 +1,67 @@
+package goconcurrentqueue
+
+import (
+	"encoding/json"
+	"expvar"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type QueueExpvarTestSuite struct {
+	suite.Suite
+}
+
+// getExpvarQueueState returns the state published under name
+func (suite *QueueExpvarTestSuite) getExpvarQueueState(name string) expvarQueueState {
+	variable := expvar.Get(name)
+	suite.Require().NotNil(variable, "%v must be published", name)
+
+	var state expvarQueueState
+	suite.Require().NoError(json.Unmarshal([]byte(variable.String()), &state))
+
+	return state
+}
+
+// FIFO's state, read on every request
+func (suite *QueueExpvarTestSuite) TestFIFO() {
+	fifo := NewFIFO()
+	suite.NoError(fifo.PublishExpvar("goconcurrentqueue-test-fifo"))
+	suite.Equal(expvarQueueState{}, suite.getExpvarQueueState("goconcurrentqueue-test-fifo"))
+
+	for i := 0; i < 3; i++ {
+		fifo.Enqueue(i)
+	}
+	fifo.Dequeue()
+	fifo.Lock()
+	fifo.Enqueue(3)
+
+	state := suite.getExpvarQueueState("goconcurrentqueue-test-fifo")
+	suite.Equal(expvarQueueState{Len: 2, Cap: fifo.GetCap(), Enqueued: 3, Dequeued: 1, Rejected: 1, PeakLen: 3, Locked: true}, state)
+}
+
+// FixedFIFO's state
+func (suite *QueueExpvarTestSuite) TestFixedFIFO() {
+	fifo := NewFixedFIFO(2)
+	suite.NoError(fifo.PublishExpvar("goconcurrentqueue-test-fixed-fifo"))
+
+	for i := 0; i < 3; i++ {
+		fifo.Enqueue(i)
+	}
+
+	state := suite.getExpvarQueueState("goconcurrentqueue-test-fixed-fifo")
+	suite.Equal(expvarQueueState{Len: 2, Cap: 2, Enqueued: 2, Rejected: 1, PeakLen: 2}, state)
+}
+
+// duplicated names are rejected instead of panicking
+func (suite *QueueExpvarTestSuite) TestDuplicatedName() {
+	suite.NoError(NewFIFO().PublishExpvar("goconcurrentqueue-test-duplicated"))
+
+	err := NewFixedFIFO(1).PublishExpvar("goconcurrentqueue-test-duplicated")
+	suite.Error(err)
+	suite.Equal(QueueErrorCodeDuplicatedName, err.(*QueueError).Code())
+}
+
+func TestQueueExpvarTestSuite(t *testing.T) {
+	suite.Run(t, new(QueueExpvarTestSuite))
+}
@@ -0,0 +1,158 @@
+//go:build go1.18
+// +build go1.18
+
+package goconcurrentqueue
+
+import (
+	"reflect"
+)
+
+// GenericQueue is the typed version of Queue: same contract, elements of type T.
+// Every built-in queue is a GenericQueue[interface{}]; NewTypedQueue wraps any of them into a GenericQueue[T].
+type GenericQueue[T any] interface {
+	// Enqueue element
+	Enqueue(T) error
+	// Dequeue element
+	Dequeue() (T, error)
+	// DequeueOrWaitForNextElement dequeues an element (if exist) or waits until the next element gets enqueued and returns it.
+	DequeueOrWaitForNextElement() (T, error)
+	// Get number of enqueued elements
+	GetLen() int
+	// Get queue's capacity
+	GetCap() int
+
+	// Lock the queue. No enqueue/dequeue/remove/get operations will be allowed after this point.
+	Lock()
+	// Unlock the queue.
+	Unlock()
+	// Return true whether the queue is locked
+	IsLocked() bool
+}
+
+// compile-time assertions: the built-in queues satisfy GenericQueue
+var (
+	_ GenericQueue[interface{}] = Queue(nil)
+	_ GenericQueue[interface{}] = (*FIFO)(nil)
+	_ GenericQueue[interface{}] = (*FixedFIFO)(nil)
+	_ GenericQueue[interface{}] = (*UnsynchronizedFIFO)(nil)
+	_ GenericQueue[interface{}] = (*OrderingKeyFIFO)(nil)
+	_ GenericQueue[interface{}] = (*BurstAbsorber)(nil)
+	_ GenericQueue[interface{}] = (*PriorityQueue)(nil)
+	_ GenericQueue[interface{}] = (*DelayQueue)(nil)
+	_ GenericQueue[interface{}] = (*TTLFIFO)(nil)
+	_ GenericQueue[interface{}] = (*SPSCQueue)(nil)
+	_ GenericQueue[interface{}] = (*PrioritySelector)(nil)
+	_ GenericQueue[interface{}] = (*Gate)(nil)
+	_ GenericQueue[interface{}] = (*AdaptiveLIFO)(nil)
+	_ GenericQueue[interface{}] = (*TypedQueue[interface{}])(nil)
+)
+
+// TypedQueue is a GenericQueue[T] over a Queue. Elements should be enqueued through the TypedQueue only, otherwise the
+// dequeue operations return an invalid element type error for the elements that aren't a T.
+type TypedQueue[T any] struct {
+	queue Queue
+}
+
+// NewTypedQueue returns a new TypedQueue over the given queue
+func NewTypedQueue[T any](queue Queue) *TypedQueue[T] {
+	return &TypedQueue[T]{
+		queue: queue,
+	}
+}
+
+// Enqueue enqueues an element. Returns the underlying queue's error.
+func (st *TypedQueue[T]) Enqueue(value T) error {
+	return st.queue.Enqueue(value)
+}
+
+// Dequeue dequeues an element. Returns the underlying queue's error or an invalid element type error.
+func (st *TypedQueue[T]) Dequeue() (T, error) {
+	return st.typed(st.queue.Dequeue())
+}
+
+// DequeueOrWaitForNextElement dequeues an element (if exist) or waits until the next element gets enqueued and returns
+// it. Returns the underlying queue's error or an invalid element type error.
+func (st *TypedQueue[T]) DequeueOrWaitForNextElement() (T, error) {
+	return st.typed(st.queue.DequeueOrWaitForNextElement())
+}
+
+// typed converts a dequeued element into a T
+func (st *TypedQueue[T]) typed(value interface{}, err error) (T, error) {
+	var zero T
+	if err != nil {
+		return zero, err
+	}
+
+	// nil elements: only valid if T is an interface, pointer, map, ... (a nil int isn't 0)
+	if value == nil {
+		if !isNilable[T]() {
+			return zero, NewQueueError(QueueErrorCodeInvalidElementType, "invalid element type")
+		}
+		return zero, nil
+	}
+
+	typedValue, ok := value.(T)
+	if !ok {
+		return zero, NewQueueError(QueueErrorCodeInvalidElementType, "invalid element type")
+	}
+
+	return typedValue, nil
+}
+
+// isNilable returns true whether nil is a valid T
+func isNilable[T any]() bool {
+	switch reflect.TypeOf((*T)(nil)).Elem().Kind() {
+	case reflect.Interface, reflect.Ptr, reflect.Map, reflect.Slice, reflect.Chan, reflect.Func, reflect.UnsafePointer:
+		return true
+	default:
+		return false
+	}
+}
+
+// GetLen returns the number of enqueued elements
+func (st *TypedQueue[T]) GetLen() int {
+	return st.queue.GetLen()
+}
+
+// GetCap returns the queue's capacity
+func (st *TypedQueue[T]) GetCap() int {
+	return st.queue.GetCap()
+}
+
+// Lock locks the queue
+func (st *TypedQueue[T]) Lock() {
+	st.queue.Lock()
+}
+
+// Unlock unlocks the queue
+func (st *TypedQueue[T]) Unlock() {
+	st.queue.Unlock()
+}
+
+// IsLocked returns true whether the queue is locked
+func (st *TypedQueue[T]) IsLocked() bool {
+	return st.queue.IsLocked()
+}
+
+// ***************************************************************************************
+// ** Search
+// ***************************************************************************************
+
+// SearchableQueue is a queue whose elements could be searched (FIFO, UnsynchronizedFIFO)
+type SearchableQueue interface {
+	IndexOf(value interface{}, equals func(a, b interface{}) bool) int
+}
+
+// IndexOf returns the position of the first element equal (==) to value, or -1 if there is none. Elements that aren't
+// a T are never equal.
+func IndexOf[T comparable](queue SearchableQueue, value T) int {
+	return queue.IndexOf(value, func(a, b interface{}) bool {
+		element, ok := b.(T)
+		return ok && element == a.(T)
+	})
+}
+
+// Contains returns true whether there is an element equal (==) to value, see IndexOf
+func Contains[T comparable](queue SearchableQueue, value T) bool {
+	return IndexOf(queue, value) >= 0
+}
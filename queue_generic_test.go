@@ -0,0 +1,123 @@
+//go:build go1.18
+// +build go1.18
+
+package goconcurrentqueue
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type TypedQueueTestSuite struct {
+	suite.Suite
+	fifo  *FIFO
+	typed GenericQueue[int]
+}
+
+func (suite *TypedQueueTestSuite) SetupTest() {
+	suite.fifo = NewFIFO()
+	suite.typed = NewTypedQueue[int](suite.fifo)
+}
+
+// ***************************************************************************************
+// ** Enqueue / Dequeue
+// ***************************************************************************************
+
+// typed elements in, typed elements out
+func (suite *TypedQueueTestSuite) TestEnqueueDequeue() {
+	for i := 0; i < 10; i++ {
+		suite.NoError(suite.typed.Enqueue(i))
+	}
+	suite.Equal(10, suite.typed.GetLen())
+	suite.Equal(10, suite.fifo.GetLen())
+
+	for i := 0; i < 10; i++ {
+		value, err := suite.typed.Dequeue()
+		suite.NoError(err)
+		suite.Equal(i, value)
+	}
+}
+
+// underlying queue's errors
+func (suite *TypedQueueTestSuite) TestDequeueEmptyQueue() {
+	value, err := suite.typed.Dequeue()
+	suite.Equal(ErrEmptyQueue, err)
+	suite.Equal(0, value)
+}
+
+// element enqueued straight into the underlying queue
+func (suite *TypedQueueTestSuite) TestDequeueInvalidElementType() {
+	suite.fifo.Enqueue(testValue)
+
+	_, err := suite.typed.Dequeue()
+	suite.Error(err)
+	suite.Equal(QueueErrorCodeInvalidElementType, errorCode(err))
+}
+
+// nil elements for nilable types
+func (suite *TypedQueueTestSuite) TestDequeueNilElement() {
+	typed := NewTypedQueue[error](suite.fifo)
+	suite.NoError(typed.Enqueue(nil))
+
+	value, err := typed.Dequeue()
+	suite.NoError(err)
+	suite.Nil(value)
+}
+
+// nil elements (enqueued straight into the underlying queue) for non-nilable types
+func (suite *TypedQueueTestSuite) TestDequeueNilElementNonNilable() {
+	suite.fifo.Enqueue(nil)
+
+	value, err := suite.typed.Dequeue()
+	suite.Equal(QueueErrorCodeInvalidElementType, errorCode(err))
+	suite.Equal(0, value)
+
+	structs := NewTypedQueue[struct{}](suite.fifo)
+	suite.fifo.Enqueue(nil)
+	_, err = structs.Dequeue()
+	suite.Equal(QueueErrorCodeInvalidElementType, errorCode(err))
+
+	for _, nilable := range []func() bool{isNilable[*int], isNilable[[]int], isNilable[map[int]int], isNilable[chan int], isNilable[func()], isNilable[interface{}]} {
+		suite.True(nilable())
+	}
+}
+
+// ***************************************************************************************
+// ** Lock / Unlock / IsLocked
+// ***************************************************************************************
+
+func (suite *TypedQueueTestSuite) TestLock() {
+	suite.typed.Lock()
+	suite.True(suite.fifo.IsLocked())
+	suite.Equal(ErrLockedQueue, suite.typed.Enqueue(1))
+
+	suite.typed.Unlock()
+	suite.False(suite.typed.IsLocked())
+}
+
+// ***************************************************************************************
+// ** Search
+// ***************************************************************************************
+
+// comparable elements, no comparator needed
+func (suite *TypedQueueTestSuite) TestIndexOfContains() {
+	for i := 0; i < 5; i++ {
+		suite.typed.Enqueue(i)
+	}
+	suite.fifo.Enqueue("not an int")
+
+	suite.Equal(3, IndexOf(suite.fifo, 3))
+	suite.Equal(-1, IndexOf(suite.fifo, 5))
+	suite.True(Contains(suite.fifo, 0))
+	suite.True(Contains(suite.fifo, "not an int"))
+	suite.False(Contains(NewUnsynchronizedFIFO(), 0))
+}
+
+// ***************************************************************************************
+// ** Run suite
+// ***************************************************************************************
+
+func TestTypedQueueTestSuite(t *testing.T) {
+	suite.Run(t, new(TypedQueueTestSuite))
+}
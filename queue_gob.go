@@ -0,0 +1,77 @@
+package goconcurrentqueue
+
+import (
+	"bytes"
+	"encoding/gob"
+)
+
+// gobQueue is the gob encoded form of FIFO and FixedFIFO
+type gobQueue struct {
+	// FixedFIFO only
+	Capacity int
+	Elements []interface{}
+}
+
+// encodeGob encodes the given queue's form
+func encodeGob(queue gobQueue) ([]byte, error) {
+	var buffer bytes.Buffer
+	if err := gob.NewEncoder(&buffer).Encode(queue); err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
+
+// decodeGob decodes a queue's form
+func decodeGob(data []byte) (gobQueue, error) {
+	var queue gobQueue
+	err := gob.NewDecoder(bytes.NewReader(data)).Decode(&queue)
+
+	return queue, err
+}
+
+// GobEncode encodes the (non claimed) elements, in dequeue order, taken from a single consistent snapshot (see
+// GetAll), so the queue could be sent over net/rpc or saved with encoding/gob. The elements' concrete types (other than
+// the basic ones) must be registered through gob.Register. It works on locked queues too.
+func (st *FIFO) GobEncode() ([]byte, error) {
+	return encodeGob(gobQueue{Elements: st.snapshot()})
+}
+
+// GobDecode replaces the queue's elements with the decoded ones, in order (the waiting consumers get the first ones).
+// A zero value FIFO gets initialized. Returns error if data can't be decoded or the queue is locked.
+func (st *FIFO) GobDecode(data []byte) error {
+	queue, err := decodeGob(data)
+	if err != nil {
+		return err
+	}
+
+	if st.counters == nil {
+		st.initialize()
+	}
+
+	return st.replaceElements(queue.Elements)
+}
+
+// GobEncode encodes the capacity and the elements, in dequeue order, taken from a single consistent snapshot (see
+// MarshalJSON), so the queue could be sent over net/rpc or saved with encoding/gob. The elements' concrete types
+// (other than the basic ones) must be registered through gob.Register. It works on locked queues too.
+func (st *FixedFIFO) GobEncode() ([]byte, error) {
+	return encodeGob(gobQueue{Capacity: st.GetCap(), Elements: st.snapshot()})
+}
+
+// GobDecode replaces the queue's elements with the decoded ones, in order (the waiting consumers get the first ones).
+// A zero value FixedFIFO gets initialized with the encoded capacity (the overflow policy isn't encoded, it keeps the
+// default one), any other queue keeps its own configuration. Returns error if data
+// can't be decoded, the queue is locked or there are more elements than the queue's capacity.
+func (st *FixedFIFO) GobDecode(data []byte) error {
+	queue, err := decodeGob(data)
+	if err != nil {
+		return err
+	}
+
+	if st.queue == nil {
+		st.initialize(queue.Capacity)
+	}
+
+	return st.replaceElements(queue.Elements)
+}
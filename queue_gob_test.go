@@ -0,0 +1,143 @@
+package goconcurrentqueue
+
+import (
+	"bytes"
+	"encoding/gob"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type QueueGobTestSuite struct {
+	suite.Suite
+}
+
+// gobTestElement is a custom element type, registered for gob
+type gobTestElement struct {
+	ID   int
+	Name string
+}
+
+func init() {
+	gob.Register(gobTestElement{})
+}
+
+// gobTestState is a struct holding queues, as it could be sent over net/rpc
+type gobTestState struct {
+	Pending *FIFO
+	Recent  *FixedFIFO
+}
+
+// gobRoundTrip encodes and decodes value into target
+func (suite *QueueGobTestSuite) gobRoundTrip(value interface{}, target interface{}) {
+	var buffer bytes.Buffer
+	suite.Require().NoError(gob.NewEncoder(&buffer).Encode(value))
+	suite.Require().NoError(gob.NewDecoder(&buffer).Decode(target))
+}
+
+// ***************************************************************************************
+// ** FIFO
+// ***************************************************************************************
+
+// the elements (and their types) survive the round trip, the decoded queue is fully working
+func (suite *QueueGobTestSuite) TestFIFO() {
+	fifo := NewFIFO()
+	fifo.Enqueue(1)
+	fifo.Enqueue("two")
+	fifo.Enqueue(gobTestElement{ID: 3, Name: "three"})
+	fifo.Lock()
+
+	var decoded FIFO
+	suite.gobRoundTrip(fifo, &decoded)
+	suite.Equal(3, fifo.GetLen(), "encoding keeps the elements")
+
+	suite.Equal(3, decoded.GetLen())
+	suite.False(decoded.IsLocked())
+	for _, expected := range []interface{}{1, "two", gobTestElement{ID: 3, Name: "three"}} {
+		value, err := decoded.Dequeue()
+		suite.NoError(err)
+		suite.Equal(expected, value)
+	}
+	suite.NoError(decoded.Enqueue(4))
+}
+
+// locked target queue
+func (suite *QueueGobTestSuite) TestFIFOLockedQueue() {
+	fifo := NewFIFO()
+	fifo.Enqueue(1)
+	data, err := fifo.GobEncode()
+	suite.Require().NoError(err)
+
+	target := NewFIFO()
+	target.Lock()
+	suite.Equal(ErrLockedQueue, target.GobDecode(data))
+	suite.Error(target.GobDecode([]byte("invalid")))
+}
+
+// ***************************************************************************************
+// ** FixedFIFO
+// ***************************************************************************************
+
+// zero value: the encoded capacity gets restored
+func (suite *QueueGobTestSuite) TestFixedFIFO() {
+	fifo := NewFixedFIFO(3)
+	fifo.Enqueue(1)
+	fifo.Enqueue(2)
+
+	var decoded FixedFIFO
+	suite.gobRoundTrip(fifo, &decoded)
+	suite.Equal(3, decoded.GetCap())
+	suite.Equal(2, decoded.GetLen())
+	suite.NoError(decoded.Enqueue(3))
+	suite.Equal(ErrFullCapacity, decoded.Enqueue(4))
+
+	value, err := decoded.Dequeue()
+	suite.NoError(err)
+	suite.Equal(1, value)
+}
+
+// initialized queue: its own capacity is kept
+func (suite *QueueGobTestSuite) TestFixedFIFOCapacity() {
+	fifo := NewFixedFIFO(3)
+	for i := 0; i < 3; i++ {
+		fifo.Enqueue(i)
+	}
+	data, err := fifo.GobEncode()
+	suite.Require().NoError(err)
+
+	err = NewFixedFIFO(2).GobDecode(data)
+	suite.Error(err)
+	suite.Equal(QueueErrorCodeFullCapacity, err.(*QueueError).Code())
+
+	target := NewFixedFIFO(10)
+	suite.NoError(target.GobDecode(data))
+	suite.Equal(10, target.GetCap())
+	suite.Equal(3, target.GetLen())
+}
+
+// queues as struct fields
+func (suite *QueueGobTestSuite) TestStruct() {
+	state := gobTestState{Pending: NewFIFO(), Recent: NewKeepLatestFixedFIFO(2)}
+	state.Pending.Enqueue("job")
+	for i := 0; i < 5; i++ {
+		state.Recent.Enqueue(i)
+	}
+
+	var decoded gobTestState
+	suite.gobRoundTrip(state, &decoded)
+
+	value, err := decoded.Pending.Dequeue()
+	suite.NoError(err)
+	suite.Equal("job", value)
+	suite.Equal(2, decoded.Recent.GetCap())
+	value, _ = decoded.Recent.Dequeue()
+	suite.Equal(3, value)
+}
+
+// ***************************************************************************************
+// ** Run suite
+// ***************************************************************************************
+
+func TestQueueGobTestSuite(t *testing.T) {
+	suite.Run(t, new(QueueGobTestSuite))
+}
@@ -0,0 +1,205 @@
+package goconcurrentqueue
+
+import (
+	"log"
+	"sync/atomic"
+)
+
+// HookPanicPolicy defines what a queue does when one of its hooks (QueueHooks) panics
+type HookPanicPolicy int
+
+const (
+	// HookPanicPropagate lets the panic go up to the goroutine performing the operation (default). The queue's locks
+	// get released, so it remains usable, but the operation could have partially taken effect (i.e. the element got
+	// enqueued but the Enqueue caller panics)
+	HookPanicPropagate HookPanicPolicy = iota
+	// HookPanicRecover recovers the panic and reports it (see QueueHooks.OnPanic), the hook keeps being invoked
+	HookPanicRecover
+	// HookPanicDisable recovers the panic and reports it (see QueueHooks.OnPanic), the panicking hook doesn't get
+	// invoked anymore (the rest do) until the hooks get set again (SetHooks)
+	HookPanicDisable
+)
+
+// hooks' bits at QueueHooks.disabled, and names reported to OnPanic
+const (
+	hookOnEnqueue = iota
+	hookOnDequeue
+	hookOnEmpty
+	hookOnFull
+)
+
+var hookNames = [...]string{"OnEnqueue", "OnDequeue", "OnEmpty", "OnFull"}
+
+// QueueHooks are the callbacks invoked on a queue's lifecycle events (logging, metrics, autoscaling triggers) so
+// nobody needs to poll GetLen. nil callbacks are skipped. See FIFO.SetHooks and FixedFIFO.SetHooks.
+// The callbacks run synchronously at the goroutine performing the operation, most of them while the queue's lock is
+// held: they must be fast and must not invoke the queue (hand the work over to a channel / goroutine instead).
+// A panicking hook panics the operation unless PanicPolicy says otherwise.
+// Hooks observe the queue, not the callers: they get no context, since several events don't belong to the operation
+// firing them (an element handed over to a waiting consumer fires OnDequeue at the producer's goroutine). Request-scoped
+// values (tenant, trace) are available to the middlewares (see Chain).
+type QueueHooks struct {
+	// invoked with every enqueued element (the ones handed over to waiting consumers included)
+	OnEnqueue func(value interface{})
+	// invoked with every dequeued element (the ones handed over to waiting consumers included)
+	OnDequeue func(value interface{})
+	// invoked every time the queue becomes empty: the last element got dequeued or removed (i.e. FIFO.Truncate), or
+	// the queue got cleared
+	OnEmpty func()
+	// invoked every time an enqueue fills a bounded queue up
+	OnFull func()
+	// what to do when a hook panics
+	PanicPolicy HookPanicPolicy
+	// invoked with the hook's name (i.e. "OnEnqueue") and the recovered value every time a hook panics, unless the
+	// policy is HookPanicPropagate. nil logs it (standard logger).
+	OnPanic func(hook string, recovered interface{})
+	// hooks disabled by HookPanicDisable (bits), updated atomically
+	disabled uint32
+}
+
+// invoke invokes the hook following the panic policy
+func (st *QueueHooks) invoke(hook int, fn func()) {
+	if st.PanicPolicy == HookPanicPropagate {
+		fn()
+		return
+	}
+
+	if st.isDisabled(hook) {
+		return
+	}
+	defer st.recover(hook)
+	fn()
+}
+
+// invokeValue invokes the element's hook following the panic policy
+func (st *QueueHooks) invokeValue(hook int, fn func(value interface{}), value interface{}) {
+	if st.PanicPolicy == HookPanicPropagate {
+		fn(value)
+		return
+	}
+
+	if st.isDisabled(hook) {
+		return
+	}
+	defer st.recover(hook)
+	fn(value)
+}
+
+// isDisabled returns true whether the hook got disabled (HookPanicDisable)
+func (st *QueueHooks) isDisabled(hook int) bool {
+	return atomic.LoadUint32(&st.disabled)&(1<<uint(hook)) != 0
+}
+
+// recover recovers the hook's panic (if any): reports it and disables the hook (HookPanicDisable). It must be deferred.
+func (st *QueueHooks) recover(hook int) {
+	recovered := recover()
+	if recovered == nil {
+		return
+	}
+
+	if st.PanicPolicy == HookPanicDisable {
+		for {
+			disabled := atomic.LoadUint32(&st.disabled)
+			if atomic.CompareAndSwapUint32(&st.disabled, disabled, disabled|1<<uint(hook)) {
+				break
+			}
+		}
+	}
+
+	if st.OnPanic != nil {
+		st.OnPanic(hookNames[hook], recovered)
+		return
+	}
+	log.Printf("goconcurrentqueue: %s hook panicked: %v", hookNames[hook], recovered)
+}
+
+// enqueued invokes OnEnqueue (if any)
+func (st *QueueHooks) enqueued(value interface{}) {
+	if st != nil && st.OnEnqueue != nil {
+		st.invokeValue(hookOnEnqueue, st.OnEnqueue, value)
+	}
+}
+
+// handedOver invokes OnEnqueue and OnDequeue (if any) for an element handed over to a waiting consumer, it never was
+// at the queue. Futures get resolved, hooks or not.
+func (st *QueueHooks) handedOver(value interface{}) {
+	resolveDequeuedFuture(value)
+	st.enqueued(value)
+	if st != nil && st.OnDequeue != nil {
+		st.invokeValue(hookOnDequeue, st.OnDequeue, value)
+	}
+}
+
+// dequeued invokes OnDequeue (if any), and OnEmpty if length (the queue's length afterwards) is 0. Futures get
+// resolved, hooks or not.
+func (st *QueueHooks) dequeued(value interface{}, length int) {
+	resolveDequeuedFuture(value)
+	st.removed(value, length)
+}
+
+// removed works as dequeued, but the element isn't delivered yet (i.e. prefetched): its future doesn't get resolved
+func (st *QueueHooks) removed(value interface{}, length int) {
+	if st == nil {
+		return
+	}
+
+	if st.OnDequeue != nil {
+		st.invokeValue(hookOnDequeue, st.OnDequeue, value)
+	}
+	st.emptied(length)
+}
+
+// dequeuedAll invokes OnDequeue (if any) with every element, and OnEmpty if length (the queue's length afterwards) is
+// 0. Nothing gets invoked if there are no elements. Futures get resolved, hooks or not.
+func (st *QueueHooks) dequeuedAll(values []interface{}, length int) {
+	for _, value := range values {
+		resolveDequeuedFuture(value)
+	}
+	st.removedAll(values, length)
+}
+
+// removedAll works as dequeuedAll, but the elements aren't delivered yet (i.e. prefetched): their futures don't get
+// resolved
+func (st *QueueHooks) removedAll(values []interface{}, length int) {
+	if st == nil || len(values) == 0 {
+		return
+	}
+
+	if st.OnDequeue != nil {
+		for _, value := range values {
+			st.invokeValue(hookOnDequeue, st.OnDequeue, value)
+		}
+	}
+	st.emptied(length)
+}
+
+// emptied invokes OnEmpty (if any) if length is 0
+func (st *QueueHooks) emptied(length int) {
+	if st != nil && st.OnEmpty != nil && length == 0 {
+		st.invoke(hookOnEmpty, st.OnEmpty)
+	}
+}
+
+// filled invokes OnFull (if any) if length reached capacity
+func (st *QueueHooks) filled(length int, capacity int) {
+	if st != nil && st.OnFull != nil && capacity > 0 && length >= capacity {
+		st.invoke(hookOnFull, st.OnFull)
+	}
+}
+
+// atomicQueueHooks holds the hooks of a queue whose operations don't share a lock (FixedFIFO's dequeues are lock-free)
+type atomicQueueHooks struct {
+	value atomic.Value
+}
+
+// store replaces the hooks
+func (st *atomicQueueHooks) store(hooks QueueHooks) {
+	st.value.Store(&hooks)
+}
+
+// load returns the hooks, nil if they were never set
+func (st *atomicQueueHooks) load() *QueueHooks {
+	hooks, _ := st.value.Load().(*QueueHooks)
+
+	return hooks
+}
@@ -0,0 +1,374 @@
+package goconcurrentqueue
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type QueueHooksTestSuite struct {
+	suite.Suite
+	mutex    sync.Mutex
+	enqueued []interface{}
+	dequeued []interface{}
+	empty    int
+	full     int
+}
+
+func (suite *QueueHooksTestSuite) SetupTest() {
+	suite.enqueued = make([]interface{}, 0)
+	suite.dequeued = make([]interface{}, 0)
+	suite.empty = 0
+	suite.full = 0
+}
+
+// hooks returns the hooks recording the events
+func (suite *QueueHooksTestSuite) hooks() QueueHooks {
+	return QueueHooks{
+		OnEnqueue: func(value interface{}) {
+			suite.mutex.Lock()
+			defer suite.mutex.Unlock()
+			suite.enqueued = append(suite.enqueued, value)
+		},
+		OnDequeue: func(value interface{}) {
+			suite.mutex.Lock()
+			defer suite.mutex.Unlock()
+			suite.dequeued = append(suite.dequeued, value)
+		},
+		OnEmpty: func() {
+			suite.mutex.Lock()
+			defer suite.mutex.Unlock()
+			suite.empty++
+		},
+		OnFull: func() {
+			suite.mutex.Lock()
+			defer suite.mutex.Unlock()
+			suite.full++
+		},
+	}
+}
+
+// ***************************************************************************************
+// ** FIFO
+// ***************************************************************************************
+
+// enqueue / dequeue / empty events
+func (suite *QueueHooksTestSuite) TestFIFO() {
+	fifo := NewFIFO()
+	fifo.SetHooks(suite.hooks())
+
+	fifo.Enqueue(1)
+	fifo.EnqueueBatch([]interface{}{2, 3})
+	fifo.Dequeue()
+	fifo.DequeueUpTo(5)
+	suite.Equal([]interface{}{1, 2, 3}, suite.enqueued)
+	suite.Equal([]interface{}{1, 2, 3}, suite.dequeued)
+	suite.Equal(1, suite.empty, "only the last dequeue leaves the queue empty")
+	suite.Equal(0, suite.full, "FIFO is never full")
+
+	fifo.Enqueue(4)
+	fifo.Drain()
+	fifo.Enqueue(5)
+	fifo.Clear()
+	suite.Equal([]interface{}{1, 2, 3, 4}, suite.dequeued, "cleared elements aren't dequeued")
+	suite.Equal(3, suite.empty)
+
+	// removed hooks
+	fifo.SetHooks(QueueHooks{})
+	fifo.Enqueue(6)
+	fifo.Dequeue()
+	suite.Equal(5, len(suite.enqueued))
+}
+
+// elements handed over to waiting consumers are enqueued and dequeued, but the queue doesn't become empty
+func (suite *QueueHooksTestSuite) TestFIFOWaiters() {
+	var (
+		fifo = NewFIFO()
+		done = make(chan struct{})
+	)
+	fifo.SetHooks(suite.hooks())
+
+	go func() {
+		defer close(done)
+		fifo.DequeueOrWaitForNextElement()
+	}()
+	for i := 0; i < 1000 && fifo.Stats().Waiters == 0; i++ {
+		time.Sleep(time.Millisecond)
+	}
+
+	fifo.Enqueue(1)
+	<-done
+
+	suite.mutex.Lock()
+	defer suite.mutex.Unlock()
+	suite.Equal([]interface{}{1}, suite.enqueued)
+	suite.Equal([]interface{}{1}, suite.dequeued)
+	suite.Equal(0, suite.empty)
+}
+
+// claimed elements fire OnDequeue once they get removed
+func (suite *QueueHooksTestSuite) TestFIFOClaim() {
+	fifo := NewFIFO()
+	fifo.SetHooks(suite.hooks())
+
+	fifo.Enqueue(1)
+	claim, err := fifo.Claim(0)
+	suite.Require().NoError(err)
+	suite.Equal(0, len(suite.dequeued))
+
+	suite.NoError(claim.Remove())
+	suite.Equal([]interface{}{1}, suite.dequeued)
+	suite.Equal(1, suite.empty)
+}
+
+// removing the last elements (Remove, RemoveWhere, Filter, Truncate) empties the queue
+func (suite *QueueHooksTestSuite) TestFIFORemovals() {
+	fifo := NewFIFO()
+	fifo.SetHooks(suite.hooks())
+
+	fifo.Enqueue(1)
+	fifo.Enqueue(2)
+	suite.NoError(fifo.Remove(1))
+	suite.Equal(0, suite.empty)
+	suite.NoError(fifo.Remove(0))
+	suite.Equal(1, suite.empty)
+
+	fifo.Enqueue(3)
+	fifo.Enqueue(4)
+	suite.Equal(1, fifo.RemoveWhere(func(value interface{}) bool { return value == 3 }))
+	suite.Equal(1, suite.empty)
+	suite.Equal(1, fifo.RemoveWhere(func(value interface{}) bool { return true }))
+	suite.Equal(2, suite.empty)
+	suite.Equal(0, fifo.RemoveWhere(func(value interface{}) bool { return true }))
+	suite.Equal(2, suite.empty, "nothing removed")
+
+	fifo.Enqueue(5)
+	fifo.Filter(func(value interface{}) bool { return false })
+	suite.Equal(3, suite.empty)
+
+	fifo.Enqueue(6)
+	fifo.Enqueue(7)
+	suite.NoError(fifo.Truncate(1))
+	suite.Equal(3, suite.empty)
+	suite.NoError(fifo.Truncate(0))
+	suite.Equal(4, suite.empty)
+	suite.NoError(fifo.Truncate(0))
+	suite.Equal(4, suite.empty, "nothing removed")
+
+	suite.Equal([]interface{}{}, suite.dequeued, "removed elements aren't dequeued")
+}
+
+// a panicking hook doesn't leave the queue locked, no matter the dequeue path
+func (suite *QueueHooksTestSuite) TestFIFOPanickingHookReleasesLock() {
+	fifo := NewFIFO()
+	fifo.SetHooks(QueueHooks{
+		OnDequeue: func(value interface{}) {
+			panic(value)
+		},
+	})
+
+	dequeues := map[string]func(){
+		"Dequeue": func() {
+			fifo.Dequeue()
+		},
+		"DequeueOrWaitForNextElement": func() {
+			fifo.DequeueOrWaitForNextElement()
+		},
+		"DequeueOrWaitForNextElement (spinning)": func() {
+			fifo.SetWaitSpins(1)
+			defer fifo.SetWaitSpins(0)
+			fifo.DequeueOrWaitForNextElement()
+		},
+		"DequeueBatchOrWait": func() {
+			fifo.DequeueBatchOrWait(1, 1, time.Second)
+		},
+	}
+	for name, dequeue := range dequeues {
+		suite.Require().NoError(fifo.Enqueue(name))
+		suite.PanicsWithValue(name, dequeue, name)
+
+		done := make(chan int, 1)
+		go func() {
+			done <- fifo.GetLen()
+		}()
+		select {
+		case length := <-done:
+			suite.Equal(0, length, name)
+		case <-time.After(2 * time.Second):
+			suite.FailNow("the queue remained locked", name)
+		}
+	}
+}
+
+// ***************************************************************************************
+// ** FixedFIFO
+// ***************************************************************************************
+
+// enqueue / dequeue / empty / full events
+func (suite *QueueHooksTestSuite) TestFixedFIFO() {
+	fifo := NewFixedFIFO(2)
+	fifo.SetHooks(suite.hooks())
+
+	fifo.Enqueue(1)
+	fifo.Enqueue(2)
+	fifo.Enqueue(3)
+	suite.Equal([]interface{}{1, 2}, suite.enqueued, "rejected elements aren't enqueued")
+	suite.Equal(1, suite.full)
+
+	fifo.Dequeue()
+	suite.Equal(0, suite.empty)
+	fifo.Dequeue()
+	suite.Equal([]interface{}{1, 2}, suite.dequeued)
+	suite.Equal(1, suite.empty)
+
+	fifo.Enqueue(4)
+	fifo.Drain()
+	fifo.Enqueue(5)
+	fifo.Clear()
+	suite.Equal(3, suite.empty)
+}
+
+// the drop-oldest policy keeps the queue full: OnFull only once
+func (suite *QueueHooksTestSuite) TestFixedFIFODropOldest() {
+	fifo := NewKeepLatestFixedFIFO(2)
+	fifo.SetHooks(suite.hooks())
+
+	for i := 0; i < 5; i++ {
+		fifo.Enqueue(i)
+	}
+	suite.Equal(1, suite.full)
+	suite.Equal(5, len(suite.enqueued))
+	suite.Equal(0, len(suite.dequeued), "evicted elements aren't dequeued")
+}
+
+// ***************************************************************************************
+// ** Panic policy
+// ***************************************************************************************
+
+// panickingHooks returns hooks whose OnEnqueue panics, recording the reported panics
+func (suite *QueueHooksTestSuite) panickingHooks(policy HookPanicPolicy, panics *[]string) QueueHooks {
+	hooks := suite.hooks()
+	hooks.OnEnqueue = func(value interface{}) {
+		panic(value)
+	}
+	hooks.PanicPolicy = policy
+	hooks.OnPanic = func(hook string, recovered interface{}) {
+		*panics = append(*panics, hook)
+	}
+
+	return hooks
+}
+
+// default: the panic gets to the caller
+func (suite *QueueHooksTestSuite) TestPanicPropagate() {
+	var (
+		fifo   = NewFIFO()
+		panics = make([]string, 0)
+	)
+	fifo.SetHooks(suite.panickingHooks(HookPanicPropagate, &panics))
+
+	suite.PanicsWithValue(1, func() {
+		fifo.Enqueue(1)
+	})
+	suite.Equal(0, len(panics))
+	// the lock got released
+	suite.Equal(1, fifo.GetLen())
+}
+
+// default: the dequeue hooks' panics get to the caller, the queues remain usable
+func (suite *QueueHooksTestSuite) TestPanicPropagateDequeue() {
+	var (
+		fifo      = NewFIFO()
+		fixedFIFO = NewFixedFIFO(2)
+		hooks     = QueueHooks{
+			OnEmpty: func() {
+				panic("empty")
+			},
+			PanicPolicy: HookPanicPropagate,
+		}
+	)
+	fifo.SetHooks(hooks)
+	fixedFIFO.SetHooks(hooks)
+
+	for _, queue := range []Queue{fifo, fixedFIFO} {
+		suite.NoError(queue.Enqueue(1))
+		suite.PanicsWithValue("empty", func() {
+			queue.Dequeue()
+		})
+		suite.Equal(0, queue.GetLen(), "the element got dequeued")
+
+		suite.NoError(queue.Enqueue(2))
+		suite.NoError(queue.Enqueue(3))
+		value, err := queue.DequeueOrWaitForNextElement()
+		suite.NoError(err)
+		suite.Equal(2, value)
+	}
+}
+
+// recovered panics get reported, the hook keeps being invoked
+func (suite *QueueHooksTestSuite) TestPanicRecover() {
+	var (
+		fifo   = NewFIFO()
+		panics = make([]string, 0)
+	)
+	fifo.SetHooks(suite.panickingHooks(HookPanicRecover, &panics))
+
+	suite.NotPanics(func() {
+		suite.NoError(fifo.Enqueue(1))
+		suite.NoError(fifo.Enqueue(2))
+	})
+	fifo.Drain()
+
+	suite.Equal([]string{"OnEnqueue", "OnEnqueue"}, panics)
+	suite.Equal([]interface{}{1, 2}, suite.dequeued, "the rest of the hooks keep working")
+	suite.Equal(1, suite.empty)
+}
+
+// the panicking hook gets disabled until the hooks get set again
+func (suite *QueueHooksTestSuite) TestPanicDisable() {
+	var (
+		fifo   = NewFixedFIFO(3)
+		panics = make([]string, 0)
+	)
+	fifo.SetHooks(suite.panickingHooks(HookPanicDisable, &panics))
+
+	suite.NotPanics(func() {
+		for i := 0; i < 3; i++ {
+			suite.NoError(fifo.Enqueue(i))
+		}
+	})
+	suite.Equal([]string{"OnEnqueue"}, panics)
+	suite.Equal(1, suite.full, "the rest of the hooks keep working")
+
+	fifo.Dequeue()
+	fifo.SetHooks(suite.panickingHooks(HookPanicDisable, &panics))
+	fifo.Enqueue(3)
+	suite.Equal([]string{"OnEnqueue", "OnEnqueue"}, panics)
+}
+
+// no OnPanic: recovered panics get logged
+func (suite *QueueHooksTestSuite) TestPanicRecoverWithoutOnPanic() {
+	fifo := NewFIFO()
+	hooks := suite.hooks()
+	hooks.OnEmpty = func() {
+		panic("empty")
+	}
+	hooks.PanicPolicy = HookPanicRecover
+	fifo.SetHooks(hooks)
+
+	fifo.Enqueue(1)
+	suite.NotPanics(func() {
+		_, err := fifo.Dequeue()
+		suite.NoError(err)
+	})
+}
+
+// ***************************************************************************************
+// ** Run suite
+// ***************************************************************************************
+
+func TestQueueHooksTestSuite(t *testing.T) {
+	suite.Run(t, new(QueueHooksTestSuite))
+}
@@ -0,0 +1,28 @@
+//go:build go1.23
+// +build go1.23
+
+package goconcurrentqueue
+
+import (
+	"iter"
+)
+
+// All returns an iterator over the enqueued (non claimed) elements and their positions, in dequeue order:
+//
+//	for i, value := range fifo.All() { ... }
+//
+// It iterates over a snapshot taken once the loop starts (see ForEach), so the loop body could access the queue
+// without deadlocking.
+func (st *FIFO) All() iter.Seq2[int, interface{}] {
+	return func(yield func(int, interface{}) bool) {
+		st.ForEach(yield)
+	}
+}
+
+// All returns an iterator over the enqueued elements and their positions, in dequeue order. It iterates over a
+// snapshot taken once the loop starts (see ForEach).
+func (st *UnsynchronizedFIFO) All() iter.Seq2[int, interface{}] {
+	return func(yield func(int, interface{}) bool) {
+		st.ForEach(yield)
+	}
+}
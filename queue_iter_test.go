@@ -0,0 +1,69 @@
+//go:build go1.23
+// +build go1.23
+
+package goconcurrentqueue
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type QueueIterTestSuite struct {
+	suite.Suite
+}
+
+// range over FIFO.All, in order
+func (suite *QueueIterTestSuite) TestFIFOAll() {
+	fifo := NewFIFO()
+	for i := 0; i < 5; i++ {
+		fifo.Enqueue(i * 10)
+	}
+
+	visited := 0
+	for i, value := range fifo.All() {
+		suite.Equal(visited, i)
+		suite.Equal(i*10, value)
+		visited++
+	}
+	suite.Equal(5, visited)
+}
+
+// break stops the iteration, the loop body could access the queue
+func (suite *QueueIterTestSuite) TestFIFOAllBreak() {
+	fifo := NewFIFO()
+	for i := 0; i < 5; i++ {
+		fifo.Enqueue(i)
+	}
+
+	visited := make([]interface{}, 0)
+	for i, value := range fifo.All() {
+		visited = append(visited, value)
+		fifo.Enqueue(value)
+		if i == 1 {
+			break
+		}
+	}
+	suite.Equal([]interface{}{0, 1}, visited)
+	suite.Equal(7, fifo.GetLen())
+}
+
+// range over UnsynchronizedFIFO.All
+func (suite *QueueIterTestSuite) TestUnsynchronizedFIFOAll() {
+	fifo := NewUnsynchronizedFIFO()
+	for i := 0; i < 3; i++ {
+		fifo.Enqueue(i)
+	}
+
+	visited := make([]interface{}, 0)
+	for _, value := range fifo.All() {
+		visited = append(visited, value)
+		fifo.Dequeue()
+	}
+	suite.Equal([]interface{}{0, 1, 2}, visited)
+	suite.Equal(0, fifo.GetLen())
+}
+
+func TestQueueIterTestSuite(t *testing.T) {
+	suite.Run(t, new(QueueIterTestSuite))
+}
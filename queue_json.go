@@ -0,0 +1,50 @@
+package goconcurrentqueue
+
+import (
+	"encoding/json"
+)
+
+// MarshalJSON encodes the (non claimed) elements as a JSON array, in dequeue order, taken from a single consistent
+// snapshot (see GetAll). It works on locked queues too.
+func (st *FIFO) MarshalJSON() ([]byte, error) {
+	return json.Marshal(st.snapshot())
+}
+
+// UnmarshalJSON replaces the queue's elements with the ones of the given JSON array, in order (the waiting consumers
+// get the first ones). The elements are decoded as encoding/json does into interface{} (float64, string, map...).
+// A zero value FIFO gets initialized. Returns error if the JSON isn't an array or the queue is locked.
+func (st *FIFO) UnmarshalJSON(data []byte) error {
+	var elements []interface{}
+	if err := json.Unmarshal(data, &elements); err != nil {
+		return err
+	}
+
+	if st.counters == nil {
+		st.initialize()
+	}
+
+	return st.replaceElements(elements)
+}
+
+// MarshalJSON encodes the elements as a JSON array, in dequeue order, taken from a single consistent snapshot: no
+// enqueue gets in while it is taken, concurrent Dequeue calls wait for it. It works on locked queues too.
+func (st *FixedFIFO) MarshalJSON() ([]byte, error) {
+	return json.Marshal(st.snapshot())
+}
+
+// UnmarshalJSON replaces the queue's elements with the ones of the given JSON array, in order (the waiting consumers
+// get the first ones). The elements are decoded as encoding/json does into interface{} (float64, string, map...).
+// A zero value FixedFIFO gets initialized with a capacity of the number of elements. Returns error if the JSON isn't
+// an array, the queue is locked or there are more elements than the queue's capacity.
+func (st *FixedFIFO) UnmarshalJSON(data []byte) error {
+	var elements []interface{}
+	if err := json.Unmarshal(data, &elements); err != nil {
+		return err
+	}
+
+	if st.queue == nil {
+		st.initialize(len(elements))
+	}
+
+	return st.replaceElements(elements)
+}
@@ -0,0 +1,193 @@
+package goconcurrentqueue
+
+import (
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type QueueJSONTestSuite struct {
+	suite.Suite
+}
+
+// ***************************************************************************************
+// ** FIFO
+// ***************************************************************************************
+
+// elements as a JSON array, in dequeue order
+func (suite *QueueJSONTestSuite) TestFIFOMarshal() {
+	fifo := NewFIFO()
+	data, err := json.Marshal(fifo)
+	suite.NoError(err)
+	suite.Equal("[]", string(data))
+
+	fifo.Enqueue(1)
+	fifo.Enqueue("two")
+	fifo.Enqueue(map[string]int{"three": 3})
+	fifo.Lock()
+
+	data, err = json.Marshal(fifo)
+	suite.NoError(err)
+	suite.Equal(`[1,"two",{"three":3}]`, string(data))
+	suite.Equal(3, fifo.GetLen(), "marshalling keeps the elements")
+}
+
+// claimed elements aren't marshalled
+func (suite *QueueJSONTestSuite) TestFIFOMarshalClaimed() {
+	fifo := NewFIFO()
+	fifo.Enqueue(1)
+	fifo.Enqueue(2)
+	_, err := fifo.Claim(0)
+	suite.Require().NoError(err)
+
+	data, err := json.Marshal(fifo)
+	suite.NoError(err)
+	suite.Equal("[2]", string(data))
+}
+
+// round trip into a zero value FIFO
+func (suite *QueueJSONTestSuite) TestFIFOUnmarshal() {
+	var fifo FIFO
+	suite.NoError(json.Unmarshal([]byte(`[1,"two",3]`), &fifo))
+	suite.Equal(3, fifo.GetLen())
+
+	value, err := fifo.Dequeue()
+	suite.NoError(err)
+	suite.Equal(float64(1), value)
+
+	// the elements get replaced
+	suite.NoError(json.Unmarshal([]byte(`[4]`), &fifo))
+	suite.Equal(1, fifo.GetLen())
+	value, _ = fifo.Dequeue()
+	suite.Equal(float64(4), value)
+}
+
+// waiting consumers get the unmarshalled elements
+func (suite *QueueJSONTestSuite) TestFIFOUnmarshalWaitingConsumer() {
+	var (
+		fifo   = NewFIFO()
+		result = make(chan interface{})
+	)
+
+	go func() {
+		value, _ := fifo.DequeueOrWaitForNextElement()
+		result <- value
+	}()
+	for i := 0; i < 1000 && fifo.Stats().Waiters == 0; i++ {
+		time.Sleep(time.Millisecond)
+	}
+
+	suite.NoError(fifo.UnmarshalJSON([]byte(`["a","b"]`)))
+	suite.Equal("a", <-result)
+	suite.Equal(1, fifo.GetLen())
+}
+
+// invalid JSON / locked queue: nothing changes
+func (suite *QueueJSONTestSuite) TestFIFOUnmarshalErrors() {
+	fifo := NewFIFO()
+	fifo.Enqueue(1)
+
+	suite.Error(json.Unmarshal([]byte(`{"a":1}`), fifo))
+	fifo.Lock()
+	suite.Equal(ErrLockedQueue, fifo.UnmarshalJSON([]byte(`[2]`)))
+	suite.Equal(1, fifo.GetLen())
+}
+
+// ***************************************************************************************
+// ** FixedFIFO
+// ***************************************************************************************
+
+// elements as a JSON array, in dequeue order, kept at the queue
+func (suite *QueueJSONTestSuite) TestFixedFIFOMarshal() {
+	fifo := NewFixedFIFO(3)
+	fifo.Enqueue(1)
+	fifo.Enqueue(2)
+	fifo.Lock()
+
+	data, err := json.Marshal(fifo)
+	suite.NoError(err)
+	suite.Equal("[1,2]", string(data))
+
+	fifo.Unlock()
+	for i := 1; i <= 2; i++ {
+		value, err := fifo.Dequeue()
+		suite.NoError(err)
+		suite.Equal(i, value)
+	}
+}
+
+// concurrent Dequeue calls never find the elements moved out by the snapshot (go test -race)
+func (suite *QueueJSONTestSuite) TestFixedFIFOMarshalConcurrentDequeue() {
+	var (
+		total = 1000
+		fifo  = NewFixedFIFO(total)
+		done  = make(chan struct{})
+		wg    sync.WaitGroup
+	)
+	for i := 0; i < total; i++ {
+		fifo.Enqueue(i)
+	}
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case <-done:
+				return
+			default:
+				_, err := json.Marshal(fifo)
+				suite.NoError(err)
+			}
+		}
+	}()
+
+	for i := 0; i < total; i++ {
+		value, err := fifo.Dequeue()
+		suite.Require().NoError(err)
+		suite.Equal(i, value)
+	}
+	close(done)
+	wg.Wait()
+}
+
+// round trip into a zero value FixedFIFO: capacity of the number of elements
+func (suite *QueueJSONTestSuite) TestFixedFIFOUnmarshal() {
+	var fifo FixedFIFO
+	suite.NoError(json.Unmarshal([]byte(`[1,2]`), &fifo))
+	suite.Equal(2, fifo.GetLen())
+	suite.Equal(2, fifo.GetCap())
+
+	value, err := fifo.Dequeue()
+	suite.NoError(err)
+	suite.Equal(float64(1), value)
+
+	// the elements get replaced
+	suite.NoError(json.Unmarshal([]byte(`["a"]`), &fifo))
+	data, _ := json.Marshal(&fifo)
+	suite.Equal(`["a"]`, string(data))
+}
+
+// more elements than capacity / locked queue: nothing changes
+func (suite *QueueJSONTestSuite) TestFixedFIFOUnmarshalErrors() {
+	fifo := NewFixedFIFO(2)
+	fifo.Enqueue(1)
+
+	err := fifo.UnmarshalJSON([]byte(`[1,2,3]`))
+	suite.Error(err)
+	suite.Equal(QueueErrorCodeFullCapacity, err.(*QueueError).Code())
+	fifo.Lock()
+	suite.Equal(ErrLockedQueue, fifo.UnmarshalJSON([]byte(`[2]`)))
+	suite.Equal(1, fifo.GetLen())
+}
+
+// ***************************************************************************************
+// ** Run suite
+// ***************************************************************************************
+
+func TestQueueJSONTestSuite(t *testing.T) {
+	suite.Run(t, new(QueueJSONTestSuite))
+}
@@ -0,0 +1,241 @@
+package goconcurrentqueue
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// LockHistogramBuckets is the number of LockHistogram buckets
+	LockHistogramBuckets = 21
+	// upper bound of the first LockHistogram bucket, every next bucket doubles it
+	lockHistogramFirstBound = time.Microsecond
+)
+
+// LockHistogram is a histogram of lock durations: Buckets[i] counts the durations up to LockHistogramBound(i), the
+// last bucket counts the longer ones
+type LockHistogram struct {
+	Buckets [LockHistogramBuckets]uint64
+	// number of recorded durations
+	Count uint64
+	// sum of the recorded durations
+	Total time.Duration
+}
+
+// LockHistogramBound returns the upper bound of the LockHistogram bucket i (from 1µs up to ~0.5s), the last bucket
+// has no upper bound (0)
+func LockHistogramBound(i int) time.Duration {
+	if i < 0 || i >= LockHistogramBuckets-1 {
+		return 0
+	}
+
+	return lockHistogramFirstBound << uint(i)
+}
+
+// Mean returns the mean recorded duration
+func (st LockHistogram) Mean() time.Duration {
+	if st.Count == 0 {
+		return 0
+	}
+
+	return st.Total / time.Duration(st.Count)
+}
+
+// LockStats is a snapshot of a queue's internal lock contention, see SetLockInstrumentation
+type LockStats struct {
+	// time waited to acquire the lock (exclusive and shared)
+	Wait LockHistogram
+	// time the lock was held exclusively (i.e. enqueues, dequeues, Drain)
+	Hold LockHistogram
+	// time the lock was held by readers (i.e. GetAll, GetLen), from the first reader in until the last one out
+	ReadHold LockHistogram
+}
+
+// lockHistogram is the LockHistogram updated atomically.
+// It must be allocated on its own (pointer, see lockCounters), so the 64-bit counters stay aligned on 32-bit platforms.
+type lockHistogram struct {
+	buckets [LockHistogramBuckets]uint64
+	count   uint64
+	total   int64
+}
+
+// record counts a duration
+func (st *lockHistogram) record(d time.Duration) {
+	bucket := 0
+	for bucket < LockHistogramBuckets-1 && d > LockHistogramBound(bucket) {
+		bucket++
+	}
+
+	atomic.AddUint64(&st.buckets[bucket], 1)
+	atomic.AddUint64(&st.count, 1)
+	atomic.AddInt64(&st.total, int64(d))
+}
+
+// snapshot returns the histogram's counters
+func (st *lockHistogram) snapshot() LockHistogram {
+	ret := LockHistogram{
+		Count: atomic.LoadUint64(&st.count),
+		Total: time.Duration(atomic.LoadInt64(&st.total)),
+	}
+	for i := range st.buckets {
+		ret.Buckets[i] = atomic.LoadUint64(&st.buckets[i])
+	}
+
+	return ret
+}
+
+// lockCounters are the LockStats histograms
+type lockCounters struct {
+	wait     lockHistogram
+	hold     lockHistogram
+	readHold lockHistogram
+}
+
+// instrumentedMutex is a sync.RWMutex optionally recording its acquisition wait and hold times (see
+// SetLockInstrumentation). Disabled (default) it costs an atomic load per Lock / Unlock.
+type instrumentedMutex struct {
+	sync.RWMutex
+	// 1 while instrumented, it only changes while the mutex is held exclusively (setInstrumented): stable during any
+	// other hold
+	instrumented uint32
+	// lazy initialized by setInstrumented, kept while disabled
+	counters *lockCounters
+	// exclusive hold's start, protected by the mutex itself
+	lockedAt time.Time
+	// readers holding the mutex and the start of their (shared) hold, protected by readersMutex
+	readers      int
+	readSince    time.Time
+	readersMutex sync.Mutex
+}
+
+// isInstrumented returns true whether the acquisition wait and hold times are being recorded
+func (st *instrumentedMutex) isInstrumented() bool {
+	return atomic.LoadUint32(&st.instrumented) == 1
+}
+
+// setInstrumented enables / disables the instrumentation. The mutex must be locked (exclusively) by the caller.
+func (st *instrumentedMutex) setInstrumented(enabled bool) {
+	if !enabled {
+		atomic.StoreUint32(&st.instrumented, 0)
+		return
+	}
+
+	if st.counters == nil {
+		st.counters = &lockCounters{}
+	}
+	atomic.StoreUint32(&st.instrumented, 1)
+}
+
+// Lock locks the mutex exclusively
+func (st *instrumentedMutex) Lock() {
+	if !st.isInstrumented() {
+		st.RWMutex.Lock()
+		return
+	}
+
+	start := time.Now()
+	st.RWMutex.Lock()
+	// the instrumentation could have been disabled while waiting
+	if st.isInstrumented() {
+		st.lockedAt = time.Now()
+		st.counters.wait.record(st.lockedAt.Sub(start))
+	}
+}
+
+// Unlock unlocks the mutex, held exclusively
+func (st *instrumentedMutex) Unlock() {
+	if !st.lockedAt.IsZero() {
+		if st.isInstrumented() {
+			st.counters.hold.record(time.Since(st.lockedAt))
+		}
+		st.lockedAt = time.Time{}
+	}
+
+	st.RWMutex.Unlock()
+}
+
+// RLock locks the mutex for reading
+func (st *instrumentedMutex) RLock() {
+	if !st.isInstrumented() {
+		st.RWMutex.RLock()
+		// the instrumentation could have been enabled while waiting: the hold counts (RUnlock decides the same way),
+		// the wait wasn't measured
+		if st.isInstrumented() {
+			st.addReader(time.Now())
+		}
+		return
+	}
+
+	start := time.Now()
+	st.RWMutex.RLock()
+	// the instrumentation could have been disabled while waiting
+	if !st.isInstrumented() {
+		return
+	}
+
+	now := time.Now()
+	st.counters.wait.record(now.Sub(start))
+	st.addReader(now)
+}
+
+// addReader counts a reader holding the mutex since now. The mutex must be read locked by the caller.
+func (st *instrumentedMutex) addReader(now time.Time) {
+	st.readersMutex.Lock()
+	defer st.readersMutex.Unlock()
+
+	if st.readers == 0 {
+		st.readSince = now
+	}
+	st.readers++
+}
+
+// RUnlock unlocks the mutex, held for reading
+func (st *instrumentedMutex) RUnlock() {
+	// the instrumentation only changes while the mutex is held exclusively, so it is the same it was once RLock got
+	// the mutex: RLock counted this reader if it is instrumented
+	if st.isInstrumented() {
+		st.readersMutex.Lock()
+		st.readers--
+		if st.readers == 0 {
+			st.counters.readHold.record(time.Since(st.readSince))
+		}
+		st.readersMutex.Unlock()
+	}
+
+	st.RWMutex.RUnlock()
+}
+
+// stats returns the recorded histograms, zero if it was never instrumented. The mutex must be locked (either way) by
+// the caller.
+func (st *instrumentedMutex) stats() LockStats {
+	if st.counters == nil {
+		return LockStats{}
+	}
+
+	return LockStats{
+		Wait:     st.counters.wait.snapshot(),
+		Hold:     st.counters.hold.snapshot(),
+		ReadHold: st.counters.readHold.snapshot(),
+	}
+}
+
+// SetLockInstrumentation enables / disables recording the internal lock's acquisition wait and hold times, reported by
+// Stats (QueueStats.Lock), i.e. to measure the cost of slow hooks or large GetAll calls. Disabled by default, the
+// histograms are kept while disabled.
+func (st *FIFO) SetLockInstrumentation(enabled bool) {
+	st.rwmutex.Lock()
+	defer st.rwmutex.Unlock()
+
+	st.rwmutex.setInstrumented(enabled)
+}
+
+// SetLockInstrumentation enables / disables recording the internal lock's acquisition wait and hold times, reported by
+// Stats (QueueStats.Lock). The lock serializes enqueues and waiting consumers' registrations, plain dequeues don't take
+// it. Disabled by default, the histograms are kept while disabled.
+func (st *FixedFIFO) SetLockInstrumentation(enabled bool) {
+	st.mutex.Lock()
+	defer st.mutex.Unlock()
+
+	st.mutex.setInstrumented(enabled)
+}
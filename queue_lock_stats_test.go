@@ -0,0 +1,203 @@
+package goconcurrentqueue
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type QueueLockStatsTestSuite struct {
+	suite.Suite
+}
+
+// ***************************************************************************************
+// ** LockHistogram
+// ***************************************************************************************
+
+// buckets' bounds double from 1µs, the last one has no bound
+func (suite *QueueLockStatsTestSuite) TestLockHistogramBound() {
+	suite.Equal(time.Microsecond, LockHistogramBound(0))
+	suite.Equal(2*time.Microsecond, LockHistogramBound(1))
+	suite.Equal(time.Duration(0), LockHistogramBound(LockHistogramBuckets-1))
+	suite.Equal(time.Duration(0), LockHistogramBound(-1))
+}
+
+// durations go to the first bucket whose bound they don't exceed
+func (suite *QueueLockStatsTestSuite) TestLockHistogramRecord() {
+	histogram := &lockHistogram{}
+	histogram.record(0)
+	histogram.record(time.Microsecond)
+	histogram.record(3 * time.Microsecond)
+	histogram.record(time.Hour)
+
+	snapshot := histogram.snapshot()
+	suite.Equal(uint64(2), snapshot.Buckets[0])
+	suite.Equal(uint64(1), snapshot.Buckets[2])
+	suite.Equal(uint64(1), snapshot.Buckets[LockHistogramBuckets-1])
+	suite.Equal(uint64(4), snapshot.Count)
+	suite.Equal(time.Hour+4*time.Microsecond, snapshot.Total)
+	suite.Equal(snapshot.Total/4, snapshot.Mean())
+	suite.Equal(time.Duration(0), LockHistogram{}.Mean())
+}
+
+// ***************************************************************************************
+// ** FIFO
+// ***************************************************************************************
+
+// not instrumented by default
+func (suite *QueueLockStatsTestSuite) TestFIFODisabled() {
+	fifo := NewFIFO()
+	fifo.Enqueue(1)
+	fifo.Dequeue()
+
+	suite.Equal(LockStats{}, fifo.Stats().Lock)
+}
+
+// long holds and the waits behind them get recorded
+func (suite *QueueLockStatsTestSuite) TestFIFOHoldAndWait() {
+	var (
+		fifo = NewFIFO()
+		hold = 20 * time.Millisecond
+		wg   sync.WaitGroup
+	)
+	fifo.SetLockInstrumentation(true)
+	fifo.SetHooks(QueueHooks{
+		OnEnqueue: func(value interface{}) {
+			// slow hook, runs with the lock held
+			if value == "slow" {
+				time.Sleep(hold)
+			}
+		},
+	})
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		fifo.Enqueue("slow")
+	}()
+	time.Sleep(hold / 4)
+	fifo.Enqueue("fast")
+	wg.Wait()
+
+	stats := fifo.Stats().Lock
+	suite.True(stats.Hold.Count >= 3)
+	suite.True(stats.Hold.Total >= hold)
+	suite.True(stats.Wait.Total >= hold/2, "the second enqueue waited for the slow one")
+}
+
+// long read holds get recorded as shared holds
+func (suite *QueueLockStatsTestSuite) TestFIFOReadHold() {
+	fifo := NewFIFO()
+	fifo.SetLockInstrumentation(true)
+
+	fifo.rwmutex.RLock()
+	time.Sleep(10 * time.Millisecond)
+	fifo.rwmutex.RUnlock()
+
+	suite.True(fifo.Stats().Lock.ReadHold.Total >= 10*time.Millisecond)
+}
+
+// disabled, the histograms are kept but don't change
+func (suite *QueueLockStatsTestSuite) TestFIFODisable() {
+	fifo := NewFIFO()
+	fifo.SetLockInstrumentation(true)
+	fifo.Enqueue(1)
+	fifo.SetLockInstrumentation(false)
+
+	stats := fifo.Stats().Lock
+	suite.NotEqual(LockStats{}, stats)
+
+	fifo.Enqueue(2)
+	fifo.Dequeue()
+	suite.Equal(stats, fifo.Stats().Lock)
+}
+
+// concurrent readers and writers while toggling the instrumentation (go test -race)
+func (suite *QueueLockStatsTestSuite) TestFIFOConcurrency() {
+	var (
+		fifo     = NewFIFO()
+		totalGRs = 4
+		wg       sync.WaitGroup
+	)
+
+	for gr := 0; gr < totalGRs; gr++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				fifo.Enqueue(i)
+				fifo.GetLen()
+				fifo.Dequeue()
+			}
+		}()
+	}
+	for i := 0; i < 10; i++ {
+		fifo.SetLockInstrumentation(i%2 == 0)
+		fifo.Stats()
+	}
+	wg.Wait()
+
+	suite.Equal(0, fifo.GetLen())
+}
+
+// readers blocked while the instrumentation gets enabled are counted: the read holds keep getting recorded
+func (suite *QueueLockStatsTestSuite) TestFIFOEnabledWhileReadersWait() {
+	var (
+		fifo     = NewFIFO()
+		totalGRs = 4
+		wg       sync.WaitGroup
+	)
+
+	fifo.rwmutex.Lock()
+	for gr := 0; gr < totalGRs; gr++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			fifo.GetLen()
+		}()
+	}
+	// the readers got past the (not instrumented) fast path and wait for the mutex
+	time.Sleep(20 * time.Millisecond)
+	fifo.rwmutex.setInstrumented(true)
+	fifo.rwmutex.Unlock()
+	wg.Wait()
+
+	fifo.rwmutex.readersMutex.Lock()
+	suite.Equal(0, fifo.rwmutex.readers)
+	fifo.rwmutex.readersMutex.Unlock()
+	readHold := fifo.Stats().Lock.ReadHold
+	suite.True(readHold.Count >= 1)
+
+	fifo.rwmutex.RLock()
+	time.Sleep(10 * time.Millisecond)
+	fifo.rwmutex.RUnlock()
+	suite.True(fifo.Stats().Lock.ReadHold.Total-readHold.Total >= 10*time.Millisecond)
+}
+
+// ***************************************************************************************
+// ** FixedFIFO
+// ***************************************************************************************
+
+// enqueues get recorded
+func (suite *QueueLockStatsTestSuite) TestFixedFIFO() {
+	fifo := NewFixedFIFO(3)
+	fifo.Enqueue(1)
+	suite.Equal(LockStats{}, fifo.Stats().Lock)
+
+	fifo.SetLockInstrumentation(true)
+	fifo.Enqueue(2)
+
+	stats := fifo.Stats().Lock
+	suite.True(stats.Hold.Count >= 1)
+	suite.True(stats.Wait.Count >= 1)
+}
+
+// ***************************************************************************************
+// ** Run suite
+// ***************************************************************************************
+
+func TestQueueLockStatsTestSuite(t *testing.T) {
+	suite.Run(t, new(QueueLockStatsTestSuite))
+}
@@ -0,0 +1,81 @@
+package goconcurrentqueue
+
+import (
+	"sync/atomic"
+)
+
+// QueueStats is a snapshot of a queue's counters and gauges, see FIFO.Stats and FixedFIFO.Stats
+type QueueStats struct {
+	// total enqueued elements (the ones handed over to waiting consumers included)
+	Enqueued uint64
+	// total dequeued elements (the ones handed over to waiting consumers included)
+	Dequeued uint64
+	// total rejected elements: enqueued into a locked queue, at full capacity or sampled out (FixedFIFO)
+	Rejected uint64
+	// current number of enqueued elements
+	Len int
+	// max number of enqueued elements at the same time
+	PeakLen int
+	// consumers waiting for the next element(s)
+	Waiters int
+	// internal lock's wait and hold times, zero unless instrumented (SetLockInstrumentation)
+	Lock LockStats
+	// queueing delay SLO, zero unless tracked (SetWaitSLO)
+	WaitSLO WaitSLOStats
+}
+
+// queueCounters are the QueueStats counters, updated atomically so the lock-free paths could update them too.
+// It must be allocated on its own (pointer), so the 64-bit counters stay aligned on 32-bit platforms.
+type queueCounters struct {
+	enqueued uint64
+	dequeued uint64
+	rejected uint64
+	peakLen  int64
+}
+
+func newQueueCounters() *queueCounters {
+	return &queueCounters{}
+}
+
+// enqueue counts n enqueued elements, length is the queue's length afterwards
+func (st *queueCounters) enqueue(n int, length int) {
+	atomic.AddUint64(&st.enqueued, uint64(n))
+	st.updatePeakLen(length)
+}
+
+// dequeue counts n dequeued elements
+func (st *queueCounters) dequeue(n int) {
+	atomic.AddUint64(&st.dequeued, uint64(n))
+}
+
+// undequeue discounts n dequeued elements, given back to the queue (see Prefetcher.Close)
+func (st *queueCounters) undequeue(n int) {
+	atomic.AddUint64(&st.dequeued, ^uint64(n-1))
+}
+
+// reject counts n rejected elements
+func (st *queueCounters) reject(n int) {
+	atomic.AddUint64(&st.rejected, uint64(n))
+}
+
+// updatePeakLen keeps the max length seen
+func (st *queueCounters) updatePeakLen(length int) {
+	for {
+		peak := atomic.LoadInt64(&st.peakLen)
+		if int64(length) <= peak || atomic.CompareAndSwapInt64(&st.peakLen, peak, int64(length)) {
+			return
+		}
+	}
+}
+
+// stats returns the counters snapshot along with the given gauges
+func (st *queueCounters) stats(length int, waiters int) QueueStats {
+	return QueueStats{
+		Enqueued: atomic.LoadUint64(&st.enqueued),
+		Dequeued: atomic.LoadUint64(&st.dequeued),
+		Rejected: atomic.LoadUint64(&st.rejected),
+		Len:      length,
+		PeakLen:  int(atomic.LoadInt64(&st.peakLen)),
+		Waiters:  waiters,
+	}
+}
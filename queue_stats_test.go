@@ -0,0 +1,146 @@
+package goconcurrentqueue
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type QueueStatsTestSuite struct {
+	suite.Suite
+}
+
+// waitForWaiters waits until the stats report the given waiters
+func (suite *QueueStatsTestSuite) waitForWaiters(stats func() QueueStats, waiters int) {
+	for i := 0; i < 1000 && stats().Waiters != waiters; i++ {
+		time.Sleep(time.Millisecond)
+	}
+	suite.Equal(waiters, stats().Waiters)
+}
+
+// ***************************************************************************************
+// ** FIFO
+// ***************************************************************************************
+
+// counters and gauges
+func (suite *QueueStatsTestSuite) TestFIFOStats() {
+	fifo := NewFIFO()
+	suite.Equal(QueueStats{}, fifo.Stats())
+
+	for i := 0; i < 5; i++ {
+		fifo.Enqueue(i)
+	}
+	fifo.Dequeue()
+	fifo.DequeueUpTo(2)
+	fifo.Lock()
+	fifo.Enqueue(5)
+	fifo.EnqueueBatch([]interface{}{6, 7})
+	fifo.Unlock()
+
+	suite.Equal(QueueStats{Enqueued: 5, Dequeued: 3, Rejected: 3, Len: 2, PeakLen: 5}, fifo.Stats())
+
+	fifo.Drain()
+	suite.Equal(QueueStats{Enqueued: 5, Dequeued: 5, Rejected: 3, Len: 0, PeakLen: 5}, fifo.Stats())
+}
+
+// elements handed over to waiting consumers count as enqueued and dequeued
+func (suite *QueueStatsTestSuite) TestFIFOStatsWaiters() {
+	var (
+		fifo      = NewFIFO()
+		consumers = 3
+		wg        sync.WaitGroup
+	)
+
+	for i := 0; i < consumers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			fifo.DequeueOrWaitForNextElement()
+		}()
+	}
+	suite.waitForWaiters(fifo.Stats, consumers)
+
+	for i := 0; i < consumers; i++ {
+		fifo.Enqueue(i)
+	}
+	wg.Wait()
+
+	suite.Equal(QueueStats{Enqueued: 3, Dequeued: 3}, fifo.Stats())
+}
+
+// prefetched elements given back don't count as dequeued
+func (suite *QueueStatsTestSuite) TestFIFOStatsPrefetcher() {
+	fifo := NewFIFO()
+	for i := 0; i < 10; i++ {
+		fifo.Enqueue(i)
+	}
+
+	prefetcher := NewPrefetcher(fifo, 5)
+	prefetcher.Dequeue()
+	suite.Equal(uint64(5), fifo.Stats().Dequeued)
+
+	prefetcher.Close()
+	suite.Equal(uint64(1), fifo.Stats().Dequeued)
+	suite.Equal(9, fifo.Stats().Len)
+}
+
+// ***************************************************************************************
+// ** FixedFIFO
+// ***************************************************************************************
+
+// counters and gauges
+func (suite *QueueStatsTestSuite) TestFixedFIFOStats() {
+	fifo := NewFixedFIFO(3)
+	for i := 0; i < 4; i++ {
+		fifo.Enqueue(i)
+	}
+	fifo.Dequeue()
+	fifo.Lock()
+	fifo.Enqueue(4)
+	fifo.Unlock()
+
+	suite.Equal(QueueStats{Enqueued: 3, Dequeued: 1, Rejected: 2, Len: 2, PeakLen: 3}, fifo.Stats())
+
+	fifo.Drain()
+	suite.Equal(QueueStats{Enqueued: 3, Dequeued: 3, Rejected: 2, Len: 0, PeakLen: 3}, fifo.Stats())
+}
+
+// dropped elements aren't rejected
+func (suite *QueueStatsTestSuite) TestFixedFIFOStatsDropOldest() {
+	fifo := NewKeepLatestFixedFIFO(2)
+	for i := 0; i < 5; i++ {
+		fifo.Enqueue(i)
+	}
+
+	suite.Equal(QueueStats{Enqueued: 5, Len: 2, PeakLen: 2}, fifo.Stats())
+	suite.Equal(uint64(3), fifo.GetEvictions())
+}
+
+// elements handed over to waiting consumers count as enqueued and dequeued
+func (suite *QueueStatsTestSuite) TestFixedFIFOStatsWaiters() {
+	var (
+		fifo = NewFixedFIFO(3)
+		done = make(chan struct{})
+	)
+
+	go func() {
+		defer close(done)
+		fifo.DequeueOrWaitForNextElement()
+	}()
+	suite.waitForWaiters(fifo.Stats, 1)
+
+	fifo.Enqueue(1)
+	<-done
+
+	suite.Equal(QueueStats{Enqueued: 1, Dequeued: 1}, fifo.Stats())
+}
+
+// ***************************************************************************************
+// ** Run suite
+// ***************************************************************************************
+
+func TestQueueStatsTestSuite(t *testing.T) {
+	suite.Run(t, new(QueueStatsTestSuite))
+}
@@ -0,0 +1,199 @@
+package goconcurrentqueue
+
+import (
+	"fmt"
+	"unsafe"
+)
+
+// transactionalQueue is implemented by the queues EnqueueAllQueues enqueues into atomically
+type transactionalQueue interface {
+	// lockAddress identifies the queue, multiple queues get locked in increasing lockAddress order (see lockInOrder)
+	lockAddress() uintptr
+	// lockEnqueues / unlockEnqueues lock / unlock the queue's enqueues
+	lockEnqueues()
+	unlockEnqueues()
+	// admit returns the error enqueueing value would return, nil if it would get enqueued. The queue's enqueues must
+	// be locked by the caller.
+	admit(value interface{}) error
+	// enqueueAdmitted enqueues the admitted value, returning the dropped elements (if any) along with the eviction
+	// handler. The queue's enqueues must be locked by the caller.
+	enqueueAdmitted(value interface{}) ([]interface{}, func(value interface{}))
+	// rejected counts the value as rejected (Stats)
+	rejected()
+}
+
+// EnqueueAllQueues enqueues the value into every given queue or into none of them, for fan-out writes that must not
+// partially succeed. The queues' enqueues get locked in a canonical order (so concurrent calls over the same queues
+// can't deadlock), then the value is enqueued only if every queue admits it. Nothing waits: a FixedFIFO at full
+// capacity rejects the value even if its overflow policy is OverflowPolicyBlock.
+// Returns the first refusing queue's error (i.e. ErrLockedQueue, ErrFullCapacity), or error if a queue is given twice
+// or it isn't a FIFO / FixedFIFO.
+func EnqueueAllQueues(value interface{}, queues ...Queue) error {
+	transactional := make([]transactionalQueue, 0, len(queues))
+	for _, queue := range queues {
+		tq, ok := queue.(transactionalQueue)
+		if !ok {
+			return NewQueueError(QueueErrorCodeUnsupportedQueue, fmt.Sprintf("unsupported queue type: %T", queue))
+		}
+		for _, previous := range transactional {
+			if previous.lockAddress() == tq.lockAddress() {
+				return NewQueueError(QueueErrorCodeDuplicatedQueue, "the same queue is given more than once")
+			}
+		}
+		transactional = append(transactional, tq)
+	}
+
+	evicted, err := enqueueAllLocked(value, transactional)
+	if err != nil {
+		return err
+	}
+
+	// the handlers are invoked out of the locks, so they could access the queues
+	for _, dropped := range evicted {
+		for _, element := range dropped.elements {
+			dropped.handler(element)
+		}
+	}
+
+	return nil
+}
+
+// evictedElements are the elements a queue dropped to make room for the value, along with its eviction handler
+type evictedElements struct {
+	elements []interface{}
+	handler  func(value interface{})
+}
+
+// enqueueAllLocked locks the queues (see lockInOrder) and enqueues the value into every queue if every queue admits
+// it. Returns the dropped elements, or the first refusing queue's error.
+func enqueueAllLocked(value interface{}, queues []transactionalQueue) ([]evictedElements, error) {
+	// deferred: the hooks invoked by enqueueAdmitted could panic
+	unlock := lockInOrder(queues)
+	defer unlock()
+
+	for _, queue := range queues {
+		if err := queue.admit(value); err != nil {
+			queue.rejected()
+			return nil, err
+		}
+	}
+
+	evicted := make([]evictedElements, 0)
+	for _, queue := range queues {
+		if elements, handler := queue.enqueueAdmitted(value); len(elements) > 0 {
+			evicted = append(evicted, evictedElements{elements: elements, handler: handler})
+		}
+	}
+
+	return evicted, nil
+}
+
+// lockInOrder locks the queues' enqueues in increasing lockAddress order, so any goroutine locking several queues
+// this way can't deadlock. Returns the function unlocking them.
+func lockInOrder(queues []transactionalQueue) func() {
+	// insertion sort, a few queues at most (sort.Slice needs go1.8)
+	ordered := make([]transactionalQueue, len(queues))
+	copy(ordered, queues)
+	for i := 1; i < len(ordered); i++ {
+		for j := i; j > 0 && ordered[j].lockAddress() < ordered[j-1].lockAddress(); j-- {
+			ordered[j], ordered[j-1] = ordered[j-1], ordered[j]
+		}
+	}
+
+	for _, queue := range ordered {
+		queue.lockEnqueues()
+	}
+
+	return func() {
+		for i := len(ordered) - 1; i >= 0; i-- {
+			ordered[i].unlockEnqueues()
+		}
+	}
+}
+
+// ***************************************************************************************
+// ** FIFO
+// ***************************************************************************************
+
+func (st *FIFO) lockAddress() uintptr {
+	return uintptr(unsafe.Pointer(st))
+}
+
+func (st *FIFO) lockEnqueues() {
+	st.rwmutex.Lock()
+}
+
+func (st *FIFO) unlockEnqueues() {
+	st.rwmutex.Unlock()
+}
+
+// admit only refuses the value if the queue is locked. st.rwmutex must be locked by the caller.
+func (st *FIFO) admit(value interface{}) error {
+	if st.IsLocked() {
+		return ErrLockedQueue
+	}
+
+	return nil
+}
+
+// enqueueAdmitted never drops elements. st.rwmutex must be locked by the caller.
+func (st *FIFO) enqueueAdmitted(value interface{}) ([]interface{}, func(value interface{})) {
+	st.enqueueElement(value)
+
+	return nil, nil
+}
+
+func (st *FIFO) rejected() {
+	st.counters.reject(1)
+}
+
+// ***************************************************************************************
+// ** FixedFIFO
+// ***************************************************************************************
+
+func (st *FixedFIFO) lockAddress() uintptr {
+	return uintptr(unsafe.Pointer(st))
+}
+
+func (st *FixedFIFO) lockEnqueues() {
+	st.mutex.Lock()
+}
+
+func (st *FixedFIFO) unlockEnqueues() {
+	st.mutex.Unlock()
+}
+
+// admit refuses the value if the queue is locked, the sampling admission rejects it or the queue is at full capacity
+// (unless a consumer is waiting for it or the overflow policy drops an element). Concurrent dequeues (lock-free) only
+// free slots, so the admitted value is guaranteed to fit. st.mutex must be locked by the caller.
+func (st *FixedFIFO) admit(value interface{}) error {
+	if st.IsLocked() {
+		return ErrLockedQueue
+	}
+
+	// handed over to the waiting consumer
+	if len(st.waitForNextElementChan) > 0 {
+		return nil
+	}
+
+	if st.sampledOut() {
+		return ErrSampledOut
+	}
+
+	if len(st.queue) >= cap(st.queue) && st.overflowPolicy != OverflowPolicyDropOldest && st.overflowPolicy != OverflowPolicyDropNewest {
+		return ErrFullCapacity
+	}
+
+	return nil
+}
+
+// enqueueAdmitted enqueues the value, already sampled by admit. st.mutex must be locked by the caller.
+func (st *FixedFIFO) enqueueAdmitted(value interface{}) ([]interface{}, func(value interface{})) {
+	evicted, evictionHandler, _ := st.enqueueLocked(value, false)
+
+	return evicted, evictionHandler
+}
+
+func (st *FixedFIFO) rejected() {
+	st.counters.reject(1)
+}
@@ -0,0 +1,162 @@
+package goconcurrentqueue
+
+import (
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type QueueTransactionTestSuite struct {
+	suite.Suite
+}
+
+// the value gets enqueued into every queue
+func (suite *QueueTransactionTestSuite) TestEnqueueAllQueues() {
+	var (
+		fifo      = NewFIFO()
+		fixedFIFO = NewFixedFIFO(2)
+	)
+
+	suite.NoError(EnqueueAllQueues(1, fifo, fixedFIFO))
+	suite.NoError(EnqueueAllQueues(2, fixedFIFO, fifo))
+
+	suite.Equal([]interface{}{1, 2}, fifo.Drain())
+	suite.Equal([]interface{}{1, 2}, fixedFIFO.Drain())
+	suite.NoError(EnqueueAllQueues(3))
+}
+
+// a refusing queue: no queue gets the value
+func (suite *QueueTransactionTestSuite) TestEnqueueAllQueuesNone() {
+	var (
+		fifo      = NewFIFO()
+		fixedFIFO = NewFixedFIFO(1)
+		locked    = NewFIFO()
+	)
+	fixedFIFO.Enqueue(0)
+
+	suite.Equal(ErrFullCapacity, EnqueueAllQueues(1, fifo, fixedFIFO))
+	suite.Equal(0, fifo.GetLen())
+	suite.Equal([]interface{}{0}, fixedFIFO.Drain())
+	suite.Equal(uint64(1), fixedFIFO.Stats().Rejected)
+
+	locked.Lock()
+	suite.Equal(ErrLockedQueue, EnqueueAllQueues(1, fifo, fixedFIFO, locked))
+	suite.Equal(0, fifo.GetLen())
+	suite.Equal(0, fixedFIFO.GetLen())
+}
+
+// waiting consumers get the value, drop policies make room for it
+func (suite *QueueTransactionTestSuite) TestEnqueueAllQueuesHandOverAndEvict() {
+	var (
+		rendezvous = NewRendezvousFIFO()
+		keepLatest = NewKeepLatestFixedFIFO(1)
+		evicted    = make([]interface{}, 0)
+		received   = make(chan interface{}, 1)
+	)
+	keepLatest.SetEvictionHandler(func(value interface{}) {
+		evicted = append(evicted, value)
+	})
+	keepLatest.Enqueue(0)
+
+	// no consumer waiting
+	suite.Equal(ErrFullCapacity, EnqueueAllQueues(1, rendezvous, keepLatest))
+
+	go func() {
+		value, _ := rendezvous.DequeueOrWaitForNextElement()
+		received <- value
+	}()
+	for rendezvous.Stats().Waiters == 0 {
+		// the consumer isn't waiting yet
+		runtime.Gosched()
+	}
+
+	suite.NoError(EnqueueAllQueues(1, rendezvous, keepLatest))
+	suite.Equal(1, <-received)
+	suite.Equal([]interface{}{1}, keepLatest.Drain())
+	suite.Equal([]interface{}{0}, evicted)
+}
+
+// unsupported and duplicated queues
+func (suite *QueueTransactionTestSuite) TestEnqueueAllQueuesInvalid() {
+	fifo := NewFIFO()
+
+	err := EnqueueAllQueues(1, fifo, NewUnsynchronizedFIFO())
+	suite.Require().IsType(&QueueError{}, err)
+	suite.Equal(QueueErrorCodeUnsupportedQueue, err.(*QueueError).Code())
+
+	err = EnqueueAllQueues(1, fifo, fifo)
+	suite.Require().IsType(&QueueError{}, err)
+	suite.Equal(QueueErrorCodeDuplicatedQueue, err.(*QueueError).Code())
+
+	suite.Equal(0, fifo.GetLen())
+}
+
+// a panicking hook doesn't leave the queues locked
+func (suite *QueueTransactionTestSuite) TestEnqueueAllQueuesPanickingHook() {
+	var (
+		fifo      = NewFIFO()
+		fixedFIFO = NewFixedFIFO(2)
+		done      = make(chan error, 1)
+	)
+	fixedFIFO.SetHooks(QueueHooks{
+		OnEnqueue: func(value interface{}) {
+			panic(value)
+		},
+	})
+
+	suite.PanicsWithValue(1, func() {
+		EnqueueAllQueues(1, fifo, fixedFIFO)
+	})
+
+	fixedFIFO.SetHooks(QueueHooks{})
+	go func() {
+		done <- EnqueueAllQueues(2, fifo, fixedFIFO)
+	}()
+	select {
+	case err := <-done:
+		suite.NoError(err)
+	case <-time.After(2 * time.Second):
+		suite.FailNow("the queues remained locked")
+	}
+	suite.Equal([]interface{}{1, 2}, fifo.Drain())
+	suite.Equal([]interface{}{1, 2}, fixedFIFO.Drain())
+}
+
+// concurrent calls over the same queues (in any order) and SwapQueues don't deadlock, every queue gets every value
+func (suite *QueueTransactionTestSuite) TestEnqueueAllQueuesConcurrent() {
+	var (
+		a, b, c = NewFIFO(), NewFIFO(), NewFixedFIFO(1000)
+		wg      sync.WaitGroup
+	)
+
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				if i%2 == 0 {
+					suite.NoError(EnqueueAllQueues(j, a, b, c))
+				} else {
+					suite.NoError(EnqueueAllQueues(j, c, b, a))
+				}
+				SwapQueues(b, a)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	suite.Equal(500, a.GetLen())
+	suite.Equal(500, b.GetLen())
+	suite.Equal(500, c.GetLen())
+}
+
+// ***************************************************************************************
+// ** Run suite
+// ***************************************************************************************
+
+func TestQueueTransactionTestSuite(t *testing.T) {
+	suite.Run(t, new(QueueTransactionTestSuite))
+}
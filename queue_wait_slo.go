@@ -0,0 +1,178 @@
+package goconcurrentqueue
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// waitSLOEpoch is the origin of the enqueue timestamps: time.Since(waitSLOEpoch) is monotonic, so wall clock jumps
+// don't distort the waits
+var waitSLOEpoch = time.Now()
+
+// WaitSLOStats is a snapshot of a queue's queueing delay SLO tracking, see FIFO.SetWaitSLO and FixedFIFO.SetWaitSLO
+type WaitSLOStats struct {
+	// max time an element should wait at the queue, 0 if the tracking is disabled
+	Threshold time.Duration
+	// size of the rolling window (last dequeued elements)
+	Window int
+	// dequeued elements within the window and the ones among them that waited longer than Threshold
+	Dequeued int
+	Breached int
+}
+
+// BreachRatio returns the fraction of the window's dequeued elements that waited longer than the threshold (0 if no
+// element got dequeued), i.e. the SLO burn to alert on
+func (st WaitSLOStats) BreachRatio() float64 {
+	if st.Dequeued == 0 {
+		return 0
+	}
+
+	return float64(st.Breached) / float64(st.Dequeued)
+}
+
+// waitSLOTracker records whether the last window dequeued elements waited longer than the threshold, along with the
+// last dequeued element's wait (Consume autoscaler's age target). It is concurrent-safe on its own (FixedFIFO's
+// lock-free dequeues). It must be allocated on its own (pointer), so the 64-bit fields stay aligned on 32-bit
+// platforms.
+type waitSLOTracker struct {
+	// threshold (nanoseconds), 0 while disabled; read atomically by stamp, written under mutex
+	threshold int64
+	// wait of the last dequeued tracked element (nanoseconds)
+	lastWait int64
+	// other users of the enqueue timestamps (Consume autoscalers with an age target): elements get stamped while any
+	stampers int32
+	mutex    sync.Mutex
+	// outcomes of the window (true == breached), in a ring
+	outcomes []bool
+	next     int
+	breached int
+}
+
+func newWaitSLOTracker() *waitSLOTracker {
+	return &waitSLOTracker{}
+}
+
+// set enables the tracking (threshold 0 disables it), starting a new window. Returns error if threshold is negative or
+// window isn't positive.
+func (st *waitSLOTracker) set(threshold time.Duration, window int) error {
+	if threshold < 0 {
+		return NewQueueError(QueueErrorCodeInvalidConfig, fmt.Sprintf("invalid threshold: %v", threshold))
+	}
+	if threshold > 0 && window < 1 {
+		return NewQueueError(QueueErrorCodeInvalidConfig, fmt.Sprintf("invalid window: %v", window))
+	}
+
+	st.mutex.Lock()
+	defer st.mutex.Unlock()
+
+	atomic.StoreInt64(&st.threshold, int64(threshold))
+	st.outcomes = nil
+	if threshold > 0 {
+		st.outcomes = make([]bool, 0, window)
+	}
+	st.next = 0
+	st.breached = 0
+
+	return nil
+}
+
+// enabled returns true whether the elements' waits are tracked: SLO or stampers
+func (st *waitSLOTracker) enabled() bool {
+	return atomic.LoadInt64(&st.threshold) > 0 || atomic.LoadInt32(&st.stampers) > 0
+}
+
+// addStamper registers (delta 1) / unregisters (delta -1) a user of the enqueue timestamps besides the SLO
+func (st *waitSLOTracker) addStamper(delta int32) {
+	atomic.AddInt32(&st.stampers, delta)
+}
+
+// stamp returns the enqueue timestamp of a new element, 0 (not tracked) while the tracking is disabled
+func (st *waitSLOTracker) stamp() int64 {
+	if !st.enabled() {
+		return 0
+	}
+
+	// never 0, that would mean not tracked
+	return int64(time.Since(waitSLOEpoch)) + 1
+}
+
+// dequeued records the waits of the elements dequeued right now, given their enqueue timestamps (see record)
+func (st *waitSLOTracker) dequeued(stamps ...int64) {
+	if !st.enabled() {
+		return
+	}
+
+	st.record(time.Now(), stamps...)
+}
+
+// record records the waits of the elements dequeued at now, given their enqueue timestamps (see stamp): the ones not
+// tracked (0) are skipped
+func (st *waitSLOTracker) record(now time.Time, stamps ...int64) {
+	st.mutex.Lock()
+	defer st.mutex.Unlock()
+
+	elapsed := int64(now.Sub(waitSLOEpoch)) + 1
+	for _, at := range stamps {
+		// enqueued while disabled
+		if at == 0 {
+			continue
+		}
+		atomic.StoreInt64(&st.lastWait, elapsed-at)
+		// SLO disabled in the meantime (or never enabled)
+		if st.threshold == 0 {
+			continue
+		}
+
+		breached := elapsed-at > st.threshold
+		window := cap(st.outcomes)
+		if len(st.outcomes) < window {
+			st.outcomes = append(st.outcomes, breached)
+		} else {
+			if st.outcomes[st.next] {
+				st.breached--
+			}
+			st.outcomes[st.next] = breached
+			st.next = (st.next + 1) % window
+		}
+		if breached {
+			st.breached++
+		}
+	}
+}
+
+// lastWaitTime returns how long the last dequeued tracked element waited
+func (st *waitSLOTracker) lastWaitTime() time.Duration {
+	return time.Duration(atomic.LoadInt64(&st.lastWait))
+}
+
+// stats returns the tracking's snapshot
+func (st *waitSLOTracker) stats() WaitSLOStats {
+	st.mutex.Lock()
+	defer st.mutex.Unlock()
+
+	return WaitSLOStats{
+		Threshold: time.Duration(st.threshold),
+		Window:    cap(st.outcomes),
+		Dequeued:  len(st.outcomes),
+		Breached:  st.breached,
+	}
+}
+
+// SetWaitSLO starts tracking the queueing delay SLO, reported by Stats (QueueStats.WaitSLO): the fraction of the last
+// window dequeued elements that waited at the queue longer than threshold (handed over elements didn't wait at all).
+// Only the elements enqueued from then on are tracked. threshold 0 disables it. Every call starts a new window.
+// Returns error if threshold is negative or window isn't positive.
+func (st *FIFO) SetWaitSLO(threshold time.Duration, window int) error {
+	return st.waitSLO.set(threshold, window)
+}
+
+// SetWaitSLO starts tracking the queueing delay SLO, reported by Stats (QueueStats.WaitSLO): the fraction of the last
+// window dequeued elements that waited at the queue longer than threshold (handed over elements didn't wait at all).
+// Only the elements enqueued from then on are tracked, elements dropped by the overflow policy aren't dequeued.
+// threshold 0 disables it. Every call starts a new window. Returns error if threshold is negative or window isn't
+// positive.
+func (st *FixedFIFO) SetWaitSLO(threshold time.Duration, window int) error {
+	return st.waitSLO.set(threshold, window)
+}
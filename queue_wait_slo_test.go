@@ -0,0 +1,121 @@
+package goconcurrentqueue
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+const (
+	waitSLOTestThreshold = 5 * time.Millisecond
+)
+
+type WaitSLOTestSuite struct {
+	suite.Suite
+}
+
+// the window keeps the last outcomes, the not tracked elements are skipped
+func (suite *WaitSLOTestSuite) TestRecord() {
+	tracker := newWaitSLOTracker()
+	suite.Require().NoError(tracker.set(time.Second, 3))
+
+	var (
+		enqueuedAt = int64(time.Minute)
+		now        = waitSLOEpoch.Add(time.Minute)
+	)
+	tracker.record(now.Add(2*time.Second), enqueuedAt, enqueuedAt, 0)
+	tracker.record(now, enqueuedAt)
+	suite.Equal(WaitSLOStats{Threshold: time.Second, Window: 3, Dequeued: 3, Breached: 2}, tracker.stats())
+	suite.InDelta(2.0/3, tracker.stats().BreachRatio(), 0.001)
+
+	// the breaches roll out of the window
+	tracker.record(now, enqueuedAt, enqueuedAt)
+	suite.Equal(0, tracker.stats().Breached)
+
+	// a new window
+	suite.NoError(tracker.set(time.Second, 5))
+	suite.Equal(WaitSLOStats{Threshold: time.Second, Window: 5}, tracker.stats())
+	suite.Equal(0.0, tracker.stats().BreachRatio())
+}
+
+// invalid settings, disabling
+func (suite *WaitSLOTestSuite) TestInvalid() {
+	fifo := NewFIFO()
+
+	suite.Equal(QueueErrorCodeInvalidConfig, errorCode(fifo.SetWaitSLO(-time.Second, 1)))
+	suite.Equal(QueueErrorCodeInvalidConfig, errorCode(fifo.SetWaitSLO(time.Second, 0)))
+
+	suite.NoError(fifo.SetWaitSLO(time.Second, 1))
+	suite.NoError(fifo.SetWaitSLO(0, 0))
+	fifo.Enqueue(1)
+	fifo.Dequeue()
+	suite.Equal(WaitSLOStats{}, fifo.Stats().WaitSLO)
+}
+
+// FIFO: the waits get recorded on every dequeue path, the elements enqueued before the tracking aren't
+func (suite *WaitSLOTestSuite) TestFIFO() {
+	fifo := NewFIFO()
+	fifo.Enqueue("untracked")
+	suite.Require().NoError(fifo.SetWaitSLO(waitSLOTestThreshold, 10))
+
+	fifo.Enqueue("removed")
+	fifo.Enqueue("slow 1")
+	fifo.Enqueue("slow 2")
+	time.Sleep(2 * waitSLOTestThreshold)
+	fifo.Enqueue("fast 1")
+	fifo.Enqueue("fast 2")
+
+	// the elements after the removed one get moved
+	suite.Equal(1, fifo.RemoveWhere(func(value interface{}) bool { return value == "removed" }))
+	value, err := fifo.Dequeue()
+	suite.NoError(err)
+	suite.Equal("untracked", value)
+	fifo.DequeueUpTo(1)
+	fifo.Drain()
+
+	suite.Equal(WaitSLOStats{Threshold: waitSLOTestThreshold, Window: 10, Dequeued: 4, Breached: 2}, fifo.Stats().WaitSLO)
+}
+
+// FIFO: handed over elements don't wait
+func (suite *WaitSLOTestSuite) TestFIFOWaitingConsumer() {
+	var (
+		fifo   = NewFIFO()
+		result = make(chan interface{})
+	)
+	suite.Require().NoError(fifo.SetWaitSLO(waitSLOTestThreshold, 10))
+
+	go func() {
+		value, _ := fifo.DequeueOrWaitForNextElement()
+		result <- value
+	}()
+	for i := 0; i < 1000 && fifo.Stats().Waiters == 0; i++ {
+		time.Sleep(time.Millisecond)
+	}
+
+	fifo.Enqueue(1)
+	suite.Equal(1, <-result)
+	suite.Equal(WaitSLOStats{Threshold: waitSLOTestThreshold, Window: 10, Dequeued: 1}, fifo.Stats().WaitSLO)
+}
+
+// FixedFIFO: Dequeue and Drain, the elements dropped by the overflow policy aren't dequeued
+func (suite *WaitSLOTestSuite) TestFixedFIFO() {
+	fifo := NewBoundedFIFO(2, OverflowPolicyDropOldest)
+	suite.Require().NoError(fifo.SetWaitSLO(waitSLOTestThreshold, 10))
+
+	fifo.Enqueue("dropped")
+	fifo.Enqueue("slow")
+	time.Sleep(2 * waitSLOTestThreshold)
+	fifo.Enqueue("fast")
+
+	value, err := fifo.Dequeue()
+	suite.NoError(err)
+	suite.Equal("slow", value)
+	suite.Equal([]interface{}{"fast"}, fifo.Drain())
+
+	suite.Equal(WaitSLOStats{Threshold: waitSLOTestThreshold, Window: 10, Dequeued: 2, Breached: 1}, fifo.Stats().WaitSLO)
+}
+
+func TestWaitSLOTestSuite(t *testing.T) {
+	suite.Run(t, new(WaitSLOTestSuite))
+}
@@ -0,0 +1,247 @@
+package goconcurrentqueue
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+// The following tests are meant to be run with the race detector enabled (go test -race). They stress every
+// Queue's implementation from multiple GRs at the same time.
+
+const (
+	raceTestTotalGRs         = 20
+	raceTestElementsPerGR    = WaitForNextElementChanCapacity / raceTestTotalGRs
+	raceTestFixedFIFOCap     = raceTestTotalGRs * raceTestElementsPerGR
+	raceTestWaitingTimeLimit = 5 * time.Second
+)
+
+type QueueRaceTestSuite struct {
+	suite.Suite
+	newQueue func() Queue
+	queue    Queue
+}
+
+func (suite *QueueRaceTestSuite) SetupTest() {
+	suite.queue = suite.newQueue()
+}
+
+// ***************************************************************************************
+// ** Enqueue && Dequeue
+// ***************************************************************************************
+
+// concurrent producers and consumers, every enqueued element must be dequeued exactly once
+func (suite *QueueRaceTestSuite) TestEnqueueDequeueMultipleGRs() {
+	var (
+		wg       sync.WaitGroup
+		mutex    sync.Mutex
+		dequeued = make(map[int]int)
+		total    = raceTestTotalGRs * raceTestElementsPerGR
+	)
+
+	for p := 0; p < raceTestTotalGRs; p++ {
+		wg.Add(1)
+		go func(producer int) {
+			defer wg.Done()
+			for i := 0; i < raceTestElementsPerGR; i++ {
+				suite.NoError(suite.queue.Enqueue(producer*raceTestElementsPerGR + i))
+			}
+		}(p)
+	}
+
+	deadline := time.Now().Add(raceTestWaitingTimeLimit)
+	for c := 0; c < raceTestTotalGRs; c++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for time.Now().Before(deadline) {
+				mutex.Lock()
+				if len(dequeued) == total {
+					mutex.Unlock()
+					return
+				}
+				mutex.Unlock()
+
+				value, err := suite.queue.Dequeue()
+				if err != nil {
+					continue
+				}
+
+				mutex.Lock()
+				dequeued[value.(int)]++
+				mutex.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	suite.Equal(total, len(dequeued), "every enqueued element must be dequeued")
+	for value, times := range dequeued {
+		suite.Equalf(1, times, "%v was dequeued %v times", value, times)
+	}
+	suite.Equal(0, suite.queue.GetLen())
+}
+
+// ***************************************************************************************
+// ** DequeueOrWaitForNextElement
+// ***************************************************************************************
+
+// waiters and producers racing, no element may be lost or delivered twice
+func (suite *QueueRaceTestSuite) TestDequeueOrWaitForNextElementMultipleGRs() {
+	var (
+		total    = raceTestTotalGRs * raceTestElementsPerGR
+		results  = make(chan int, total)
+		dequeued = make(map[int]int)
+	)
+
+	for w := 0; w < total; w++ {
+		go func() {
+			value, err := suite.queue.DequeueOrWaitForNextElement()
+			suite.NoError(err)
+			results <- value.(int)
+		}()
+	}
+
+	for p := 0; p < raceTestTotalGRs; p++ {
+		go func(producer int) {
+			for i := 0; i < raceTestElementsPerGR; i++ {
+				suite.NoError(suite.queue.Enqueue(producer*raceTestElementsPerGR + i))
+			}
+		}(p)
+	}
+
+	for i := 0; i < total; i++ {
+		select {
+		case value := <-results:
+			dequeued[value]++
+		case <-time.After(raceTestWaitingTimeLimit):
+			suite.FailNowf("lost element", "only %v out of %v elements were delivered to the waiters", i, total)
+		}
+	}
+
+	for value, times := range dequeued {
+		suite.Equalf(1, times, "%v was dequeued %v times", value, times)
+	}
+}
+
+// ***************************************************************************************
+// ** Lock / Unlock / IsLocked
+// ***************************************************************************************
+
+// lock state toggled while other GRs operate over the queue
+func (suite *QueueRaceTestSuite) TestLockUnlockMultipleGRs() {
+	var (
+		wg   sync.WaitGroup
+		done = make(chan struct{})
+	)
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case <-done:
+				suite.queue.Unlock()
+				return
+			default:
+				suite.queue.Lock()
+				suite.queue.IsLocked()
+				suite.queue.Unlock()
+			}
+		}
+	}()
+
+	for g := 0; g < raceTestTotalGRs; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < raceTestElementsPerGR; i++ {
+				suite.queue.Enqueue(i)
+				suite.queue.Dequeue()
+				suite.queue.GetLen()
+				suite.queue.GetCap()
+				suite.queue.IsLocked()
+			}
+		}()
+	}
+
+	// wait for the workers, then stop the lock toggling GR
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		close(done)
+	}()
+	wg.Wait()
+
+	suite.False(suite.queue.IsLocked())
+}
+
+// ***************************************************************************************
+// ** Run suites
+// ***************************************************************************************
+
+func TestFIFORaceTestSuite(t *testing.T) {
+	suite.Run(t, &QueueRaceTestSuite{newQueue: func() Queue { return NewFIFO() }})
+}
+
+func TestFixedFIFORaceTestSuite(t *testing.T) {
+	suite.Run(t, &QueueRaceTestSuite{newQueue: func() Queue { return NewFixedFIFO(raceTestFixedFIFOCap) }})
+}
+
+// ***************************************************************************************
+// ** FIFO only: Get / Remove / GetAll
+// ***************************************************************************************
+
+// Get, Remove and GetAll while other GRs enqueue / dequeue. GetAll's result is modified to verify it doesn't share
+// memory with the queue.
+func TestFIFOGetRemoveGetAllRace(t *testing.T) {
+	var (
+		fifo = NewFIFO()
+		wg   sync.WaitGroup
+	)
+
+	for g := 0; g < raceTestTotalGRs; g++ {
+		wg.Add(3)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < raceTestElementsPerGR; i++ {
+				fifo.Enqueue(i)
+				if i%2 == 0 {
+					fifo.Dequeue()
+				}
+			}
+		}()
+
+		go func() {
+			defer wg.Done()
+			for i := 0; i < raceTestElementsPerGR; i++ {
+				fifo.Get(0)
+				fifo.Remove(0)
+			}
+		}()
+
+		go func() {
+			defer wg.Done()
+			for i := 0; i < raceTestElementsPerGR; i++ {
+				all, err := fifo.GetAll(nil, nil)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				elements := all.([]interface{})
+				for i := range elements {
+					elements[i] = nil
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	all, _ := fifo.GetAll(nil, nil)
+	for _, element := range all.([]interface{}) {
+		if element == nil {
+			t.Fatal("GetAll's returned slice must not share memory with the queue")
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package goconcurrentqueue
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// DequeueRateLimiter is a QueueMiddleware gating the dequeues (Dequeue / DequeueOrWaitForNextElement) through a token
+// bucket, so a fast consumer draining a deep queue doesn't overwhelm the downstream systems: up to burst elements get
+// dequeued at once, then rate elements per second. A dequeue waits for its token before reaching the queue; the token
+// is given back if no element gets dequeued (i.e. empty or locked queue). Enqueues go straight to the queue.
+// A DequeueRateLimiter could be shared by several queues (Chain), they share the rate then.
+type DequeueRateLimiter struct {
+	mutex sync.Mutex
+	// tokens per second and bucket's capacity
+	rate  float64
+	burst float64
+	// available tokens as of last, negative if there are dequeues waiting for their tokens
+	tokens float64
+	last   time.Time
+}
+
+// NewDequeueRateLimiter returns a new DequeueRateLimiter allowing rate dequeues per second, up to burst (at least 1)
+// at once. The bucket starts full. Returns error if rate isn't positive.
+func NewDequeueRateLimiter(rate float64, burst int) (*DequeueRateLimiter, error) {
+	if rate <= 0 {
+		return nil, NewQueueError(QueueErrorCodeInvalidConfig, fmt.Sprintf("invalid rate: %v", rate))
+	}
+	if burst < 1 {
+		burst = 1
+	}
+
+	return &DequeueRateLimiter{
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}, nil
+}
+
+// WrapEnqueue returns next, enqueues aren't rate limited
+func (st *DequeueRateLimiter) WrapEnqueue(next EnqueueFunc) EnqueueFunc {
+	return next
+}
+
+// WrapDequeue returns the dequeue operation waiting for a token before calling next. The token is given back if the
+// context gets done while waiting for it (returning ctx.Err()).
+func (st *DequeueRateLimiter) WrapDequeue(next DequeueFunc) DequeueFunc {
+	return func(ctx context.Context, wait bool) (interface{}, error) {
+		if delay := st.reserve(time.Now()); delay > 0 {
+			timer := time.NewTimer(delay)
+			select {
+			case <-timer.C:
+			case <-ctx.Done():
+				timer.Stop()
+				st.refund(time.Now())
+				return nil, ctx.Err()
+			}
+		}
+
+		value, err := next(ctx, wait)
+		if err != nil {
+			st.refund(time.Now())
+		}
+
+		return value, err
+	}
+}
+
+// reserve takes a token, returning how long the caller has to wait until it is available
+func (st *DequeueRateLimiter) reserve(now time.Time) time.Duration {
+	st.mutex.Lock()
+	defer st.mutex.Unlock()
+
+	st.refill(now)
+	st.tokens--
+	if st.tokens >= 0 {
+		return 0
+	}
+
+	return time.Duration(-st.tokens / st.rate * float64(time.Second))
+}
+
+// refund gives a token back (unused reservation)
+func (st *DequeueRateLimiter) refund(now time.Time) {
+	st.mutex.Lock()
+	defer st.mutex.Unlock()
+
+	st.refill(now)
+	st.tokens++
+	if st.tokens > st.burst {
+		st.tokens = st.burst
+	}
+}
+
+// refill adds the tokens accumulated since last (up to burst). st.mutex must be locked by the caller.
+func (st *DequeueRateLimiter) refill(now time.Time) {
+	if elapsed := now.Sub(st.last); elapsed > 0 {
+		st.tokens += elapsed.Seconds() * st.rate
+		if st.tokens > st.burst {
+			st.tokens = st.burst
+		}
+		st.last = now
+	}
+}
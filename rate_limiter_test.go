@@ -0,0 +1,121 @@
+package goconcurrentqueue
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type DequeueRateLimiterTestSuite struct {
+	suite.Suite
+}
+
+// rate and burst: up to burst tokens at once, then rate tokens per second
+func (suite *DequeueRateLimiterTestSuite) TestReserve() {
+	limiter, err := NewDequeueRateLimiter(10, 2)
+	suite.Require().NoError(err)
+	now := limiter.last
+
+	suite.Equal(time.Duration(0), limiter.reserve(now))
+	suite.Equal(time.Duration(0), limiter.reserve(now))
+	suite.Equal(100*time.Millisecond, limiter.reserve(now))
+	suite.Equal(200*time.Millisecond, limiter.reserve(now))
+
+	// refilled (up to burst)
+	now = now.Add(time.Hour)
+	suite.Equal(time.Duration(0), limiter.reserve(now))
+	suite.Equal(time.Duration(0), limiter.reserve(now))
+	suite.Equal(100*time.Millisecond, limiter.reserve(now))
+
+	// unused reservation
+	limiter.refund(now)
+	suite.Equal(100*time.Millisecond, limiter.reserve(now))
+}
+
+// invalid config
+func (suite *DequeueRateLimiterTestSuite) TestInvalid() {
+	_, err := NewDequeueRateLimiter(0, 1)
+	suite.Require().IsType(&QueueError{}, err)
+	suite.Equal(QueueErrorCodeInvalidConfig, err.(*QueueError).Code())
+
+	limiter, err := NewDequeueRateLimiter(1, 0)
+	suite.NoError(err)
+	suite.Equal(float64(1), limiter.burst)
+}
+
+// the dequeues beyond burst wait, the enqueues don't
+func (suite *DequeueRateLimiterTestSuite) TestChain() {
+	limiter, err := NewDequeueRateLimiter(50, 2)
+	suite.Require().NoError(err)
+	queue := Chain(NewFIFO(), limiter)
+
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		suite.NoError(queue.Enqueue(i))
+	}
+	suite.True(time.Since(start) < 20*time.Millisecond)
+
+	for i := 0; i < 5; i++ {
+		value, err := queue.Dequeue()
+		suite.NoError(err)
+		suite.Equal(i, value)
+	}
+	// 3 elements beyond burst at 50 per second
+	suite.True(time.Since(start) >= 60*time.Millisecond)
+
+	go queue.Enqueue(5)
+	value, err := queue.DequeueOrWaitForNextElement()
+	suite.NoError(err)
+	suite.Equal(5, value)
+}
+
+// failed dequeues give their token back
+func (suite *DequeueRateLimiterTestSuite) TestEmptyQueue() {
+	limiter, err := NewDequeueRateLimiter(1, 1)
+	suite.Require().NoError(err)
+	queue := Chain(NewFIFO(), limiter)
+
+	for i := 0; i < 3; i++ {
+		_, err := queue.Dequeue()
+		suite.Equal(ErrEmptyQueue, err)
+	}
+
+	queue.Enqueue(1)
+	start := time.Now()
+	value, err := queue.Dequeue()
+	suite.NoError(err)
+	suite.Equal(1, value)
+	suite.True(time.Since(start) < 500*time.Millisecond, "the token wasn't used up by the failed dequeues")
+}
+
+// the dequeues stop waiting for their token once the context is done, giving it back
+func (suite *DequeueRateLimiterTestSuite) TestContext() {
+	limiter, err := NewDequeueRateLimiter(1, 1)
+	suite.Require().NoError(err)
+	queue := Chain(NewFIFO(), limiter).(interface {
+		Queue
+		DequeueOrWaitForNextElementWithContext(ctx context.Context) (interface{}, error)
+	})
+	queue.Enqueue(1)
+	queue.Enqueue(2)
+	queue.Dequeue()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err = queue.DequeueOrWaitForNextElementWithContext(ctx)
+	suite.Equal(context.DeadlineExceeded, err)
+	suite.True(time.Since(start) < 500*time.Millisecond)
+	suite.Equal(1, queue.GetLen())
+	suite.True(limiter.tokens > -1, "the token was given back")
+}
+
+// ***************************************************************************************
+// ** Run suite
+// ***************************************************************************************
+
+func TestDequeueRateLimiterTestSuite(t *testing.T) {
+	suite.Run(t, new(DequeueRateLimiterTestSuite))
+}
@@ -0,0 +1,212 @@
+package goconcurrentqueue
+
+import (
+	"fmt"
+	"time"
+)
+
+// Option is a setting changed on a live queue by its Reconfigure method (i.e. to loosen the limits during an incident
+// without restarting). See WithOverflowPolicy, WithOverflowCapacity, WithDefaultTTL and WithRate.
+type Option func(options *reconfigureOptions) error
+
+// reconfigureOptions are the settings given to Reconfigure, nil the ones not given
+type reconfigureOptions struct {
+	// options' names, in the given order (unsupported options' errors)
+	names            []string
+	overflowPolicy   *OverflowPolicy
+	overflowCapacity *int
+	defaultTTL       *time.Duration
+	rate             *float64
+	burst            *int
+}
+
+// WithOverflowPolicy sets what a FixedFIFO does at full capacity (see FixedFIFO.SetOverflowPolicy) or what a
+// BurstAbsorber does at the overflow limit (see NewBurstAbsorberWithPolicy)
+func WithOverflowPolicy(policy OverflowPolicy) Option {
+	return func(options *reconfigureOptions) error {
+		if _, ok := overflowPolicyNames[policy]; !ok {
+			return NewQueueError(QueueErrorCodeInvalidConfig, fmt.Sprintf("unknown overflow policy: %v", int(policy)))
+		}
+		options.names = append(options.names, "WithOverflowPolicy")
+		options.overflowPolicy = &policy
+
+		return nil
+	}
+}
+
+// WithOverflowCapacity sets a BurstAbsorber's overflow limit (0 means no limit). Elements already over the new limit
+// are kept.
+func WithOverflowCapacity(capacity int) Option {
+	return func(options *reconfigureOptions) error {
+		if capacity < 0 {
+			return NewQueueError(QueueErrorCodeInvalidConfig, fmt.Sprintf("invalid overflow capacity: %v", capacity))
+		}
+		options.names = append(options.names, "WithOverflowCapacity")
+		options.overflowCapacity = &capacity
+
+		return nil
+	}
+}
+
+// WithDefaultTTL sets the TTL of the elements enqueued into a TTLFIFO by Enqueue (0 means no expiration). The elements
+// already enqueued keep their TTL.
+func WithDefaultTTL(ttl time.Duration) Option {
+	return func(options *reconfigureOptions) error {
+		if ttl < 0 {
+			return NewQueueError(QueueErrorCodeInvalidConfig, fmt.Sprintf("invalid TTL: %v", ttl))
+		}
+		options.names = append(options.names, "WithDefaultTTL")
+		options.defaultTTL = &ttl
+
+		return nil
+	}
+}
+
+// WithRate sets a DequeueRateLimiter's rate (dequeues per second) and burst (at least 1)
+func WithRate(rate float64, burst int) Option {
+	return func(options *reconfigureOptions) error {
+		if rate <= 0 {
+			return NewQueueError(QueueErrorCodeInvalidConfig, fmt.Sprintf("invalid rate: %v", rate))
+		}
+		if burst < 1 {
+			burst = 1
+		}
+		options.names = append(options.names, "WithRate")
+		options.rate = &rate
+		options.burst = &burst
+
+		return nil
+	}
+}
+
+// newReconfigureOptions applies the options, returning error if any of them is invalid or isn't supported (name is the
+// reconfigured type)
+func newReconfigureOptions(name string, supported []string, opts []Option) (*reconfigureOptions, error) {
+	options := &reconfigureOptions{}
+	for _, opt := range opts {
+		if err := opt(options); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, option := range options.names {
+		if !containsString(supported, option) {
+			return nil, NewQueueError(QueueErrorCodeInvalidConfig, fmt.Sprintf("%v doesn't support %v", name, option))
+		}
+	}
+
+	return options, nil
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ***************************************************************************************
+// ** FixedFIFO
+// ***************************************************************************************
+
+// Reconfigure changes the queue's settings at once: WithOverflowPolicy. The capacity can't be changed (the elements
+// are held by a channel consumers receive from without a lock).
+// Returns error if any option is invalid or unsupported (nothing changes then).
+func (st *FixedFIFO) Reconfigure(opts ...Option) error {
+	options, err := newReconfigureOptions("FixedFIFO", []string{"WithOverflowPolicy"}, opts)
+	if err != nil {
+		return err
+	}
+
+	st.mutex.Lock()
+	defer st.mutex.Unlock()
+
+	if options.overflowPolicy != nil {
+		st.setOverflowPolicy(*options.overflowPolicy)
+	}
+
+	return nil
+}
+
+// ***************************************************************************************
+// ** BurstAbsorber
+// ***************************************************************************************
+
+// Reconfigure changes the queue's settings at once: WithOverflowCapacity and WithOverflowPolicy (block isn't
+// supported). Returns error if any option is invalid or unsupported (nothing changes then).
+func (st *BurstAbsorber) Reconfigure(opts ...Option) error {
+	options, err := newReconfigureOptions("BurstAbsorber", []string{"WithOverflowCapacity", "WithOverflowPolicy"}, opts)
+	if err != nil {
+		return err
+	}
+	if options.overflowPolicy != nil {
+		if err := validateBurstAbsorberPolicy(*options.overflowPolicy); err != nil {
+			return err
+		}
+	}
+
+	st.mutex.Lock()
+	defer st.mutex.Unlock()
+
+	if options.overflowCapacity != nil {
+		st.overflowCapacity = *options.overflowCapacity
+	}
+	if options.overflowPolicy != nil {
+		st.overflowPolicy = *options.overflowPolicy
+	}
+
+	return nil
+}
+
+// ***************************************************************************************
+// ** TTLFIFO
+// ***************************************************************************************
+
+// Reconfigure changes the queue's settings at once: WithDefaultTTL.
+// Returns error if any option is invalid or unsupported (nothing changes then).
+func (st *TTLFIFO) Reconfigure(opts ...Option) error {
+	options, err := newReconfigureOptions("TTLFIFO", []string{"WithDefaultTTL"}, opts)
+	if err != nil {
+		return err
+	}
+
+	st.rwmutex.Lock()
+	defer st.rwmutex.Unlock()
+
+	if options.defaultTTL != nil {
+		st.defaultTTL = *options.defaultTTL
+	}
+
+	return nil
+}
+
+// ***************************************************************************************
+// ** DequeueRateLimiter
+// ***************************************************************************************
+
+// Reconfigure changes the limiter's settings at once: WithRate. The tokens accumulated so far are kept (up to the new
+// burst), the dequeues already waiting for their tokens keep waiting as scheduled.
+// Returns error if any option is invalid or unsupported (nothing changes then).
+func (st *DequeueRateLimiter) Reconfigure(opts ...Option) error {
+	options, err := newReconfigureOptions("DequeueRateLimiter", []string{"WithRate"}, opts)
+	if err != nil {
+		return err
+	}
+
+	st.mutex.Lock()
+	defer st.mutex.Unlock()
+
+	if options.rate != nil {
+		st.refill(time.Now())
+		st.rate = *options.rate
+		st.burst = float64(*options.burst)
+		if st.tokens > st.burst {
+			st.tokens = st.burst
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,93 @@
+package goconcurrentqueue
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type ReconfigureTestSuite struct {
+	suite.Suite
+}
+
+// FixedFIFO: overflow policy
+func (suite *ReconfigureTestSuite) TestFixedFIFO() {
+	fifo := NewFixedFIFO(1)
+	fifo.Enqueue(1)
+
+	suite.NoError(fifo.Reconfigure(WithOverflowPolicy(OverflowPolicyDropOldest)))
+	suite.Equal(OverflowPolicyDropOldest, fifo.GetOverflowPolicy())
+	suite.NoError(fifo.Enqueue(2))
+	suite.Equal([]interface{}{2}, fifo.Drain())
+}
+
+// BurstAbsorber: the overflow limit gets loosened / tightened
+func (suite *ReconfigureTestSuite) TestBurstAbsorber() {
+	queue := NewBurstAbsorber(1, 1)
+	suite.NoError(queue.Enqueue(1))
+	suite.NoError(queue.Enqueue(2))
+	suite.Error(queue.Enqueue(3))
+
+	suite.NoError(queue.Reconfigure(WithOverflowCapacity(2)))
+	suite.Equal(3, queue.GetCap())
+	suite.NoError(queue.Enqueue(3))
+	suite.Error(queue.Enqueue(4))
+
+	suite.NoError(queue.Reconfigure(WithOverflowCapacity(0)))
+	suite.NoError(queue.Enqueue(4))
+	suite.Equal(QueueConfig{Type: QueueTypeBurstAbsorber, Capacity: 1}, queue.Config())
+}
+
+// TTLFIFO: Enqueue's TTL
+func (suite *ReconfigureTestSuite) TestTTLFIFO() {
+	queue := NewTTLFIFO()
+	queue.Enqueue(1)
+
+	suite.NoError(queue.Reconfigure(WithDefaultTTL(time.Millisecond)))
+	queue.Enqueue(2)
+	time.Sleep(5 * time.Millisecond)
+
+	value, err := queue.Dequeue()
+	suite.NoError(err)
+	suite.Equal(1, value, "enqueued before the change: never expires")
+	_, err = queue.Dequeue()
+	suite.Equal(ErrEmptyQueue, err)
+}
+
+// DequeueRateLimiter: rate and burst
+func (suite *ReconfigureTestSuite) TestDequeueRateLimiter() {
+	limiter, err := NewDequeueRateLimiter(10, 2)
+	suite.Require().NoError(err)
+
+	suite.NoError(limiter.Reconfigure(WithRate(100, 1)))
+	now := limiter.last
+	suite.Equal(time.Duration(0), limiter.reserve(now), "tokens kept up to the new burst")
+	suite.Equal(10*time.Millisecond, limiter.reserve(now))
+}
+
+// invalid and unsupported options: nothing changes
+func (suite *ReconfigureTestSuite) TestInvalidOptions() {
+	var (
+		fifo  = NewBoundedFIFO(1, OverflowPolicyBlock)
+		queue = NewBurstAbsorber(1, 1)
+	)
+
+	err := fifo.Reconfigure(WithOverflowPolicy(OverflowPolicy(100)))
+	suite.Equal(QueueErrorCodeInvalidConfig, errorCode(err))
+	err = fifo.Reconfigure(WithOverflowPolicy(OverflowPolicyReject), WithDefaultTTL(time.Second))
+	suite.Equal(QueueErrorCodeInvalidConfig, errorCode(err))
+	suite.Equal(OverflowPolicyBlock, fifo.GetOverflowPolicy())
+
+	err = queue.Reconfigure(WithOverflowCapacity(-1))
+	suite.Equal(QueueErrorCodeInvalidConfig, errorCode(err))
+	err = queue.Reconfigure(WithRate(0, 1))
+	suite.Equal(QueueErrorCodeInvalidConfig, errorCode(err))
+	suite.Equal(2, queue.GetCap())
+
+	suite.NoError(queue.Reconfigure())
+}
+
+func TestReconfigureTestSuite(t *testing.T) {
+	suite.Run(t, new(ReconfigureTestSuite))
+}
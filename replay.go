@@ -0,0 +1,67 @@
+package goconcurrentqueue
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	// ReplaySnapshot's max sleeping time between enqueues, high rates enqueue several elements per wake up
+	replayMinInterval = time.Millisecond
+)
+
+// ReplaySnapshot re-enqueues the persisted elements (i.e. a GetAll / Drain snapshot) into a live queue, in order, at
+// up to rate elements per second (rate <= 0 means no limit), so restoring a large backlog doesn't overwhelm the
+// consumers at once. Full capacity queues are retried (backpressure) until there is room.
+// Returns the number of replayed elements along with ctx.Err() if the context gets done first, or the target queue's
+// error (i.e. locked queue).
+func ReplaySnapshot(ctx context.Context, data []interface{}, target Queue, rate int) (int, error) {
+	var (
+		replayed = 0
+		start    = time.Now()
+		interval = replayMinInterval
+	)
+	if rate > 0 && time.Second/time.Duration(rate) > interval {
+		interval = time.Second / time.Duration(rate)
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for replayed < len(data) {
+		if err := ctx.Err(); err != nil {
+			return replayed, err
+		}
+
+		// elements due so far
+		due := len(data)
+		if rate > 0 {
+			due = int(time.Since(start).Seconds()*float64(rate)) + 1
+			if due > len(data) {
+				due = len(data)
+			}
+		}
+
+		for ; replayed < due; replayed++ {
+			if err := target.Enqueue(data[replayed]); err != nil {
+				if queueError, ok := err.(*QueueError); !ok || queueError.Code() != QueueErrorCodeFullCapacity {
+					return replayed, err
+				}
+				// no room, retry on the next tick
+				break
+			}
+		}
+
+		if replayed == len(data) {
+			break
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return replayed, ctx.Err()
+		}
+	}
+
+	return replayed, nil
+}
@@ -0,0 +1,99 @@
+package goconcurrentqueue
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type ReplaySnapshotTestSuite struct {
+	suite.Suite
+}
+
+// replaySnapshotTestData returns n elements: 0 ... n-1
+func replaySnapshotTestData(n int) []interface{} {
+	data := make([]interface{}, n)
+	for i := range data {
+		data[i] = i
+	}
+
+	return data
+}
+
+// no rate limit: everything gets enqueued right away, in order
+func (suite *ReplaySnapshotTestSuite) TestReplayNoLimit() {
+	fifo := NewFIFO()
+
+	replayed, err := ReplaySnapshot(context.Background(), replaySnapshotTestData(1000), fifo, 0)
+	suite.NoError(err)
+	suite.Equal(1000, replayed)
+	suite.Equal(replaySnapshotTestData(1000), fifo.Drain())
+}
+
+// the rate paces the enqueues
+func (suite *ReplaySnapshotTestSuite) TestReplayRate() {
+	var (
+		fifo  = NewFIFO()
+		start = time.Now()
+	)
+
+	// 50 elements at 500 per second: ~100ms
+	replayed, err := ReplaySnapshot(context.Background(), replaySnapshotTestData(50), fifo, 500)
+	suite.NoError(err)
+	suite.Equal(50, replayed)
+	suite.True(time.Since(start) >= 80*time.Millisecond, "the replay must follow the rate, it took %v", time.Since(start))
+	suite.Equal(replaySnapshotTestData(50), fifo.Drain())
+}
+
+// full capacity queues get retried until the consumers make room
+func (suite *ReplaySnapshotTestSuite) TestReplayFullCapacity() {
+	var (
+		fifo     = NewFixedFIFO(2)
+		consumed = make(chan []interface{})
+	)
+
+	go func() {
+		elements := make([]interface{}, 0)
+		for len(elements) < 10 {
+			value, err := fifo.DequeueOrWaitForNextElement()
+			suite.NoError(err)
+			elements = append(elements, value)
+			time.Sleep(time.Millisecond)
+		}
+		consumed <- elements
+	}()
+
+	replayed, err := ReplaySnapshot(context.Background(), replaySnapshotTestData(10), fifo, 0)
+	suite.NoError(err)
+	suite.Equal(10, replayed)
+	suite.Equal(replaySnapshotTestData(10), <-consumed)
+}
+
+// the target queue's errors stop the replay
+func (suite *ReplaySnapshotTestSuite) TestReplayLockedQueue() {
+	fifo := NewFIFO()
+	fifo.Lock()
+
+	replayed, err := ReplaySnapshot(context.Background(), replaySnapshotTestData(10), fifo, 0)
+	suite.Equal(ErrLockedQueue, err)
+	suite.Equal(0, replayed)
+}
+
+// the replay stops once the context is done
+func (suite *ReplaySnapshotTestSuite) TestReplayContext() {
+	fifo := NewFIFO()
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+	defer cancel()
+
+	// 100 elements at 100 per second: ~1s
+	replayed, err := ReplaySnapshot(ctx, replaySnapshotTestData(100), fifo, 100)
+	suite.Equal(context.DeadlineExceeded, err)
+	suite.True(replayed > 0 && replayed < 100, "partial replay expected, replayed: %v", replayed)
+	suite.Equal(replayed, fifo.GetLen())
+}
+
+func TestReplaySnapshotTestSuite(t *testing.T) {
+	suite.Run(t, new(ReplaySnapshotTestSuite))
+}
@@ -0,0 +1,175 @@
+package goconcurrentqueue
+
+const (
+	// the ring buffer never shrinks below this capacity, so steady-state load doesn't reallocate it over and over
+	ringBufferMinShrinkCapacity = 64
+)
+
+// ringBuffer is a growable circular buffer: elements live at buffer[head], buffer[head+1], ... (wrapping around), so
+// removing from the front reuses the slots instead of leaking the backing array's head. The buffer doubles when it is
+// full and halves once it is a quarter full, so memory usage follows the queue's length.
+// Every element carries its enqueue timestamp (see waitSLOTracker.stamp), 0 if it isn't tracked.
+// It is not concurrent-safe: the queue using it must provide the synchronization.
+type ringBuffer struct {
+	buffer []ringEntry
+	head   int
+	count  int
+}
+
+// ringEntry is an element along with its enqueue timestamp
+type ringEntry struct {
+	value interface{}
+	at    int64
+}
+
+// length returns the number of elements
+func (st *ringBuffer) length() int {
+	return st.count
+}
+
+// capacity returns the number of slots
+func (st *ringBuffer) capacity() int {
+	return len(st.buffer)
+}
+
+// index returns the buffer's slot for the i-th element
+func (st *ringBuffer) index(i int) int {
+	return (st.head + i) % len(st.buffer)
+}
+
+// get returns the i-th element
+func (st *ringBuffer) get(i int) interface{} {
+	return st.buffer[st.index(i)].value
+}
+
+// stamp returns the i-th element's enqueue timestamp
+func (st *ringBuffer) stamp(i int) int64 {
+	return st.buffer[st.index(i)].at
+}
+
+// set replaces the i-th element's value, keeping its enqueue timestamp
+func (st *ringBuffer) set(i int, value interface{}) {
+	st.buffer[st.index(i)].value = value
+}
+
+// move copies the src-th element (and its enqueue timestamp) over the dst-th one
+func (st *ringBuffer) move(dst, src int) {
+	st.buffer[st.index(dst)] = st.buffer[st.index(src)]
+}
+
+// swap swaps the i-th and j-th elements
+func (st *ringBuffer) swap(i, j int) {
+	i, j = st.index(i), st.index(j)
+	st.buffer[i], st.buffer[j] = st.buffer[j], st.buffer[i]
+}
+
+// pushBack appends a not tracked element
+func (st *ringBuffer) pushBack(value interface{}) {
+	st.pushBackAt(value, 0)
+}
+
+// pushBackAt appends an element enqueued at the given timestamp
+func (st *ringBuffer) pushBackAt(value interface{}, at int64) {
+	if st.count == len(st.buffer) {
+		st.resize(2 * len(st.buffer))
+	}
+
+	st.buffer[st.index(st.count)] = ringEntry{value: value, at: at}
+	st.count++
+}
+
+// pushFront prepends a not tracked element
+func (st *ringBuffer) pushFront(value interface{}) {
+	if st.count == len(st.buffer) {
+		st.resize(2 * len(st.buffer))
+	}
+
+	st.head = (st.head - 1 + len(st.buffer)) % len(st.buffer)
+	st.buffer[st.head] = ringEntry{value: value}
+	st.count++
+}
+
+// popFront removes and returns the first element, the ring buffer must not be empty
+func (st *ringBuffer) popFront() interface{} {
+	value := st.buffer[st.head].value
+	// release the reference
+	st.buffer[st.head] = ringEntry{}
+	st.head = (st.head + 1) % len(st.buffer)
+	st.count--
+
+	st.shrink()
+
+	return value
+}
+
+// removeAt removes and returns the i-th element, shifting the shorter side of the ring buffer
+func (st *ringBuffer) removeAt(i int) interface{} {
+	value := st.get(i)
+
+	if i < st.count/2 {
+		// shift the elements before i one slot forward
+		for j := i; j > 0; j-- {
+			st.move(j, j-1)
+		}
+		st.buffer[st.head] = ringEntry{}
+		st.head = (st.head + 1) % len(st.buffer)
+	} else {
+		// shift the elements after i one slot backward
+		for j := i; j < st.count-1; j++ {
+			st.move(j, j+1)
+		}
+		st.buffer[st.index(st.count-1)] = ringEntry{}
+	}
+	st.count--
+
+	st.shrink()
+
+	return value
+}
+
+// truncate keeps the first n elements
+func (st *ringBuffer) truncate(n int) {
+	for i := n; i < st.count; i++ {
+		// release the references
+		st.buffer[st.index(i)] = ringEntry{}
+	}
+	st.count = n
+
+	st.shrink()
+}
+
+// copyRange returns a copy of the elements in [low, high)
+func (st *ringBuffer) copyRange(low, high int) []interface{} {
+	ret := make([]interface{}, high-low)
+	for i := low; i < high; i++ {
+		ret[i-low] = st.get(i)
+	}
+
+	return ret
+}
+
+// elements returns a copy of all the elements, in order
+func (st *ringBuffer) elements() []interface{} {
+	return st.copyRange(0, st.count)
+}
+
+// shrink halves the buffer once it is a quarter full
+func (st *ringBuffer) shrink() {
+	if len(st.buffer) > ringBufferMinShrinkCapacity && st.count <= len(st.buffer)/4 {
+		st.resize(len(st.buffer) / 2)
+	}
+}
+
+// resize moves the elements to a new buffer with the given capacity (at least 1), starting at slot 0
+func (st *ringBuffer) resize(capacity int) {
+	if capacity < 1 {
+		capacity = 1
+	}
+
+	buffer := make([]ringEntry, capacity)
+	for i := 0; i < st.count; i++ {
+		buffer[i] = st.buffer[st.index(i)]
+	}
+	st.buffer = buffer
+	st.head = 0
+}
@@ -0,0 +1,189 @@
+package goconcurrentqueue
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type RingBufferTestSuite struct {
+	suite.Suite
+	ring *ringBuffer
+}
+
+func (suite *RingBufferTestSuite) SetupTest() {
+	suite.ring = &ringBuffer{}
+}
+
+// pushes the given values, in order
+func (suite *RingBufferTestSuite) pushBack(values ...interface{}) {
+	for _, value := range values {
+		suite.ring.pushBack(value)
+	}
+}
+
+// ***************************************************************************************
+// ** pushBack / pushFront / popFront
+// ***************************************************************************************
+
+// elements are popped in the same order they were pushed
+func (suite *RingBufferTestSuite) TestPushBackPopFront() {
+	suite.pushBack(0, 1, 2, 3, 4)
+	suite.Equal(5, suite.ring.length())
+
+	for i := 0; i < 5; i++ {
+		suite.Equal(i, suite.ring.popFront())
+	}
+	suite.Equal(0, suite.ring.length())
+}
+
+// pushFront prepends the elements
+func (suite *RingBufferTestSuite) TestPushFront() {
+	suite.pushBack(2, 3)
+	suite.ring.pushFront(1)
+	suite.ring.pushFront(0)
+
+	suite.Equal([]interface{}{0, 1, 2, 3}, suite.ring.elements())
+}
+
+// the slots freed at the head are reused: a steady push / pop load doesn't grow the buffer
+func (suite *RingBufferTestSuite) TestSlotsReuse() {
+	suite.pushBack(0, 1, 2, 3)
+	capacity := suite.ring.capacity()
+
+	for i := 4; i < 1000; i++ {
+		suite.Equal(i-4, suite.ring.popFront())
+		suite.ring.pushBack(i)
+	}
+
+	suite.Equal(capacity, suite.ring.capacity())
+	suite.Equal([]interface{}{996, 997, 998, 999}, suite.ring.elements())
+}
+
+// popped slots don't keep references to the elements
+func (suite *RingBufferTestSuite) TestPopFrontReleasesReferences() {
+	suite.pushBack(0, 1, 2)
+	suite.ring.popFront()
+	suite.ring.popFront()
+
+	total := 0
+	for _, entry := range suite.ring.buffer {
+		if entry.value != nil {
+			total++
+		}
+	}
+	suite.Equal(1, total)
+}
+
+// ***************************************************************************************
+// ** grow / shrink
+// ***************************************************************************************
+
+// a wrapped around buffer keeps the order after growing
+func (suite *RingBufferTestSuite) TestGrowWrappedAround() {
+	suite.pushBack(0, 1, 2, 3)
+	suite.ring.popFront()
+	suite.ring.popFront()
+	// wraps around
+	suite.pushBack(4, 5)
+	suite.Equal(4, suite.ring.capacity())
+
+	suite.pushBack(6)
+	suite.Equal(8, suite.ring.capacity())
+	suite.Equal([]interface{}{2, 3, 4, 5, 6}, suite.ring.elements())
+}
+
+// the buffer shrinks once the elements get dequeued, but never below ringBufferMinShrinkCapacity
+func (suite *RingBufferTestSuite) TestShrink() {
+	total := ringBufferMinShrinkCapacity * 16
+	for i := 0; i < total; i++ {
+		suite.ring.pushBack(i)
+	}
+	suite.True(suite.ring.capacity() >= total)
+
+	for i := 0; i < total; i++ {
+		suite.Equal(i, suite.ring.popFront())
+	}
+	suite.Equal(ringBufferMinShrinkCapacity, suite.ring.capacity())
+}
+
+// ***************************************************************************************
+// ** removeAt / truncate / swap
+// ***************************************************************************************
+
+// removeAt removes elements from both halves of a wrapped around buffer
+func (suite *RingBufferTestSuite) TestRemoveAt() {
+	suite.pushBack(-2, -1, 0, 1, 2, 3)
+	suite.ring.popFront()
+	suite.ring.popFront()
+	suite.pushBack(4, 5)
+
+	// first half
+	suite.Equal(1, suite.ring.removeAt(1))
+	suite.Equal([]interface{}{0, 2, 3, 4, 5}, suite.ring.elements())
+
+	// second half
+	suite.Equal(4, suite.ring.removeAt(3))
+	suite.Equal([]interface{}{0, 2, 3, 5}, suite.ring.elements())
+
+	// ends
+	suite.Equal(0, suite.ring.removeAt(0))
+	suite.Equal(5, suite.ring.removeAt(2))
+	suite.Equal([]interface{}{2, 3}, suite.ring.elements())
+}
+
+// truncate keeps the first n elements
+func (suite *RingBufferTestSuite) TestTruncate() {
+	suite.pushBack(0, 1, 2, 3)
+	suite.ring.truncate(1)
+
+	suite.Equal([]interface{}{0}, suite.ring.elements())
+	suite.Nil(suite.ring.buffer[1].value)
+}
+
+// swap / copyRange over a wrapped around buffer
+func (suite *RingBufferTestSuite) TestSwapCopyRange() {
+	suite.pushBack(0, 1, 2, 3)
+	suite.ring.popFront()
+	suite.pushBack(4)
+
+	suite.ring.swap(0, 3)
+	suite.Equal([]interface{}{4, 2, 3, 1}, suite.ring.elements())
+	suite.Equal([]interface{}{2, 3}, suite.ring.copyRange(1, 3))
+}
+
+// ***************************************************************************************
+// ** enqueue timestamps
+// ***************************************************************************************
+
+// the timestamps follow their elements through removals, moves, swaps and resizes
+func (suite *RingBufferTestSuite) TestStamps() {
+	for i := 0; i < 10; i++ {
+		suite.ring.pushBackAt(i, int64(i+100))
+	}
+	suite.ring.popFront()
+	suite.ring.removeAt(1)
+	suite.ring.removeAt(6)
+	suite.ring.swap(0, 1)
+	suite.ring.move(2, 3)
+	suite.ring.set(3, "replaced")
+	suite.ring.resize(32)
+
+	stamps := make([]int64, suite.ring.length())
+	for i := range stamps {
+		stamps[i] = suite.ring.stamp(i)
+	}
+	suite.Equal([]interface{}{3, 1, 5, "replaced", 6, 7, 9}, suite.ring.elements())
+	suite.Equal([]int64{103, 101, 105, 105, 106, 107, 109}, stamps, "set keeps the timestamp")
+
+	suite.ring.pushFront(0)
+	suite.Equal(int64(0), suite.ring.stamp(0))
+}
+
+// ***************************************************************************************
+// ** Run suite
+// ***************************************************************************************
+
+func TestRingBufferTestSuite(t *testing.T) {
+	suite.Run(t, new(RingBufferTestSuite))
+}
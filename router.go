@@ -0,0 +1,299 @@
+package goconcurrentqueue
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+const (
+	// RouterTopicSeparator separates the topics' segments, i.e. "orders.eu.created"
+	RouterTopicSeparator = "."
+	// RouterWildcardSegment matches exactly one topic segment, i.e. "orders.*.created"
+	RouterWildcardSegment = "*"
+	// RouterWildcardRest (last segment only) matches zero or more topic segments, i.e. "orders.#"
+	RouterWildcardRest = "#"
+)
+
+// routerSubscription is a subscriber's queue registered for the topics matching a pattern
+type routerSubscription struct {
+	pattern    string
+	segments   []string
+	subscriber Queue
+}
+
+// Router is a lightweight in-process message bus: published elements get routed to the queues subscribed to their
+// topic. Every topic gets its own BroadcastQueue (created on demand, on the first publish matching a subscription or
+// the first Topic call), so every subscriber gets every element published to the topics matching its pattern, at its
+// own queue (which defines its backpressure policy, see BroadcastQueue). Publishing to topics no one subscribes to
+// doesn't create them.
+// Topics are segments separated by RouterTopicSeparator. Patterns accept RouterWildcardSegment in place of any segment
+// and RouterWildcardRest as the last one. A queue subscribed by several patterns gets the elements only once.
+// Topics are never removed.
+type Router struct {
+	// elements published to topics with no subscribers. First field: 64-bit aligned on 32-bit platforms.
+	unrouted      uint64
+	topics        map[string]*BroadcastQueue
+	subscriptions []routerSubscription
+	rwmutex       sync.RWMutex
+	lockRWmutex   sync.RWMutex
+	isLocked      bool
+}
+
+// NewRouter returns a new Router with no topics
+func NewRouter() *Router {
+	ret := &Router{}
+	ret.initialize()
+
+	return ret
+}
+
+func (st *Router) initialize() {
+	st.topics = make(map[string]*BroadcastQueue)
+}
+
+// Subscribe registers a subscriber's queue: it gets every element published from now on to the topics matching the
+// pattern (i.e. "orders.*.created", "orders.#", "#"). Returns error if the pattern is invalid.
+func (st *Router) Subscribe(pattern string, subscriber Queue) error {
+	segments, err := splitTopic(pattern, true)
+	if err != nil {
+		return err
+	}
+
+	st.rwmutex.Lock()
+	defer st.rwmutex.Unlock()
+
+	for topic, broadcast := range st.topics {
+		if matchTopic(segments, strings.Split(topic, RouterTopicSeparator)) && !st.isSubscribed(topic, subscriber) {
+			broadcast.Subscribe(subscriber)
+		}
+	}
+	st.subscriptions = append(st.subscriptions, routerSubscription{
+		pattern:    pattern,
+		segments:   segments,
+		subscriber: subscriber,
+	})
+
+	return nil
+}
+
+// Unsubscribe removes a subscriber's pattern, the queue keeps getting the elements of the topics matching its other
+// patterns (if any). Returns false if the queue wasn't subscribed by the pattern.
+func (st *Router) Unsubscribe(pattern string, subscriber Queue) bool {
+	st.rwmutex.Lock()
+	defer st.rwmutex.Unlock()
+
+	for i, subscription := range st.subscriptions {
+		if subscription.pattern != pattern || subscription.subscriber != subscriber {
+			continue
+		}
+
+		subscriptions := make([]routerSubscription, 0, len(st.subscriptions)-1)
+		subscriptions = append(subscriptions, st.subscriptions[:i]...)
+		st.subscriptions = append(subscriptions, st.subscriptions[i+1:]...)
+
+		for topic, broadcast := range st.topics {
+			if matchTopic(subscription.segments, strings.Split(topic, RouterTopicSeparator)) && !st.isSubscribed(topic, subscriber) {
+				broadcast.Unsubscribe(subscriber)
+			}
+		}
+
+		return true
+	}
+
+	return false
+}
+
+// isSubscribed returns true whether any of the subscriber's patterns matches the topic. st.rwmutex must be locked by
+// the caller.
+func (st *Router) isSubscribed(topic string, subscriber Queue) bool {
+	segments := strings.Split(topic, RouterTopicSeparator)
+	for _, subscription := range st.subscriptions {
+		if subscription.subscriber == subscriber && matchTopic(subscription.segments, segments) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Publish routes the element to the queues subscribed to the topic (if any, see GetUnrouted). Subscribers' queues
+// rejecting it don't affect the rest (see BroadcastQueue.Enqueue). Returns error if the router is locked or the topic
+// is invalid (empty segments or wildcards).
+func (st *Router) Publish(topic string, value interface{}) error {
+	if st.IsLocked() {
+		return ErrLockedQueue
+	}
+
+	if _, err := splitTopic(topic, false); err != nil {
+		return err
+	}
+
+	broadcast := st.getRoutedTopic(topic)
+	if broadcast == nil || broadcast.GetSubscribers() == 0 {
+		atomic.AddUint64(&st.unrouted, 1)
+		return nil
+	}
+
+	return broadcast.Enqueue(value)
+}
+
+// getRoutedTopic returns the topic's BroadcastQueue, creating it only if any subscription matches the topic. Returns
+// nil if the topic doesn't exist and there are no matching subscriptions.
+func (st *Router) getRoutedTopic(topic string) *BroadcastQueue {
+	st.rwmutex.RLock()
+	broadcast, ok := st.topics[topic]
+	matching := ok || st.hasSubscriptions(topic)
+	st.rwmutex.RUnlock()
+	if ok {
+		return broadcast
+	}
+	if !matching {
+		return nil
+	}
+
+	return st.getTopic(topic)
+}
+
+// hasSubscriptions returns true whether any subscription's pattern matches the topic. st.rwmutex must be locked by the
+// caller.
+func (st *Router) hasSubscriptions(topic string) bool {
+	segments := strings.Split(topic, RouterTopicSeparator)
+	for _, subscription := range st.subscriptions {
+		if matchTopic(subscription.segments, segments) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// getTopic returns the topic's BroadcastQueue, creating it (along with its matching subscriptions) if needed
+func (st *Router) getTopic(topic string) *BroadcastQueue {
+	st.rwmutex.RLock()
+	broadcast, ok := st.topics[topic]
+	st.rwmutex.RUnlock()
+	if ok {
+		return broadcast
+	}
+
+	st.rwmutex.Lock()
+	defer st.rwmutex.Unlock()
+
+	// created while waiting for the lock
+	if broadcast, ok := st.topics[topic]; ok {
+		return broadcast
+	}
+
+	broadcast = NewBroadcastQueue()
+	segments := strings.Split(topic, RouterTopicSeparator)
+	for i, subscription := range st.subscriptions {
+		if matchTopic(subscription.segments, segments) && !st.isSubscribedBefore(i, segments) {
+			broadcast.Subscribe(subscription.subscriber)
+		}
+	}
+	st.topics[topic] = broadcast
+
+	return broadcast
+}
+
+// isSubscribedBefore returns true whether a subscription previous to the given one (index) already subscribes its queue
+// to the topic. st.rwmutex must be locked by the caller.
+func (st *Router) isSubscribedBefore(index int, topic []string) bool {
+	for _, previous := range st.subscriptions[:index] {
+		if previous.subscriber == st.subscriptions[index].subscriber && matchTopic(previous.segments, topic) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Topic returns the topic's BroadcastQueue (created on demand), i.e. to set its rejection handler. Returns error if the
+// topic is invalid.
+func (st *Router) Topic(topic string) (*BroadcastQueue, error) {
+	if _, err := splitTopic(topic, false); err != nil {
+		return nil, err
+	}
+
+	return st.getTopic(topic), nil
+}
+
+// GetTopics returns the topics, sorted
+func (st *Router) GetTopics() []string {
+	st.rwmutex.RLock()
+	defer st.rwmutex.RUnlock()
+
+	topics := make([]string, 0, len(st.topics))
+	for topic := range st.topics {
+		topics = append(topics, topic)
+	}
+	sort.Strings(topics)
+
+	return topics
+}
+
+// GetUnrouted returns the number of elements published to topics with no subscribers (dropped)
+func (st *Router) GetUnrouted() uint64 {
+	return atomic.LoadUint64(&st.unrouted)
+}
+
+// Lock // Locks the router. No publish operations will be allowed after this point.
+func (st *Router) Lock() {
+	st.lockRWmutex.Lock()
+	defer st.lockRWmutex.Unlock()
+
+	st.isLocked = true
+}
+
+// Unlock unlocks the router
+func (st *Router) Unlock() {
+	st.lockRWmutex.Lock()
+	defer st.lockRWmutex.Unlock()
+
+	st.isLocked = false
+}
+
+// IsLocked returns true whether the router is locked
+func (st *Router) IsLocked() bool {
+	st.lockRWmutex.RLock()
+	defer st.lockRWmutex.RUnlock()
+
+	return st.isLocked
+}
+
+// splitTopic returns the topic's (or pattern's, if wildcards are allowed) segments. Returns error if it is invalid.
+func splitTopic(topic string, wildcards bool) ([]string, error) {
+	segments := strings.Split(topic, RouterTopicSeparator)
+	for i, segment := range segments {
+		switch {
+		case segment == "":
+			return nil, NewQueueError(QueueErrorCodeInvalidTopic, fmt.Sprintf("invalid topic %q: empty segment", topic))
+		case segment == RouterWildcardSegment || segment == RouterWildcardRest:
+			if !wildcards {
+				return nil, NewQueueError(QueueErrorCodeInvalidTopic, fmt.Sprintf("invalid topic %q: wildcards are only allowed at subscriptions", topic))
+			}
+			if segment == RouterWildcardRest && i != len(segments)-1 {
+				return nil, NewQueueError(QueueErrorCodeInvalidTopic, fmt.Sprintf("invalid pattern %q: %s must be the last segment", topic, RouterWildcardRest))
+			}
+		}
+	}
+
+	return segments, nil
+}
+
+// matchTopic returns true whether the pattern's segments match the topic's ones
+func matchTopic(pattern []string, topic []string) bool {
+	for i, segment := range pattern {
+		if segment == RouterWildcardRest {
+			return true
+		}
+		if i >= len(topic) || (segment != RouterWildcardSegment && segment != topic[i]) {
+			return false
+		}
+	}
+
+	return len(pattern) == len(topic)
+}
@@ -0,0 +1,201 @@
+package goconcurrentqueue
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type RouterTestSuite struct {
+	suite.Suite
+	router *Router
+}
+
+func (suite *RouterTestSuite) SetupTest() {
+	suite.router = NewRouter()
+}
+
+// ***************************************************************************************
+// ** Topics
+// ***************************************************************************************
+
+// topics get created on demand
+func (suite *RouterTestSuite) TestTopics() {
+	suite.Equal([]string{}, suite.router.GetTopics())
+
+	suite.NoError(suite.router.Subscribe("orders.*", NewFIFO()))
+	suite.NoError(suite.router.Publish("orders.created", 1))
+	broadcast, err := suite.router.Topic("payments.created")
+	suite.NoError(err)
+	suite.NotNil(broadcast)
+
+	suite.Equal([]string{"orders.created", "payments.created"}, suite.router.GetTopics())
+}
+
+// elements published to topics with no subscribers get dropped, the topics don't get created
+func (suite *RouterTestSuite) TestUnrouted() {
+	suite.NoError(suite.router.Subscribe("orders.*", NewFIFO()))
+	suite.NoError(suite.router.Publish("payments.created", 1))
+	suite.NoError(suite.router.Publish("payments.deleted", 2))
+
+	suite.Equal(uint64(2), suite.router.GetUnrouted())
+	suite.Equal([]string{}, suite.router.GetTopics())
+
+	// existing topic, no subscribers
+	suite.router.Topic("invoices.created")
+	suite.NoError(suite.router.Publish("invoices.created", 3))
+	suite.Equal(uint64(3), suite.router.GetUnrouted())
+}
+
+// empty segments and wildcards aren't valid topics
+func (suite *RouterTestSuite) TestInvalidTopics() {
+	for _, topic := range []string{"", "orders.", ".orders", "orders..created", "orders.*", "orders.#"} {
+		err := suite.router.Publish(topic, 1)
+		suite.Error(err, topic)
+		suite.Equal(QueueErrorCodeInvalidTopic, err.(*QueueError).Code(), topic)
+	}
+
+	_, err := suite.router.Topic("orders.*")
+	suite.Error(err)
+	suite.Equal([]string{}, suite.router.GetTopics())
+}
+
+// ***************************************************************************************
+// ** Subscribe / Unsubscribe
+// ***************************************************************************************
+
+// exact and wildcard patterns
+func (suite *RouterTestSuite) TestSubscribe() {
+	var (
+		exact   = NewFIFO()
+		segment = NewFIFO()
+		rest    = NewFIFO()
+		all     = NewFIFO()
+	)
+	suite.NoError(suite.router.Subscribe("orders.eu.created", exact))
+	suite.NoError(suite.router.Subscribe("orders.*.created", segment))
+	suite.NoError(suite.router.Subscribe("orders.#", rest))
+	suite.NoError(suite.router.Subscribe("#", all))
+
+	suite.router.Publish("orders.eu.created", 1)
+	suite.router.Publish("orders.us.created", 2)
+	suite.router.Publish("orders.us.created.late", 3)
+	suite.router.Publish("orders", 4)
+	suite.router.Publish("payments.eu.created", 5)
+
+	suite.Equal([]interface{}{1}, exact.Drain())
+	suite.Equal([]interface{}{1, 2}, segment.Drain())
+	suite.Equal([]interface{}{1, 2, 3, 4}, rest.Drain())
+	suite.Equal([]interface{}{1, 2, 3, 4, 5}, all.Drain())
+	suite.Equal(uint64(0), suite.router.GetUnrouted())
+}
+
+// subscriptions also apply to the existing topics
+func (suite *RouterTestSuite) TestSubscribeExistingTopic() {
+	suite.router.Topic("orders.created")
+
+	subscriber := NewFIFO()
+	suite.NoError(suite.router.Subscribe("orders.*", subscriber))
+	suite.router.Publish("orders.created", 2)
+
+	suite.Equal([]interface{}{2}, subscriber.Drain())
+}
+
+// a queue subscribed by several matching patterns gets the elements once
+func (suite *RouterTestSuite) TestSubscribeOverlappingPatterns() {
+	subscriber := NewFIFO()
+	suite.router.Topic("orders.created")
+	suite.NoError(suite.router.Subscribe("orders.*", subscriber))
+	suite.NoError(suite.router.Subscribe("orders.#", subscriber))
+	suite.NoError(suite.router.Subscribe("orders.#", subscriber))
+
+	suite.router.Publish("orders.created", 2)
+	suite.router.Publish("orders.deleted", 3)
+
+	suite.Equal([]interface{}{2, 3}, subscriber.Drain())
+}
+
+// invalid patterns
+func (suite *RouterTestSuite) TestSubscribeInvalidPatterns() {
+	for _, pattern := range []string{"", "orders.", "orders.#.created", "#.orders"} {
+		err := suite.router.Subscribe(pattern, NewFIFO())
+		suite.Error(err, pattern)
+		suite.Equal(QueueErrorCodeInvalidTopic, err.(*QueueError).Code(), pattern)
+	}
+}
+
+// unsubscribed patterns stop the elements, the rest of the queue's patterns keep them coming
+func (suite *RouterTestSuite) TestUnsubscribe() {
+	subscriber := NewFIFO()
+	suite.router.Subscribe("orders.*", subscriber)
+	suite.router.Subscribe("orders.created", subscriber)
+	suite.router.Publish("orders.created", 1)
+	suite.router.Publish("orders.deleted", 2)
+
+	suite.True(suite.router.Unsubscribe("orders.*", subscriber))
+	suite.False(suite.router.Unsubscribe("orders.*", subscriber))
+	suite.router.Publish("orders.created", 3)
+	suite.router.Publish("orders.deleted", 4)
+
+	suite.True(suite.router.Unsubscribe("orders.created", subscriber))
+	suite.router.Publish("orders.created", 5)
+
+	suite.Equal([]interface{}{1, 2, 3}, subscriber.Drain())
+	suite.Equal(uint64(2), suite.router.GetUnrouted())
+}
+
+// ***************************************************************************************
+// ** Lock / Unlock / IsLocked
+// ***************************************************************************************
+
+// locked router: nothing gets published
+func (suite *RouterTestSuite) TestLock() {
+	subscriber := NewFIFO()
+	suite.router.Subscribe("#", subscriber)
+
+	suite.router.Lock()
+	suite.True(suite.router.IsLocked())
+	suite.Equal(ErrLockedQueue, suite.router.Publish("orders.created", 1))
+
+	suite.router.Unlock()
+	suite.NoError(suite.router.Publish("orders.created", 2))
+	suite.Equal([]interface{}{2}, subscriber.Drain())
+}
+
+// ***************************************************************************************
+// ** Concurrency
+// ***************************************************************************************
+
+// concurrent publishers and subscribers (go test -race)
+func (suite *RouterTestSuite) TestConcurrency() {
+	var (
+		subscriber = NewFIFO()
+		totalGRs   = 4
+		perGR      = 100
+		wg         sync.WaitGroup
+	)
+	suite.router.Subscribe("orders.#", subscriber)
+
+	for gr := 0; gr < totalGRs; gr++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < perGR; i++ {
+				suite.router.Publish("orders.created", i)
+				suite.router.Subscribe("payments.*", NewFIFO())
+			}
+		}()
+	}
+	wg.Wait()
+
+	suite.Equal(totalGRs*perGR, subscriber.GetLen())
+}
+
+// ***************************************************************************************
+// ** Run suite
+// ***************************************************************************************
+
+func TestRouterTestSuite(t *testing.T) {
+	suite.Run(t, new(RouterTestSuite))
+}
@@ -0,0 +1,27 @@
+package goconcurrentqueue
+
+// schedPoint identifies a decision point in the concurrent code paths of the queues: right before the internal lock
+// gets acquired and right before a listener blocks waiting for the element handoff.
+// They only exist to let the tests script (or seed) the goroutine interleavings at those points, see sched_test.go.
+type schedPoint int
+
+const (
+	// Enqueue is about to acquire the internal lock
+	schedPointEnqueue schedPoint = iota
+	// Dequeue is about to acquire the internal lock
+	schedPointDequeue
+	// DequeueOrWaitForNextElement is about to acquire the internal lock
+	schedPointWaitForNextElement
+	// DequeueOrWaitForNextElement registered its listener and is about to block until an element gets handed over
+	schedPointWaitForNextElementHandoff
+)
+
+// schedHook is invoked (if not nil) at every schedPoint. It is always nil outside tests.
+type schedHook func(point schedPoint)
+
+// sched invokes the hook (if any) for the given point
+func (hook schedHook) sched(point schedPoint) {
+	if hook != nil {
+		hook(point)
+	}
+}
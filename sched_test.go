@@ -0,0 +1,323 @@
+package goconcurrentqueue
+
+import (
+	"fmt"
+	"math/rand"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+// ***************************************************************************************
+// ** Deterministic simulation harness
+// ***************************************************************************************
+
+// simScheduler runs a set of goroutines one at a time, switching between them only at the queues' schedPoints.
+// The goroutine to resume at every step is taken from a script (names) or picked by a seeded random source, so a given
+// interleaving can be reproduced at will.
+//
+// A goroutine reaching schedPointWaitForNextElementHandoff is about to block until another goroutine hands it an
+// element, so once resumed it runs freely (it must not reach any other schedPoint afterwards).
+type simScheduler struct {
+	t          *testing.T
+	mutex      sync.Mutex
+	current    *simGoroutine
+	goroutines []*simGoroutine
+	events     chan simEvent
+	trace      []string
+}
+
+type simGoroutine struct {
+	name   string
+	resume chan struct{}
+	point  string
+	done   bool
+	// running freely after the handoff point
+	free bool
+}
+
+type simEvent struct {
+	goroutine *simGoroutine
+	point     string
+	done      bool
+}
+
+const (
+	simStartPoint        = "start"
+	simEventWaitingLimit = 2 * time.Second
+)
+
+var simPointNames = map[schedPoint]string{
+	schedPointEnqueue:                   "enqueue",
+	schedPointDequeue:                   "dequeue",
+	schedPointWaitForNextElement:        "wait",
+	schedPointWaitForNextElementHandoff: "handoff",
+}
+
+func newSimScheduler(t *testing.T) *simScheduler {
+	return &simScheduler{
+		t:      t,
+		events: make(chan simEvent),
+	}
+}
+
+// hook is the schedHook to install into the queues
+func (st *simScheduler) hook(point schedPoint) {
+	st.mutex.Lock()
+	goroutine := st.current
+	st.mutex.Unlock()
+
+	st.events <- simEvent{goroutine: goroutine, point: simPointNames[point]}
+	<-goroutine.resume
+}
+
+// Go registers a goroutine, it doesn't run until the scheduler resumes it
+func (st *simScheduler) Go(name string, fn func()) {
+	goroutine := &simGoroutine{
+		name:   name,
+		resume: make(chan struct{}),
+		point:  simStartPoint,
+	}
+	st.goroutines = append(st.goroutines, goroutine)
+
+	go func() {
+		<-goroutine.resume
+		fn()
+		st.events <- simEvent{goroutine: goroutine, done: true}
+	}()
+}
+
+// Run resumes the goroutines following the script (goroutine names), then keeps resuming the remaining ones picking
+// them with the given seed, until all goroutines are done.
+func (st *simScheduler) Run(seed int64, script ...string) {
+	random := rand.New(rand.NewSource(seed))
+
+	for _, name := range script {
+		goroutine := st.find(name)
+		if goroutine == nil || goroutine.done || goroutine.free {
+			st.t.Fatalf("scripted goroutine %v can't be resumed. trace: %v", name, st.trace)
+		}
+		st.step(goroutine)
+	}
+
+	for {
+		var runnable []*simGoroutine
+		pending := 0
+		for _, goroutine := range st.goroutines {
+			if goroutine.done {
+				continue
+			}
+			pending++
+			if !goroutine.free {
+				runnable = append(runnable, goroutine)
+			}
+		}
+
+		if pending == 0 {
+			return
+		}
+
+		if len(runnable) == 0 {
+			// only goroutines waiting for a handoff remain
+			st.waitFor(nil)
+			continue
+		}
+
+		st.step(runnable[random.Intn(len(runnable))])
+	}
+}
+
+// Trace returns the sequence of executed steps: "name@point"
+func (st *simScheduler) Trace() []string {
+	return st.trace
+}
+
+func (st *simScheduler) find(name string) *simGoroutine {
+	for _, goroutine := range st.goroutines {
+		if goroutine.name == name {
+			return goroutine
+		}
+	}
+
+	return nil
+}
+
+// step resumes the goroutine and waits until it reaches its next point (or ends)
+func (st *simScheduler) step(goroutine *simGoroutine) {
+	st.trace = append(st.trace, fmt.Sprintf("%v@%v", goroutine.name, goroutine.point))
+
+	st.mutex.Lock()
+	st.current = goroutine
+	st.mutex.Unlock()
+
+	if goroutine.point == simPointNames[schedPointWaitForNextElementHandoff] {
+		goroutine.free = true
+		goroutine.resume <- struct{}{}
+		return
+	}
+
+	goroutine.resume <- struct{}{}
+	st.waitFor(goroutine)
+}
+
+// waitFor processes events until the given goroutine sends one (or until any event arrives if goroutine is nil)
+func (st *simScheduler) waitFor(goroutine *simGoroutine) {
+	for {
+		select {
+		case event := <-st.events:
+			if event.done {
+				event.goroutine.done = true
+			} else {
+				event.goroutine.point = event.point
+			}
+
+			if goroutine == nil || event.goroutine == goroutine {
+				return
+			}
+		case <-time.After(simEventWaitingLimit):
+			st.t.Fatalf("goroutines stuck (lost element?). trace: %v", st.trace)
+		}
+	}
+}
+
+// ***************************************************************************************
+// ** Scripted interleavings: Enqueue <-> DequeueOrWaitForNextElement handoff
+// ***************************************************************************************
+
+type simQueue interface {
+	Queue
+	setSchedHook(hook schedHook)
+}
+
+func (st *FIFO) setSchedHook(hook schedHook) {
+	st.schedHook = hook
+}
+
+func (st *FixedFIFO) setSchedHook(hook schedHook) {
+	st.schedHook = hook
+}
+
+type SchedTestSuite struct {
+	suite.Suite
+	newQueue func() simQueue
+}
+
+// runs a waiter and a producer following the script, the waiter must always get the element
+func (suite *SchedTestSuite) runWaiterProducer(script ...string) {
+	var (
+		queue     = suite.newQueue()
+		scheduler = newSimScheduler(suite.T())
+		result    interface{}
+		err       error
+	)
+	queue.setSchedHook(scheduler.hook)
+
+	scheduler.Go("waiter", func() {
+		result, err = queue.DequeueOrWaitForNextElement()
+	})
+	scheduler.Go("producer", func() {
+		suite.NoError(queue.Enqueue(testValue))
+	})
+	scheduler.Run(0, script...)
+
+	suite.NoErrorf(err, "trace: %v", scheduler.Trace())
+	suite.Equalf(testValue, result, "trace: %v", scheduler.Trace())
+	suite.Equalf(0, queue.GetLen(), "the element must be handed over exactly once. trace: %v", scheduler.Trace())
+}
+
+// producer enqueues before the waiter checks the queue
+func (suite *SchedTestSuite) TestProducerBeforeWaiter() {
+	suite.runWaiterProducer("producer", "producer", "waiter", "waiter")
+}
+
+// waiter registers its listener before the producer enqueues
+func (suite *SchedTestSuite) TestWaiterBeforeProducer() {
+	suite.runWaiterProducer("waiter", "waiter", "waiter", "producer", "producer")
+}
+
+// producer is about to lock while the waiter registers its listener
+func (suite *SchedTestSuite) TestProducerAboutToLockWhileWaiterRegisters() {
+	suite.runWaiterProducer("producer", "waiter", "waiter", "producer")
+}
+
+// waiter is about to lock while the producer enqueues
+func (suite *SchedTestSuite) TestWaiterAboutToLockWhileProducerEnqueues() {
+	suite.runWaiterProducer("waiter", "producer", "producer", "waiter")
+}
+
+// ***************************************************************************************
+// ** Seeded interleavings
+// ***************************************************************************************
+
+// producers, waiters and a dequeuer under many seeded interleavings: no element lost or duplicated
+func (suite *SchedTestSuite) TestSeededInterleavings() {
+	const (
+		totalSeeds = 50
+		totalGRs   = 3
+	)
+
+	for seed := int64(0); seed < totalSeeds; seed++ {
+		var (
+			queue     = suite.newQueue()
+			scheduler = newSimScheduler(suite.T())
+			mutex     sync.Mutex
+			delivered = make(map[interface{}]int)
+		)
+		queue.setSchedHook(scheduler.hook)
+
+		deliver := func(value interface{}) {
+			mutex.Lock()
+			delivered[value]++
+			mutex.Unlock()
+		}
+
+		// one waiter less than producers: the dequeuer may (or may not) take the remaining element
+		for i := 0; i < totalGRs; i++ {
+			value := i
+			scheduler.Go(fmt.Sprintf("producer%v", i), func() {
+				suite.NoError(queue.Enqueue(value))
+			})
+			if i == 0 {
+				continue
+			}
+			scheduler.Go(fmt.Sprintf("waiter%v", i), func() {
+				value, err := queue.DequeueOrWaitForNextElement()
+				suite.NoError(err)
+				deliver(value)
+			})
+		}
+		scheduler.Go("dequeuer", func() {
+			if value, err := queue.Dequeue(); err == nil {
+				deliver(value)
+			}
+		})
+		scheduler.Run(seed)
+		queue.setSchedHook(nil)
+
+		// the element the dequeuer didn't take
+		if queue.GetLen() > 0 {
+			value, err := queue.Dequeue()
+			suite.NoError(err)
+			deliver(value)
+		}
+
+		suite.Equalf(totalGRs, len(delivered), "seed: %v, trace: %v", seed, scheduler.Trace())
+		for value, times := range delivered {
+			suite.Equalf(1, times, "%v delivered %v times. seed: %v, trace: %v", value, times, seed, scheduler.Trace())
+		}
+	}
+}
+
+// ***************************************************************************************
+// ** Run suites
+// ***************************************************************************************
+
+func TestFIFOSchedTestSuite(t *testing.T) {
+	suite.Run(t, &SchedTestSuite{newQueue: func() simQueue { return NewFIFO() }})
+}
+
+func TestFixedFIFOSchedTestSuite(t *testing.T) {
+	suite.Run(t, &SchedTestSuite{newQueue: func() simQueue { return NewFixedFIFO(fixedFIFOQueueCapacity) }})
+}
@@ -0,0 +1,150 @@
+package goconcurrentqueue
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+const (
+	// keeps head and tail at different cache lines (no false sharing between producer and consumer)
+	spscCacheLinePadding = 64 - 8
+)
+
+// SPSCQueue fixed capacity FIFO (First In First Out) queue for a single producer and a single consumer (i.e. a
+// pipeline stage): Enqueue and Dequeue are wait-free, no mutexes involved, just a ring buffer whose head is owned
+// by the consumer and whose tail is owned by the producer.
+//
+// Only one goroutine may enqueue and only one goroutine may dequeue at a time (they could be different goroutines over
+// time, as long as their calls don't overlap). Overlapping calls are detected: they return ErrConcurrentAccess.
+//
+// The 64-bit counters are accessed atomically, so they must stay 64-bit aligned on 32-bit platforms (386, ARM, MIPS):
+// create the queue with NewSPSCQueue (or allocate it on its own), don't embed it by value into another struct.
+type SPSCQueue struct {
+	// next position to dequeue, written by the consumer only (first field: 64-bit aligned for the atomic operations)
+	head uint64
+	_    [spscCacheLinePadding]byte
+	// next position to enqueue, written by the producer only
+	tail uint64
+	_    [spscCacheLinePadding]byte
+	// slots, filled by the producer and emptied by the consumer
+	buffer []interface{}
+	// 1 while locked
+	locked int32
+	// 1 while an Enqueue / Dequeue is running (misuse detection)
+	producing int32
+	consuming int32
+}
+
+// NewSPSCQueue returns a new SPSCQueue holding up to capacity elements (at least 1)
+func NewSPSCQueue(capacity int) *SPSCQueue {
+	ret := &SPSCQueue{}
+	ret.initialize(capacity)
+
+	return ret
+}
+
+func (st *SPSCQueue) initialize(capacity int) {
+	if capacity < 1 {
+		capacity = 1
+	}
+	st.buffer = make([]interface{}, capacity)
+}
+
+// Enqueue enqueues an element. Returns error if queue is locked, it is at full capacity or another Enqueue is running
+// at the same time.
+func (st *SPSCQueue) Enqueue(value interface{}) error {
+	if st.IsLocked() {
+		return ErrLockedQueue
+	}
+
+	if !atomic.CompareAndSwapInt32(&st.producing, 0, 1) {
+		return ErrConcurrentAccess
+	}
+	defer atomic.StoreInt32(&st.producing, 0)
+
+	// only the producer writes tail
+	tail := atomic.LoadUint64(&st.tail)
+	if tail-atomic.LoadUint64(&st.head) == uint64(len(st.buffer)) {
+		return ErrFullCapacity
+	}
+
+	st.buffer[tail%uint64(len(st.buffer))] = value
+	// publishes the element to the consumer
+	atomic.StoreUint64(&st.tail, tail+1)
+
+	return nil
+}
+
+// Dequeue dequeues an element. Returns error if queue is locked, it is empty or another Dequeue is running at the same
+// time.
+func (st *SPSCQueue) Dequeue() (interface{}, error) {
+	if st.IsLocked() {
+		return nil, ErrLockedQueue
+	}
+
+	if !atomic.CompareAndSwapInt32(&st.consuming, 0, 1) {
+		return nil, ErrConcurrentAccess
+	}
+	defer atomic.StoreInt32(&st.consuming, 0)
+
+	// only the consumer writes head
+	head := atomic.LoadUint64(&st.head)
+	if head == atomic.LoadUint64(&st.tail) {
+		return nil, ErrEmptyQueue
+	}
+
+	slot := head % uint64(len(st.buffer))
+	value := st.buffer[slot]
+	// release the reference
+	st.buffer[slot] = nil
+	// hands the slot back to the producer
+	atomic.StoreUint64(&st.head, head+1)
+
+	return value, nil
+}
+
+// DequeueOrWaitForNextElement dequeues an element (if exist) or waits until the next element gets enqueued and returns
+// it. The consumer polls the queue (yielding first, then sleeping a bit between polls), so the producer remains
+// wait-free.
+func (st *SPSCQueue) DequeueOrWaitForNextElement() (interface{}, error) {
+	return st.DequeueOrWaitForNextElementWithContext(context.Background())
+}
+
+// DequeueOrWaitForNextElementWithContext works as DequeueOrWaitForNextElement, but it stops waiting once the context
+// is done, returning ctx.Err().
+func (st *SPSCQueue) DequeueOrWaitForNextElementWithContext(ctx context.Context) (interface{}, error) {
+	return pollForNextElement(ctx, st.Dequeue)
+}
+
+// GetLen returns the number of enqueued elements
+func (st *SPSCQueue) GetLen() int {
+	// head first: tail never goes behind it
+	head := atomic.LoadUint64(&st.head)
+	length := int(atomic.LoadUint64(&st.tail) - head)
+	if length > len(st.buffer) {
+		// the consumer and the producer moved in between
+		length = len(st.buffer)
+	}
+
+	return length
+}
+
+// GetCap returns the queue's capacity
+func (st *SPSCQueue) GetCap() int {
+	return len(st.buffer)
+}
+
+// Lock // Locks the queue. No enqueue/dequeue operations will be allowed after this point.
+func (st *SPSCQueue) Lock() {
+	atomic.StoreInt32(&st.locked, 1)
+}
+
+// Unlock unlocks the queue
+func (st *SPSCQueue) Unlock() {
+	atomic.StoreInt32(&st.locked, 0)
+}
+
+// IsLocked returns true whether the queue is locked
+func (st *SPSCQueue) IsLocked() bool {
+	return atomic.LoadInt32(&st.locked) == 1
+}
@@ -0,0 +1,34 @@
+package goconcurrentqueue
+
+import (
+	"runtime"
+	"testing"
+)
+
+// single goroutine - enqueue / dequeue 1 element
+func BenchmarkSPSCQueueEnqueueDequeueSingleGR(b *testing.B) {
+	queue := NewSPSCQueue(5)
+
+	for i := 0; i < b.N; i++ {
+		queue.Enqueue(i)
+		queue.Dequeue()
+	}
+}
+
+// a producer and a consumer
+func BenchmarkSPSCQueueProducerConsumer(b *testing.B) {
+	queue := NewSPSCQueue(1024)
+
+	go func() {
+		for i := 0; i < b.N; i++ {
+			for queue.Enqueue(i) != nil {
+				// full capacity: let the consumer run
+				runtime.Gosched()
+			}
+		}
+	}()
+
+	for i := 0; i < b.N; i++ {
+		queue.DequeueOrWaitForNextElement()
+	}
+}
@@ -0,0 +1,199 @@
+package goconcurrentqueue
+
+import (
+	"context"
+	"runtime"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+const (
+	spscQueueCapacity = 10
+)
+
+type SPSCQueueTestSuite struct {
+	suite.Suite
+	queue *SPSCQueue
+}
+
+func (suite *SPSCQueueTestSuite) SetupTest() {
+	suite.queue = NewSPSCQueue(spscQueueCapacity)
+}
+
+// ***************************************************************************************
+// ** Queue initialization
+// ***************************************************************************************
+
+// no elements at initialization
+func (suite *SPSCQueueTestSuite) TestNoElementsAtInitialization() {
+	suite.Equal(0, suite.queue.GetLen())
+	suite.Equal(spscQueueCapacity, suite.queue.GetCap())
+}
+
+// capacity is at least 1
+func (suite *SPSCQueueTestSuite) TestMinimumCapacity() {
+	suite.Equal(1, NewSPSCQueue(0).GetCap())
+}
+
+// ***************************************************************************************
+// ** Enqueue / Dequeue
+// ***************************************************************************************
+
+// elements are dequeued in enqueue order, wrapping around the buffer
+func (suite *SPSCQueueTestSuite) TestEnqueueDequeueSingleGR() {
+	for round := 0; round < 3; round++ {
+		for i := 0; i < spscQueueCapacity; i++ {
+			suite.NoError(suite.queue.Enqueue(i))
+		}
+		suite.Equal(spscQueueCapacity, suite.queue.GetLen())
+
+		for i := 0; i < spscQueueCapacity; i++ {
+			value, err := suite.queue.Dequeue()
+			suite.NoError(err)
+			suite.Equal(i, value)
+		}
+	}
+}
+
+// full capacity
+func (suite *SPSCQueueTestSuite) TestEnqueueFullCapacity() {
+	for i := 0; i < spscQueueCapacity; i++ {
+		suite.NoError(suite.queue.Enqueue(i))
+	}
+
+	suite.Equal(ErrFullCapacity, suite.queue.Enqueue(spscQueueCapacity))
+}
+
+// empty queue
+func (suite *SPSCQueueTestSuite) TestDequeueEmptyQueue() {
+	_, err := suite.queue.Dequeue()
+	suite.Equal(ErrEmptyQueue, err)
+}
+
+// dequeued slots don't keep references to the elements
+func (suite *SPSCQueueTestSuite) TestDequeueReleasesReferences() {
+	suite.queue.Enqueue(1)
+	suite.queue.Dequeue()
+
+	suite.Nil(suite.queue.buffer[0])
+}
+
+// locked queue
+func (suite *SPSCQueueTestSuite) TestLockedQueue() {
+	suite.queue.Enqueue(1)
+	suite.queue.Lock()
+	suite.True(suite.queue.IsLocked())
+
+	suite.Equal(ErrLockedQueue, suite.queue.Enqueue(2))
+	_, err := suite.queue.Dequeue()
+	suite.Equal(ErrLockedQueue, err)
+
+	suite.queue.Unlock()
+	suite.False(suite.queue.IsLocked())
+	value, err := suite.queue.Dequeue()
+	suite.NoError(err)
+	suite.Equal(1, value)
+}
+
+// a producer and a consumer running concurrently: every element delivered once, in order
+func (suite *SPSCQueueTestSuite) TestProducerConsumer() {
+	const total = 10000
+
+	go func() {
+		for i := 0; i < total; i++ {
+			for suite.queue.Enqueue(i) != nil {
+				// full capacity: let the consumer run
+				runtime.Gosched()
+			}
+		}
+	}()
+
+	for i := 0; i < total; i++ {
+		value, err := suite.queue.DequeueOrWaitForNextElement()
+		suite.NoError(err)
+		if !suite.Equal(i, value) {
+			return
+		}
+	}
+	suite.Equal(0, suite.queue.GetLen())
+}
+
+// ***************************************************************************************
+// ** Misuse detection
+// ***************************************************************************************
+
+// overlapping Enqueue calls
+func (suite *SPSCQueueTestSuite) TestConcurrentProducers() {
+	// a producer in the middle of an Enqueue
+	atomic.StoreInt32(&suite.queue.producing, 1)
+	suite.Equal(ErrConcurrentAccess, suite.queue.Enqueue(1))
+
+	atomic.StoreInt32(&suite.queue.producing, 0)
+	suite.NoError(suite.queue.Enqueue(1))
+}
+
+// overlapping Dequeue calls
+func (suite *SPSCQueueTestSuite) TestConcurrentConsumers() {
+	suite.queue.Enqueue(1)
+
+	// a consumer in the middle of a Dequeue
+	atomic.StoreInt32(&suite.queue.consuming, 1)
+	_, err := suite.queue.Dequeue()
+	suite.Equal(ErrConcurrentAccess, err)
+
+	atomic.StoreInt32(&suite.queue.consuming, 0)
+	value, err := suite.queue.Dequeue()
+	suite.NoError(err)
+	suite.Equal(1, value)
+}
+
+// ***************************************************************************************
+// ** DequeueOrWaitForNextElement
+// ***************************************************************************************
+
+// element enqueued while waiting
+func (suite *SPSCQueueTestSuite) TestDequeueOrWaitForNextElementWaiting() {
+	result := make(chan interface{}, 1)
+	go func() {
+		value, _ := suite.queue.DequeueOrWaitForNextElement()
+		result <- value
+	}()
+	time.Sleep(10 * time.Millisecond)
+
+	suite.NoError(suite.queue.Enqueue(7))
+
+	select {
+	case value := <-result:
+		suite.Equal(7, value)
+	case <-time.After(2 * time.Second):
+		suite.FailNow("too much time waiting for the enqueued element")
+	}
+}
+
+// context deadline exceeded while waiting
+func (suite *SPSCQueueTestSuite) TestDequeueOrWaitForNextElementWithContextDeadline() {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err := suite.queue.DequeueOrWaitForNextElementWithContext(ctx)
+	suite.Equal(context.DeadlineExceeded, err)
+}
+
+// locked queue
+func (suite *SPSCQueueTestSuite) TestDequeueOrWaitForNextElementLockedQueue() {
+	suite.queue.Lock()
+
+	_, err := suite.queue.DequeueOrWaitForNextElement()
+	suite.Equal(ErrLockedQueue, err)
+}
+
+// ***************************************************************************************
+// ** Run suite
+// ***************************************************************************************
+
+func TestSPSCQueueTestSuite(t *testing.T) {
+	suite.Run(t, new(SPSCQueueTestSuite))
+}
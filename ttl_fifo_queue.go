@@ -0,0 +1,323 @@
+package goconcurrentqueue
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// ttlElement is an element enqueued into a TTLFIFO
+type ttlElement struct {
+	value interface{}
+	// zero means the element never expires
+	expiresAt time.Time
+}
+
+// TTLFIFO (First In First Out) concurrent queue where elements could have a TTL: the ones not dequeued in time get
+// silently dropped, or sent to the expiration handler (see SetExpirationHandler).
+// Expiration is lazy: expired elements are dropped by the next operation accessing the queue (or by Expire), there is
+// no background goroutine.
+type TTLFIFO struct {
+	slice       []ttlElement
+	rwmutex     sync.RWMutex
+	lockRWmutex sync.RWMutex
+	isLocked    bool
+	// queue for watchers that will wait for next elements (if queue is empty at DequeueOrWaitForNextElement execution )
+	waitForNextElementChan chan chan interface{}
+	// the earliest expiration among the enqueued elements (zero if none expires), so the queue is only scanned once
+	// there is an expired element
+	earliestExpiration time.Time
+	expirationHandler  func(value interface{})
+	// Enqueue's TTL (see WithDefaultTTL), 0 means no expiration
+	defaultTTL time.Duration
+}
+
+// NewTTLFIFO returns a new TTLFIFO concurrent queue
+func NewTTLFIFO() *TTLFIFO {
+	ret := &TTLFIFO{}
+	ret.initialize()
+
+	return ret
+}
+
+func (st *TTLFIFO) initialize() {
+	st.slice = make([]ttlElement, 0)
+	st.waitForNextElementChan = make(chan chan interface{}, WaitForNextElementChanCapacity)
+}
+
+// Enqueue enqueues an element expiring after the default TTL (see Reconfigure), by default it never expires. Returns
+// error if queue is locked.
+func (st *TTLFIFO) Enqueue(value interface{}) error {
+	st.rwmutex.RLock()
+	ttl := st.defaultTTL
+	st.rwmutex.RUnlock()
+
+	return st.EnqueueWithTTL(value, ttl)
+}
+
+// EnqueueWithTTL enqueues an element that expires if it isn't dequeued within ttl (0 means no expiration). Returns
+// error if queue is locked.
+func (st *TTLFIFO) EnqueueWithTTL(value interface{}, ttl time.Duration) error {
+	if st.IsLocked() {
+		return ErrLockedQueue
+	}
+
+	element := ttlElement{value: value}
+	if ttl > 0 {
+		element.expiresAt = time.Now().Add(ttl)
+	}
+
+	st.rwmutex.Lock()
+	defer st.rwmutex.Unlock()
+
+	// check if there is a listener waiting for the next element (this element)
+	select {
+	case listener := <-st.waitForNextElementChan:
+		select {
+		case listener <- value:
+			return nil
+		default:
+		}
+	default:
+	}
+
+	st.slice = append(st.slice, element)
+	if !element.expiresAt.IsZero() && (st.earliestExpiration.IsZero() || element.expiresAt.Before(st.earliestExpiration)) {
+		st.earliestExpiration = element.expiresAt
+	}
+
+	return nil
+}
+
+// Dequeue dequeues the first non expired element. Returns error if queue is locked or empty.
+func (st *TTLFIFO) Dequeue() (interface{}, error) {
+	if st.IsLocked() {
+		return nil, ErrLockedQueue
+	}
+
+	st.rwmutex.Lock()
+	expired := st.expire(time.Now())
+	value, ok := st.dequeueFirst()
+	st.rwmutex.Unlock()
+
+	st.notifyExpired(expired)
+
+	if !ok {
+		return nil, ErrEmptyQueue
+	}
+
+	return value, nil
+}
+
+// DequeueOrWaitForNextElement dequeues the first non expired element (if exist) or waits until the next element gets
+// enqueued and returns it.
+// Multiple calls to DequeueOrWaitForNextElement() would enqueue multiple "listeners" for future enqueued elements.
+func (st *TTLFIFO) DequeueOrWaitForNextElement() (interface{}, error) {
+	return st.DequeueOrWaitForNextElementWithContext(context.Background())
+}
+
+// DequeueOrWaitForNextElementWithContext works as DequeueOrWaitForNextElement, but it stops waiting once the context
+// is done, returning ctx.Err().
+func (st *TTLFIFO) DequeueOrWaitForNextElementWithContext(ctx context.Context) (interface{}, error) {
+	if st.IsLocked() {
+		return nil, ErrLockedQueue
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	st.rwmutex.Lock()
+	expired := st.expire(time.Now())
+	value, ok := st.dequeueFirst()
+	if ok {
+		st.rwmutex.Unlock()
+		st.notifyExpired(expired)
+
+		return value, nil
+	}
+
+	// channel to wait for next enqueued element (buffered, so Enqueue never blocks handing it over)
+	waitChan := make(chan interface{}, 1)
+
+	select {
+	case st.waitForNextElementChan <- waitChan:
+		st.rwmutex.Unlock()
+		st.notifyExpired(expired)
+
+		select {
+		case value := <-waitChan:
+			return value, nil
+		case <-ctx.Done():
+			st.rwmutex.Lock()
+			defer st.rwmutex.Unlock()
+
+			removeListener(st.waitForNextElementChan, waitChan)
+			// the element could have been handed over right before the listener's removal
+			select {
+			case value := <-waitChan:
+				return value, nil
+			default:
+				return nil, ctx.Err()
+			}
+		}
+	default:
+		st.rwmutex.Unlock()
+		st.notifyExpired(expired)
+
+		// too many watchers (waitForNextElementChanCapacity) enqueued waiting for next elements
+		return nil, NewQueueError(QueueErrorCodeEmptyQueue, "empty queue and can't wait for next element because there are too many DequeueOrWaitForNextElement() waiting")
+	}
+}
+
+// Expire drops the expired elements right away (instead of waiting for the next operation to do it) and returns how
+// many of them were dropped.
+func (st *TTLFIFO) Expire() int {
+	st.rwmutex.Lock()
+	expired := st.expire(time.Now())
+	st.rwmutex.Unlock()
+
+	st.notifyExpired(expired)
+
+	return len(expired)
+}
+
+// SetExpirationHandler sets the function invoked with every expired element. The handler runs at the goroutine whose
+// operation found the expired elements, out of the queue's lock. nil removes the handler.
+func (st *TTLFIFO) SetExpirationHandler(handler func(value interface{})) {
+	st.rwmutex.Lock()
+	defer st.rwmutex.Unlock()
+
+	st.expirationHandler = handler
+}
+
+// expire removes the elements expired at the given moment and returns them. st.rwmutex must be locked by the caller.
+func (st *TTLFIFO) expire(now time.Time) []interface{} {
+	if st.earliestExpiration.IsZero() || now.Before(st.earliestExpiration) {
+		return nil
+	}
+
+	var (
+		expired []interface{}
+		kept    = 0
+	)
+	st.earliestExpiration = time.Time{}
+	for i := 0; i < len(st.slice); i++ {
+		element := st.slice[i]
+		if !element.expiresAt.IsZero() {
+			if !now.Before(element.expiresAt) {
+				expired = append(expired, element.value)
+				continue
+			}
+			if st.earliestExpiration.IsZero() || element.expiresAt.Before(st.earliestExpiration) {
+				st.earliestExpiration = element.expiresAt
+			}
+		}
+
+		st.slice[kept] = element
+		kept++
+	}
+
+	// release the references
+	for i := kept; i < len(st.slice); i++ {
+		st.slice[i] = ttlElement{}
+	}
+	st.slice = st.slice[:kept]
+
+	return expired
+}
+
+// notifyExpired sends the expired elements to the expiration handler (if any)
+func (st *TTLFIFO) notifyExpired(expired []interface{}) {
+	if len(expired) == 0 {
+		return
+	}
+
+	st.rwmutex.RLock()
+	handler := st.expirationHandler
+	st.rwmutex.RUnlock()
+
+	if handler == nil {
+		return
+	}
+
+	for _, value := range expired {
+		handler(value)
+	}
+}
+
+// dequeueFirst removes and returns the first element. st.rwmutex must be locked by the caller.
+func (st *TTLFIFO) dequeueFirst() (interface{}, bool) {
+	if len(st.slice) == 0 {
+		return nil, false
+	}
+
+	value := st.slice[0].value
+	// release the reference
+	st.slice[0] = ttlElement{}
+	st.slice = st.slice[1:]
+
+	return value, true
+}
+
+// Clear atomically removes every element, releasing the references. The already expired ones are sent to the
+// expiration handler (if any). Returns error if queue is locked.
+func (st *TTLFIFO) Clear() error {
+	if st.IsLocked() {
+		return ErrLockedQueue
+	}
+
+	st.rwmutex.Lock()
+	expired := st.expire(time.Now())
+	st.slice = make([]ttlElement, 0)
+	st.earliestExpiration = time.Time{}
+	st.rwmutex.Unlock()
+
+	st.notifyExpired(expired)
+
+	return nil
+}
+
+// GetLen returns the number of enqueued (non expired) elements
+func (st *TTLFIFO) GetLen() int {
+	st.rwmutex.Lock()
+	expired := st.expire(time.Now())
+	length := len(st.slice)
+	st.rwmutex.Unlock()
+
+	st.notifyExpired(expired)
+
+	return length
+}
+
+// GetCap returns the queue's capacity
+func (st *TTLFIFO) GetCap() int {
+	st.rwmutex.RLock()
+	defer st.rwmutex.RUnlock()
+
+	return cap(st.slice)
+}
+
+// Lock // Locks the queue. No enqueue/dequeue operations will be allowed after this point.
+func (st *TTLFIFO) Lock() {
+	st.lockRWmutex.Lock()
+	defer st.lockRWmutex.Unlock()
+
+	st.isLocked = true
+}
+
+// Unlock unlocks the queue
+func (st *TTLFIFO) Unlock() {
+	st.lockRWmutex.Lock()
+	defer st.lockRWmutex.Unlock()
+
+	st.isLocked = false
+}
+
+// IsLocked returns true whether the queue is locked
+func (st *TTLFIFO) IsLocked() bool {
+	st.lockRWmutex.RLock()
+	defer st.lockRWmutex.RUnlock()
+
+	return st.isLocked
+}
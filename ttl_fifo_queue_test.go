@@ -0,0 +1,226 @@
+package goconcurrentqueue
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+const (
+	ttlFIFOTestTTL = 20 * time.Millisecond
+)
+
+type TTLFIFOTestSuite struct {
+	suite.Suite
+	fifo *TTLFIFO
+}
+
+func (suite *TTLFIFOTestSuite) SetupTest() {
+	suite.fifo = NewTTLFIFO()
+}
+
+// ***************************************************************************************
+// ** Enqueue && Dequeue
+// ***************************************************************************************
+
+// elements with no TTL never expire
+func (suite *TTLFIFOTestSuite) TestEnqueueNoTTL() {
+	for i := 0; i < 10; i++ {
+		suite.NoError(suite.fifo.Enqueue(i))
+	}
+	time.Sleep(ttlFIFOTestTTL)
+
+	suite.Equal(10, suite.fifo.GetLen())
+	for i := 0; i < 10; i++ {
+		value, err := suite.fifo.Dequeue()
+		suite.NoError(err)
+		suite.Equal(i, value)
+	}
+}
+
+// elements dequeued in time
+func (suite *TTLFIFOTestSuite) TestDequeueBeforeExpiration() {
+	suite.NoError(suite.fifo.EnqueueWithTTL(testValue, time.Hour))
+
+	value, err := suite.fifo.Dequeue()
+	suite.NoError(err)
+	suite.Equal(testValue, value)
+}
+
+// expired elements are silently dropped
+func (suite *TTLFIFOTestSuite) TestDequeueExpired() {
+	suite.fifo.EnqueueWithTTL(1, ttlFIFOTestTTL)
+	suite.fifo.Enqueue(2)
+	suite.fifo.EnqueueWithTTL(3, ttlFIFOTestTTL)
+	suite.fifo.EnqueueWithTTL(4, time.Hour)
+	time.Sleep(ttlFIFOTestTTL)
+
+	suite.Equal(2, suite.fifo.GetLen())
+	for _, expected := range []int{2, 4} {
+		value, err := suite.fifo.Dequeue()
+		suite.NoError(err)
+		suite.Equal(expected, value)
+	}
+
+	_, err := suite.fifo.Dequeue()
+	suite.Equal(ErrEmptyQueue, err)
+}
+
+// locked queue
+func (suite *TTLFIFOTestSuite) TestLockedQueue() {
+	suite.fifo.Enqueue(1)
+	suite.fifo.Lock()
+	suite.True(suite.fifo.IsLocked())
+
+	suite.Equal(ErrLockedQueue, suite.fifo.EnqueueWithTTL(2, time.Hour))
+	_, err := suite.fifo.Dequeue()
+	suite.Equal(ErrLockedQueue, err)
+	_, err = suite.fifo.DequeueOrWaitForNextElement()
+	suite.Equal(ErrLockedQueue, err)
+
+	suite.fifo.Unlock()
+	suite.Equal(1, suite.fifo.GetLen())
+}
+
+// ***************************************************************************************
+// ** Expiration handler / Expire
+// ***************************************************************************************
+
+// expired elements are sent to the handler, in order
+func (suite *TTLFIFOTestSuite) TestExpirationHandler() {
+	var expired []interface{}
+	suite.fifo.SetExpirationHandler(func(value interface{}) {
+		expired = append(expired, value)
+		// the handler could access the queue
+		suite.fifo.GetCap()
+	})
+
+	suite.fifo.EnqueueWithTTL(1, ttlFIFOTestTTL)
+	suite.fifo.EnqueueWithTTL(2, time.Hour)
+	suite.fifo.EnqueueWithTTL(3, ttlFIFOTestTTL)
+	time.Sleep(ttlFIFOTestTTL)
+
+	suite.Equal(2, suite.fifo.Expire())
+	suite.Equal([]interface{}{1, 3}, expired)
+	suite.Equal(0, suite.fifo.Expire())
+	suite.Equal(1, suite.fifo.GetLen())
+}
+
+// ***************************************************************************************
+// ** DequeueOrWaitForNextElement
+// ***************************************************************************************
+
+// expired elements are skipped, then it waits for the next one
+func (suite *TTLFIFOTestSuite) TestDequeueOrWaitForNextElementSkipsExpired() {
+	suite.fifo.EnqueueWithTTL(1, ttlFIFOTestTTL)
+	time.Sleep(ttlFIFOTestTTL)
+
+	result := make(chan interface{}, 1)
+	go func() {
+		value, _ := suite.fifo.DequeueOrWaitForNextElement()
+		result <- value
+	}()
+	time.Sleep(10 * time.Millisecond)
+	suite.fifo.EnqueueWithTTL(2, time.Hour)
+
+	select {
+	case value := <-result:
+		suite.Equal(2, value)
+	case <-time.After(2 * time.Second):
+		suite.FailNow("too much time waiting for the enqueued element")
+	}
+}
+
+// context deadline exceeded while waiting
+func (suite *TTLFIFOTestSuite) TestDequeueOrWaitForNextElementWithContextDeadline() {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err := suite.fifo.DequeueOrWaitForNextElementWithContext(ctx)
+	suite.Equal(context.DeadlineExceeded, err)
+
+	suite.fifo.Enqueue(testValue)
+	suite.Equal(1, suite.fifo.GetLen())
+}
+
+// concurrent producers and consumers: every element is either dequeued or expired, once
+func (suite *TTLFIFOTestSuite) TestMultipleGRs() {
+	var (
+		totalGRs = 4
+		perGR    = 500
+		wg       sync.WaitGroup
+		mutex    sync.Mutex
+		seen     = make(map[interface{}]int)
+	)
+	see := func(value interface{}) {
+		mutex.Lock()
+		seen[value]++
+		mutex.Unlock()
+	}
+	suite.fifo.SetExpirationHandler(see)
+
+	for gr := 0; gr < totalGRs; gr++ {
+		wg.Add(2)
+		go func(gr int) {
+			defer wg.Done()
+			for i := 0; i < perGR; i++ {
+				suite.NoError(suite.fifo.EnqueueWithTTL(gr*perGR+i, time.Duration(i%3)*time.Millisecond))
+			}
+		}(gr)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < perGR; i++ {
+				if value, err := suite.fifo.Dequeue(); err == nil {
+					see(value)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	for {
+		value, err := suite.fifo.Dequeue()
+		if err != nil {
+			break
+		}
+		see(value)
+	}
+
+	suite.Len(seen, totalGRs*perGR)
+	for value, times := range seen {
+		suite.Equalf(1, times, "%v seen %v times", value, times)
+	}
+}
+
+// ***************************************************************************************
+// ** Clear
+// ***************************************************************************************
+
+// every element gets removed, the expired ones go to the expiration handler
+func (suite *TTLFIFOTestSuite) TestClear() {
+	expired := make([]interface{}, 0)
+	suite.fifo.SetExpirationHandler(func(value interface{}) {
+		expired = append(expired, value)
+	})
+	suite.fifo.EnqueueWithTTL(1, time.Millisecond)
+	suite.fifo.Enqueue(2)
+	time.Sleep(5 * time.Millisecond)
+
+	suite.NoError(suite.fifo.Clear())
+	suite.Equal(0, suite.fifo.GetLen())
+	suite.Equal([]interface{}{1}, expired)
+
+	suite.fifo.Lock()
+	suite.Equal(ErrLockedQueue, suite.fifo.Clear())
+}
+
+// ***************************************************************************************
+// ** Run suite
+// ***************************************************************************************
+
+func TestTTLFIFOTestSuite(t *testing.T) {
+	suite.Run(t, new(TTLFIFOTestSuite))
+}
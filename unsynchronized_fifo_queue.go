@@ -0,0 +1,237 @@
+package goconcurrentqueue
+
+import (
+	"fmt"
+)
+
+// UnsynchronizedFIFO (First In First Out) queue with no internal synchronization.
+// It is NOT concurrent-safe: it is meant to be embedded behind the caller's own synchronization (a mutex shared with
+// other state, a single owner goroutine, etc) to avoid paying for a second, redundant, lock on every operation.
+type UnsynchronizedFIFO struct {
+	slice    []interface{}
+	isLocked bool
+}
+
+// NewUnsynchronizedFIFO returns a new UnsynchronizedFIFO queue
+func NewUnsynchronizedFIFO() *UnsynchronizedFIFO {
+	ret := &UnsynchronizedFIFO{}
+	ret.initialize()
+
+	return ret
+}
+
+func (st *UnsynchronizedFIFO) initialize() {
+	st.slice = make([]interface{}, 0)
+}
+
+// Enqueue enqueues an element. Returns error if queue is locked.
+func (st *UnsynchronizedFIFO) Enqueue(value interface{}) error {
+	if st.isLocked {
+		return ErrLockedQueue
+	}
+
+	st.slice = append(st.slice, value)
+
+	return nil
+}
+
+// Dequeue dequeues an element. Returns error if queue is locked or empty.
+func (st *UnsynchronizedFIFO) Dequeue() (interface{}, error) {
+	if st.isLocked {
+		return nil, ErrLockedQueue
+	}
+
+	if len(st.slice) == 0 {
+		return nil, ErrEmptyQueue
+	}
+
+	elementToReturn := st.slice[0]
+	st.slice[0] = nil
+	st.slice = st.slice[1:]
+
+	return elementToReturn, nil
+}
+
+// DequeueOrWaitForNextElement dequeues an element (if exist). There is no way to wait for the next element without
+// internal synchronization (nobody else could enqueue it while this call blocks), so it behaves exactly as Dequeue.
+func (st *UnsynchronizedFIFO) DequeueOrWaitForNextElement() (interface{}, error) {
+	return st.Dequeue()
+}
+
+// Get returns an element's value and keeps the element at the queue
+func (st *UnsynchronizedFIFO) Get(index int) (interface{}, error) {
+	if st.isLocked {
+		return nil, ErrLockedQueue
+	}
+
+	if index < 0 || len(st.slice) <= index {
+		return nil, NewQueueError(QueueErrorCodeIndexOutOfBounds, fmt.Sprintf("index out of bounds: %v", index))
+	}
+
+	return st.slice[index], nil
+}
+
+// IndexOf returns the position of the first element equal to value, or -1 if there is none. equals(value, element)
+// compares them, nil equals compares them using == (it
+// panics on uncomparable elements, i.e. slices).
+func (st *UnsynchronizedFIFO) IndexOf(value interface{}, equals func(a, b interface{}) bool) int {
+	for i, element := range st.slice {
+		if isEqual(equals, value, element) {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// Contains returns true whether there is an element equal to value, see IndexOf
+func (st *UnsynchronizedFIFO) Contains(value interface{}, equals func(a, b interface{}) bool) bool {
+	return st.IndexOf(value, equals) >= 0
+}
+
+// ForEach calls fn for every enqueued element, in dequeue order, along with its position, until fn returns false. It
+// iterates over a snapshot, so fn could modify the queue.
+func (st *UnsynchronizedFIFO) ForEach(fn func(index int, value interface{}) bool) {
+	elements := make([]interface{}, len(st.slice))
+	copy(elements, st.slice)
+
+	for i, value := range elements {
+		if !fn(i, value) {
+			return
+		}
+	}
+}
+
+// Peek returns the first element, the next one to be dequeued, keeping it at the queue. Returns error if queue is
+// locked or empty.
+func (st *UnsynchronizedFIFO) Peek() (interface{}, error) {
+	if st.isLocked {
+		return nil, ErrLockedQueue
+	}
+
+	if len(st.slice) == 0 {
+		return nil, ErrEmptyQueue
+	}
+
+	return st.slice[0], nil
+}
+
+// PeekBack returns the last element, the latest enqueued one, keeping it at the queue. Returns error if queue is
+// locked or empty.
+func (st *UnsynchronizedFIFO) PeekBack() (interface{}, error) {
+	if st.isLocked {
+		return nil, ErrLockedQueue
+	}
+
+	if len(st.slice) == 0 {
+		return nil, ErrEmptyQueue
+	}
+
+	return st.slice[len(st.slice)-1], nil
+}
+
+// Remove removes an element from the queue
+func (st *UnsynchronizedFIFO) Remove(index int) error {
+	if st.isLocked {
+		return ErrLockedQueue
+	}
+
+	if index < 0 || len(st.slice) <= index {
+		return NewQueueError(QueueErrorCodeIndexOutOfBounds, fmt.Sprintf("index out of bounds: %v", index))
+	}
+
+	// remove the element
+	st.slice = append(st.slice[:index], st.slice[index+1:]...)
+
+	return nil
+}
+
+// RemoveWhere removes every element matching the predicate, keeping the order of the rest, and returns the number of
+// removed elements. Nothing gets removed if queue is locked.
+func (st *UnsynchronizedFIFO) RemoveWhere(predicate func(value interface{}) bool) int {
+	if st.isLocked {
+		return 0
+	}
+
+	kept := 0
+	for _, value := range st.slice {
+		if !predicate(value) {
+			st.slice[kept] = value
+			kept++
+		}
+	}
+	removed := len(st.slice) - kept
+	for i := kept; i < len(st.slice); i++ {
+		// release the references
+		st.slice[i] = nil
+	}
+	st.slice = st.slice[:kept]
+
+	return removed
+}
+
+// Filter keeps only the elements matching the predicate. See RemoveWhere.
+func (st *UnsynchronizedFIFO) Filter(predicate func(value interface{}) bool) {
+	st.RemoveWhere(func(value interface{}) bool {
+		return !predicate(value)
+	})
+}
+
+// Apply replaces every element with fn(element), keeping the order. Returns error if queue is locked (nothing gets
+// replaced).
+func (st *UnsynchronizedFIFO) Apply(fn func(value interface{}) interface{}) error {
+	if st.isLocked {
+		return ErrLockedQueue
+	}
+
+	for i, value := range st.slice {
+		st.slice[i] = fn(value)
+	}
+
+	return nil
+}
+
+// Clear removes every element, releasing the references. Returns error if queue is locked.
+func (st *UnsynchronizedFIFO) Clear() error {
+	if st.isLocked {
+		return ErrLockedQueue
+	}
+
+	st.slice = make([]interface{}, 0)
+
+	return nil
+}
+
+// Drain removes and returns every element, in order. It works on locked queues too. Returns an empty slice if there are
+// no elements.
+func (st *UnsynchronizedFIFO) Drain() []interface{} {
+	elements := st.slice
+	st.slice = make([]interface{}, 0)
+
+	return elements
+}
+
+// GetLen returns the number of enqueued elements
+func (st *UnsynchronizedFIFO) GetLen() int {
+	return len(st.slice)
+}
+
+// GetCap returns the queue's capacity
+func (st *UnsynchronizedFIFO) GetCap() int {
+	return cap(st.slice)
+}
+
+// Lock // Locks the queue. No enqueue/dequeue operations will be allowed after this point.
+func (st *UnsynchronizedFIFO) Lock() {
+	st.isLocked = true
+}
+
+// Unlock unlocks the queue
+func (st *UnsynchronizedFIFO) Unlock() {
+	st.isLocked = false
+}
+
+// IsLocked returns true whether the queue is locked
+func (st *UnsynchronizedFIFO) IsLocked() bool {
+	return st.isLocked
+}
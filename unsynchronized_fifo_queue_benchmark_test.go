@@ -0,0 +1,100 @@
+package goconcurrentqueue
+
+import (
+	"sync"
+	"testing"
+)
+
+// ***************************************************************************************
+// ** Enqueue
+// ***************************************************************************************
+
+// single goroutine - enqueue 1 element
+func BenchmarkUnsynchronizedFIFOEnqueueSingleGR(b *testing.B) {
+	fifo := NewUnsynchronizedFIFO()
+	for i := 0; i < b.N; i++ {
+		fifo.Enqueue(i)
+	}
+}
+
+// single goroutine - enqueue 1000 elements
+func BenchmarkUnsynchronizedFIFOEnqueue1000SingleGR(b *testing.B) {
+	fifo := NewUnsynchronizedFIFO()
+
+	for i := 0; i < b.N; i++ {
+		for c := 0; c < 1000; c++ {
+			fifo.Enqueue(c)
+		}
+	}
+}
+
+// ***************************************************************************************
+// ** Dequeue
+// ***************************************************************************************
+
+// single goroutine 1000 - dequeue 1000 elements
+func BenchmarkUnsynchronizedFIFODequeue1000SingleGR(b *testing.B) {
+	// do not measure the queue initialization
+	b.StopTimer()
+	fifo := NewUnsynchronizedFIFO()
+
+	for i := 0; i < b.N; i++ {
+		// do not measure the enqueueing process
+		b.StopTimer()
+		for i := 0; i < 1000; i++ {
+			fifo.Enqueue(i)
+		}
+
+		// measure the dequeueing process
+		b.StartTimer()
+		for i := 0; i < 1000; i++ {
+			fifo.Dequeue()
+		}
+	}
+}
+
+// ***************************************************************************************
+// ** Behind an external mutex (UnsynchronizedFIFO vs FIFO)
+// ***************************************************************************************
+
+// multiple goroutines - enqueue + dequeue 100 elements per gr, guarded by the caller's mutex
+func BenchmarkUnsynchronizedFIFOBehindMutex100MultipleGRs(b *testing.B) {
+	var (
+		mutex sync.Mutex
+		fifo  = NewUnsynchronizedFIFO()
+	)
+
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			mutex.Lock()
+			for c := 0; c < 100; c++ {
+				fifo.Enqueue(c)
+			}
+			for c := 0; c < 100; c++ {
+				fifo.Dequeue()
+			}
+			mutex.Unlock()
+		}
+	})
+}
+
+// multiple goroutines - enqueue + dequeue 100 elements per gr, guarded by the caller's mutex (double locking)
+func BenchmarkFIFOBehindMutex100MultipleGRs(b *testing.B) {
+	var (
+		mutex sync.Mutex
+		fifo  = NewFIFO()
+	)
+
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			mutex.Lock()
+			for c := 0; c < 100; c++ {
+				fifo.Enqueue(c)
+			}
+			for c := 0; c < 100; c++ {
+				fifo.Dequeue()
+			}
+			mutex.Unlock()
+		}
+	})
+}
@@ -0,0 +1,317 @@
+package goconcurrentqueue
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type UnsynchronizedFIFOTestSuite struct {
+	suite.Suite
+	fifo *UnsynchronizedFIFO
+}
+
+func (suite *UnsynchronizedFIFOTestSuite) SetupTest() {
+	suite.fifo = NewUnsynchronizedFIFO()
+}
+
+// ***************************************************************************************
+// ** Queue initialization
+// ***************************************************************************************
+
+// no elements at initialization
+func (suite *UnsynchronizedFIFOTestSuite) TestNoElementsAtInitialization() {
+	length := suite.fifo.GetLen()
+	suite.Equalf(0, length, "No elements expected at initialization, currently: %v", length)
+}
+
+// unlocked at initialization
+func (suite *UnsynchronizedFIFOTestSuite) TestNoLockedAtInitialization() {
+	suite.False(suite.fifo.IsLocked(), "Queue must be unlocked at initialization")
+}
+
+// Queue interface
+func (suite *UnsynchronizedFIFOTestSuite) TestQueueInterface() {
+	var queue Queue = suite.fifo
+	suite.NotNil(queue)
+}
+
+// ***************************************************************************************
+// ** Enqueue && GetLen && GetCap
+// ***************************************************************************************
+
+// single enqueue lock verification
+func (suite *UnsynchronizedFIFOTestSuite) TestEnqueueLockSingleGR() {
+	suite.NoError(suite.fifo.Enqueue(1), "Unlocked queue allows to enqueue elements")
+
+	suite.fifo.Lock()
+	err := suite.fifo.Enqueue(1)
+	suite.Error(err, "Locked queue does not allow to enqueue elements")
+
+	// verify custom error: code: QueueErrorCodeLockedQueue
+	customError, ok := err.(*QueueError)
+	suite.True(ok, "Expected error type: QueueError")
+	// verify custom error code
+	suite.Equalf(QueueErrorCodeLockedQueue, customError.Code(), "Expected code: '%v'", QueueErrorCodeLockedQueue)
+
+	suite.fifo.Unlock()
+	suite.NoError(suite.fifo.Enqueue(1), "Unlocked queue allows to enqueue elements")
+}
+
+// single enqueue (1 element, 1 goroutine)
+func (suite *UnsynchronizedFIFOTestSuite) TestEnqueueLenSingleGR() {
+	suite.fifo.Enqueue(testValue)
+	suite.Equal(1, suite.fifo.GetLen())
+
+	suite.fifo.Enqueue(5)
+	suite.Equal(2, suite.fifo.GetLen())
+	suite.Equal(cap(suite.fifo.slice), suite.fifo.GetCap())
+}
+
+// ***************************************************************************************
+// ** Dequeue && DequeueOrWaitForNextElement
+// ***************************************************************************************
+
+// dequeue elements in FIFO order
+func (suite *UnsynchronizedFIFOTestSuite) TestDequeueSingleGR() {
+	for i := 0; i < 10; i++ {
+		suite.fifo.Enqueue(i)
+	}
+
+	for i := 0; i < 10; i++ {
+		value, err := suite.fifo.Dequeue()
+		suite.NoError(err)
+		suite.Equal(i, value)
+	}
+	suite.Equal(0, suite.fifo.GetLen())
+}
+
+// dequeue from an empty queue
+func (suite *UnsynchronizedFIFOTestSuite) TestDequeueEmptyQueueSingleGR() {
+	value, err := suite.fifo.Dequeue()
+	suite.Nil(value)
+	suite.Error(err)
+
+	customError, ok := err.(*QueueError)
+	suite.True(ok, "Expected error type: QueueError")
+	suite.Equalf(QueueErrorCodeEmptyQueue, customError.Code(), "Expected code: '%v'", QueueErrorCodeEmptyQueue)
+}
+
+// dequeue from a locked queue
+func (suite *UnsynchronizedFIFOTestSuite) TestDequeueLockSingleGR() {
+	suite.fifo.Enqueue(1)
+	suite.fifo.Lock()
+
+	value, err := suite.fifo.Dequeue()
+	suite.Nil(value)
+	suite.Equal(ErrLockedQueue, err)
+}
+
+// DequeueOrWaitForNextElement never blocks
+func (suite *UnsynchronizedFIFOTestSuite) TestDequeueOrWaitForNextElementSingleGR() {
+	value, err := suite.fifo.DequeueOrWaitForNextElement()
+	suite.Nil(value)
+	suite.Equal(ErrEmptyQueue, err)
+
+	suite.fifo.Enqueue(testValue)
+	value, err = suite.fifo.DequeueOrWaitForNextElement()
+	suite.NoError(err)
+	suite.Equal(testValue, value)
+}
+
+// ***************************************************************************************
+// ** Get && Remove
+// ***************************************************************************************
+
+// get elements by position
+func (suite *UnsynchronizedFIFOTestSuite) TestGetSingleGR() {
+	suite.fifo.Enqueue(testValue)
+	suite.fifo.Enqueue(5)
+
+	value, err := suite.fifo.Get(1)
+	suite.NoError(err)
+	suite.Equal(5, value)
+	suite.Equal(2, suite.fifo.GetLen(), "Get should keep the element at the queue")
+
+	_, err = suite.fifo.Get(2)
+	suite.Error(err)
+	_, err = suite.fifo.Get(-1)
+	suite.Error(err)
+}
+
+// remove elements by position
+func (suite *UnsynchronizedFIFOTestSuite) TestRemoveSingleGR() {
+	for i := 0; i < 3; i++ {
+		suite.fifo.Enqueue(i)
+	}
+
+	suite.NoError(suite.fifo.Remove(1))
+	suite.Equal(2, suite.fifo.GetLen())
+
+	value, _ := suite.fifo.Get(1)
+	suite.Equal(2, value)
+
+	err := suite.fifo.Remove(2)
+	customError, ok := err.(*QueueError)
+	suite.True(ok, "Expected error type: QueueError")
+	suite.Equal(QueueErrorCodeIndexOutOfBounds, customError.Code())
+}
+
+// ***************************************************************************************
+// ** Peek && PeekBack
+// ***************************************************************************************
+
+// first and last elements, kept at the queue
+func (suite *UnsynchronizedFIFOTestSuite) TestPeekSingleGR() {
+	for i := 0; i < 3; i++ {
+		suite.fifo.Enqueue(i)
+	}
+
+	value, err := suite.fifo.Peek()
+	suite.NoError(err)
+	suite.Equal(0, value)
+
+	value, err = suite.fifo.PeekBack()
+	suite.NoError(err)
+	suite.Equal(2, value)
+	suite.Equal(3, suite.fifo.GetLen())
+}
+
+// empty and locked queue
+func (suite *UnsynchronizedFIFOTestSuite) TestPeekErrors() {
+	_, err := suite.fifo.Peek()
+	suite.Equal(ErrEmptyQueue, err)
+	_, err = suite.fifo.PeekBack()
+	suite.Equal(ErrEmptyQueue, err)
+
+	suite.fifo.Enqueue(1)
+	suite.fifo.Lock()
+	_, err = suite.fifo.Peek()
+	suite.Equal(ErrLockedQueue, err)
+	_, err = suite.fifo.PeekBack()
+	suite.Equal(ErrLockedQueue, err)
+}
+
+// ***************************************************************************************
+// ** ForEach
+// ***************************************************************************************
+
+// every element gets visited, in order, until fn returns false
+func (suite *UnsynchronizedFIFOTestSuite) TestForEach() {
+	for i := 0; i < 5; i++ {
+		suite.fifo.Enqueue(i)
+	}
+
+	visited := make([]interface{}, 0)
+	suite.fifo.ForEach(func(index int, value interface{}) bool {
+		suite.Equal(len(visited), index)
+		visited = append(visited, value)
+		suite.fifo.Dequeue()
+		return index < 3
+	})
+	suite.Equal([]interface{}{0, 1, 2, 3}, visited)
+	suite.Equal(1, suite.fifo.GetLen())
+}
+
+// ***************************************************************************************
+// ** IndexOf && Contains
+// ***************************************************************************************
+
+// first matching position, -1 if there is none
+func (suite *UnsynchronizedFIFOTestSuite) TestIndexOf() {
+	for _, value := range []string{"a", "b", "b"} {
+		suite.fifo.Enqueue(value)
+	}
+
+	suite.Equal(1, suite.fifo.IndexOf("b", nil))
+	suite.Equal(-1, suite.fifo.IndexOf("c", nil))
+	suite.True(suite.fifo.Contains("a", nil))
+	suite.True(suite.fifo.Contains("A", func(a, b interface{}) bool { return strings.EqualFold(a.(string), b.(string)) }))
+}
+
+// ***************************************************************************************
+// ** RemoveWhere
+// ***************************************************************************************
+
+// matching elements get removed, the rest keep their order
+func (suite *UnsynchronizedFIFOTestSuite) TestRemoveWhere() {
+	for i := 0; i < 5; i++ {
+		suite.fifo.Enqueue(i)
+	}
+
+	suite.Equal(2, suite.fifo.RemoveWhere(func(value interface{}) bool { return value.(int) > 2 }))
+	suite.Equal([]interface{}{0, 1, 2}, suite.fifo.Drain())
+
+	suite.fifo.Enqueue(1)
+	suite.fifo.Lock()
+	suite.Equal(0, suite.fifo.RemoveWhere(func(value interface{}) bool { return true }))
+	suite.Equal(1, suite.fifo.GetLen())
+}
+
+// only the matching elements are kept
+func (suite *UnsynchronizedFIFOTestSuite) TestFilter() {
+	for i := 0; i < 5; i++ {
+		suite.fifo.Enqueue(i)
+	}
+
+	suite.fifo.Filter(func(value interface{}) bool { return value.(int)%2 == 1 })
+	suite.Equal([]interface{}{1, 3}, suite.fifo.Drain())
+}
+
+// ***************************************************************************************
+// ** Apply
+// ***************************************************************************************
+
+// every element gets replaced, in place
+func (suite *UnsynchronizedFIFOTestSuite) TestApply() {
+	for i := 0; i < 3; i++ {
+		suite.fifo.Enqueue(i)
+	}
+
+	suite.NoError(suite.fifo.Apply(func(value interface{}) interface{} { return value.(int) * 10 }))
+	suite.Equal([]interface{}{0, 10, 20}, suite.fifo.Drain())
+
+	suite.fifo.Enqueue(1)
+	suite.fifo.Lock()
+	suite.Equal(ErrLockedQueue, suite.fifo.Apply(func(value interface{}) interface{} { return nil }))
+	suite.fifo.Unlock()
+	suite.Equal([]interface{}{1}, suite.fifo.Drain())
+}
+
+// ***************************************************************************************
+// ** Clear
+// ***************************************************************************************
+
+// every element gets removed
+func (suite *UnsynchronizedFIFOTestSuite) TestClear() {
+	for i := 0; i < 3; i++ {
+		suite.fifo.Enqueue(i)
+	}
+
+	suite.NoError(suite.fifo.Clear())
+	suite.Equal(0, suite.fifo.GetLen())
+
+	suite.fifo.Lock()
+	suite.Equal(ErrLockedQueue, suite.fifo.Clear())
+}
+
+// every element gets returned, in order, no matter whether the queue is locked
+func (suite *UnsynchronizedFIFOTestSuite) TestDrain() {
+	for i := 0; i < 3; i++ {
+		suite.fifo.Enqueue(i)
+	}
+	suite.fifo.Lock()
+
+	suite.Equal([]interface{}{0, 1, 2}, suite.fifo.Drain())
+	suite.Equal(0, suite.fifo.GetLen())
+	suite.Equal([]interface{}{}, suite.fifo.Drain())
+}
+
+// ***************************************************************************************
+// ** Run suite
+// ***************************************************************************************
+
+func TestUnsynchronizedFIFOTestSuite(t *testing.T) {
+	suite.Run(t, new(UnsynchronizedFIFOTestSuite))
+}